@@ -0,0 +1,108 @@
+// Package assignment decides who a generated campaign task instance goes to
+// and when it is due, from the defaults on its master task.
+package assignment
+
+import (
+	"sort"
+	"time"
+)
+
+// Strategies for resolving a team to one of its members.
+const (
+	// Lead picks the team lead, or the least loaded member if the team has
+	// no lead.
+	Lead = "lead"
+	// RoundRobin picks the member after the one the team last assigned to.
+	RoundRobin = "round_robin"
+	// LeastLoaded picks the member with the fewest open task instances.
+	LeastLoaded = "least_loaded"
+)
+
+// DefaultStrategy is used for teams that have not chosen one.
+const DefaultStrategy = Lead
+
+// Anchors a due date offset is measured from.
+const (
+	AnchorStart = "start"
+	AnchorEnd   = "end"
+)
+
+// IsValidStrategy reports whether strategy is a known assignment strategy.
+func IsValidStrategy(strategy string) bool {
+	return strategy == Lead || strategy == RoundRobin || strategy == LeastLoaded
+}
+
+// IsValidAnchor reports whether anchor is a known due date anchor.
+func IsValidAnchor(anchor string) bool {
+	return anchor == AnchorStart || anchor == AnchorEnd
+}
+
+// Member is a team member as seen by the strategies.
+type Member struct {
+	UserID string
+	IsLead bool
+	// OpenTasks is the number of task instances assigned to the member that
+	// are not closed or failed.
+	OpenTasks int
+}
+
+// Pick returns the member of a team the strategy assigns the next task
+// instance to. lastAssigned is the user the team last assigned to, if any.
+// It returns false for a team without members. Unknown strategies fall back
+// to DefaultStrategy.
+func Pick(strategy string, members []Member, lastAssigned string) (string, bool) {
+	if len(members) == 0 {
+		return "", false
+	}
+	sorted := append([]Member(nil), members...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].UserID < sorted[j].UserID })
+
+	switch strategy {
+	case RoundRobin:
+		for _, m := range sorted {
+			if m.UserID > lastAssigned {
+				return m.UserID, true
+			}
+		}
+		return sorted[0].UserID, true
+	case LeastLoaded:
+		return leastLoaded(sorted), true
+	default:
+		for _, m := range sorted {
+			if m.IsLead {
+				return m.UserID, true
+			}
+		}
+		return leastLoaded(sorted), true
+	}
+}
+
+// leastLoaded returns the member with the fewest open tasks, the first one by
+// user ID on a tie.
+func leastLoaded(sorted []Member) string {
+	best := sorted[0]
+	for _, m := range sorted[1:] {
+		if m.OpenTasks < best.OpenTasks {
+			best = m
+		}
+	}
+	return best.UserID
+}
+
+// DueDate returns the date offsetDays days after the campaign's start or end
+// date, as chosen by anchor; a negative offset counts back. It returns nil if
+// the anchor is unknown or the campaign has no such date.
+func DueDate(anchor string, offsetDays int, campaignStart, campaignEnd *time.Time) *time.Time {
+	var base *time.Time
+	switch anchor {
+	case AnchorStart:
+		base = campaignStart
+	case AnchorEnd:
+		base = campaignEnd
+	}
+	if base == nil {
+		return nil
+	}
+	due := base.AddDate(0, 0, offsetDays)
+	return &due
+}
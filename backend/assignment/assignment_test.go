@@ -0,0 +1,53 @@
+package assignment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPick(t *testing.T) {
+	members := []Member{
+		{UserID: "c", OpenTasks: 1},
+		{UserID: "a", OpenTasks: 3},
+		{UserID: "b", IsLead: true, OpenTasks: 5},
+	}
+
+	tests := []struct {
+		name         string
+		strategy     string
+		members      []Member
+		lastAssigned string
+		want         string
+		wantOK       bool
+	}{
+		{"lead", Lead, members, "", "b", true},
+		{"lead falls back to least loaded", Lead, members[:2], "", "c", true},
+		{"unknown strategy uses the lead", "random", members, "", "b", true},
+		{"least loaded", LeastLoaded, members, "", "c", true},
+		{"least loaded tie goes to the first user", LeastLoaded, []Member{{UserID: "y"}, {UserID: "x"}}, "", "x", true},
+		{"round robin starts at the first user", RoundRobin, members, "", "a", true},
+		{"round robin moves on", RoundRobin, members, "a", "b", true},
+		{"round robin wraps around", RoundRobin, members, "c", "a", true},
+		{"round robin after a removed member", RoundRobin, members, "bb", "c", true},
+		{"empty team", LeastLoaded, nil, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Pick(tt.strategy, tt.members, tt.lastAssigned)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC), *DueDate(AnchorEnd, -14, &start, &end))
+	assert.Equal(t, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), *DueDate(AnchorStart, 30, &start, &end))
+	assert.Nil(t, DueDate(AnchorEnd, -14, &start, nil), "campaign without an end date")
+	assert.Nil(t, DueDate("", 10, &start, &end), "no anchor")
+}
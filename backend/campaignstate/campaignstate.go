@@ -0,0 +1,129 @@
+// Package campaignstate defines the campaign lifecycle: the statuses a
+// campaign moves through, which transitions are allowed, the conditions each
+// transition must meet and how a campaign rolls over into its next period.
+package campaignstate
+
+import "fmt"
+
+const (
+	Draft         = "Draft"
+	Active        = "Active"
+	InProgress    = "In Progress"
+	PendingReview = "Pending Review"
+	Completed     = "Completed"
+	Archived      = "Archived"
+)
+
+// transitions lists, per status, the statuses a campaign may move to next.
+// Completion is only reachable through a sign-off.
+var transitions = map[string][]string{
+	Draft:         {Active, Archived},
+	Active:        {InProgress, PendingReview, Archived},
+	InProgress:    {Active, PendingReview, Archived},
+	PendingReview: {Active, InProgress, Completed},
+	Completed:     {Archived},
+	Archived:      {},
+}
+
+// terminalTaskStatuses are the campaign task instance statuses that count as
+// done when a campaign is put up for review.
+var terminalTaskStatuses = []string{"Closed", "Failed"}
+
+// Facts are what the guards of a transition look at.
+type Facts struct {
+	// ApplicableRequirements is the number of selected requirements marked
+	// applicable. A campaign cannot be activated without any.
+	ApplicableRequirements int
+	// OpenTaskInstances is the number of task instances not in a terminal
+	// status. A campaign can only go to review when there are none.
+	OpenTaskInstances int
+	// SignedOff reports whether the transition carries an approver's sign-off,
+	// which completing a campaign requires.
+	SignedOff bool
+}
+
+// TransitionError explains why a transition was refused.
+type TransitionError struct {
+	From, To string
+	Reason   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move campaign from %q to %q: %s", e.From, e.To, e.Reason)
+}
+
+// IsValid reports whether status is a known campaign status.
+func IsValid(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// IsInitial reports whether a campaign may be created in status. Campaigns
+// created as Active get their task instances straight away.
+func IsInitial(status string) bool {
+	return status == Draft || status == Active
+}
+
+// IsFrozen reports whether campaigns in status are read-only: their task
+// instances, evidence and selected requirements may no longer change.
+func IsFrozen(status string) bool {
+	return status == Completed || status == Archived
+}
+
+// GeneratesTaskInstances reports whether selected requirements get task
+// instances while the campaign is in status. Drafts only record the scope,
+// and completed or archived campaigns no longer change.
+func GeneratesTaskInstances(status string) bool {
+	return status != Draft && !IsFrozen(status) && IsValid(status)
+}
+
+// GeneratesTaskInstancesOn reports whether moving a campaign from one status
+// to another creates the task instances of its selected requirements, as
+// activating a draft does. Archiving a draft creates none.
+func GeneratesTaskInstancesOn(from, to string) bool {
+	return from == Draft && GeneratesTaskInstances(to)
+}
+
+// TerminalTaskStatuses returns the task instance statuses that count as done.
+func TerminalTaskStatuses() []string {
+	return append([]string(nil), terminalTaskStatuses...)
+}
+
+// Next returns the statuses a campaign in status may move to.
+func Next(status string) []string {
+	return append([]string(nil), transitions[status]...)
+}
+
+// Check returns a *TransitionError if a campaign may not move from one status
+// to another given facts, and nil otherwise.
+func Check(from, to string, facts Facts) error {
+	if !IsValid(to) {
+		return &TransitionError{From: from, To: to, Reason: "unknown status"}
+	}
+	allowed := false
+	for _, next := range transitions[from] {
+		if next == to {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return &TransitionError{From: from, To: to, Reason: "transition is not allowed"}
+	}
+
+	switch to {
+	case Active:
+		if from == Draft && facts.ApplicableRequirements == 0 {
+			return &TransitionError{From: from, To: to, Reason: "no applicable requirements are selected"}
+		}
+	case PendingReview:
+		if facts.OpenTaskInstances > 0 {
+			return &TransitionError{From: from, To: to, Reason: fmt.Sprintf("%d task instances are not closed or failed", facts.OpenTaskInstances)}
+		}
+	case Completed:
+		if !facts.SignedOff {
+			return &TransitionError{From: from, To: to, Reason: "completion requires a sign-off"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package campaignstate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		facts    Facts
+		wantErr  bool
+	}{
+		{"activate draft", Draft, Active, Facts{ApplicableRequirements: 2}, false},
+		{"activate empty draft", Draft, Active, Facts{}, true},
+		{"start work", Active, InProgress, Facts{}, false},
+		{"review with open tasks", Active, PendingReview, Facts{OpenTaskInstances: 1}, true},
+		{"review when all done", InProgress, PendingReview, Facts{}, false},
+		{"send back from review", PendingReview, Active, Facts{}, false},
+		{"complete without sign-off", PendingReview, Completed, Facts{}, true},
+		{"complete with sign-off", PendingReview, Completed, Facts{SignedOff: true}, false},
+		{"skip review", Active, Completed, Facts{SignedOff: true}, true},
+		{"reopen completed", Completed, Active, Facts{}, true},
+		{"archive completed", Completed, Archived, Facts{}, false},
+		{"archive draft", Draft, Archived, Facts{}, false},
+		{"back to draft", Active, Draft, Facts{}, true},
+		{"unknown target", Draft, "Started", Facts{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.from, tt.to, tt.facts)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var transitionErr *TransitionError
+			assert.True(t, errors.As(err, &transitionErr))
+		})
+	}
+}
+
+func TestStatusProperties(t *testing.T) {
+	assert.True(t, IsFrozen(Completed))
+	assert.True(t, IsFrozen(Archived))
+	assert.False(t, IsFrozen(PendingReview))
+
+	assert.False(t, GeneratesTaskInstances(Draft))
+	assert.True(t, GeneratesTaskInstances(Active))
+	assert.False(t, GeneratesTaskInstances("Unknown"))
+	assert.False(t, GeneratesTaskInstances(Completed))
+	assert.False(t, GeneratesTaskInstances(Archived))
+
+	assert.True(t, GeneratesTaskInstancesOn(Draft, Active))
+	assert.False(t, GeneratesTaskInstancesOn(Draft, Archived), "archiving a draft freezes it without task instances")
+	assert.False(t, GeneratesTaskInstancesOn(Active, InProgress))
+	assert.False(t, GeneratesTaskInstancesOn(Completed, Archived))
+
+	assert.True(t, IsInitial(Active))
+	assert.False(t, IsInitial(Completed))
+
+	next := Next(Draft)
+	next[0] = Completed
+	assert.Equal(t, []string{Active, Archived}, Next(Draft), "Next returns a copy")
+}
+
+func TestRollover(t *testing.T) {
+	date := func(y int, m time.Month, d int) *time.Time {
+		t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	t.Run("defaults to a year later", func(t *testing.T) {
+		r := NewRollover(date(2024, 1, 1), date(2024, 12, 31), nil, nil)
+		assert.Equal(t, date(2025, 1, 1), r.StartDate)
+		assert.Equal(t, date(2025, 12, 31), r.EndDate)
+		assert.Equal(t, date(2025, 3, 15), r.ShiftDueDate(date(2024, 3, 15)))
+		assert.Nil(t, r.ShiftDueDate(nil))
+	})
+
+	t.Run("shifts by the new start date", func(t *testing.T) {
+		r := NewRollover(date(2024, 1, 1), date(2024, 3, 31), date(2024, 4, 1), nil)
+		assert.Equal(t, date(2024, 4, 1), r.StartDate)
+		assert.Equal(t, date(2024, 6, 30), r.EndDate, "moved by the same 91 days")
+		assert.Equal(t, date(2024, 5, 1), r.ShiftDueDate(date(2024, 1, 31)))
+	})
+
+	t.Run("shifts by the end date when the source has no start", func(t *testing.T) {
+		r := NewRollover(nil, date(2024, 6, 30), date(2025, 1, 1), date(2025, 6, 30))
+		assert.Equal(t, date(2025, 1, 1), r.StartDate)
+		assert.Equal(t, date(2025, 6, 30), r.EndDate)
+		assert.Equal(t, date(2025, 6, 1), r.ShiftDueDate(date(2024, 6, 1)))
+	})
+
+	t.Run("keeps due dates inside a shorter period", func(t *testing.T) {
+		r := NewRollover(date(2024, 1, 1), date(2024, 12, 31), date(2025, 1, 1), date(2025, 3, 31))
+		assert.Equal(t, date(2025, 2, 1), r.ShiftDueDate(date(2024, 2, 1)), "anchored to the start")
+		assert.Equal(t, date(2025, 3, 15), r.ShiftDueDate(date(2024, 12, 15)), "anchored to the end")
+		assert.Equal(t, date(2025, 3, 31), r.ShiftDueDate(date(2024, 6, 30)), "clamped to the new end")
+		assert.Equal(t, date(2025, 1, 1), r.ShiftDueDate(date(2023, 12, 1)), "clamped to the new start")
+	})
+}
@@ -0,0 +1,66 @@
+package campaignstate
+
+import "time"
+
+// Rollover maps a campaign's audit period onto the period of its clone.
+type Rollover struct {
+	StartDate, EndDate *time.Time
+	shift              func(time.Time) time.Time
+}
+
+// NewRollover returns the rollover from a source period to a new one. Dates
+// left nil in the new period are the source's moved by the same amount as the
+// given ones; with neither given, the new period is the source a year later.
+// The shift is measured between the start dates, or the end dates when a
+// start date is missing on either side. When both periods are fully known,
+// each date keeps its distance to the nearer boundary of the source period,
+// so dates near the end stay near the end of a shorter or longer period.
+func NewRollover(sourceStart, sourceEnd, start, end *time.Time) Rollover {
+	var shift func(time.Time) time.Time
+	switch {
+	case sourceStart != nil && sourceEnd != nil && start != nil && end != nil:
+		startDelta, endDelta := start.Sub(*sourceStart), end.Sub(*sourceEnd)
+		middle := sourceStart.Add(sourceEnd.Sub(*sourceStart) / 2)
+		shift = func(t time.Time) time.Time {
+			if t.After(middle) {
+				return t.Add(endDelta)
+			}
+			return t.Add(startDelta)
+		}
+	case sourceStart != nil && start != nil:
+		delta := start.Sub(*sourceStart)
+		shift = func(t time.Time) time.Time { return t.Add(delta) }
+	case sourceEnd != nil && end != nil:
+		delta := end.Sub(*sourceEnd)
+		shift = func(t time.Time) time.Time { return t.Add(delta) }
+	default:
+		shift = func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }
+	}
+
+	r := Rollover{StartDate: start, EndDate: end, shift: shift}
+	if r.StartDate == nil && sourceStart != nil {
+		shifted := shift(*sourceStart)
+		r.StartDate = &shifted
+	}
+	if r.EndDate == nil && sourceEnd != nil {
+		shifted := shift(*sourceEnd)
+		r.EndDate = &shifted
+	}
+	return r
+}
+
+// ShiftDueDate moves a due date of the source period into the new period,
+// clamping it to the new period's bounds.
+func (r Rollover) ShiftDueDate(due *time.Time) *time.Time {
+	if due == nil {
+		return nil
+	}
+	shifted := r.shift(*due)
+	if r.StartDate != nil && shifted.Before(*r.StartDate) {
+		shifted = *r.StartDate
+	}
+	if r.EndDate != nil && shifted.After(*r.EndDate) {
+		shifted = *r.EndDate
+	}
+	return &shifted
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/tls"
 	"log"
 	"net/http"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/vdparikh/compliance-automation/backend/handlers"
+	"github.com/vdparikh/compliance-automation/backend/internalapi"
 	"github.com/vdparikh/compliance-automation/backend/middleware"
 	"github.com/vdparikh/compliance-automation/backend/queue"
 	"github.com/vdparikh/compliance-automation/backend/store"
@@ -22,6 +24,8 @@ const (
 	defaultDBPort     = "5432"
 	defaultDBName     = "compliance"
 	defaultServerPort = ":8080"
+
+	defaultInternalAPIAddr = ":8091"
 )
 
 func main() {
@@ -102,6 +106,9 @@ func main() {
 	integrationHandler := handlers.NewIntegrationHandler(dbStore)           // Instantiate IntegrationHandler
 	systemDefinitionHandler := handlers.NewSystemDefinitionHandler(dbStore) // Instantiate SystemDefinitionHandler
 	riskHandler := handlers.NewRiskHandler(dbStore)                         // New Risk Handler
+	vulnerabilityHandler := handlers.NewVulnerabilityHandler(dbStore)
+	configSnapshotHandler := handlers.NewConfigSnapshotHandler(dbStore)
+	testExecutionHandler := handlers.NewTestExecutionHandler(dbStore, q)
 
 	apiV1 := router.Group("/api")
 
@@ -123,6 +130,7 @@ func main() {
 
 		// Endpoint for fetching dynamic check type configurations for the frontend
 		api.GET("/integration-check-types", integrationHandler.GetIntegrationCheckTypesHandler)
+		api.GET("/plugins/:id/versions", integrationHandler.GetPluginVersionsHandler)
 		api.GET("/system-type-definitions", systemDefinitionHandler.GetSystemTypeDefinitionsHandler) // New endpoint
 		api.POST("/auth/change-password", authAPI.ChangePasswordHandler)
 
@@ -132,6 +140,10 @@ func main() {
 		api.PUT("/tasks/:id", taskHandler.UpdateTaskHandler)
 		api.POST("/tasks/:id/link-requirements", taskHandler.LinkTaskToRequirementsHandler)
 		api.POST("/tasks/:id/unlink-requirements", taskHandler.UnlinkTaskFromRequirementsHandler)
+		api.POST("/tasks/:id/result-policy/dry-run", taskHandler.DryRunResultPolicyHandler)
+		api.POST("/tasks/:id/test-execution", testExecutionHandler.TestMasterTaskExecutionHandler)
+		api.POST("/checks/test", testExecutionHandler.TestCheckHandler)
+		api.GET("/checks/test/:executionId", testExecutionHandler.GetTestExecutionHandler)
 
 		api.POST("/requirements", requirementHandler.CreateRequirementHandler)
 		api.GET("/requirements", requirementHandler.GetRequirementsHandler)
@@ -147,6 +159,11 @@ func main() {
 		api.GET("/campaigns/:id", campaignHandler.GetCampaignByIDHandler)
 		api.PUT("/campaigns/:id", campaignHandler.UpdateCampaignHandler)
 		api.DELETE("/campaigns/:id", campaignHandler.DeleteCampaignHandler)
+		api.POST("/campaigns/:id/transitions", campaignHandler.TransitionCampaignHandler)
+		api.POST("/campaigns/:id/sign-off", campaignHandler.SignOffCampaignHandler)
+		api.POST("/campaigns/:id/clone", campaignHandler.CloneCampaignHandler)
+		api.GET("/campaigns/:id/comparison", campaignHandler.GetCampaignComparisonHandler)
+		api.GET("/campaigns/:id/compliance", campaignHandler.GetCampaignComplianceHandler)
 		api.GET("/campaigns/:id/requirements", campaignHandler.GetCampaignSelectedRequirementsHandler)
 		api.GET("/campaigns/:id/task-instances", campaignHandler.GetCampaignTaskInstancesHandler)
 
@@ -164,6 +181,16 @@ func main() {
 		api.POST("/campaign-task-instances/:id/copy-evidence", campaignHandler.CopyEvidenceHandler)
 		api.POST("/campaign-task-instances/:id/execute", campaignHandler.ExecuteCampaignTaskInstanceHandler)
 		api.GET("/campaign-task-instances/:id/results", campaignHandler.GetCampaignTaskInstanceResultsHandler)
+		api.GET("/results/trend", campaignHandler.GetResultTrendHandler)
+		api.GET("/campaign-task-instances/:id/executions/:execId/logs", campaignHandler.GetTaskExecutionLogsHandler)
+		api.GET("/campaign-task-instances/:id/executions/:execId/stream", campaignHandler.StreamTaskExecutionHandler)
+		api.POST("/campaign-task-instances/:id/executions/:execId/cancel", campaignHandler.CancelTaskExecutionHandler)
+
+		// Vulnerability scanner report ingestion (Trivy, Grype, Nessus, SARIF)
+		api.POST("/campaign-task-instances/:id/vulnerability-reports", vulnerabilityHandler.IngestVulnerabilityReportHandler)
+		api.GET("/campaign-task-instances/:id/vulnerability-reports", vulnerabilityHandler.GetVulnerabilityReportsHandler)
+		api.GET("/vulnerability-reports/:reportId/findings", vulnerabilityHandler.GetVulnerabilityReportFindingsHandler)
+		api.GET("/vulnerability-history", vulnerabilityHandler.GetAssetVulnerabilityHistoryHandler)
 
 		api.PUT("/evidence/:evidenceId/review", handlers.HandleReviewEvidence(dbStore))
 
@@ -173,6 +200,12 @@ func main() {
 		systemRoutes.GET("/:id", systemIntegrationHandler.GetConnectedSystemHandler)
 		systemRoutes.PUT("/:id", systemIntegrationHandler.UpdateConnectedSystemHandler)
 		systemRoutes.DELETE("/:id", systemIntegrationHandler.DeleteConnectedSystemHandler)
+		systemRoutes.GET("/:id/snapshots", configSnapshotHandler.GetSystemConfigSnapshotsHandler)
+
+		// Configuration snapshots and drift
+		api.GET("/config-snapshots/:snapshotId", configSnapshotHandler.GetConfigSnapshotHandler)
+		api.GET("/config-snapshots/:snapshotId/diff", configSnapshotHandler.DiffConfigSnapshotsHandler)
+		api.GET("/config-drift", configSnapshotHandler.GetConfigDriftHandler)
 
 		// Document Routes
 		documents := api.Group("/documents")
@@ -225,6 +258,31 @@ func main() {
 	// The 'true' parameter enables SPA mode (serves index.html for unmatched routes if file not found)
 	router.Use(static.Serve("/", static.LocalFile(frontendDir, true)))
 
+	// Internal API for the integrations worker, served on its own listener
+	if secret := os.Getenv("INTERNAL_API_SECRET"); secret != "" {
+		internalServer, err := internalapi.NewServer(dbStore, q, []byte(secret), os.Getenv("INTERNAL_API_CLIENT_NAME"))
+		if err != nil {
+			log.Fatalf("Failed to set up internal API: %v", err)
+		}
+		var internalTLS *tls.Config
+		if certFile := os.Getenv("INTERNAL_API_TLS_CERT"); certFile != "" {
+			internalTLS, err = internalapi.ServerTLSConfig(certFile, os.Getenv("INTERNAL_API_TLS_KEY"), os.Getenv("INTERNAL_API_CLIENT_CA"))
+			if err != nil {
+				log.Fatalf("Failed to set up internal API TLS: %v", err)
+			}
+		}
+		internalAddr := os.Getenv("INTERNAL_API_ADDR")
+		if internalAddr == "" {
+			internalAddr = defaultInternalAPIAddr
+		}
+		go func() {
+			log.Printf("Starting internal API on %s (TLS: %t)...", internalAddr, internalTLS != nil)
+			if err := internalServer.ListenAndServe(internalAddr, internalTLS); err != nil {
+				log.Fatalf("Failed to run internal API: %v", err)
+			}
+		}()
+	}
+
 	log.Printf("Starting server on %s...", defaultServerPort)
 	if err = router.Run(defaultServerPort); err != nil {
 		log.Fatalf("Failed to run server: %v", err)
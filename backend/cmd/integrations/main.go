@@ -2,28 +2,40 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/vdparikh/compliance-automation/backend/integrations"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/awschecker" // Import new AWS plugin
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/azuresqlchecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/databasequerier"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/dnschecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/filechecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/gcpbucketchecker"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/githubchecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/httpchecker"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/kuberneteschecker"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/ldapchecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/n8nchecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/pingchecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/portscanner"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/prometheuschecker"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/restapichecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/scriptrunner"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/sshchecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/sslchecker"
 	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/temporalchecker"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugins/vulnscanchecker"
+	"github.com/vdparikh/compliance-automation/backend/internalapi"
 	"github.com/vdparikh/compliance-automation/backend/queue"
 	"github.com/vdparikh/compliance-automation/backend/services"
 	"github.com/vdparikh/compliance-automation/backend/store"
+	"github.com/vdparikh/compliance-automation/backend/systemguard"
 )
 
 func main() {
@@ -40,36 +52,13 @@ func main() {
 		cancel()
 	}()
 
-	// Get database connection string from environment
-	dbConnStr := os.Getenv("DATABASE_URL")
-	if dbConnStr == "" {
-		log.Fatal("DATABASE_URL environment variable is required")
-	}
-
-	// Initialize database connection
-	db, err := sql.Open("postgres", dbConnStr)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Initialize store
-	store, err := store.NewDBStore(dbConnStr)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Initialize queue
-	queueConfig := map[string]interface{}{
-		"connection_string": dbConnStr,
-	}
-	q, err := queue.NewPostgresQueue(queueConfig)
+	workerStore, q, err := connect()
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Initialize the plugin registry service
-	pluginRegistry := services.NewPluginRegistryService(store)
+	pluginRegistry := services.NewPluginRegistryService(workerStore)
 
 	httpPlugin := httpchecker.New()
 	if err := pluginRegistry.RegisterPlugin(httpPlugin); err != nil {
@@ -132,11 +121,118 @@ func main() {
 		log.Fatalf("Failed to register Temporal Checker plugin: %v", err)
 	}
 
+	githubPlugin := githubchecker.New()
+	if err := pluginRegistry.RegisterPlugin(githubPlugin); err != nil {
+		log.Fatalf("Failed to register GitHub Checker plugin: %v", err)
+	}
+
+	kubernetesPlugin := kuberneteschecker.New()
+	if err := pluginRegistry.RegisterPlugin(kubernetesPlugin); err != nil {
+		log.Fatalf("Failed to register Kubernetes Checker plugin: %v", err)
+	}
+
+	ldapPlugin := ldapchecker.New()
+	if err := pluginRegistry.RegisterPlugin(ldapPlugin); err != nil {
+		log.Fatalf("Failed to register LDAP Checker plugin: %v", err)
+	}
+
+	dnsPlugin := dnschecker.New()
+	if err := pluginRegistry.RegisterPlugin(dnsPlugin); err != nil {
+		log.Fatalf("Failed to register DNS Checker plugin: %v", err)
+	}
+
+	sshPlugin := sshchecker.New()
+	if err := pluginRegistry.RegisterPlugin(sshPlugin); err != nil {
+		log.Fatalf("Failed to register SSH Checker plugin: %v", err)
+	}
+
+	vulnScanPlugin := vulnscanchecker.New()
+	if err := pluginRegistry.RegisterPlugin(vulnScanPlugin); err != nil {
+		log.Fatalf("Failed to register Vulnerability Report Checker plugin: %v", err)
+	}
+
+	prometheusPlugin := prometheuschecker.New()
+	if err := pluginRegistry.RegisterPlugin(prometheusPlugin); err != nil {
+		log.Fatalf("Failed to register Prometheus Checker plugin: %v", err)
+	}
+
+	restAPIPlugin := restapichecker.New()
+	if err := pluginRegistry.RegisterPlugin(restAPIPlugin); err != nil {
+		log.Fatalf("Failed to register REST API Checker plugin: %v", err)
+	}
+
 	// // Example: Print registered check types (optional)
 	// checkTypes := pluginRegistry.GetCheckTypeConfigurations()
 	// fmt.Printf("Registered check types: %+v\n", checkTypes)
 
 	// Create and start the task execution service (queue processor)
-	taskExecutionSvc := integrations.NewTaskExecutionService(db, q, store, pluginRegistry)
+	// Per system type rate limits and circuit breaker thresholds, see systemguard.Config
+	systemLimits, err := systemguard.LoadConfig(os.Getenv("SYSTEM_LIMITS_FILE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	retention := integrations.DefaultRetentionPolicy()
+	if days, ok := envDays("RESULT_COMPACTION_DAYS"); ok {
+		retention.CompactResultsAfter = days
+	}
+	if days, ok := envDays("EXECUTION_RETENTION_DAYS"); ok {
+		retention.PurgeExecutionsAfter = days
+	}
+	go integrations.RunRetention(ctx, workerStore, q, retention)
+
+	taskExecutionSvc := integrations.NewTaskExecutionService(q, workerStore, pluginRegistry, systemLimits)
 	taskExecutionSvc.Start(ctx)
 }
+
+// connect returns the worker's store and queue. They go through the app's
+// authenticated internal API at INTERNAL_API_URL, so the worker holds no
+// database credentials. Connecting to DATABASE_URL directly instead has to be
+// asked for with INTERNAL_API_DISABLED=true.
+func connect() (integrations.WorkerStore, queue.Queue, error) {
+	if os.Getenv("INTERNAL_API_DISABLED") != "true" {
+		apiURL := os.Getenv("INTERNAL_API_URL")
+		if apiURL == "" {
+			return nil, nil, errors.New("INTERNAL_API_URL environment variable is required; set INTERNAL_API_DISABLED=true to connect to DATABASE_URL directly")
+		}
+		tlsConfig, err := internalapi.ClientTLSConfig(os.Getenv("INTERNAL_API_CLIENT_CERT"), os.Getenv("INTERNAL_API_CLIENT_KEY"), os.Getenv("INTERNAL_API_CA"))
+		if err != nil {
+			return nil, nil, err
+		}
+		client, err := internalapi.NewClient(apiURL, []byte(os.Getenv("INTERNAL_API_SECRET")), tlsConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Using internal API at %s", apiURL)
+		return client, client.Queue(), nil
+	}
+
+	dbConnStr := os.Getenv("DATABASE_URL")
+	if dbConnStr == "" {
+		return nil, nil, errors.New("DATABASE_URL environment variable is required when INTERNAL_API_DISABLED is set")
+	}
+	log.Println("Warning: INTERNAL_API_DISABLED is set, connecting to the database directly")
+	dbStore, err := store.NewDBStore(dbConnStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	q, err := queue.NewPostgresQueue(map[string]interface{}{
+		"connection_string": dbConnStr,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return dbStore, q, nil
+}
+
+// envDays reads a whole number of days from an environment variable; 0 disables.
+func envDays(name string) (time.Duration, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, false
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		log.Fatalf("%s must be a whole number of days, got %q", name, value)
+	}
+	return time.Duration(days) * 24 * time.Hour, true
+}
@@ -0,0 +1,152 @@
+// Package configdrift compares configuration snapshots and turns the
+// differences into drift events.
+package configdrift
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+type resourceKey struct {
+	Type string
+	ID   string
+}
+
+// Diff reports the resources added, removed and changed from one snapshot's
+// resources to another's. Resources are matched by type and ID; attributes are
+// compared by top-level key. Results are sorted by type, then ID.
+func Diff(from, to []models.SnapshotResource) models.SnapshotDiff {
+	before := index(from)
+	after := index(to)
+	diff := models.SnapshotDiff{
+		Added:   []models.SnapshotResource{},
+		Removed: []models.SnapshotResource{},
+		Changed: []models.ResourceChange{},
+	}
+
+	for key, res := range after {
+		old, ok := before[key]
+		if !ok {
+			diff.Added = append(diff.Added, res)
+			continue
+		}
+		if changed := changedAttributes(old.Attributes, res.Attributes); len(changed) > 0 {
+			diff.Changed = append(diff.Changed, models.ResourceChange{
+				ResourceType:      key.Type,
+				ResourceID:        key.ID,
+				ChangedAttributes: changed,
+				Before:            old.Attributes,
+				After:             res.Attributes,
+			})
+		}
+	}
+	for key, res := range before {
+		if _, ok := after[key]; !ok {
+			diff.Removed = append(diff.Removed, res)
+		}
+	}
+
+	sortResources(diff.Added)
+	sortResources(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		a, b := diff.Changed[i], diff.Changed[j]
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		return a.ResourceID < b.ResourceID
+	})
+	return diff
+}
+
+// Events turns a diff into drift events for the snapshot that produced it.
+func Events(diff models.SnapshotDiff, current *models.ConfigSnapshot, requirementIDs []string, detectedAt time.Time) []models.ConfigDriftEvent {
+	base := models.ConfigDriftEvent{
+		ConnectedSystemID:      current.ConnectedSystemID,
+		Scope:                  current.Scope,
+		SnapshotID:             diff.ToSnapshotID,
+		PreviousSnapshotID:     diff.FromSnapshotID,
+		CampaignTaskInstanceID: current.CampaignTaskInstanceID,
+		RequirementIDs:         requirementIDs,
+		DetectedAt:             detectedAt,
+	}
+	var events []models.ConfigDriftEvent
+	for _, r := range diff.Added {
+		e := base
+		e.ResourceType, e.ResourceID, e.ChangeType = r.ResourceType, r.ResourceID, ChangeAdded
+		events = append(events, e)
+	}
+	for _, r := range diff.Removed {
+		e := base
+		e.ResourceType, e.ResourceID, e.ChangeType = r.ResourceType, r.ResourceID, ChangeRemoved
+		events = append(events, e)
+	}
+	for _, c := range diff.Changed {
+		e := base
+		e.ResourceType, e.ResourceID, e.ChangeType = c.ResourceType, c.ResourceID, ChangeChanged
+		e.ChangedAttributes = c.ChangedAttributes
+		events = append(events, e)
+	}
+	return events
+}
+
+func index(resources []models.SnapshotResource) map[resourceKey]models.SnapshotResource {
+	out := make(map[resourceKey]models.SnapshotResource, len(resources))
+	for _, r := range resources {
+		out[resourceKey{r.ResourceType, r.ResourceID}] = r
+	}
+	return out
+}
+
+func changedAttributes(before, after map[string]interface{}) []string {
+	var changed []string
+	for k, v := range after {
+		if old, ok := before[k]; !ok || !equal(old, v) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// equal compares attribute values by their JSON form, so a value freshly
+// produced by a plugin (e.g. an int) matches the same value read back from
+// the database (a float64).
+func equal(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	var na, nb interface{}
+	if json.Unmarshal(ja, &na) != nil || json.Unmarshal(jb, &nb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(na, nb)
+}
+
+func sortResources(resources []models.SnapshotResource) {
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].ResourceType != resources[j].ResourceType {
+			return resources[i].ResourceType < resources[j].ResourceType
+		}
+		return resources[i].ResourceID < resources[j].ResourceID
+	})
+}
@@ -0,0 +1,66 @@
+package configdrift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+func bucket(name string, attrs map[string]interface{}) models.SnapshotResource {
+	return models.SnapshotResource{ResourceType: "aws_s3_bucket", ResourceID: name, Attributes: attrs}
+}
+
+func TestDiff(t *testing.T) {
+	from := []models.SnapshotResource{
+		bucket("logs", map[string]interface{}{"default_encryption": true, "sse_algorithm": "aws:kms", "block_public_acls": true}),
+		bucket("assets", map[string]interface{}{"default_encryption": true, "versions": float64(3)}),
+		bucket("tmp", map[string]interface{}{"default_encryption": false}),
+	}
+	to := []models.SnapshotResource{
+		bucket("logs", map[string]interface{}{"default_encryption": true, "sse_algorithm": "AES256"}),
+		bucket("assets", map[string]interface{}{"default_encryption": true, "versions": 3}),
+		bucket("public-site", map[string]interface{}{"default_encryption": false}),
+	}
+
+	d := Diff(from, to)
+	require.Len(t, d.Added, 1)
+	assert.Equal(t, "public-site", d.Added[0].ResourceID)
+	require.Len(t, d.Removed, 1)
+	assert.Equal(t, "tmp", d.Removed[0].ResourceID)
+	require.Len(t, d.Changed, 1, "an int and a float64 of the same value are equal")
+	assert.Equal(t, "logs", d.Changed[0].ResourceID)
+	assert.Equal(t, []string{"block_public_acls", "sse_algorithm"}, d.Changed[0].ChangedAttributes)
+	assert.False(t, d.Empty())
+
+	assert.True(t, Diff(from, from).Empty())
+	assert.NotNil(t, Diff(nil, nil).Added, "empty lists marshal as [] rather than null")
+}
+
+func TestEvents(t *testing.T) {
+	cti := "cti-1"
+	d := Diff(
+		[]models.SnapshotResource{bucket("logs", map[string]interface{}{"versioning": "Enabled"}), bucket("tmp", nil)},
+		[]models.SnapshotResource{bucket("logs", map[string]interface{}{"versioning": "Suspended"}), bucket("new", nil)},
+	)
+	d.FromSnapshotID, d.ToSnapshotID = "snap-1", "snap-2"
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	events := Events(d, &models.ConfigSnapshot{ID: "snap-2", ConnectedSystemID: "sys-1", Scope: "aws_s3", CampaignTaskInstanceID: &cti}, []string{"req-1"}, now)
+	require.Len(t, events, 3)
+	assert.Equal(t, ChangeAdded, events[0].ChangeType)
+	assert.Equal(t, ChangeRemoved, events[1].ChangeType)
+	assert.Equal(t, ChangeChanged, events[2].ChangeType)
+	assert.Equal(t, []string{"versioning"}, events[2].ChangedAttributes)
+	for _, e := range events {
+		assert.Equal(t, "snap-1", e.PreviousSnapshotID)
+		assert.Equal(t, "snap-2", e.SnapshotID)
+		assert.Equal(t, "sys-1", e.ConnectedSystemID)
+		assert.Equal(t, []string{"req-1"}, e.RequirementIDs)
+		assert.Equal(t, &cti, e.CampaignTaskInstanceID)
+		assert.Equal(t, now, e.DetectedAt)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vdparikh/compliance-automation/backend/campaignstate"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/store"
+	"github.com/vdparikh/compliance-automation/backend/utils"
+)
+
+// CloneCampaignHandler rolls a campaign over into its next audit period as a
+// new Draft campaign. start_date and end_date default to the source period a
+// year later, and due dates move with the period. With
+// carry_forward_evidence, approved evidence is referenced from the new
+// instances, optionally only if uploaded within evidence_max_age_days.
+func (h *CampaignHandler) CloneCampaignHandler(c *gin.Context) {
+	sourceID := c.Param("id")
+	var payload struct {
+		Name                 string             `json:"name"`
+		Description          *string            `json:"description"`
+		StartDate            *models.CustomDate `json:"start_date"`
+		EndDate              *models.CustomDate `json:"end_date"`
+		CarryForwardEvidence bool               `json:"carry_forward_evidence"`
+		EvidenceMaxAgeDays   int                `json:"evidence_max_age_days"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+		sendError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if payload.EvidenceMaxAgeDays < 0 {
+		sendError(c, http.StatusBadRequest, "evidence_max_age_days cannot be negative", nil)
+		return
+	}
+
+	source, err := h.Store.GetCampaignByID(sourceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			sendError(c, http.StatusNotFound, "Campaign not found", nil)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to fetch campaign", err)
+		return
+	}
+
+	var start, end *time.Time
+	if payload.StartDate != nil {
+		start = &payload.StartDate.Time
+	}
+	if payload.EndDate != nil {
+		end = &payload.EndDate.Time
+	}
+	opts := store.CampaignCloneOptions{
+		Name:                 payload.Name,
+		Description:          payload.Description,
+		Rollover:             campaignstate.NewRollover(source.StartDate, source.EndDate, start, end),
+		CarryForwardEvidence: payload.CarryForwardEvidence,
+	}
+	if opts.Name == "" {
+		opts.Name = source.Name + " (next period)"
+	}
+	if payload.EvidenceMaxAgeDays > 0 {
+		opts.EvidenceUploadedAfter = time.Now().AddDate(0, 0, -payload.EvidenceMaxAgeDays)
+	}
+	if opts.Rollover.StartDate != nil && opts.Rollover.EndDate != nil && opts.Rollover.EndDate.Before(*opts.Rollover.StartDate) {
+		sendError(c, http.StatusBadRequest, "end_date cannot be before start_date", nil)
+		return
+	}
+
+	result, err := h.Store.CloneCampaign(sourceID, opts)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendError(c, http.StatusNotFound, "Campaign not found", nil)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to clone campaign", err)
+		return
+	}
+
+	var actorUserID *string
+	if uid, ok := c.Get("userID"); ok {
+		if s, ok := uid.(string); ok {
+			actorUserID = &s
+		}
+	}
+	auditChanges := map[string]interface{}{
+		"cloned_from_campaign_id":  sourceID,
+		"name":                     opts.Name,
+		"start_date":               opts.Rollover.StartDate,
+		"end_date":                 opts.Rollover.EndDate,
+		"task_instances_cloned":    result.TaskInstancesCloned,
+		"evidence_carried_forward": result.EvidenceCarriedForward,
+	}
+	if err := utils.RecordAuditLog(h.Store, actorUserID, "clone_campaign", "campaign", result.CampaignID, auditChanges); err != nil {
+		log.Printf("Error recording audit log for clone campaign %s: %v", result.CampaignID, err)
+	}
+
+	campaign, err := h.Store.GetCampaignByID(result.CampaignID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to fetch cloned campaign", err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"campaign":                 campaign,
+		"task_instances_cloned":    result.TaskInstancesCloned,
+		"evidence_carried_forward": result.EvidenceCarriedForward,
+	})
+}
+
+// GetCampaignComparisonHandler compares a cloned campaign's task instances
+// with the same tasks in the campaign it was cloned from.
+func (h *CampaignHandler) GetCampaignComparisonHandler(c *gin.Context) {
+	comparison, err := h.Store.CompareCampaignWithPrevious(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendError(c, http.StatusNotFound, "Campaign not found", nil)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to compare campaign with its previous period", err)
+		return
+	}
+	c.JSON(http.StatusOK, comparison)
+}
@@ -11,12 +11,14 @@ import (
 	"os"
 	"path/filepath"
 	"reflect" // For audit logging complex fields
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/vdparikh/compliance-automation/backend/auth"
+	"github.com/vdparikh/compliance-automation/backend/campaignstate"
 	"github.com/vdparikh/compliance-automation/backend/models"
 	"github.com/vdparikh/compliance-automation/backend/queue"
 	"github.com/vdparikh/compliance-automation/backend/store"
@@ -48,6 +50,7 @@ func (h *CampaignHandler) CreateCampaignHandler(c *gin.Context) {
 		Name                 string                               `json:"name" binding:"required"`
 		Description          *string                              `json:"description"`
 		StandardID           *string                              `json:"standard_id"`
+		StandardIDs          []string                             `json:"standard_ids"`
 		StartDate            *models.CustomDate                   `json:"start_date"`
 		EndDate              *models.CustomDate                   `json:"end_date"`
 		Status               string                               `json:"status"`
@@ -62,12 +65,16 @@ func (h *CampaignHandler) CreateCampaignHandler(c *gin.Context) {
 	campaign := models.Campaign{
 		Name:        payload.Name,
 		Description: payload.Description,
-		StandardID:  payload.StandardID,
 		Status:      "Draft",
 	}
+	campaign.StandardID, campaign.StandardIDs = mergeStandardIDs(payload.StandardID, payload.StandardIDs)
 	if payload.Status != "" {
 		campaign.Status = payload.Status
 	}
+	if !campaignstate.IsInitial(campaign.Status) {
+		sendError(c, http.StatusBadRequest, "Campaigns can only be created as Draft or Active", nil)
+		return
+	}
 	if payload.StartDate != nil {
 		campaign.StartDate = &payload.StartDate.Time
 	}
@@ -106,6 +113,7 @@ func (h *CampaignHandler) CreateCampaignHandler(c *gin.Context) {
 		"name":                  campaign.Name,
 		"description":           campaign.Description,
 		"standard_id":           campaign.StandardID,
+		"standard_ids":          campaign.StandardIDs,
 		"start_date":            campaign.StartDate,
 		"end_date":              campaign.EndDate,
 		"status":                campaign.Status,
@@ -118,65 +126,94 @@ func (h *CampaignHandler) CreateCampaignHandler(c *gin.Context) {
 	c.JSON(http.StatusCreated, campaign)
 }
 
-func (h *CampaignHandler) GetCampaignsHandler(c *gin.Context) {
-	campaignStatus := c.Query("campaignStatus")
+// mergeStandardIDs returns the primary standard and all standards of a
+// campaign from the standard_id and standard_ids of a request. The primary is
+// standard_id if given and the first of standard_ids otherwise; it always
+// leads the list, which has no duplicates.
+func mergeStandardIDs(primary *string, ids []string) (*string, []string) {
+	merged := []string{}
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			merged = append(merged, id)
+		}
+	}
+	if primary != nil {
+		add(*primary)
+	}
+	for _, id := range ids {
+		add(id)
+	}
+	if len(merged) == 0 {
+		return nil, merged
+	}
+	return &merged[0], merged
+}
 
-	campaigns, err := h.Store.GetCampaigns(campaignStatus)
-	if err != nil {
-		sendError(c, http.StatusInternalServerError, "Failed to fetch campaigns", err)
+// GetCampaignsHandler lists campaigns with their requirement counts and task
+// summaries. They can be filtered by ?status=, ?standard_id= and a ?from= /
+// ?to= period (RFC3339), sorted with ?sort_by= and ?sort_order=, and paged
+// with ?page= and ?limit= (up to 100, default 20).
+func (h *CampaignHandler) GetCampaignsHandler(c *gin.Context) {
+	filter := store.CampaignListFilter{
+		Status:     c.Query("status"),
+		StandardID: c.Query("standard_id"),
+		SortBy:     c.DefaultQuery("sort_by", "created_at"),
+		SortOrder:  strings.ToLower(c.DefaultQuery("sort_order", "desc")),
+	}
+	if !store.IsCampaignSortColumn(filter.SortBy) {
+		sendError(c, http.StatusBadRequest, fmt.Sprintf("Invalid sort_by %q", filter.SortBy), nil)
 		return
 	}
-	if campaigns == nil {
-		campaigns = []models.Campaign{}
+	if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		sendError(c, http.StatusBadRequest, "Invalid sort_order. Use asc or desc.", nil)
+		return
 	}
-
-	// Populate RequirementsCount and TaskSummary for each campaign
-	for i := range campaigns {
-		// Get requirements count
-		requirements, err := h.Store.GetCampaignSelectedRequirements(campaigns[i].ID)
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
 		if err != nil {
-			log.Printf("Error fetching requirements count for campaign %s: %v", campaigns[i].ID, err)
-			continue
+			sendError(c, http.StatusBadRequest, "Invalid from format. Use RFC3339.", err)
+			return
 		}
-		campaigns[i].RequirementsCount = len(requirements)
-
-		// Get task instances for summary
-		taskInstances, err := h.Store.GetCampaignTaskInstances(campaigns[i].ID, "", "")
+		filter.From = &from
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
 		if err != nil {
-			log.Printf("Error fetching task instances for campaign %s: %v", campaigns[i].ID, err)
-			continue
-		}
-
-		// Initialize task summary
-		summary := &models.TaskSummary{}
-		now := time.Now()
-
-		// Count tasks by status
-		for _, task := range taskInstances {
-			summary.TotalTasks++
-			switch task.Status {
-			case "Open":
-				summary.Open++
-			case "In Progress":
-				summary.InProgress++
-			case "Pending Review":
-				summary.PendingReview++
-			case "Closed":
-				summary.Closed++
-			case "Failed":
-				summary.Failed++
-			}
-
-			// Check for overdue tasks
-			if task.DueDate != nil && task.Status != "Closed" && task.Status != "Failed" && task.DueDate.Before(now) {
-				summary.OverdueTasks++
-			}
+			sendError(c, http.StatusBadRequest, "Invalid to format. Use RFC3339.", err)
+			return
 		}
+		filter.To = &to
+	}
 
-		campaigns[i].TaskSummary = summary
+	page, errPage := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if errPage != nil || page < 1 {
+		page = 1
 	}
+	limit, errLimit := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if errLimit != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	filter.Page, filter.Limit = page, limit
 
-	c.JSON(http.StatusOK, campaigns)
+	campaigns, total, err := h.Store.GetCampaigns(filter)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to fetch campaigns", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"campaigns": campaigns,
+		"pagination": gin.H{
+			"total_records": total,
+			"current_page":  page,
+			"page_size":     limit,
+			"total_pages":   (total + limit - 1) / limit,
+		},
+	})
 }
 
 func (h *CampaignHandler) GetCampaignByIDHandler(c *gin.Context) {
@@ -199,6 +236,7 @@ func (h *CampaignHandler) UpdateCampaignHandler(c *gin.Context) {
 		Name                 string                               `json:"name"`
 		Description          *string                              `json:"description"`
 		StandardID           *string                              `json:"standard_id"`
+		StandardIDs          []string                             `json:"standard_ids"`
 		StartDate            *models.CustomDate                   `json:"start_date"`
 		EndDate              *models.CustomDate                   `json:"end_date"`
 		Status               string                               `json:"status"`
@@ -219,6 +257,13 @@ func (h *CampaignHandler) UpdateCampaignHandler(c *gin.Context) {
 		sendError(c, http.StatusInternalServerError, "Failed to retrieve campaign for update", err)
 		return
 	}
+	if rejectIfFrozen(c, campaign.Status) {
+		return
+	}
+	if payload.Status != "" && payload.Status != campaign.Status {
+		sendError(c, http.StatusBadRequest, "Use POST /campaigns/:id/transitions to change a campaign's status", nil)
+		return
+	}
 
 	if payload.Name != "" {
 		campaign.Name = payload.Name
@@ -226,8 +271,8 @@ func (h *CampaignHandler) UpdateCampaignHandler(c *gin.Context) {
 	if payload.Description != nil {
 		campaign.Description = payload.Description
 	}
-	if payload.StandardID != nil {
-		campaign.StandardID = payload.StandardID
+	if payload.StandardID != nil || payload.StandardIDs != nil {
+		campaign.StandardID, campaign.StandardIDs = mergeStandardIDs(payload.StandardID, payload.StandardIDs)
 	}
 	if payload.StartDate != nil {
 		campaign.StartDate = &payload.StartDate.Time
@@ -235,9 +280,6 @@ func (h *CampaignHandler) UpdateCampaignHandler(c *gin.Context) {
 	if payload.EndDate != nil {
 		campaign.EndDate = &payload.EndDate.Time
 	}
-	if payload.Status != "" {
-		campaign.Status = payload.Status
-	}
 
 	// For detailed audit of SelectedRequirements, fetch them before the update transaction
 	oldSelectedRequirements, errGetOldReqs := h.Store.GetCampaignSelectedRequirements(campaignID)
@@ -295,8 +337,9 @@ func (h *CampaignHandler) UpdateCampaignHandler(c *gin.Context) {
 	if payload.Description != nil {
 		auditChanges["description"] = campaign.Description
 	}
-	if payload.StandardID != nil {
+	if payload.StandardID != nil || payload.StandardIDs != nil {
 		auditChanges["standard_id"] = campaign.StandardID
+		auditChanges["standard_ids"] = campaign.StandardIDs
 	}
 	if payload.StartDate != nil {
 		auditChanges["start_date"] = campaign.StartDate
@@ -304,9 +347,6 @@ func (h *CampaignHandler) UpdateCampaignHandler(c *gin.Context) {
 	if payload.EndDate != nil {
 		auditChanges["end_date"] = campaign.EndDate
 	}
-	if payload.Status != "" {
-		auditChanges["status"] = campaign.Status
-	}
 
 	// Diffing SelectedRequirements
 	if oldSelectedRequirements != nil { // if we successfully fetched them
@@ -441,6 +481,9 @@ func (h *CampaignHandler) GetTaskInstancesByMasterTaskIDHandler(c *gin.Context)
 
 func (h *CampaignHandler) UpdateCampaignTaskInstanceHandler(c *gin.Context) {
 	ctiID := c.Param("id")
+	if rejectIfTaskInstanceFrozen(c, h.Store, ctiID) {
+		return
+	}
 
 	existingInstance, err := h.Store.GetCampaignTaskInstanceByID(ctiID)
 	if err != nil {
@@ -777,6 +820,9 @@ func (h *CampaignHandler) GetCampaignTaskInstanceCommentsHandler(c *gin.Context)
 
 func (h *CampaignHandler) UploadCampaignTaskInstanceEvidenceHandler(c *gin.Context) {
 	instanceID := c.Param("id")
+	if rejectIfTaskInstanceFrozen(c, h.Store, instanceID) {
+		return
+	}
 
 	claimsValue, exists := c.Get(string(auth.ContextKeyClaims))
 	if !exists {
@@ -912,6 +958,9 @@ func (h *CampaignHandler) GetCampaignTaskInstanceEvidenceHandler(c *gin.Context)
 
 func (h *CampaignHandler) ExecuteCampaignTaskInstanceHandler(c *gin.Context) {
 	instanceID := c.Param("id")
+	if rejectIfTaskInstanceFrozen(c, h.Store, instanceID) {
+		return
+	}
 
 	claimsValue, exists := c.Get(string(auth.ContextKeyClaims))
 	if !exists {
@@ -1015,15 +1064,30 @@ func (h *CampaignHandler) ExecuteCampaignTaskInstanceHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Task has been queued for execution",
-		"status":  "queued",
-		"output":  "Task has been queued for execution. Results will be available shortly.",
+		"message":     "Task has been queued for execution",
+		"status":      "queued",
+		"output":      "Task has been queued for execution. Results will be available shortly.",
+		"executionId": request.ID,
 	})
 }
 
+// GetCampaignTaskInstanceResultsHandler returns a page of an instance's
+// execution results, newest first (?page=, ?limit= up to 100, default 20).
 func (h *CampaignHandler) GetCampaignTaskInstanceResultsHandler(c *gin.Context) {
 	instanceID := c.Param("id")
-	results, err := h.Store.GetCampaignTaskInstanceResults(instanceID)
+	page, errPage := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if errPage != nil || page < 1 {
+		page = 1
+	}
+	limit, errLimit := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if errLimit != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	results, total, err := h.Store.GetCampaignTaskInstanceResultsPage(instanceID, page, limit)
 	if err != nil {
 		sendError(c, http.StatusInternalServerError, "Failed to retrieve execution results", err)
 		return
@@ -1031,11 +1095,22 @@ func (h *CampaignHandler) GetCampaignTaskInstanceResultsHandler(c *gin.Context)
 	if results == nil {
 		results = []models.CampaignTaskInstanceResult{}
 	}
-	c.JSON(http.StatusOK, results)
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"pagination": gin.H{
+			"total_records": total,
+			"current_page":  page,
+			"page_size":     limit,
+			"total_pages":   (total + limit - 1) / limit,
+		},
+	})
 }
 
 func (h *CampaignHandler) CopyEvidenceHandler(c *gin.Context) {
 	targetInstanceID := c.Param("id")
+	if rejectIfTaskInstanceFrozen(c, h.Store, targetInstanceID) {
+		return
+	}
 
 	claimsValue, exists := c.Get(string(auth.ContextKeyClaims))
 	if !exists {
@@ -1087,7 +1162,7 @@ func (h *CampaignHandler) GetTaskExecutionStatusHandler(c *gin.Context) {
 	}
 
 	// Get the latest task execution result
-	results, err := h.Store.GetCampaignTaskInstanceResults(instanceID)
+	results, _, err := h.Store.GetCampaignTaskInstanceResultsPage(instanceID, 1, 1)
 	if err != nil {
 		sendError(c, http.StatusInternalServerError, "Failed to retrieve execution results", err)
 		return
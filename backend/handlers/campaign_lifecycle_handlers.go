@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vdparikh/compliance-automation/backend/auth"
+	"github.com/vdparikh/compliance-automation/backend/campaignstate"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/store"
+	"github.com/vdparikh/compliance-automation/backend/utils"
+)
+
+// rejectIfTaskInstanceFrozen writes an error response and returns true if the
+// task instance does not exist or its campaign is completed or archived.
+func rejectIfTaskInstanceFrozen(c *gin.Context, s *store.DBStore, ctiID string) bool {
+	status, err := s.GetCampaignStatusForTaskInstance(ctiID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendError(c, http.StatusNotFound, "Campaign Task Instance not found", nil)
+			return true
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to check campaign status", err)
+		return true
+	}
+	return rejectIfFrozen(c, status)
+}
+
+// rejectIfEvidenceFrozen is rejectIfTaskInstanceFrozen for the campaign an
+// evidence item belongs to.
+func rejectIfEvidenceFrozen(c *gin.Context, s *store.DBStore, evidenceID string) bool {
+	status, err := s.GetCampaignStatusForEvidence(evidenceID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendError(c, http.StatusNotFound, "Evidence not found", nil)
+			return true
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to check campaign status", err)
+		return true
+	}
+	return rejectIfFrozen(c, status)
+}
+
+func rejectIfFrozen(c *gin.Context, campaignStatus string) bool {
+	if campaignstate.IsFrozen(campaignStatus) {
+		sendError(c, http.StatusConflict, "Campaign is "+campaignStatus+" and can no longer be changed", nil)
+		return true
+	}
+	return false
+}
+
+// transitionCampaign applies a status transition and writes the response:
+// the updated campaign, or 404, 409 for a refused transition, or 500.
+func (h *CampaignHandler) transitionCampaign(c *gin.Context, campaignID, to string, signOff *models.CampaignSignOff) {
+	from, generated, err := h.Store.TransitionCampaign(campaignID, to, signOff)
+	if err != nil {
+		var transitionErr *campaignstate.TransitionError
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			sendError(c, http.StatusNotFound, "Campaign not found", nil)
+		case errors.As(err, &transitionErr):
+			sendError(c, http.StatusConflict, transitionErr.Error(), nil)
+		default:
+			sendError(c, http.StatusInternalServerError, "Failed to change campaign status", err)
+		}
+		return
+	}
+
+	auditChanges := map[string]interface{}{
+		"old_status": from,
+		"new_status": to,
+	}
+	action := "transition_campaign"
+	if generated > 0 {
+		auditChanges["generated_task_instances"] = generated
+	}
+	var actorUserID *string
+	if signOff != nil {
+		action = "sign_off_campaign"
+		actorUserID = signOff.UserID
+		auditChanges["sign_off_comment"] = signOff.Comment
+	} else if claims, ok := c.Get(string(auth.ContextKeyClaims)); ok {
+		if userClaims, ok := claims.(*auth.Claims); ok {
+			actorUserID = &userClaims.UserID
+		}
+	}
+	if err := utils.RecordAuditLog(h.Store, actorUserID, action, "campaign", campaignID, auditChanges); err != nil {
+		log.Printf("Error recording audit log for %s %s: %v", action, campaignID, err)
+	}
+
+	campaign, err := h.Store.GetCampaignByID(campaignID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to fetch campaign", err)
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// TransitionCampaignHandler moves a campaign to another status. Activating a
+// draft creates its task instances and review requires every task instance
+// to be closed or failed. Completion goes through SignOffCampaignHandler.
+func (h *CampaignHandler) TransitionCampaignHandler(c *gin.Context) {
+	var payload struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if payload.Status == campaignstate.Completed {
+		sendError(c, http.StatusBadRequest, "Completing a campaign requires a sign-off", nil)
+		return
+	}
+	h.transitionCampaign(c, c.Param("id"), payload.Status, nil)
+}
+
+// SignOffCampaignHandler completes a campaign in review, recording the
+// approving user, the time and an optional comment. Only admins and auditors
+// can sign off.
+func (h *CampaignHandler) SignOffCampaignHandler(c *gin.Context) {
+	claimsValue, exists := c.Get(string(auth.ContextKeyClaims))
+	if !exists {
+		sendError(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+	claims, ok := claimsValue.(*auth.Claims)
+	if !ok || claims == nil || claims.UserID == "" {
+		sendError(c, http.StatusInternalServerError, "Error processing user authentication claims", nil)
+		return
+	}
+	if claims.Role != "admin" && claims.Role != "auditor" {
+		sendError(c, http.StatusForbidden, "You do not have permission to sign off campaigns", nil)
+		return
+	}
+
+	var payload struct {
+		Comment *string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+		sendError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	userID := claims.UserID
+	signOff := &models.CampaignSignOff{
+		UserID:      &userID,
+		SignedOffAt: time.Now(),
+		Comment:     payload.Comment,
+	}
+	h.transitionCampaign(c, c.Param("id"), campaignstate.Completed, signOff)
+}
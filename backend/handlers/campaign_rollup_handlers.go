@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vdparikh/compliance-automation/backend/store"
+)
+
+// GetCampaignComplianceHandler reports the compliance status of each of a
+// campaign's selected requirements, coverage per standard section and the
+// requirements no master task is mapped to.
+func (h *CampaignHandler) GetCampaignComplianceHandler(c *gin.Context) {
+	result, err := h.Store.GetCampaignComplianceRollup(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendError(c, http.StatusNotFound, "Campaign not found", nil)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to roll up campaign compliance", err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vdparikh/compliance-automation/backend/configdrift"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/store"
+)
+
+type ConfigSnapshotHandler struct {
+	Store *store.DBStore
+}
+
+func NewConfigSnapshotHandler(s *store.DBStore) *ConfigSnapshotHandler {
+	return &ConfigSnapshotHandler{Store: s}
+}
+
+// GetSystemConfigSnapshotsHandler lists a connected system's snapshots, newest
+// first. ?scope= limits them to one check type.
+func (h *ConfigSnapshotHandler) GetSystemConfigSnapshotsHandler(c *gin.Context) {
+	snapshots, err := h.Store.GetConfigSnapshotsBySystemID(c.Param("id"), c.Query("scope"))
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve config snapshots", err)
+		return
+	}
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// GetConfigSnapshotHandler returns a snapshot with all of its resources.
+func (h *ConfigSnapshotHandler) GetConfigSnapshotHandler(c *gin.Context) {
+	snapshot, err := h.Store.GetConfigSnapshotByID(c.Param("snapshotId"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendError(c, http.StatusNotFound, "Config snapshot not found", nil)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve config snapshot", err)
+		return
+	}
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// DiffConfigSnapshotsHandler reports the resources added, removed and changed
+// from ?against=<snapshotId> to :snapshotId. Without against, the previous
+// snapshot of the same system and scope is used.
+func (h *ConfigSnapshotHandler) DiffConfigSnapshotsHandler(c *gin.Context) {
+	to, err := h.Store.GetConfigSnapshotByID(c.Param("snapshotId"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendError(c, http.StatusNotFound, "Config snapshot not found", nil)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve config snapshot", err)
+		return
+	}
+
+	var from *models.ConfigSnapshot
+	if againstID := c.Query("against"); againstID != "" {
+		from, err = h.Store.GetConfigSnapshotByID(againstID)
+	} else {
+		from, err = h.Store.GetPreviousConfigSnapshot(to)
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendError(c, http.StatusNotFound, "No snapshot to compare against", nil)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve config snapshot", err)
+		return
+	}
+	if from.ConnectedSystemID != to.ConnectedSystemID || from.Scope != to.Scope {
+		sendError(c, http.StatusBadRequest, "Snapshots must belong to the same connected system and scope", nil)
+		return
+	}
+
+	diff := configdrift.Diff(from.Resources, to.Resources)
+	diff.FromSnapshotID, diff.ToSnapshotID = from.ID, to.ID
+	c.JSON(http.StatusOK, gin.H{
+		"from": gin.H{"id": from.ID, "capturedAt": from.CapturedAt, "resourceCount": from.ResourceCount},
+		"to":   gin.H{"id": to.ID, "capturedAt": to.CapturedAt, "resourceCount": to.ResourceCount},
+		"diff": diff,
+	})
+}
+
+// GetConfigDriftHandler lists detected drift, newest first. Filters:
+// connectedSystemId, requirementId and since (RFC 3339).
+func (h *ConfigSnapshotHandler) GetConfigDriftHandler(c *gin.Context) {
+	filter := store.ConfigDriftFilter{
+		ConnectedSystemID: c.Query("connectedSystemId"),
+		RequirementID:     c.Query("requirementId"),
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			sendError(c, http.StatusBadRequest, "since must be an RFC 3339 time", err)
+			return
+		}
+		filter.Since = &t
+	}
+	events, err := h.Store.GetConfigDriftEvents(filter)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve config drift", err)
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
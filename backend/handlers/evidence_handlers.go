@@ -43,6 +43,9 @@ func HandleReviewEvidence(s *store.DBStore) gin.HandlerFunc {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to review evidence"})
 			return
 		}
+		if rejectIfEvidenceFrozen(c, s, evidenceID) {
+			return
+		}
 
 		var req store.ReviewEvidenceUpdateRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
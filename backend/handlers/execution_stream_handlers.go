@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/auth"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+	"github.com/vdparikh/compliance-automation/backend/utils"
+)
+
+const (
+	executionStreamPollInterval = time.Second
+	executionStreamHeartbeat    = 15 * time.Second
+	executionLogBatchSize       = 500
+)
+
+// loadInstanceExecution returns the execution :execId of task instance :id,
+// writing an error response and returning nil when it cannot.
+func (h *CampaignHandler) loadInstanceExecution(c *gin.Context) *queue.TaskExecutionRequest {
+	executionID, err := uuid.Parse(c.Param("execId"))
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid execution ID", err)
+		return nil
+	}
+	execution, err := h.Queue.GetTaskStatus(c.Request.Context(), executionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			sendError(c, http.StatusNotFound, "Execution not found", nil)
+			return nil
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve execution", err)
+		return nil
+	}
+	if execution.TaskInstanceID.String() != c.Param("id") {
+		sendError(c, http.StatusNotFound, "Execution not found for this task instance", nil)
+		return nil
+	}
+	return execution
+}
+
+// GetTaskExecutionLogsHandler returns the stored log lines of an execution.
+// ?after=<logId> returns only newer lines.
+func (h *CampaignHandler) GetTaskExecutionLogsHandler(c *gin.Context) {
+	execution := h.loadInstanceExecution(c)
+	if execution == nil {
+		return
+	}
+	afterID, _ := strconv.ParseInt(c.Query("after"), 10, 64)
+	logs, err := h.Store.GetTaskExecutionLogs(execution.ID.String(), afterID, executionLogBatchSize)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve execution logs", err)
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
+
+// StreamTaskExecutionHandler streams an execution's log lines as Server-Sent
+// Events. Each "log" event carries the log ID as its event ID, so a reconnecting
+// client resumes from Last-Event-ID. A final "done" event reports the status.
+func (h *CampaignHandler) StreamTaskExecutionHandler(c *gin.Context) {
+	execution := h.loadInstanceExecution(c)
+	if execution == nil {
+		return
+	}
+	lastID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	ctx := c.Request.Context()
+	ticker := time.NewTicker(executionStreamPollInterval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+	for {
+		// Status is read before the logs so that lines written just before
+		// completion are always sent ahead of the "done" event.
+		finished := execution.CompletedAt != nil
+		logs, err := h.Store.GetTaskExecutionLogs(execution.ID.String(), lastID, executionLogBatchSize)
+		if err != nil {
+			writeServerSentEvent(c, "error", "", gin.H{"error": "Failed to read execution logs"})
+			log.Printf("Error streaming logs of execution %s: %v", execution.ID, err)
+			return
+		}
+		for _, line := range logs {
+			writeServerSentEvent(c, "log", strconv.FormatInt(line.ID, 10), line)
+			lastID = line.ID
+			lastWrite = time.Now()
+		}
+		if finished && len(logs) < executionLogBatchSize {
+			done := gin.H{"status": execution.Status, "completedAt": execution.CompletedAt}
+			if execution.ErrorMessage != nil && *execution.ErrorMessage != "" {
+				done["errorMessage"] = *execution.ErrorMessage
+			}
+			writeServerSentEvent(c, "done", "", done)
+			return
+		}
+		if time.Since(lastWrite) >= executionStreamHeartbeat {
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+			lastWrite = time.Now()
+		}
+
+		if len(logs) < executionLogBatchSize {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+		latest, err := h.Queue.GetTaskStatus(ctx, execution.ID)
+		if err != nil {
+			if ctx.Err() == nil {
+				writeServerSentEvent(c, "error", "", gin.H{"error": "Failed to read execution status"})
+				log.Printf("Error streaming status of execution %s: %v", execution.ID, err)
+			}
+			return
+		}
+		execution = latest
+	}
+}
+
+// CancelTaskExecutionHandler cancels a pending execution outright, or asks the
+// worker to stop a running one; the worker then records the Cancelled result.
+// Executions of completed or archived campaigns are discarded by the worker
+// and cannot be cancelled here.
+func (h *CampaignHandler) CancelTaskExecutionHandler(c *gin.Context) {
+	instanceID := c.Param("id")
+	claimsValue, exists := c.Get(string(auth.ContextKeyClaims))
+	if !exists {
+		sendError(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+	claims, ok := claimsValue.(*auth.Claims)
+	if !ok || claims == nil || claims.UserID == "" {
+		sendError(c, http.StatusInternalServerError, "Error processing user authentication claims", nil)
+		return
+	}
+	userID := claims.UserID
+
+	if rejectIfTaskInstanceFrozen(c, h.Store, instanceID) {
+		return
+	}
+	execution := h.loadInstanceExecution(c)
+	if execution == nil {
+		return
+	}
+	status, err := h.Queue.CancelTask(c.Request.Context(), execution.ID)
+	if err != nil {
+		if errors.Is(err, queue.ErrNotCancellable) {
+			sendError(c, http.StatusConflict, fmt.Sprintf("Execution has already finished with status %s", execution.Status), nil)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to cancel execution", err)
+		return
+	}
+
+	executionID := execution.ID.String()
+	if status == queue.StatusCancelled {
+		// The worker never picked it up, so record the outcome here.
+		if err := h.Store.AppendTaskExecutionLog(executionID, common.LogLevelWarn, "Execution cancelled by user before it started"); err != nil {
+			log.Printf("Error logging cancellation of execution %s: %v", executionID, err)
+		}
+		result := models.CampaignTaskInstanceResult{
+			CampaignTaskInstanceID: instanceID,
+			TaskExecutionID:        &executionID,
+			ExecutedByUserID:       &userID,
+			Timestamp:              time.Now(),
+			Status:                 common.StatusCancelled,
+			Output:                 `{"overall_execution_status":"Cancelled","execution_error_message":"Execution cancelled by user"}`,
+		}
+		if err := h.Store.CreateCampaignTaskInstanceResult(&result); err != nil {
+			log.Printf("Error storing cancelled result for instance %s: %v", instanceID, err)
+		}
+		if taskInstance, err := h.Store.GetCampaignTaskInstanceByID(instanceID); err == nil {
+			taskInstance.LastCheckedAt = &result.Timestamp
+			taskInstance.LastCheckStatus = &result.Status
+			if err := h.Store.UpdateCampaignTaskInstance(taskInstance); err != nil {
+				log.Printf("Error updating task instance %s after cancellation: %v", instanceID, err)
+			}
+		}
+	}
+
+	auditChanges := map[string]interface{}{"execution_id": executionID, "previous_status": execution.Status, "status": status}
+	if err := utils.RecordAuditLog(h.Store, &userID, "cancel_task_execution", "campaign_task_instance", instanceID, auditChanges); err != nil {
+		log.Printf("Error recording audit log for cancelling execution %s: %v", executionID, err)
+	}
+	c.JSON(http.StatusAccepted, gin.H{"executionId": executionID, "status": status})
+}
+
+func writeServerSentEvent(c *gin.Context, event, id string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload = []byte(`{}`)
+	}
+	if id != "" {
+		fmt.Fprintf(c.Writer, "id: %s\n", id)
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload)
+	c.Writer.Flush()
+}
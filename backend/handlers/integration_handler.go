@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vdparikh/compliance-automation/backend/models"
 	"github.com/vdparikh/compliance-automation/backend/store" // Changed to store
 )
 
@@ -17,7 +19,24 @@ func NewIntegrationHandler(s *store.DBStore) *IntegrationHandler { // Changed pa
 	return &IntegrationHandler{dbStore: s}
 }
 
+// CheckTypeSchemaWarning flags a master task whose parameters were saved
+// against an older schema version of its check type, or no longer fit it.
+type CheckTypeSchemaWarning struct {
+	TaskID               string   `json:"taskId"`
+	TaskTitle            string   `json:"taskTitle"`
+	TaskSchemaVersion    *int     `json:"taskSchemaVersion,omitempty"`
+	CurrentSchemaVersion int      `json:"currentSchemaVersion"`
+	MissingParameters    []string `json:"missingParameters,omitempty"`
+	UnknownParameters    []string `json:"unknownParameters,omitempty"`
+}
+
+type checkTypeWithWarnings struct {
+	models.CheckTypeConfiguration
+	Warnings []CheckTypeSchemaWarning `json:"warnings,omitempty"`
+}
+
 // GetIntegrationCheckTypesHandler serves the compiled check type configurations from all registered plugins.
+// Each check type lists warnings for master tasks that need revisiting after a schema change.
 func (h *IntegrationHandler) GetIntegrationCheckTypesHandler(c *gin.Context) {
 	// Directly query the database via dbStore
 	configs, err := h.dbStore.GetActiveCheckTypeConfigurations()
@@ -25,5 +44,59 @@ func (h *IntegrationHandler) GetIntegrationCheckTypesHandler(c *gin.Context) {
 		sendError(c, http.StatusInternalServerError, "Failed to retrieve integration check types", err)
 		return
 	}
-	c.JSON(http.StatusOK, configs)
+	tasks, err := h.dbStore.GetTasks("", "")
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve tasks for check type warnings", err)
+		return
+	}
+
+	warnings := checkTypeSchemaWarnings(configs, tasks)
+	response := make(map[string]checkTypeWithWarnings, len(configs))
+	for key, config := range configs {
+		response[key] = checkTypeWithWarnings{CheckTypeConfiguration: config, Warnings: warnings[key]}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPluginVersionsHandler lists the versions a plugin has been registered with.
+func (h *IntegrationHandler) GetPluginVersionsHandler(c *gin.Context) {
+	versions, err := h.dbStore.GetRegisteredPluginVersions(c.Param("id"))
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve plugin versions", err)
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// checkTypeSchemaWarnings groups, by check type key, the master tasks saved
+// against a different schema version or whose parameters don't match it.
+func checkTypeSchemaWarnings(configs map[string]models.CheckTypeConfiguration, tasks []models.Task) map[string][]CheckTypeSchemaWarning {
+	warnings := make(map[string][]CheckTypeSchemaWarning)
+	for _, task := range tasks {
+		if task.CheckType == nil {
+			continue
+		}
+		config, ok := configs[*task.CheckType]
+		if !ok {
+			continue
+		}
+		current := config.CurrentSchemaVersion()
+		missing, unknown := config.ParameterMismatches(task.Parameters)
+		outdated := task.CheckTypeSchemaVersion == nil || *task.CheckTypeSchemaVersion != current
+		if !outdated && len(missing) == 0 && len(unknown) == 0 {
+			continue
+		}
+		warnings[*task.CheckType] = append(warnings[*task.CheckType], CheckTypeSchemaWarning{
+			TaskID:               task.ID,
+			TaskTitle:            task.Title,
+			TaskSchemaVersion:    task.CheckTypeSchemaVersion,
+			CurrentSchemaVersion: current,
+			MissingParameters:    missing,
+			UnknownParameters:    unknown,
+		})
+	}
+	for key := range warnings {
+		sort.Slice(warnings[key], func(i, j int) bool { return warnings[key][i].TaskTitle < warnings[key][j].TaskTitle })
+	}
+	return warnings
 }
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+var resultTrendIntervals = map[string]bool{"day": true, "week": true, "month": true}
+
+// GetResultTrendHandler returns pass/fail counts over time for a campaign task
+// instance, a master task or a connected system (?campaignTaskInstanceId=,
+// ?masterTaskId=, ?connectedSystemId=; at least one is required). ?from= and
+// ?to= (RFC3339) bound the period and ?interval= is day, week or month.
+func (h *CampaignHandler) GetResultTrendHandler(c *gin.Context) {
+	filter := models.ResultTrendFilter{
+		CampaignTaskInstanceID: c.Query("campaignTaskInstanceId"),
+		MasterTaskID:           c.Query("masterTaskId"),
+		ConnectedSystemID:      c.Query("connectedSystemId"),
+		Interval:               c.DefaultQuery("interval", "day"),
+	}
+	if filter.CampaignTaskInstanceID == "" && filter.MasterTaskID == "" && filter.ConnectedSystemID == "" {
+		sendError(c, http.StatusBadRequest, "One of campaignTaskInstanceId, masterTaskId or connectedSystemId is required", nil)
+		return
+	}
+	if !resultTrendIntervals[filter.Interval] {
+		sendError(c, http.StatusBadRequest, "Invalid interval. Use day, week or month.", nil)
+		return
+	}
+	var err error
+	if filter.From, err = parseOptionalTime(c.Query("from")); err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid from format. Use RFC3339.", err)
+		return
+	}
+	if filter.To, err = parseOptionalTime(c.Query("to")); err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid to format. Use RFC3339.", err)
+		return
+	}
+
+	points, err := h.Store.GetResultTrend(filter, common.StatusSuccess, common.StatusFailed, common.StatusError)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve result trend", err)
+		return
+	}
+
+	var summary models.ResultTrendPoint
+	for _, p := range points {
+		summary.Passed += p.Passed
+		summary.Failed += p.Failed
+		summary.Errored += p.Errored
+		summary.Other += p.Other
+		summary.Total += p.Total
+	}
+	if summary.Total > 0 {
+		summary.PassRate = float64(summary.Passed) / float64(summary.Total)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"interval": filter.Interval,
+		"from":     filter.From,
+		"to":       filter.To,
+		"points":   points,
+		"summary": gin.H{
+			"passed":   summary.Passed,
+			"failed":   summary.Failed,
+			"errored":  summary.Errored,
+			"other":    summary.Other,
+			"total":    summary.Total,
+			"passRate": summary.PassRate,
+		},
+	})
+}
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
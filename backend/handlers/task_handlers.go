@@ -2,12 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vdparikh/compliance-automation/backend/assignment"
 	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/policy"
 	"github.com/vdparikh/compliance-automation/backend/store"
 	"github.com/vdparikh/compliance-automation/backend/utils"
 )
@@ -46,6 +51,15 @@ func (h *TaskHandler) CreateTaskHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "status is required"})
 		return
 	}
+	if err := normalizeResultPolicy(&newTask); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := normalizeAssignmentDefaults(&newTask); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.stampCheckTypeSchemaVersion(&newTask)
 	// Create the task and handle requirementIds join table
 	taskID, err := h.Store.CreateTask(&newTask)
 	if err != nil {
@@ -104,6 +118,15 @@ func (h *TaskHandler) UpdateTaskHandler(c *gin.Context) {
 
 	taskUpdates.ID = taskID
 	taskUpdates.CreatedAt = existingTask.CreatedAt
+	if err := normalizeResultPolicy(&taskUpdates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := normalizeAssignmentDefaults(&taskUpdates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.stampCheckTypeSchemaVersion(&taskUpdates)
 
 	if err := h.Store.UpdateTask(&taskUpdates); err != nil {
 		log.Printf("Error updating task %s: %v", taskID, err)
@@ -164,6 +187,16 @@ func (h *TaskHandler) UpdateTaskHandler(c *gin.Context) {
 	compareStringPtr("check_type", existingTask.CheckType, updatedTask.CheckType)
 	compareStringPtr("target", existingTask.Target, updatedTask.Target)
 	compareStringPtr("default_priority", existingTask.DefaultPriority, updatedTask.DefaultPriority)
+	compareStringPtr("result_policy_language", existingTask.ResultPolicyLanguage, updatedTask.ResultPolicyLanguage)
+	compareStringPtr("result_policy", existingTask.ResultPolicy, updatedTask.ResultPolicy)
+	compareStringPtr("default_owner_team_id", existingTask.DefaultOwnerTeamID, updatedTask.DefaultOwnerTeamID)
+	compareStringPtr("default_assignee_team_id", existingTask.DefaultAssigneeTeamID, updatedTask.DefaultAssigneeTeamID)
+	compareStringPtr("default_owner_user_id", existingTask.DefaultOwnerUserID, updatedTask.DefaultOwnerUserID)
+	compareStringPtr("default_assignee_user_id", existingTask.DefaultAssigneeUserID, updatedTask.DefaultAssigneeUserID)
+	compareStringPtr("due_date_anchor", existingTask.DueDateAnchor, updatedTask.DueDateAnchor)
+	if !reflect.DeepEqual(existingTask.DueDateOffsetDays, updatedTask.DueDateOffsetDays) {
+		auditChanges["due_date_offset_days"] = map[string]interface{}{"old": existingTask.DueDateOffsetDays, "new": updatedTask.DueDateOffsetDays}
+	}
 
 	// Comparing Parameters (map[string]interface{})
 	if !reflect.DeepEqual(existingTask.Parameters, updatedTask.Parameters) {
@@ -268,3 +301,134 @@ func (h *TaskHandler) UnlinkTaskFromRequirementsHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Task successfully unlinked from requirements"})
 }
+
+// normalizeResultPolicy clears an empty result policy and otherwise checks
+// that it compiles, so a broken policy never reaches the worker.
+func normalizeResultPolicy(task *models.Task) error {
+	if task.ResultPolicy == nil || strings.TrimSpace(*task.ResultPolicy) == "" {
+		task.ResultPolicy = nil
+		task.ResultPolicyLanguage = nil
+		return nil
+	}
+	if task.ResultPolicyLanguage == nil || *task.ResultPolicyLanguage == "" {
+		return fmt.Errorf("resultPolicyLanguage is required when resultPolicy is set (cel or rego)")
+	}
+	if err := policy.Validate(*task.ResultPolicyLanguage, *task.ResultPolicy); err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalizeAssignmentDefaults clears empty default owners and assignees and
+// checks that a due date offset comes with a valid anchor and vice versa.
+func normalizeAssignmentDefaults(task *models.Task) error {
+	for _, field := range []**string{&task.DefaultOwnerTeamID, &task.DefaultAssigneeTeamID, &task.DefaultOwnerUserID, &task.DefaultAssigneeUserID, &task.DueDateAnchor} {
+		if *field != nil && strings.TrimSpace(**field) == "" {
+			*field = nil
+		}
+	}
+	if task.DueDateAnchor == nil && task.DueDateOffsetDays == nil {
+		return nil
+	}
+	if task.DueDateAnchor == nil || task.DueDateOffsetDays == nil {
+		return fmt.Errorf("dueDateAnchor and dueDateOffsetDays must be set together")
+	}
+	if !assignment.IsValidAnchor(*task.DueDateAnchor) {
+		return fmt.Errorf("dueDateAnchor must be %q or %q", assignment.AnchorStart, assignment.AnchorEnd)
+	}
+	return nil
+}
+
+// stampCheckTypeSchemaVersion records which schema version of the task's
+// check type its parameters are being saved against.
+func (h *TaskHandler) stampCheckTypeSchemaVersion(task *models.Task) {
+	task.CheckTypeSchemaVersion = nil
+	if task.CheckType == nil || *task.CheckType == "" {
+		return
+	}
+	configs, err := h.Store.GetActiveCheckTypeConfigurations()
+	if err != nil {
+		log.Printf("Could not load check type configurations to version task %s: %v", task.ID, err)
+		return
+	}
+	if config, ok := configs[*task.CheckType]; ok {
+		version := config.CurrentSchemaVersion()
+		task.CheckTypeSchemaVersion = &version
+	}
+}
+
+// DryRunResultPolicyHandler evaluates a result policy against a stored
+// execution result of one of the task's instances without saving anything.
+// A draft policy in the request body takes precedence over the saved one.
+func (h *TaskHandler) DryRunResultPolicyHandler(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var payload struct {
+		ResultID string  `json:"resultId" binding:"required"`
+		Language *string `json:"language"`
+		Policy   *string `json:"policy"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	task, err := h.Store.GetTaskByID(taskID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve task", err)
+		return
+	}
+	if task == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	language, source := task.ResultPolicyLanguage, task.ResultPolicy
+	if payload.Policy != nil {
+		language, source = payload.Language, payload.Policy
+	}
+	if source == nil || strings.TrimSpace(*source) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task has no result policy; provide language and policy to test a draft"})
+		return
+	}
+	if language == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "language is required (cel or rego)"})
+		return
+	}
+
+	result, err := h.Store.GetCampaignTaskInstanceResultByID(payload.ResultID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Result not found"})
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve result", err)
+		return
+	}
+	instance, err := h.Store.GetCampaignTaskInstanceByID(result.CampaignTaskInstanceID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve campaign task instance", err)
+		return
+	}
+	if instance.MasterTaskID == nil || *instance.MasterTaskID != taskID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Result does not belong to an instance of this task"})
+		return
+	}
+
+	var output map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Output), &output); err != nil {
+		sendError(c, http.StatusUnprocessableEntity, "Stored result output is not a JSON object", err)
+		return
+	}
+	decision, err := policy.Evaluate(c.Request.Context(), *language, *source, policy.NewInput(output, result.Status, instance.Parameters))
+	if err != nil {
+		sendError(c, http.StatusUnprocessableEntity, "Policy evaluation failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resultId":      result.ID,
+		"storedStatus":  result.Status,
+		"decision":      decision,
+		"statusChanged": decision.Status != result.Status,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vdparikh/compliance-automation/backend/assignment"
 	"github.com/vdparikh/compliance-automation/backend/models"
 	"github.com/vdparikh/compliance-automation/backend/store"
 	// "github.com/vdparikh/compliance-automation/backend/auth" // For permission checks
@@ -25,6 +26,11 @@ func (h *TeamHandler) CreateTeamHandler(c *gin.Context) {
 		return
 	}
 
+	if team.AssignmentStrategy != "" && !assignment.IsValidStrategy(team.AssignmentStrategy) {
+		sendError(c, http.StatusBadRequest, "assignment_strategy must be lead, round_robin or least_loaded", nil)
+		return
+	}
+
 	// Add authorization checks here (e.g., only admin can create teams)
 
 	teamID, err := h.Store.CreateTeam(&team)
@@ -72,6 +78,11 @@ func (h *TeamHandler) UpdateTeamHandler(c *gin.Context) {
 		return
 	}
 
+	if teamUpdates.AssignmentStrategy != "" && !assignment.IsValidStrategy(teamUpdates.AssignmentStrategy) {
+		sendError(c, http.StatusBadRequest, "assignment_strategy must be lead, round_robin or least_loaded", nil)
+		return
+	}
+
 	// Add authorization checks
 
 	teamUpdates.ID = teamID // Ensure ID is set for update
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+	"github.com/vdparikh/compliance-automation/backend/store"
+)
+
+const (
+	defaultTestExecutionWait = 30 * time.Second
+	maxTestExecutionWait     = 60 * time.Second
+	testExecutionPollEvery   = 500 * time.Millisecond
+)
+
+// TestExecutionHandler runs checks through the queue's test lane so a master
+// task's check configuration can be tried before any campaign exists.
+type TestExecutionHandler struct {
+	Store *store.DBStore
+	Queue queue.Queue
+}
+
+func NewTestExecutionHandler(s *store.DBStore, q queue.Queue) *TestExecutionHandler {
+	return &TestExecutionHandler{Store: s, Queue: q}
+}
+
+type testExecutionRequest struct {
+	CheckType   string                 `json:"checkType"`
+	Target      string                 `json:"target"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	WaitSeconds int                    `json:"waitSeconds"`
+}
+
+// TestMasterTaskExecutionHandler runs the master task's check. CheckType,
+// target and parameters default to the task's own and may be overridden with
+// unsaved values from the editor. The task's result policy is applied.
+func (h *TestExecutionHandler) TestMasterTaskExecutionHandler(c *gin.Context) {
+	taskID := c.Param("id")
+	masterTaskID, err := uuid.Parse(taskID)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid task ID", err)
+		return
+	}
+	task, err := h.Store.GetTaskByID(taskID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve task", err)
+		return
+	}
+	if task == nil {
+		sendError(c, http.StatusNotFound, "Task not found", nil)
+		return
+	}
+
+	var req testExecutionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			sendError(c, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+	if req.CheckType == "" && task.CheckType != nil {
+		req.CheckType = *task.CheckType
+	}
+	if req.Target == "" && task.Target != nil {
+		req.Target = *task.Target
+	}
+	if req.Parameters == nil {
+		req.Parameters = task.Parameters
+	}
+	h.runTestExecution(c, req, &masterTaskID)
+}
+
+// TestCheckHandler runs an ad-hoc check given its check type, target and parameters.
+func (h *TestExecutionHandler) TestCheckHandler(c *gin.Context) {
+	var req testExecutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	h.runTestExecution(c, req, nil)
+}
+
+// GetTestExecutionHandler returns a test execution that was still running
+// when its request stopped waiting.
+func (h *TestExecutionHandler) GetTestExecutionHandler(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid execution ID", err)
+		return
+	}
+	execution, err := h.Queue.GetTaskStatus(c.Request.Context(), executionID)
+	if err != nil || execution.Mode != queue.ModeTest {
+		sendError(c, http.StatusNotFound, "Test execution not found", nil)
+		return
+	}
+	c.JSON(testExecutionStatusCode(execution), testExecutionResponse(execution))
+}
+
+func (h *TestExecutionHandler) runTestExecution(c *gin.Context, req testExecutionRequest, masterTaskID *uuid.UUID) {
+	req.CheckType = strings.TrimSpace(req.CheckType)
+	if req.CheckType == "" {
+		sendError(c, http.StatusBadRequest, "A check type is required for a test execution", nil)
+		return
+	}
+	systemID, err := uuid.Parse(req.Target)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "A target (connected system ID) is required for a test execution", err)
+		return
+	}
+	connectedSystem, err := h.Store.GetConnectedSystemByID(req.Target)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, fmt.Sprintf("Error retrieving Connected System %s", req.Target), err)
+		return
+	}
+	if connectedSystem == nil {
+		sendError(c, http.StatusBadRequest, fmt.Sprintf("Connected System with ID %s not found", req.Target), nil)
+		return
+	}
+	if req.Parameters == nil {
+		req.Parameters = map[string]interface{}{}
+	}
+	wait := defaultTestExecutionWait
+	if req.WaitSeconds > 0 {
+		wait = time.Duration(req.WaitSeconds) * time.Second
+		if wait > maxTestExecutionWait {
+			wait = maxTestExecutionWait
+		}
+	}
+
+	request := &queue.TaskExecutionRequest{
+		ID:                uuid.New(),
+		TaskInstanceID:    uuid.Nil,
+		TaskType:          req.CheckType,
+		Parameters:        req.Parameters,
+		SystemConfig:      map[string]interface{}{},
+		CreatedAt:         time.Now(),
+		Status:            "pending",
+		Mode:              queue.ModeTest,
+		ConnectedSystemID: &systemID,
+		MasterTaskID:      masterTaskID,
+	}
+	if err := h.Queue.EnqueueTask(c.Request.Context(), request); err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to enqueue test execution", err)
+		return
+	}
+
+	execution := request
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(testExecutionPollEvery)
+	defer ticker.Stop()
+	for execution.CompletedAt == nil {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-deadline.C:
+			c.JSON(http.StatusAccepted, testExecutionResponse(execution))
+			return
+		case <-ticker.C:
+			latest, err := h.Queue.GetTaskStatus(c.Request.Context(), request.ID)
+			if err != nil {
+				sendError(c, http.StatusInternalServerError, "Failed to read test execution status", err)
+				return
+			}
+			execution = latest
+		}
+	}
+	c.JSON(http.StatusOK, testExecutionResponse(execution))
+}
+
+func testExecutionStatusCode(execution *queue.TaskExecutionRequest) int {
+	if execution.CompletedAt == nil {
+		return http.StatusAccepted
+	}
+	return http.StatusOK
+}
+
+func testExecutionResponse(execution *queue.TaskExecutionRequest) gin.H {
+	resp := gin.H{
+		"executionId": execution.ID,
+		"checkType":   execution.TaskType,
+		"target":      execution.ConnectedSystemID,
+		"parameters":  execution.Parameters,
+		"status":      execution.Status,
+		"createdAt":   execution.CreatedAt,
+		"completedAt": execution.CompletedAt,
+	}
+	if execution.ErrorMessage != nil && *execution.ErrorMessage != "" {
+		resp["errorMessage"] = *execution.ErrorMessage
+	}
+	if len(execution.Result) > 0 {
+		resp["output"] = json.RawMessage(execution.Result)
+	}
+	return resp
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/auth"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/store"
+	"github.com/vdparikh/compliance-automation/backend/utils"
+	"github.com/vdparikh/compliance-automation/backend/vulnscan"
+)
+
+// maxVulnerabilityReportSize bounds uploads; large image scans are a few MB.
+const maxVulnerabilityReportSize = 64 << 20
+
+type VulnerabilityHandler struct {
+	Store *store.DBStore
+}
+
+func NewVulnerabilityHandler(s *store.DBStore) *VulnerabilityHandler {
+	return &VulnerabilityHandler{Store: s}
+}
+
+// IngestVulnerabilityReportHandler accepts a scanner report as multipart form data.
+// Fields: file (required), format (trivy, grype, nessus or sarif; detected when omitted),
+// asset (overrides the asset named in the report) and policy (e.g. "critical:15, high:30";
+// defaults to the task's "policy" parameter).
+// The raw report is attached as evidence, the findings are stored and the task is
+// passed or failed against the policy.
+func (h *VulnerabilityHandler) IngestVulnerabilityReportHandler(c *gin.Context) {
+	instanceID := c.Param("id")
+	if rejectIfTaskInstanceFrozen(c, h.Store, instanceID) {
+		return
+	}
+
+	claimsValue, exists := c.Get(string(auth.ContextKeyClaims))
+	if !exists {
+		sendError(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+	claims, ok := claimsValue.(*auth.Claims)
+	if !ok || claims == nil || claims.UserID == "" {
+		sendError(c, http.StatusInternalServerError, "Error processing user authentication claims", nil)
+		return
+	}
+	uploaderUserID := claims.UserID
+
+	taskInstance, err := h.Store.GetCampaignTaskInstanceByID(instanceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			sendError(c, http.StatusNotFound, "Campaign Task Instance not found", err)
+			return
+		}
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve campaign task instance", err)
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "File upload error", err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxVulnerabilityReportSize+1))
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "Failed to read uploaded report", err)
+		return
+	}
+	if len(data) > maxVulnerabilityReportSize {
+		sendError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Report exceeds %d MB", maxVulnerabilityReportSize>>20), nil)
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Request.FormValue("format")))
+	if format == "" {
+		if format, err = vulnscan.DetectFormat(data); err != nil {
+			sendError(c, http.StatusBadRequest, "Could not detect report format; set the format field", err)
+			return
+		}
+	}
+	findings, err := vulnscan.Parse(format, data)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid vulnerability report", err)
+		return
+	}
+
+	if asset := strings.TrimSpace(c.Request.FormValue("asset")); asset != "" {
+		for i := range findings {
+			findings[i].Asset = asset
+		}
+	}
+	for _, f := range findings {
+		if f.Asset == "" {
+			sendError(c, http.StatusBadRequest, fmt.Sprintf("The %s report does not name the scanned asset; set the asset field", format), nil)
+			return
+		}
+	}
+
+	var policy vulnscan.Policy
+	if p := c.Request.FormValue("policy"); strings.TrimSpace(p) != "" {
+		policy, err = vulnscan.ParsePolicy(p)
+	} else {
+		policy, err = vulnscan.PolicyFromParameters(taskInstance.Parameters)
+	}
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "Invalid vulnerability policy", err)
+		return
+	}
+
+	// Keep the raw report alongside other evidence for this task instance.
+	// Scanners in CI upload the same file name every time, so each report is
+	// stored under its own name and earlier evidence keeps pointing at its file.
+	fileName := strings.ReplaceAll(filepath.Base(header.Filename), " ", "_")
+	uploadDir := filepath.Join("./uploads/campaign_tasks/", instanceID)
+	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to create upload directory", err)
+		return
+	}
+	filePath := filepath.Join(uploadDir, uuid.NewString()+"_"+fileName)
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to save file", err)
+		return
+	}
+
+	description := fmt.Sprintf("%s vulnerability report (%d findings)", format, len(findings))
+	evidence := models.Evidence{
+		CampaignTaskInstanceID: &instanceID,
+		UploadedByUserID:       uploaderUserID,
+		FileName:               fileName,
+		FilePath:               filePath,
+		MimeType:               header.Header.Get("Content-Type"),
+		FileSize:               int64(len(data)),
+		Description:            &description,
+	}
+	if err := h.Store.CreateCampaignTaskInstanceEvidence(&evidence); err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to save evidence metadata", err)
+		return
+	}
+
+	report := models.VulnerabilityReport{
+		CampaignTaskInstanceID: &instanceID,
+		EvidenceID:             &evidence.ID,
+		Format:                 format,
+		UploadedByUserID:       &uploaderUserID,
+	}
+	if err := h.Store.CreateVulnerabilityReport(&report, findings); err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to save vulnerability findings", err)
+		return
+	}
+
+	evaluation := policy.Evaluate(findings, report.CreatedAt)
+	status := common.StatusSuccess
+	if !evaluation.Passed {
+		status = common.StatusFailed
+	}
+	output, err := json.Marshal(gin.H{
+		"message":     fmt.Sprintf("%d findings across %d assets; %d violate policy %s", evaluation.TotalFindings, len(evaluation.Assets), len(evaluation.Violations), evaluation.Policy),
+		"report_id":   report.ID,
+		"evidence_id": evidence.ID,
+		"format":      format,
+		"evaluation":  evaluation,
+	})
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to encode evaluation result", err)
+		return
+	}
+	result := models.CampaignTaskInstanceResult{
+		CampaignTaskInstanceID: instanceID,
+		ExecutedByUserID:       &uploaderUserID,
+		Timestamp:              time.Now(),
+		Status:                 status,
+		Output:                 string(output),
+	}
+	if err := h.Store.CreateCampaignTaskInstanceResult(&result); err != nil {
+		log.Printf("Error storing vulnerability evaluation result for instance %s: %v", instanceID, err)
+	}
+	taskInstance.LastCheckedAt = &result.Timestamp
+	taskInstance.LastCheckStatus = &result.Status
+	if err := h.Store.UpdateCampaignTaskInstance(taskInstance); err != nil {
+		log.Printf("Error updating task instance %s with last check status: %v", instanceID, err)
+	}
+
+	uploaderUserName := "User"
+	if claims.Email != "" {
+		uploaderUserName = claims.Email
+	}
+	activityComment := models.Comment{
+		CampaignTaskInstanceID: &instanceID,
+		UserID:                 uploaderUserID,
+		Text:                   fmt.Sprintf("%s ingested %s vulnerability report %s: %s", uploaderUserName, format, fileName, strings.ToLower(status)),
+	}
+	if err := h.Store.CreateCampaignTaskInstanceComment(&activityComment); err != nil {
+		log.Printf("Failed to log vulnerability report comment for CTI %s: %v", instanceID, err)
+	}
+
+	auditChanges := map[string]interface{}{
+		"campaign_task_instance_id": instanceID,
+		"evidence_id":               evidence.ID,
+		"format":                    format,
+		"findings_count":            report.FindingsCount,
+		"policy":                    evaluation.Policy,
+		"violations":                len(evaluation.Violations),
+		"status":                    status,
+	}
+	if err := utils.RecordAuditLog(h.Store, &uploaderUserID, "ingest_vulnerability_report", "vulnerability_report", report.ID, auditChanges); err != nil {
+		log.Printf("Error recording audit log for vulnerability report %s (CTI: %s): %v", report.ID, instanceID, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"report":     report,
+		"evidence":   evidence,
+		"status":     status,
+		"evaluation": evaluation,
+	})
+}
+
+func (h *VulnerabilityHandler) GetVulnerabilityReportsHandler(c *gin.Context) {
+	instanceID := c.Param("id")
+	reports, err := h.Store.GetVulnerabilityReportsByInstanceID(instanceID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve vulnerability reports", err)
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+func (h *VulnerabilityHandler) GetVulnerabilityReportFindingsHandler(c *gin.Context) {
+	reportID := c.Param("reportId")
+	findings, err := h.Store.GetVulnerabilityFindingsByReportID(reportID)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve vulnerability findings", err)
+		return
+	}
+	c.JSON(http.StatusOK, findings)
+}
+
+// GetAssetVulnerabilityHistoryHandler returns every sighting of every finding on
+// ?asset=, across campaigns. The asset is a query parameter because image
+// references and URLs contain slashes.
+func (h *VulnerabilityHandler) GetAssetVulnerabilityHistoryHandler(c *gin.Context) {
+	asset := strings.TrimSpace(c.Query("asset"))
+	if asset == "" {
+		sendError(c, http.StatusBadRequest, "asset query parameter is required", nil)
+		return
+	}
+	history, err := h.Store.GetVulnerabilityHistoryByAsset(asset)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, "Failed to retrieve vulnerability history", err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParams(t *testing.T) {
+	params := map[string]interface{}{
+		"name":    "  prod ",
+		"port":    float64(22),
+		"retries": "3",
+		"bad":     "three",
+		"enabled": "true",
+		"strict":  false,
+		"list":    "a, b,,c \nd",
+	}
+
+	assert.Equal(t, "prod", StringParam(params, "name"))
+	assert.Equal(t, "22", StringParam(params, "port"))
+	assert.Equal(t, "", StringParam(params, "strict"))
+	assert.Equal(t, "", StringParam(params, "missing"))
+
+	assert.Equal(t, 22, IntParam(params, "port", 0))
+	assert.Equal(t, 3, IntParam(params, "retries", 0))
+	assert.Equal(t, 5, IntParam(params, "bad", 5))
+	assert.Equal(t, 5, IntParam(params, "missing", 5))
+
+	f, ok, err := FloatParam(params, "port")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 22.0, f)
+	f, ok, err = FloatParam(params, "retries")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, f)
+	_, ok, err = FloatParam(params, "missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, _, err = FloatParam(params, "bad")
+	assert.EqualError(t, err, `invalid bad "three": must be a number`)
+
+	assert.True(t, BoolParam(params, "enabled", false))
+	assert.False(t, BoolParam(params, "strict", true))
+	assert.True(t, BoolParam(params, "bad", true))
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, SplitList(StringParam(params, "list")))
+	assert.Nil(t, SplitList(""))
+}
+
+func TestUnreachable(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("query: %w", Unreachable(cause))
+
+	assert.ErrorIs(t, err, ErrSystemUnreachable)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "query: connection refused", err.Error())
+	assert.NotErrorIs(t, fmt.Errorf("path parameter is required"), ErrSystemUnreachable)
+	assert.Nil(t, Unreachable(nil))
+}
@@ -0,0 +1,22 @@
+package common
+
+import "errors"
+
+// ErrSystemUnreachable marks plugin errors caused by failing to connect to or
+// talk with the connected system, as opposed to errors in the task's
+// configuration. Only these count against a system's circuit breaker.
+var ErrSystemUnreachable = errors.New("connected system unreachable")
+
+// Unreachable marks err, a connection or transport error, so that
+// errors.Is(err, ErrSystemUnreachable) holds. The message is unchanged.
+func Unreachable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &unreachableError{err: err}
+}
+
+type unreachableError struct{ err error }
+
+func (e *unreachableError) Error() string   { return e.err.Error() }
+func (e *unreachableError) Unwrap() []error { return []error{ErrSystemUnreachable, e.err} }
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StringParam returns a task parameter as a trimmed string. Numbers are
+// formatted; other types and missing parameters give "".
+func StringParam(params map[string]interface{}, name string) string {
+	switch v := params[name].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// IntParam returns a task parameter given as a number or numeric string, or
+// def if it is missing or not a number.
+func IntParam(params map[string]interface{}, name string, def int) int {
+	switch v := params[name].(type) {
+	case float64:
+		return int(v)
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+// FloatParam returns a task parameter given as a number or numeric string.
+// ok is false when the parameter is missing or empty; a value that is not a
+// number is an error.
+func FloatParam(params map[string]interface{}, name string) (value float64, ok bool, err error) {
+	switch v := params[name].(type) {
+	case float64:
+		return v, true, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return 0, false, nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %s %q: must be a number", name, v)
+		}
+		return f, true, nil
+	}
+	return 0, false, nil
+}
+
+// BoolParam returns a task parameter given as a bool or a string such as
+// "true", or def if it is missing or not a bool.
+func BoolParam(params map[string]interface{}, name string, def bool) bool {
+	switch v := params[name].(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// SplitList splits a parameter value listing one entry per line or separated
+// by commas, dropping empty entries.
+func SplitList(s string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
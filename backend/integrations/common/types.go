@@ -2,9 +2,9 @@ package common
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/vdparikh/compliance-automation/backend/models"
-	"github.com/vdparikh/compliance-automation/backend/store"
 )
 
 // Execution Status Constants
@@ -12,6 +12,8 @@ const (
 	StatusSuccess = "Success"
 	StatusFailed  = "Failed"
 	StatusError   = "Error"
+	// StatusCancelled is recorded when a user cancels a running execution.
+	StatusCancelled = "Cancelled"
 	// Add other statuses used by plugins if any, e.g., "completed", "pending"
 	StatusCompleted = "completed"
 	StatusPending   = "pending"
@@ -22,6 +24,10 @@ const (
 type ExecutionResult struct {
 	Status string // Should be one of the Status* constants (e.g., StatusSuccess, StatusFailed, StatusCompleted)
 	Output string // Detailed output of the execution, often JSON or plain text
+	// Snapshot optionally lists the normalized resources the check inspected. When set,
+	// it is stored per connected system and compared with the previous snapshot to detect drift.
+	// Leave nil when the check took no snapshot; an empty slice records that no resources exist.
+	Snapshot []models.SnapshotResource
 }
 
 // CheckContext provides all necessary information for a plugin to execute a check.
@@ -31,8 +37,57 @@ type CheckContext struct {
 	TaskInstance *models.CampaignTaskInstance
 	// ConnectedSystem provides details about the target system against which the check is run, including its configuration.
 	ConnectedSystem *models.ConnectedSystem
-	// Store allows the plugin to access the database if needed (use with caution to maintain plugin isolation).
-	Store *store.DBStore
+	// Store gives the plugin read access to data stored for its task instance.
+	// It is scoped by the worker: other instances' data cannot be read through it.
+	Store PluginStore
 	// StdContext is the standard Go context, allowing for cancellation and deadlines.
 	StdContext context.Context
+	// Logger receives progress lines that are stored with the execution and
+	// streamed to users while the check runs. It may be nil; use Logf.
+	Logger ExecutionLogger
+}
+
+// Context returns StdContext, or context.Background() if the worker did not
+// set one.
+func (c CheckContext) Context() context.Context {
+	if c.StdContext == nil {
+		return context.Background()
+	}
+	return c.StdContext
+}
+
+// Finding is the outcome of a check for one resource, as reported in the
+// "findings" of a plugin's output.
+type Finding struct {
+	ResourceType string                 `json:"resource_type"`
+	Resource     string                 `json:"resource"`
+	Passed       bool                   `json:"passed"`
+	Issues       []string               `json:"issues,omitempty"`
+	Details      map[string]interface{} `json:"details,omitempty"`
+}
+
+// PluginStore is the stored data a plugin may read.
+type PluginStore interface {
+	GetLatestVulnerabilityReportByInstanceID(instanceID string) (*models.VulnerabilityReport, error)
+	GetVulnerabilityFindingsByReportID(reportID string) ([]models.VulnerabilityFinding, error)
+}
+
+// Log levels accepted by ExecutionLogger.
+const (
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// ExecutionLogger is the progress sink of a single execution.
+type ExecutionLogger interface {
+	Log(level, message string)
+}
+
+// Logf formats a progress line and sends it to the execution's Logger, if any.
+func (c CheckContext) Logf(level, format string, args ...interface{}) {
+	if c.Logger == nil {
+		return
+	}
+	c.Logger.Log(level, fmt.Sprintf(format, args...))
 }
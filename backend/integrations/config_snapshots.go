@@ -0,0 +1,65 @@
+package integrations
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/configdrift"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/store"
+)
+
+// recordConfigSnapshot stores the snapshot a plugin emitted, diffs it against
+// the previous one for the same system and check type, and records any drift
+// against the requirements the task instance covers. It returns a summary for
+// the result output, or nil when the snapshot could not be stored.
+func (s *TaskExecutionService) recordConfigSnapshot(taskInstance *models.CampaignTaskInstance, systemID, scope, executionID string, resources []models.SnapshotResource) map[string]interface{} {
+	snapshot := &models.ConfigSnapshot{
+		ConnectedSystemID:      systemID,
+		Scope:                  scope,
+		CampaignTaskInstanceID: &taskInstance.ID,
+		TaskExecutionID:        &executionID,
+		CapturedAt:             time.Now(),
+		Resources:              resources,
+	}
+	if err := s.store.CreateConfigSnapshot(snapshot); err != nil {
+		log.Printf("Error storing config snapshot for task instance %s: %v", taskInstance.ID, err)
+		return nil
+	}
+	summary := map[string]interface{}{
+		"snapshot_id":    snapshot.ID,
+		"resource_count": snapshot.ResourceCount,
+	}
+
+	previous, err := s.store.GetPreviousConfigSnapshot(snapshot)
+	if errors.Is(err, store.ErrNotFound) {
+		summary["baseline"] = true
+		return summary
+	}
+	if err != nil {
+		log.Printf("Error loading previous config snapshot for %s: %v", snapshot.ID, err)
+		return summary
+	}
+
+	diff := configdrift.Diff(previous.Resources, snapshot.Resources)
+	diff.FromSnapshotID, diff.ToSnapshotID = previous.ID, snapshot.ID
+	summary["previous_snapshot_id"] = previous.ID
+	summary["added"] = len(diff.Added)
+	summary["removed"] = len(diff.Removed)
+	summary["changed"] = len(diff.Changed)
+	if diff.Empty() {
+		return summary
+	}
+
+	requirementIDs, err := s.store.GetRequirementIDsForTaskInstance(taskInstance.ID)
+	if err != nil {
+		log.Printf("Error loading requirements for drift on task instance %s: %v", taskInstance.ID, err)
+	}
+	events := configdrift.Events(diff, snapshot, requirementIDs, snapshot.CapturedAt)
+	if err := s.store.CreateConfigDriftEvents(events); err != nil {
+		log.Printf("Error storing drift events for snapshot %s: %v", snapshot.ID, err)
+	}
+	summary["drifted_requirement_ids"] = requirementIDs
+	return summary
+}
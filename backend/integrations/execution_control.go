@@ -0,0 +1,60 @@
+package integrations
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+)
+
+// cancelPollInterval is how often a running execution checks whether a user
+// asked for it to be cancelled.
+const cancelPollInterval = 2 * time.Second
+
+// storeExecutionLogger persists a plugin's progress lines for one execution.
+type storeExecutionLogger struct {
+	store       WorkerStore
+	executionID string
+}
+
+func (l *storeExecutionLogger) Log(level, message string) {
+	if err := l.store.AppendTaskExecutionLog(l.executionID, level, message); err != nil {
+		log.Printf("Error storing log line for execution %s: %v", l.executionID, err)
+	}
+}
+
+var _ common.ExecutionLogger = (*storeExecutionLogger)(nil)
+
+// watchForCancellation polls the queue until ctx is done and calls cancel when
+// the execution is marked cancelling. The returned flag reports whether that happened.
+func (s *TaskExecutionService) watchForCancellation(ctx context.Context, executionID uuid.UUID, cancel context.CancelFunc) *atomic.Bool {
+	requested := &atomic.Bool{}
+	go func() {
+		ticker := time.NewTicker(cancelPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				execution, err := s.queue.GetTaskStatus(ctx, executionID)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Printf("Error checking cancellation of execution %s: %v", executionID, err)
+					}
+					continue
+				}
+				if execution.Status == queue.StatusCancelling {
+					requested.Store(true)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return requested
+}
@@ -13,8 +13,12 @@ type IntegrationPlugin interface {
 	ID() string
 	// Name provides a human-readable name for the plugin.
 	Name() string
+	// Version is the plugin's semantic version (e.g. "1.2.0"). Bump it whenever
+	// check logic changes so results can be traced to the code that produced them.
+	Version() string
 	// GetCheckTypeConfigurations returns a map of check type keys to their configurations.
 	// The key (e.g., "http_get_check") should be unique and used by the frontend.
+	// Each configuration's SchemaVersion is bumped when its parameters change.
 	GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration
 	// Initialize is called when the plugin is registered and configured.
 	// It can take a map of configurations specific to this plugin instance
@@ -1,6 +1,7 @@
 package awschecker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,6 +11,7 @@ import (
 	"github.com/vdparikh/compliance-automation/backend/integrations/common" // Import the common package
 	"github.com/vdparikh/compliance-automation/backend/models"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -20,6 +22,7 @@ import (
 const (
 	PluginID_AWSChecker                    = "aws-checker-v1"
 	PluginName_AWSChecker                  = "AWS Infrastructure Checker"
+	PluginVersion_AWSChecker               = "1.1.0"
 	CheckTypeKey_S3BucketDefaultEncryption = "aws_s3_bucket_default_encryption_check"
 	CheckTypeKey_RDSUnencryptedInstances   = "aws_rds_unencrypted_instances_check"
 )
@@ -46,6 +49,10 @@ func (p *AWSChecker) Name() string {
 	return PluginName_AWSChecker
 }
 
+func (p *AWSChecker) Version() string {
+	return PluginVersion_AWSChecker
+}
+
 func (p *AWSChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		CheckTypeKey_S3BucketDefaultEncryption: {
@@ -129,9 +136,16 @@ func (p *AWSChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string)
 		var unencryptedBuckets []string
 		var checkedBuckets []string
 		var errorsEncountered []string
+		// A snapshot is only taken when every bucket is scanned, so consecutive
+		// snapshots cover the same set and a missing bucket really was removed.
+		var snapshot []models.SnapshotResource
+		if bucketNameParam == "" {
+			snapshot = []models.SnapshotResource{}
+		}
 
 		for _, bucketName := range bucketsToCheck {
 			checkedBuckets = append(checkedBuckets, bucketName)
+			attrs := map[string]interface{}{"default_encryption": false}
 			encryptionOutput, err := s3Client.GetBucketEncryption(ctx.StdContext, &s3.GetBucketEncryptionInput{
 				Bucket: &bucketName,
 			})
@@ -145,14 +159,27 @@ func (p *AWSChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string)
 					errMsg := fmt.Sprintf("Failed to get encryption for bucket %s: %s", bucketName, err.Error())
 					errorsEncountered = append(errorsEncountered, errMsg)
 					log.Println(errMsg)
+					delete(attrs, "default_encryption")
+				}
+			} else if encryptionOutput.ServerSideEncryptionConfiguration == nil || len(encryptionOutput.ServerSideEncryptionConfiguration.Rules) == 0 {
+				unencryptedBuckets = append(unencryptedBuckets, bucketName+" (Default encryption rule not configured or empty)")
+			} else {
+				// If Rules exist, default encryption is considered enabled. Further checks on specific algorithms could be added.
+				attrs["default_encryption"] = true
+				if def := encryptionOutput.ServerSideEncryptionConfiguration.Rules[0].ApplyServerSideEncryptionByDefault; def != nil {
+					attrs["sse_algorithm"] = string(def.SSEAlgorithm)
+					if def.KMSMasterKeyID != nil {
+						attrs["kms_key_id"] = *def.KMSMasterKeyID
+					}
 				}
-				continue
 			}
 
-			if encryptionOutput.ServerSideEncryptionConfiguration == nil || len(encryptionOutput.ServerSideEncryptionConfiguration.Rules) == 0 {
-				unencryptedBuckets = append(unencryptedBuckets, bucketName+" (Default encryption rule not configured or empty)")
+			if snapshot != nil {
+				for k, v := range bucketPublicAccessAttributes(ctx.StdContext, s3Client, bucketName) {
+					attrs[k] = v
+				}
+				snapshot = append(snapshot, models.SnapshotResource{ResourceType: "aws_s3_bucket", ResourceID: bucketName, Attributes: attrs})
 			}
-			// If Rules exist, default encryption is considered enabled. Further checks on specific algorithms could be added.
 		}
 
 		resultStatus := common.StatusSuccess
@@ -168,7 +195,7 @@ func (p *AWSChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string)
 			"errors":                errorsEncountered,
 		}
 		outputJSON, _ := json.Marshal(outputData)
-		return common.ExecutionResult{Status: resultStatus, Output: string(outputJSON)}, nil
+		return common.ExecutionResult{Status: resultStatus, Output: string(outputJSON), Snapshot: snapshot}, nil
 
 	case CheckTypeKey_RDSUnencryptedInstances:
 		// instanceIdentifierParam, _ := ctx.TaskInstance.Parameters["instanceIdentifier"].(string)
@@ -186,5 +213,27 @@ func (p *AWSChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string)
 	}
 }
 
+// bucketPublicAccessAttributes reads a bucket's public access block for the
+// configuration snapshot. A bucket without one reports it as not configured;
+// other errors leave the attributes out rather than fail the check.
+func bucketPublicAccessAttributes(ctx context.Context, client *s3.Client, bucketName string) map[string]interface{} {
+	out, err := client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: &bucketName})
+	if err != nil {
+		if strings.Contains(err.Error(), "NoSuchPublicAccessBlockConfiguration") {
+			return map[string]interface{}{"public_access_block_configured": false}
+		}
+		log.Printf("Failed to get public access block for bucket %s: %v", bucketName, err)
+		return nil
+	}
+	attrs := map[string]interface{}{"public_access_block_configured": true}
+	if cfg := out.PublicAccessBlockConfiguration; cfg != nil {
+		attrs["block_public_acls"] = aws.ToBool(cfg.BlockPublicAcls)
+		attrs["ignore_public_acls"] = aws.ToBool(cfg.IgnorePublicAcls)
+		attrs["block_public_policy"] = aws.ToBool(cfg.BlockPublicPolicy)
+		attrs["restrict_public_buckets"] = aws.ToBool(cfg.RestrictPublicBuckets)
+	}
+	return attrs
+}
+
 // Ensure AWSChecker implements the IntegrationPlugin interface
 var _ integrations.IntegrationPlugin = (*AWSChecker)(nil)
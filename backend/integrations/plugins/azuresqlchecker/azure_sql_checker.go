@@ -23,6 +23,10 @@ func (p *AzureSQLChecker) Name() string {
 	return "Azure SQL Encryption Checker"
 }
 
+func (p *AzureSQLChecker) Version() string {
+	return "1.0.0"
+}
+
 func (p *AzureSQLChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		"azure_sql_encryption": {
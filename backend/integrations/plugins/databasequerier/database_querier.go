@@ -25,6 +25,10 @@ func (p *Plugin) Name() string {
 	return "Database Query Integration"
 }
 
+func (p *Plugin) Version() string {
+	return "1.0.0"
+}
+
 func (p *Plugin) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		"database_query_check": {
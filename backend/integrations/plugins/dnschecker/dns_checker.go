@@ -0,0 +1,529 @@
+package dnschecker
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	PluginID_DNSChecker           = "dns_checker_v1"
+	PluginName_DNSChecker         = "DNS and Email Security Record Checker"
+	PluginVersion_DNSChecker      = "1.0.0"
+	CheckTypeKey_DNSSPF           = "dns_spf"
+	CheckTypeKey_DNSDKIM          = "dns_dkim"
+	CheckTypeKey_DNSDMARC         = "dns_dmarc"
+	CheckTypeKey_DNSCAA           = "dns_caa"
+	CheckTypeKey_DNSSEC           = "dns_dnssec"
+	CheckTypeKey_DNSDanglingCNAME = "dns_dangling_cname"
+
+	// spfLookupLimit is the RFC 7208 limit on DNS-querying SPF terms.
+	spfLookupLimit  = 10
+	maxCNAMEChain   = 8
+	defaultDKIMBits = 1024
+	defaultTimeout  = time.Minute
+)
+
+var dmarcPolicyStrength = map[string]int{"none": 0, "quarantine": 1, "reject": 2}
+
+type DNSChecker struct{}
+
+func New() *DNSChecker {
+	return &DNSChecker{}
+}
+
+func (p *DNSChecker) ID() string {
+	return PluginID_DNSChecker
+}
+
+func (p *DNSChecker) Name() string {
+	return PluginName_DNSChecker
+}
+
+func (p *DNSChecker) Version() string {
+	return PluginVersion_DNSChecker
+}
+
+func (p *DNSChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	domainsParam := models.ParameterDefinition{Name: "domains", Label: "Domains", Type: "text", Required: true, Placeholder: "example.com, example.org", HelpText: "Comma-separated list of domains to check."}
+	resolverParam := models.ParameterDefinition{Name: "resolver", Label: "Resolver (Optional)", Type: "text", Placeholder: "1.1.1.1:53", HelpText: "Recursive resolver to query. Defaults to the worker's system resolver."}
+	config := func(label string, params ...models.ParameterDefinition) models.CheckTypeConfiguration {
+		return models.CheckTypeConfiguration{
+			Label:       label,
+			Parameters:  append([]models.ParameterDefinition{domainsParam}, append(params, resolverParam)...),
+			TargetType:  "domain",
+			TargetLabel: "Domains",
+		}
+	}
+
+	return map[string]models.CheckTypeConfiguration{
+		CheckTypeKey_DNSSPF: config("SPF Record",
+			models.ParameterDefinition{Name: "requireHardFail", Label: "Require -all", Type: "select", Options: []string{"false", "true"}, HelpText: "Fail records ending in ~all. ?all and +all always fail."},
+		),
+		CheckTypeKey_DNSDKIM: config("DKIM Selectors",
+			models.ParameterDefinition{Name: "selectors", Label: "Selectors", Type: "text", Required: true, Placeholder: "google, selector1", HelpText: "Comma-separated DKIM selectors that must publish a key for every domain."},
+			models.ParameterDefinition{Name: "minKeyBits", Label: "Minimum RSA Key Size", Type: "number", Placeholder: strconv.Itoa(defaultDKIMBits), HelpText: "Minimum RSA modulus size in bits."},
+		),
+		CheckTypeKey_DNSDMARC: config("DMARC Policy",
+			models.ParameterDefinition{Name: "minimumPolicy", Label: "Minimum Policy", Type: "select", Options: []string{"quarantine", "reject", "none"}, HelpText: "Weakest acceptable p= (and sp=) value. Defaults to quarantine."},
+			models.ParameterDefinition{Name: "requireReporting", Label: "Require Aggregate Reports", Type: "select", Options: []string{"false", "true"}, HelpText: "Fail records without an rua= reporting address."},
+		),
+		CheckTypeKey_DNSCAA: config("CAA Records",
+			models.ParameterDefinition{Name: "allowedIssuers", Label: "Allowed Issuers (Optional)", Type: "text", Placeholder: "letsencrypt.org, digicert.com", HelpText: "Comma-separated CA domains. Any other issue/issuewild value fails."},
+		),
+		CheckTypeKey_DNSSEC:           config("DNSSEC Validation"),
+		CheckTypeKey_DNSDanglingCNAME: config("Dangling CNAMEs"),
+	}
+}
+
+func (p *DNSChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
+	params := ctx.TaskInstance.Parameters
+	domains := common.SplitList(common.StringParam(params, "domains"))
+	if len(domains) == 0 {
+		return common.ExecutionResult{Status: common.StatusError, Output: "domains parameter is required"}, fmt.Errorf("domains parameter is required")
+	}
+
+	var check func(context.Context, *resolver, string, map[string]interface{}) (common.Finding, error)
+	switch checkTypeKey {
+	case CheckTypeKey_DNSSPF:
+		check = checkSPF
+	case CheckTypeKey_DNSDKIM:
+		if len(common.SplitList(common.StringParam(params, "selectors"))) == 0 {
+			return common.ExecutionResult{Status: common.StatusError, Output: "selectors parameter is required"}, fmt.Errorf("selectors parameter is required")
+		}
+		check = checkDKIM
+	case CheckTypeKey_DNSDMARC:
+		check = checkDMARC
+	case CheckTypeKey_DNSCAA:
+		check = checkCAA
+	case CheckTypeKey_DNSSEC:
+		check = checkDNSSEC
+	case CheckTypeKey_DNSDanglingCNAME:
+		check = checkDanglingCNAME
+	default:
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+
+	res, err := newResolver(common.StringParam(params, "resolver"))
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx.Context(), defaultTimeout)
+	defer cancel()
+
+	findings := make([]common.Finding, 0, len(domains))
+	failed, errored := 0, 0
+	var firstErr error
+	for _, domain := range domains {
+		domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+		f, err := check(runCtx, res, domain, params)
+		if err != nil {
+			if runCtx.Err() != nil {
+				return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+			}
+			// One domain failing to resolve says nothing about the others.
+			f = common.Finding{ResourceType: "domain", Resource: domain, Issues: []string{"lookup failed: " + err.Error()}}
+			errored++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		f.Passed = len(f.Issues) == 0
+		if !f.Passed {
+			failed++
+		}
+		findings = append(findings, f)
+	}
+
+	if errored == len(domains) {
+		err := fmt.Errorf("no domain could be checked: %w", firstErr)
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	status := common.StatusSuccess
+	message := fmt.Sprintf("All %d domain(s) passed.", len(findings))
+	if failed > 0 {
+		status = common.StatusFailed
+		message = fmt.Sprintf("%d of %d domain(s) failed.", failed, len(findings))
+	}
+	result := map[string]interface{}{
+		"message":         message,
+		"resolver":        res.addr,
+		"domains_checked": len(findings),
+		"domains_failed":  failed,
+		"findings":        findings,
+	}
+	jsonOut, _ := json.Marshal(result)
+	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
+}
+
+func checkSPF(ctx context.Context, r *resolver, domain string, params map[string]interface{}) (common.Finding, error) {
+	f := common.Finding{ResourceType: "domain", Resource: domain, Details: map[string]interface{}{}}
+	records, err := spfRecords(ctx, r, domain)
+	if err != nil {
+		return f, err
+	}
+	switch len(records) {
+	case 0:
+		f.Issues = append(f.Issues, "no SPF record published")
+		return f, nil
+	case 1:
+	default:
+		f.Issues = append(f.Issues, fmt.Sprintf("%d SPF records published; exactly one is allowed", len(records)))
+	}
+	record := records[0]
+	f.Details["record"] = record
+
+	requireHardFail, _ := strconv.ParseBool(common.StringParam(params, "requireHardFail"))
+	all, hasRedirect := "", false
+	for _, term := range strings.Fields(record)[1:] {
+		lower := strings.ToLower(term)
+		switch {
+		case lower == "all" || (len(lower) == 4 && strings.HasSuffix(lower, "all")):
+			all = lower
+		case strings.HasPrefix(lower, "redirect="):
+			hasRedirect = true
+		}
+	}
+	switch all {
+	case "all", "+all":
+		f.Issues = append(f.Issues, "SPF record authorises any sender (+all)")
+	case "?all":
+		f.Issues = append(f.Issues, "SPF record ends in neutral ?all")
+	case "~all":
+		if requireHardFail {
+			f.Issues = append(f.Issues, "SPF record ends in ~all; -all is required")
+		}
+	case "":
+		if !hasRedirect {
+			f.Issues = append(f.Issues, "SPF record has no all mechanism")
+		}
+	}
+
+	lookups, err := spfLookupCount(ctx, r, record, map[string]bool{domain: true})
+	if err != nil {
+		return f, err
+	}
+	f.Details["dns_lookups"] = lookups
+	if lookups > spfLookupLimit {
+		f.Issues = append(f.Issues, fmt.Sprintf("SPF evaluation needs %d DNS lookups; the limit is %d", lookups, spfLookupLimit))
+	}
+	return f, nil
+}
+
+func spfRecords(ctx context.Context, r *resolver, domain string) ([]string, error) {
+	txts, err := r.txt(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, t := range txts {
+		if lower := strings.ToLower(t); lower == "v=spf1" || strings.HasPrefix(lower, "v=spf1 ") {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// spfLookupCount counts the DNS-querying terms of record, following include
+// and redirect targets recursively.
+func spfLookupCount(ctx context.Context, r *resolver, record string, visited map[string]bool) (int, error) {
+	count := 0
+	for _, term := range strings.Fields(record)[1:] {
+		lower := strings.TrimLeft(strings.ToLower(term), "+-~?")
+		var target string
+		switch {
+		case strings.HasPrefix(lower, "include:"):
+			target = strings.TrimPrefix(lower, "include:")
+		case strings.HasPrefix(lower, "redirect="):
+			target = strings.TrimPrefix(lower, "redirect=")
+		case lower == "a" || lower == "mx" || lower == "ptr" ||
+			strings.HasPrefix(lower, "a:") || strings.HasPrefix(lower, "a/") ||
+			strings.HasPrefix(lower, "mx:") || strings.HasPrefix(lower, "mx/") ||
+			strings.HasPrefix(lower, "ptr:") || strings.HasPrefix(lower, "exists:"):
+			count++
+			continue
+		default:
+			continue
+		}
+		count++
+		if visited[target] || count > spfLookupLimit {
+			continue
+		}
+		visited[target] = true
+		nested, err := spfRecords(ctx, r, target)
+		if err != nil {
+			return 0, err
+		}
+		if len(nested) > 0 {
+			n, err := spfLookupCount(ctx, r, nested[0], visited)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+	}
+	return count, nil
+}
+
+func checkDKIM(ctx context.Context, r *resolver, domain string, params map[string]interface{}) (common.Finding, error) {
+	f := common.Finding{ResourceType: "domain", Resource: domain}
+	minBits := common.IntParam(params, "minKeyBits", defaultDKIMBits)
+	selectors := map[string]interface{}{}
+	for _, selector := range common.SplitList(common.StringParam(params, "selectors")) {
+		name := selector + "._domainkey." + domain
+		txts, err := r.txt(ctx, name)
+		if err != nil {
+			return f, err
+		}
+		var record string
+		for _, t := range txts {
+			if _, ok := parseTags(t)["p"]; ok {
+				record = t
+				break
+			}
+		}
+		if record == "" {
+			f.Issues = append(f.Issues, fmt.Sprintf("no DKIM key published for selector %s", selector))
+			continue
+		}
+		tags := parseTags(record)
+		detail := map[string]interface{}{"key_type": firstNonEmpty(tags["k"], "rsa")}
+		selectors[selector] = detail
+		if tags["p"] == "" {
+			f.Issues = append(f.Issues, fmt.Sprintf("DKIM key for selector %s is revoked (empty p=)", selector))
+			continue
+		}
+		if detail["key_type"] != "rsa" {
+			continue
+		}
+		bits, err := rsaKeyBits(tags["p"])
+		if err != nil {
+			f.Issues = append(f.Issues, fmt.Sprintf("DKIM key for selector %s cannot be parsed: %v", selector, err))
+			continue
+		}
+		detail["key_bits"] = bits
+		if bits < minBits {
+			f.Issues = append(f.Issues, fmt.Sprintf("DKIM key for selector %s is %d bits; at least %d required", selector, bits, minBits))
+		}
+	}
+	f.Details = map[string]interface{}{"selectors": selectors}
+	return f, nil
+}
+
+func rsaKeyBits(p string) (int, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(p), ""))
+	if err != nil {
+		return 0, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		if rsaKey, rsaErr := x509.ParsePKCS1PublicKey(der); rsaErr == nil {
+			return rsaKey.N.BitLen(), nil
+		}
+		return 0, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return 0, fmt.Errorf("key is %T, not RSA", key)
+	}
+	return rsaKey.N.BitLen(), nil
+}
+
+func checkDMARC(ctx context.Context, r *resolver, domain string, params map[string]interface{}) (common.Finding, error) {
+	f := common.Finding{ResourceType: "domain", Resource: domain}
+	txts, err := r.txt(ctx, "_dmarc."+domain)
+	if err != nil {
+		return f, err
+	}
+	var records []string
+	for _, t := range txts {
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(t)), "V=DMARC1") {
+			records = append(records, t)
+		}
+	}
+	switch len(records) {
+	case 0:
+		f.Issues = append(f.Issues, "no DMARC record published")
+		return f, nil
+	case 1:
+	default:
+		f.Issues = append(f.Issues, fmt.Sprintf("%d DMARC records published; exactly one is allowed", len(records)))
+	}
+
+	tags := parseTags(records[0])
+	f.Details = map[string]interface{}{"record": records[0]}
+	minimum := strings.ToLower(firstNonEmpty(common.StringParam(params, "minimumPolicy"), "quarantine"))
+	minStrength, ok := dmarcPolicyStrength[minimum]
+	if !ok {
+		return f, fmt.Errorf("invalid minimumPolicy %q", minimum)
+	}
+
+	policy := strings.ToLower(tags["p"])
+	strength, ok := dmarcPolicyStrength[policy]
+	switch {
+	case !ok:
+		f.Issues = append(f.Issues, fmt.Sprintf("invalid or missing policy p=%q", tags["p"]))
+	case strength < minStrength:
+		f.Issues = append(f.Issues, fmt.Sprintf("policy p=%s is weaker than %s", policy, minimum))
+	}
+	if sp, present := tags["sp"]; present {
+		if s, ok := dmarcPolicyStrength[strings.ToLower(sp)]; !ok || s < minStrength {
+			f.Issues = append(f.Issues, fmt.Sprintf("subdomain policy sp=%s is weaker than %s", sp, minimum))
+		}
+	}
+	if pct, present := tags["pct"]; present && pct != "100" {
+		f.Issues = append(f.Issues, fmt.Sprintf("policy applies to only %s%% of mail", pct))
+	}
+	if requireReporting, _ := strconv.ParseBool(common.StringParam(params, "requireReporting")); requireReporting && tags["rua"] == "" {
+		f.Issues = append(f.Issues, "no aggregate report address (rua=)")
+	}
+	return f, nil
+}
+
+// checkCAA finds the relevant CAA record set by climbing from domain towards
+// the root, as a CA does (RFC 8659 section 3).
+func checkCAA(ctx context.Context, r *resolver, domain string, params map[string]interface{}) (common.Finding, error) {
+	f := common.Finding{ResourceType: "domain", Resource: domain}
+	var records []*dns.CAA
+	var owner string
+	labels := dns.SplitDomainName(domain)
+	for i := 0; i < len(labels) && len(records) == 0; i++ {
+		owner = strings.Join(labels[i:], ".")
+		rrs, err := r.lookup(ctx, owner, dns.TypeCAA)
+		if err != nil {
+			return f, err
+		}
+		for _, rr := range rrs {
+			records = append(records, rr.(*dns.CAA))
+		}
+	}
+	if len(records) == 0 {
+		f.Issues = append(f.Issues, "no CAA records; any CA may issue certificates")
+		return f, nil
+	}
+
+	allowed := map[string]bool{}
+	for _, a := range common.SplitList(common.StringParam(params, "allowedIssuers")) {
+		allowed[strings.ToLower(a)] = true
+	}
+	var values []string
+	for _, caa := range records {
+		values = append(values, fmt.Sprintf("%d %s %q", caa.Flag, caa.Tag, caa.Value))
+		if len(allowed) == 0 || (caa.Tag != "issue" && caa.Tag != "issuewild") {
+			continue
+		}
+		issuer := strings.ToLower(strings.TrimSpace(strings.SplitN(caa.Value, ";", 2)[0]))
+		if issuer != "" && !allowed[issuer] {
+			f.Issues = append(f.Issues, fmt.Sprintf("CAA %s permits unapproved CA %s", caa.Tag, issuer))
+		}
+	}
+	f.Details = map[string]interface{}{"owner": owner, "records": values}
+	return f, nil
+}
+
+// checkDNSSEC relies on the resolver validating: a signed zone is reported as
+// authenticated (AD flag) only when the chain of trust verifies, and a zone
+// whose signatures do not verify is answered with SERVFAIL.
+func checkDNSSEC(ctx context.Context, r *resolver, domain string, _ map[string]interface{}) (common.Finding, error) {
+	f := common.Finding{ResourceType: "domain", Resource: domain}
+	keyResp, err := r.query(ctx, domain, dns.TypeDNSKEY)
+	if err != nil {
+		return f, err
+	}
+	switch keyResp.Rcode {
+	case dns.RcodeServerFailure:
+		f.Issues = append(f.Issues, "DNSSEC validation failed (SERVFAIL)")
+		return f, nil
+	case dns.RcodeSuccess, dns.RcodeNameError:
+	default:
+		return f, fmt.Errorf("query DNSKEY %s: %s", domain, dns.RcodeToString[keyResp.Rcode])
+	}
+	keys := answers(keyResp, dns.TypeDNSKEY)
+	resp, err := r.query(ctx, domain, dns.TypeSOA)
+	if err != nil {
+		return f, err
+	}
+	f.Details = map[string]interface{}{"dnskey_records": len(keys), "authenticated_data": resp.AuthenticatedData}
+	switch {
+	case resp.Rcode == dns.RcodeServerFailure:
+		f.Issues = append(f.Issues, "DNSSEC validation failed (SERVFAIL)")
+	case len(keys) == 0:
+		f.Issues = append(f.Issues, "zone is not signed (no DNSKEY records)")
+	case !resp.AuthenticatedData:
+		f.Issues = append(f.Issues, "answer is not DNSSEC-validated (AD flag not set); check the resolver validates")
+	}
+	return f, nil
+}
+
+// checkDanglingCNAME follows the CNAME chain at domain and fails when the
+// final target does not exist.
+func checkDanglingCNAME(ctx context.Context, r *resolver, domain string, _ map[string]interface{}) (common.Finding, error) {
+	f := common.Finding{ResourceType: "domain", Resource: domain}
+	var chain []string
+	name := domain
+	for i := 0; i < maxCNAMEChain; i++ {
+		rrs, err := r.lookup(ctx, name, dns.TypeCNAME)
+		if err != nil {
+			return f, err
+		}
+		if len(rrs) == 0 {
+			break
+		}
+		name = strings.TrimSuffix(rrs[0].(*dns.CNAME).Target, ".")
+		chain = append(chain, name)
+	}
+	f.Details = map[string]interface{}{"cname_chain": chain}
+	if len(chain) == 0 {
+		return f, nil
+	}
+
+	resp, err := r.query(ctx, name, dns.TypeA)
+	if err != nil {
+		return f, err
+	}
+	switch resp.Rcode {
+	case dns.RcodeNameError:
+		f.Issues = append(f.Issues, fmt.Sprintf("CNAME target %s does not exist (NXDOMAIN)", name))
+	case dns.RcodeServerFailure:
+		f.Issues = append(f.Issues, fmt.Sprintf("CNAME target %s cannot be resolved (SERVFAIL)", name))
+	}
+	return f, nil
+}
+
+// parseTags parses "k=v; k=v" tag lists used by DKIM and DMARC records.
+func parseTags(record string) map[string]string {
+	tags := map[string]string{}
+	for _, part := range strings.Split(record, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	return tags
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+var _ integrations.IntegrationPlugin = (*DNSChecker)(nil)
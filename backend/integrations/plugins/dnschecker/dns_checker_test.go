@@ -0,0 +1,226 @@
+package dnschecker
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+type checkOutput struct {
+	DomainsChecked int              `json:"domains_checked"`
+	DomainsFailed  int              `json:"domains_failed"`
+	Findings       []common.Finding `json:"findings"`
+}
+
+// testZone answers from a fixed record set. Names listed in signed get the AD
+// flag and those in bogus SERVFAIL, mimicking a validating resolver.
+type testZone struct {
+	records map[string][]dns.RR
+	signed  map[string]bool
+	bogus   map[string]bool
+}
+
+func (z *testZone) add(t *testing.T, rrs ...string) {
+	t.Helper()
+	for _, s := range rrs {
+		rr, err := dns.NewRR(s)
+		require.NoError(t, err)
+		name := strings.ToLower(rr.Header().Name)
+		z.records[name] = append(z.records[name], rr)
+	}
+}
+
+func (z *testZone) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(req)
+	q := req.Question[0]
+	name := strings.ToLower(q.Name)
+	if z.bogus[name] {
+		m.Rcode = dns.RcodeServerFailure
+		w.WriteMsg(m)
+		return
+	}
+	rrs, exists := z.records[name]
+	if !exists {
+		m.Rcode = dns.RcodeNameError
+	}
+	for _, rr := range rrs {
+		if rr.Header().Rrtype == q.Qtype {
+			m.Answer = append(m.Answer, rr)
+		}
+	}
+	m.AuthenticatedData = z.signed[name]
+	w.WriteMsg(m)
+}
+
+func dkimKey(t *testing.T, bits int) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+// txtRR builds a TXT record, splitting long values into 255 byte strings.
+func txtRR(name, value string) string {
+	var parts []string
+	for len(value) > 255 {
+		parts = append(parts, fmt.Sprintf("%q", value[:255]))
+		value = value[255:]
+	}
+	parts = append(parts, fmt.Sprintf("%q", value))
+	return fmt.Sprintf("%s 300 IN TXT %s", name, strings.Join(parts, " "))
+}
+
+// startResolver serves good.example (well configured), bad.example (not) and
+// bogus.example (failing validation) on a local UDP port and returns its
+// address.
+func startResolver(t *testing.T) string {
+	t.Helper()
+	z := &testZone{records: map[string][]dns.RR{}, signed: map[string]bool{"good.example.": true}, bogus: map[string]bool{"bogus.example.": true}}
+	z.add(t,
+		`good.example. 300 IN TXT "v=spf1 include:_spf.mail.example -all"`,
+		`_spf.mail.example. 300 IN TXT "v=spf1 ip4:192.0.2.0/24 a mx -all"`,
+		`_dmarc.good.example. 300 IN TXT "v=DMARC1; p=reject; rua=mailto:dmarc@good.example"`,
+		txtRR("sel1._domainkey.good.example.", "v=DKIM1; k=rsa; p="+dkimKey(t, 2048)),
+		`good.example. 300 IN CAA 0 issue "letsencrypt.org"`,
+		`good.example. 300 IN DNSKEY 257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==`,
+		`good.example. 300 IN SOA ns1.good.example. hostmaster.good.example. 1 7200 3600 1209600 300`,
+		`www.good.example. 300 IN CNAME cdn.good.example.`,
+		`cdn.good.example. 300 IN A 192.0.2.10`,
+
+		`bad.example. 300 IN TXT "v=spf1 include:a.example include:b.example ?all"`,
+		`bad.example. 300 IN TXT "v=spf1 -all"`,
+		`a.example. 300 IN TXT "v=spf1 a mx ptr exists:x.example include:c.example"`,
+		`b.example. 300 IN TXT "v=spf1 a:1.example a:2.example a:3.example mx:4.example"`,
+		`c.example. 300 IN TXT "v=spf1 a mx"`,
+		`_dmarc.bad.example. 300 IN TXT "v=DMARC1; p=none; sp=none; pct=50"`,
+		txtRR("sel1._domainkey.bad.example.", "v=DKIM1; k=rsa; p="+dkimKey(t, 1024)),
+		`sel2._domainkey.bad.example. 300 IN TXT "v=DKIM1; p="`,
+		`example. 300 IN CAA 0 issue "shady-ca.example"`,
+		`bad.example. 300 IN SOA ns1.bad.example. hostmaster.bad.example. 1 7200 3600 1209600 300`,
+		`shop.bad.example. 300 IN CNAME bad-shop.azurewebsites.example.`,
+	)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	started := make(chan struct{})
+	srv := &dns.Server{PacketConn: pc, Handler: z, NotifyStartedFunc: func() { close(started) }}
+	go srv.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { srv.Shutdown() })
+	return pc.LocalAddr().String()
+}
+
+func runCheck(t *testing.T, checkType string, params map[string]interface{}) (common.ExecutionResult, checkOutput) {
+	t.Helper()
+	var out checkOutput
+	res := plugintest.Run(t, New(), common.CheckContext{
+		TaskInstance: &models.CampaignTaskInstance{Parameters: params},
+	}, checkType, &out)
+	return res, out
+}
+
+func TestSPF(t *testing.T) {
+	resolver := startResolver(t)
+	res, out := runCheck(t, CheckTypeKey_DNSSPF, map[string]interface{}{"domains": "good.example, bad.example, none.example", "resolver": resolver})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	good := plugintest.FindingFor(t, out.Findings, "good.example")
+	assert.True(t, good.Passed)
+	assert.EqualValues(t, 3, good.Details["dns_lookups"])
+
+	bad := plugintest.FindingFor(t, out.Findings, "bad.example")
+	assert.Contains(t, bad.Issues, "2 SPF records published; exactly one is allowed")
+	assert.Contains(t, bad.Issues, "SPF record ends in neutral ?all")
+	assert.Contains(t, bad.Issues, "SPF evaluation needs 13 DNS lookups; the limit is 10")
+	assert.Equal(t, []string{"no SPF record published"}, plugintest.FindingFor(t, out.Findings, "none.example").Issues)
+}
+
+func TestDKIM(t *testing.T) {
+	resolver := startResolver(t)
+	res, out := runCheck(t, CheckTypeKey_DNSDKIM, map[string]interface{}{"domains": "good.example,bad.example", "selectors": "sel1, sel2", "minKeyBits": float64(2048), "resolver": resolver})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, []string{"no DKIM key published for selector sel2"}, plugintest.FindingFor(t, out.Findings, "good.example").Issues)
+	assert.Equal(t, []string{
+		"DKIM key for selector sel1 is 1024 bits; at least 2048 required",
+		"DKIM key for selector sel2 is revoked (empty p=)",
+	}, plugintest.FindingFor(t, out.Findings, "bad.example").Issues)
+
+	res, _ = runCheck(t, CheckTypeKey_DNSDKIM, map[string]interface{}{"domains": "good.example", "selectors": "sel1", "resolver": resolver})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+}
+
+func TestDMARC(t *testing.T) {
+	resolver := startResolver(t)
+	_, out := runCheck(t, CheckTypeKey_DNSDMARC, map[string]interface{}{"domains": "good.example,bad.example", "requireReporting": "true", "resolver": resolver})
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "good.example").Passed)
+	assert.Equal(t, []string{
+		"policy p=none is weaker than quarantine",
+		"subdomain policy sp=none is weaker than quarantine",
+		"policy applies to only 50% of mail",
+		"no aggregate report address (rua=)",
+	}, plugintest.FindingFor(t, out.Findings, "bad.example").Issues)
+}
+
+func TestCAAClimbsToParent(t *testing.T) {
+	resolver := startResolver(t)
+	_, out := runCheck(t, CheckTypeKey_DNSCAA, map[string]interface{}{"domains": "good.example,bad.example", "allowedIssuers": "letsencrypt.org", "resolver": resolver})
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "good.example").Passed)
+	bad := plugintest.FindingFor(t, out.Findings, "bad.example")
+	assert.Equal(t, "example", bad.Details["owner"])
+	assert.Equal(t, []string{"CAA issue permits unapproved CA shady-ca.example"}, bad.Issues)
+}
+
+func TestDNSSEC(t *testing.T) {
+	resolver := startResolver(t)
+	_, out := runCheck(t, CheckTypeKey_DNSSEC, map[string]interface{}{"domains": "good.example,bad.example,bogus.example", "resolver": resolver})
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "good.example").Passed)
+	assert.Equal(t, []string{"zone is not signed (no DNSKEY records)"}, plugintest.FindingFor(t, out.Findings, "bad.example").Issues)
+	assert.Equal(t, []string{"DNSSEC validation failed (SERVFAIL)"}, plugintest.FindingFor(t, out.Findings, "bogus.example").Issues)
+}
+
+func TestLookupErrorIsThatDomainsFinding(t *testing.T) {
+	resolver := startResolver(t)
+	res, out := runCheck(t, CheckTypeKey_DNSSPF, map[string]interface{}{"domains": "bogus.example,good.example", "resolver": resolver})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 1, out.DomainsFailed)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "good.example").Passed)
+	assert.Equal(t, []string{"lookup failed: query TXT bogus.example: SERVFAIL"}, plugintest.FindingFor(t, out.Findings, "bogus.example").Issues)
+
+	res, err := New().ExecuteCheck(common.CheckContext{
+		TaskInstance: &models.CampaignTaskInstance{Parameters: map[string]interface{}{"domains": "bogus.example", "resolver": resolver}},
+	}, CheckTypeKey_DNSSPF)
+	assert.Error(t, err, "nothing could be checked")
+	assert.Equal(t, common.StatusError, res.Status)
+}
+
+func TestDanglingCNAME(t *testing.T) {
+	resolver := startResolver(t)
+	res, out := runCheck(t, CheckTypeKey_DNSDanglingCNAME, map[string]interface{}{"domains": "www.good.example,shop.bad.example,good.example", "resolver": resolver})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 1, out.DomainsFailed)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "www.good.example").Passed)
+	assert.Equal(t, []string{"CNAME target bad-shop.azurewebsites.example does not exist (NXDOMAIN)"}, plugintest.FindingFor(t, out.Findings, "shop.bad.example").Issues)
+}
+
+func TestMissingDomainsIsAnError(t *testing.T) {
+	res, err := New().ExecuteCheck(common.CheckContext{
+		TaskInstance: &models.CampaignTaskInstance{Parameters: map[string]interface{}{}},
+	}, CheckTypeKey_DNSSPF)
+	assert.Error(t, err)
+	assert.Equal(t, common.StatusError, res.Status)
+}
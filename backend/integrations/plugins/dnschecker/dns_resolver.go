@@ -0,0 +1,100 @@
+package dnschecker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+)
+
+const (
+	resolvConfPath = "/etc/resolv.conf"
+	queryTimeout   = 5 * time.Second
+)
+
+// resolver sends queries to a single recursive resolver.
+type resolver struct {
+	addr string
+	udp  *dns.Client
+	tcp  *dns.Client
+}
+
+// newResolver returns a resolver for addr ("host" or "host:port"). An empty
+// addr uses the first nameserver in /etc/resolv.conf.
+func newResolver(addr string) (*resolver, error) {
+	if addr == "" {
+		conf, err := dns.ClientConfigFromFile(resolvConfPath)
+		if err != nil || len(conf.Servers) == 0 {
+			return nil, fmt.Errorf("no resolver configured and none found in %s", resolvConfPath)
+		}
+		addr = net.JoinHostPort(conf.Servers[0], conf.Port)
+	} else if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "53")
+	}
+	return &resolver{
+		addr: addr,
+		udp:  &dns.Client{Net: "udp", Timeout: queryTimeout},
+		tcp:  &dns.Client{Net: "tcp", Timeout: queryTimeout},
+	}, nil
+}
+
+// query asks for name/qtype with DNSSEC OK set, retrying over TCP when the UDP
+// answer is truncated.
+func (r *resolver) query(ctx context.Context, name string, qtype uint16) (*dns.Msg, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	m.RecursionDesired = true
+	m.SetEdns0(4096, true)
+
+	resp, _, err := r.udp.ExchangeContext(ctx, m, r.addr)
+	if err == nil && resp.Truncated {
+		resp, _, err = r.tcp.ExchangeContext(ctx, m, r.addr)
+	}
+	if err != nil {
+		return nil, common.Unreachable(fmt.Errorf("query %s %s via %s: %w", dns.TypeToString[qtype], name, r.addr, err))
+	}
+	return resp, nil
+}
+
+// lookup returns the answer records of qtype. NXDOMAIN yields no records;
+// other failure codes are errors.
+func (r *resolver) lookup(ctx context.Context, name string, qtype uint16) ([]dns.RR, error) {
+	resp, err := r.query(ctx, name, qtype)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.Rcode {
+	case dns.RcodeSuccess, dns.RcodeNameError:
+	default:
+		return nil, fmt.Errorf("query %s %s: %s", dns.TypeToString[qtype], name, dns.RcodeToString[resp.Rcode])
+	}
+	return answers(resp, qtype), nil
+}
+
+// answers returns the records of qtype in the answer section of resp.
+func answers(resp *dns.Msg, qtype uint16) []dns.RR {
+	var out []dns.RR
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == qtype {
+			out = append(out, rr)
+		}
+	}
+	return out
+}
+
+// txt returns each TXT record at name with its character strings joined.
+func (r *resolver) txt(ctx context.Context, name string) ([]string, error) {
+	rrs, err := r.lookup(ctx, name, dns.TypeTXT)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(rrs))
+	for _, rr := range rrs {
+		out = append(out, strings.Join(rr.(*dns.TXT).Txt, ""))
+	}
+	return out, nil
+}
@@ -2,14 +2,59 @@
 package filechecker
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/vdparikh/compliance-automation/backend/integrations"
 	"github.com/vdparikh/compliance-automation/backend/integrations/common"
 	"github.com/vdparikh/compliance-automation/backend/models"
 )
 
+const (
+	CheckTypeKey_FileExists     = "file_exists_check"
+	CheckTypeKey_FileAttributes = "file_attributes_check"
+	CheckTypeKey_FileContent    = "file_content_check"
+
+	// maxContentBytes caps how much of a file is read for content assertions.
+	maxContentBytes = 10 << 20
+	// maxReportedMatches caps the matched lines reported per file.
+	maxReportedMatches = 20
+	// maxSymlinks caps the symlinks followed resolving a path beneath a root.
+	maxSymlinks = 40
+)
+
+// fileSystemConfig is the optional ConnectedSystem configuration for file checks.
+// Checks run on the integrations worker; RootPath lets a host filesystem that is
+// mounted into the worker (e.g. at /host) be checked using the host's own paths.
+type fileSystemConfig struct {
+	RootPath string `json:"rootPath"`
+}
+
+// fileResult is the per-file outcome reported in the check output.
+type fileResult struct {
+	Path         string     `json:"path"`
+	Passed       bool       `json:"passed"`
+	IsDir        bool       `json:"is_dir,omitempty"`
+	Mode         string     `json:"mode,omitempty"`
+	Owner        string     `json:"owner,omitempty"`
+	Group        string     `json:"group,omitempty"`
+	SHA256       string     `json:"sha256,omitempty"`
+	ModifiedAt   *time.Time `json:"modified_at,omitempty"`
+	MatchedLines []string   `json:"matched_lines,omitempty"`
+	Failures     []string   `json:"failures,omitempty"`
+}
+
 type Plugin struct{}
 
 // New creates a new instance of the FileChecker plugin.
@@ -22,44 +67,495 @@ func (p *Plugin) ID() string {
 }
 
 func (p *Plugin) Name() string {
-	return "File Existence Check Integration"
+	return "File Check Integration"
+}
+
+func (p *Plugin) Version() string {
+	return "1.0.0"
 }
 
 func (p *Plugin) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	targetHelpText := "Select the Connected System representing the host where the file check will be performed. Its configuration may contain 'rootPath' when the host filesystem is mounted into the worker (e.g. {\"rootPath\": \"/host\"})."
+	filePathParam := models.ParameterDefinition{Name: "file_path", Label: "File Path or Glob", Type: "text", Required: true, Placeholder: "/etc/ssh/sshd_config", HelpText: "Absolute path to the file on the target system. Glob patterns (e.g. /etc/cron.d/*) are checked file by file."}
+
 	return map[string]models.CheckTypeConfiguration{
-		"file_exists_check": {
+		CheckTypeKey_FileExists: {
 			Label: "File Exists Check",
 			Parameters: []models.ParameterDefinition{
-				{Name: "file_path", Label: "File Path", Type: "text", Required: true, Placeholder: "/var/log/app.log", HelpText: "Absolute path to the file on the target system."},
+				filePathParam,
 				{Name: "expected_outcome", Label: "Expected Outcome", Type: "select", Options: []string{"exists", "does_not_exist"}, Required: true, HelpText: "Whether the file is expected to exist or not."},
 			},
 			TargetType:     "connected_system",
 			TargetLabel:    "Target System for File Check",
-			TargetHelpText: "Select the Connected System representing the host where the file check will be performed.",
+			TargetHelpText: targetHelpText,
+		},
+		CheckTypeKey_FileAttributes: {
+			Label: "File Permissions, Ownership and Integrity Check",
+			Parameters: []models.ParameterDefinition{
+				filePathParam,
+				{Name: "expected_permissions", Label: "Expected Permissions (Octal)", Type: "text", Placeholder: "0600", HelpText: "Optional. Expected permission bits in octal notation."},
+				{Name: "permissions_match", Label: "Permissions Match", Type: "select", Options: []string{"exact", "at_most"}, HelpText: "Optional. 'exact' requires identical bits; 'at_most' passes when the file is equally or more restrictive. Defaults to exact."},
+				{Name: "expected_owner", Label: "Expected Owner", Type: "text", Placeholder: "root", HelpText: "Optional. User name or numeric UID that must own the file."},
+				{Name: "expected_group", Label: "Expected Group", Type: "text", Placeholder: "root", HelpText: "Optional. Group name or numeric GID that must own the file."},
+				{Name: "expected_sha256", Label: "Baseline SHA-256", Type: "text", Placeholder: "e3b0c44298fc1c149afbf4c8996fb924...", HelpText: "Optional. Hex-encoded SHA-256 the file content must match."},
+				{Name: "max_age_hours", Label: "Maximum Age (Hours)", Type: "number", Placeholder: "24", HelpText: "Optional. Fails when the file was last modified longer ago than this."},
+			},
+			TargetType:     "connected_system",
+			TargetLabel:    "Target System for File Check",
+			TargetHelpText: targetHelpText,
+		},
+		CheckTypeKey_FileContent: {
+			Label: "File Content Check",
+			Parameters: []models.ParameterDefinition{
+				filePathParam,
+				{Name: "pattern", Label: "Regular Expression", Type: "text", Required: true, Placeholder: `^\s*PermitRootLogin\s+no\b`, HelpText: "Regular expression evaluated against the file content. ^ and $ match at line boundaries."},
+				{Name: "expected_outcome", Label: "Expected Outcome", Type: "select", Options: []string{"matches", "does_not_match"}, Required: true, HelpText: "Whether every file must contain a match or must not contain one."},
+			},
+			TargetType:     "connected_system",
+			TargetLabel:    "Target System for File Check",
+			TargetHelpText: targetHelpText,
 		},
 	}
 }
 
 func (p *Plugin) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
-	// TODO: Implement actual file existence check logic.
-	// This would involve connecting to the target host (ctx.ConnectedSystem)
-	// and checking for ctx.TaskInstance.Parameters["file_path"] based on "expected_outcome".
+	params := map[string]interface{}{}
+	if ctx.TaskInstance != nil && ctx.TaskInstance.Parameters != nil {
+		params = ctx.TaskInstance.Parameters
+	}
+
+	var sysConfig fileSystemConfig
+	if ctx.ConnectedSystem != nil && len(ctx.ConnectedSystem.Configuration) > 0 {
+		if err := json.Unmarshal(ctx.ConnectedSystem.Configuration, &sysConfig); err != nil {
+			return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Invalid ConnectedSystem configuration: %v", err)}, fmt.Errorf("failed to parse file check system configuration: %w", err)
+		}
+	}
 
-	message := fmt.Sprintf("File exists check for type '%s' is not yet fully implemented. Path: %v", checkTypeKey, ctx.TaskInstance.Parameters["file_path"])
+	pattern := common.StringParam(params, "file_path")
+	if pattern == "" {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Missing file_path parameter"}, fmt.Errorf("file_path parameter is required")
+	}
+
+	matches, err := expandPath(sysConfig.RootPath, pattern)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Invalid file_path: %v", err)}, fmt.Errorf("failed to expand file_path %q: %w", pattern, err)
+	}
+
+	var (
+		status  string
+		message string
+		results []fileResult
+	)
+
+	switch checkTypeKey {
+	case CheckTypeKey_FileExists:
+		status, message, results, err = checkExists(sysConfig.RootPath, matches, params)
+	case CheckTypeKey_FileAttributes:
+		status, message, results, err = checkAttributes(sysConfig.RootPath, matches, params)
+	case CheckTypeKey_FileContent:
+		status, message, results, err = checkContent(sysConfig.RootPath, matches, params)
+	default:
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
 
-	// Prepare output as a JSON string
 	outputData := map[string]interface{}{
-		"message": message,
-		"query":   ctx.TaskInstance.Parameters["query"],
+		"message":       message,
+		"file_path":     pattern,
+		"files_checked": len(results),
+		"files_failed":  failed,
+		"results":       results,
 	}
 	outputJSON, err := json.Marshal(outputData)
 	if err != nil {
 		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("{\"error\":\"failed to marshal output: %v\", \"raw_message\":\"%s\"}", err, message)}, fmt.Errorf("failed to marshal output: %w", err)
 	}
 
-	return common.ExecutionResult{
-		Status: common.StatusPending,
-		Output: string(outputJSON),
-	}, fmt.Errorf("file_exists_check not implemented")
+	return common.ExecutionResult{Status: status, Output: string(outputJSON)}, nil
+}
+
+func checkExists(root string, matches []string, params map[string]interface{}) (string, string, []fileResult, error) {
+	expected := common.StringParam(params, "expected_outcome")
+	if expected == "" {
+		expected = "exists"
+	}
+	if expected != "exists" && expected != "does_not_exist" {
+		return "", "", nil, fmt.Errorf("invalid expected_outcome %q", expected)
+	}
+
+	results := make([]fileResult, 0, len(matches))
+	for _, m := range matches {
+		info, err := statInRoot(root, m)
+		r := fileResult{Path: displayPath(root, m), Passed: expected == "exists"}
+		if err != nil {
+			r.Passed = false
+			r.Failures = append(r.Failures, fmt.Sprintf("failed to stat file: %v", err))
+		} else {
+			r.IsDir = info.IsDir()
+			if !r.Passed {
+				r.Failures = append(r.Failures, "file exists but is expected not to")
+			}
+		}
+		results = append(results, r)
+	}
+
+	switch {
+	case expected == "exists" && len(matches) == 0:
+		return common.StatusFailed, "No files matched the path; expected them to exist.", results, nil
+	case expected == "does_not_exist" && len(matches) == 0:
+		return common.StatusSuccess, "No files matched the path, as expected.", results, nil
+	case expected == "does_not_exist":
+		return common.StatusFailed, fmt.Sprintf("%d file(s) exist that are expected not to.", len(matches)), results, nil
+	}
+	return summarize(results, "exist")
+}
+
+func checkAttributes(root string, matches []string, params map[string]interface{}) (string, string, []fileResult, error) {
+	var expectedMode *os.FileMode
+	if s := common.StringParam(params, "expected_permissions"); s != "" {
+		v, err := strconv.ParseUint(s, 8, 32)
+		if err != nil || v > 0o777 {
+			return "", "", nil, fmt.Errorf("invalid expected_permissions %q: must be octal permission bits such as 0600", s)
+		}
+		m := os.FileMode(v)
+		expectedMode = &m
+	}
+	modeMatch := common.StringParam(params, "permissions_match")
+	if modeMatch == "" {
+		modeMatch = "exact"
+	}
+	if modeMatch != "exact" && modeMatch != "at_most" {
+		return "", "", nil, fmt.Errorf("invalid permissions_match %q", modeMatch)
+	}
+	expectedOwner := common.StringParam(params, "expected_owner")
+	expectedGroup := common.StringParam(params, "expected_group")
+	expectedHash := strings.ToLower(common.StringParam(params, "expected_sha256"))
+	maxAge, hasMaxAge, err := common.FloatParam(params, "max_age_hours")
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if expectedMode == nil && expectedOwner == "" && expectedGroup == "" && expectedHash == "" && !hasMaxAge {
+		return "", "", nil, fmt.Errorf("at least one of expected_permissions, expected_owner, expected_group, expected_sha256 or max_age_hours is required")
+	}
+	if len(matches) == 0 {
+		return common.StatusFailed, "No files matched the path.", []fileResult{}, nil
+	}
+
+	now := time.Now()
+	accts := loadAccounts(root)
+	results := make([]fileResult, 0, len(matches))
+	for _, m := range matches {
+		r := fileResult{Path: displayPath(root, m)}
+		target, err := resolveInRoot(root, m)
+		var info os.FileInfo
+		if err == nil {
+			info, err = os.Stat(target)
+		}
+		if err != nil {
+			r.Failures = append(r.Failures, fmt.Sprintf("failed to stat file: %v", err))
+			results = append(results, r)
+			continue
+		}
+		r.IsDir = info.IsDir()
+		r.Mode = fmt.Sprintf("%04o", info.Mode().Perm())
+		modTime := info.ModTime().UTC()
+		r.ModifiedAt = &modTime
+
+		if expectedMode != nil {
+			actual := info.Mode().Perm()
+			if modeMatch == "exact" && actual != *expectedMode {
+				r.Failures = append(r.Failures, fmt.Sprintf("permissions %04o do not equal expected %04o", actual, *expectedMode))
+			}
+			if modeMatch == "at_most" && actual&^*expectedMode != 0 {
+				r.Failures = append(r.Failures, fmt.Sprintf("permissions %04o are less restrictive than %04o", actual, *expectedMode))
+			}
+		}
+
+		if uid, gid, ok := fileOwnership(info); ok {
+			r.Owner = accts.userName(uid)
+			r.Group = accts.groupName(gid)
+			if expectedOwner != "" && expectedOwner != uid && expectedOwner != r.Owner {
+				r.Failures = append(r.Failures, fmt.Sprintf("owner %s does not match expected %s", r.Owner, expectedOwner))
+			}
+			if expectedGroup != "" && expectedGroup != gid && expectedGroup != r.Group {
+				r.Failures = append(r.Failures, fmt.Sprintf("group %s does not match expected %s", r.Group, expectedGroup))
+			}
+		} else if expectedOwner != "" || expectedGroup != "" {
+			r.Failures = append(r.Failures, "file ownership is not available on this platform")
+		}
+
+		if expectedHash != "" {
+			if r.IsDir {
+				r.Failures = append(r.Failures, "cannot compute SHA-256 of a directory")
+			} else if sum, err := sha256File(target); err != nil {
+				r.Failures = append(r.Failures, fmt.Sprintf("failed to hash file: %v", err))
+			} else {
+				r.SHA256 = sum
+				if sum != expectedHash {
+					r.Failures = append(r.Failures, "SHA-256 does not match the baseline")
+				}
+			}
+		}
+
+		if hasMaxAge {
+			ageHours := now.Sub(info.ModTime()).Hours()
+			if ageHours > maxAge {
+				r.Failures = append(r.Failures, fmt.Sprintf("last modified %.1f hours ago, exceeding the maximum of %g hours", ageHours, maxAge))
+			}
+		}
+
+		r.Passed = len(r.Failures) == 0
+		results = append(results, r)
+	}
+	return summarize(results, "meet the expected attributes")
+}
+
+func checkContent(root string, matches []string, params map[string]interface{}) (string, string, []fileResult, error) {
+	expr := common.StringParam(params, "pattern")
+	if expr == "" {
+		return "", "", nil, fmt.Errorf("pattern parameter is required")
+	}
+	re, err := regexp.Compile("(?m)" + expr)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	expected := common.StringParam(params, "expected_outcome")
+	if expected == "" {
+		expected = "matches"
+	}
+	if expected != "matches" && expected != "does_not_match" {
+		return "", "", nil, fmt.Errorf("invalid expected_outcome %q", expected)
+	}
+	if len(matches) == 0 {
+		return common.StatusFailed, "No files matched the path.", []fileResult{}, nil
+	}
+
+	results := make([]fileResult, 0, len(matches))
+	for _, m := range matches {
+		r := fileResult{Path: displayPath(root, m)}
+		target, err := resolveInRoot(root, m)
+		var content []byte
+		if err == nil {
+			content, err = readLimited(target)
+		}
+		if err != nil {
+			r.Failures = append(r.Failures, fmt.Sprintf("failed to read file: %v", err))
+			results = append(results, r)
+			continue
+		}
+		r.MatchedLines = re.FindAllString(string(content), maxReportedMatches)
+		found := len(r.MatchedLines) > 0
+		if expected == "matches" && !found {
+			r.Failures = append(r.Failures, "pattern not found")
+		}
+		if expected == "does_not_match" && found {
+			r.Failures = append(r.Failures, "pattern found but is expected to be absent")
+		}
+		r.Passed = len(r.Failures) == 0
+		results = append(results, r)
+	}
+	return summarize(results, "satisfy the content assertion")
+}
+
+func summarize(results []fileResult, what string) (string, string, []fileResult, error) {
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return common.StatusFailed, fmt.Sprintf("%d of %d file(s) do not %s.", failed, len(results), what), results, nil
+	}
+	return common.StatusSuccess, fmt.Sprintf("All %d file(s) %s.", len(results), what), results, nil
+}
+
+// expandPath resolves the file_path pattern beneath root and expands globs.
+// A pattern without glob metacharacters is returned as-is, even when missing,
+// so that stat errors are reported per file.
+func expandPath(root, pattern string) ([]string, error) {
+	full := resolvePath(root, pattern)
+	if !strings.ContainsAny(pattern, "*?[") {
+		if _, err := os.Lstat(full); err != nil && os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return []string{full}, nil
+	}
+	return filepath.Glob(full)
+}
 
+func resolvePath(root, path string) string {
+	if root == "" {
+		return filepath.Clean(path)
+	}
+	// Cleaning against "/" keeps ".." segments from escaping the root.
+	return filepath.Join(root, filepath.Clean("/"+path))
+}
+
+// resolveInRoot follows the symlinks in path, which lies beneath root, as if
+// root were the filesystem root: absolute link targets are taken relative to
+// root and ".." never climbs above it, so a link on the mounted host cannot
+// lead to a file of the worker. Without a root, path is returned as-is.
+func resolveInRoot(root, path string) (string, error) {
+	if root == "" {
+		return path, nil
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+	pending := strings.Split(filepath.ToSlash(rel), "/")
+	resolved := "/"
+	links := 0
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+		next := filepath.Join(resolved, name)
+		info, err := os.Lstat(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		if links++; links > maxSymlinks {
+			return "", fmt.Errorf("%s: too many levels of symbolic links", displayPath(root, path))
+		}
+		link, err := os.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(link) {
+			resolved = "/"
+		}
+		pending = append(strings.Split(filepath.ToSlash(link), "/"), pending...)
+	}
+	return filepath.Join(root, resolved), nil
+}
+
+// statInRoot stats path with its symlinks resolved within root.
+func statInRoot(root, path string) (os.FileInfo, error) {
+	target, err := resolveInRoot(root, path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(target)
+}
+
+func displayPath(root, path string) string {
+	if root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return "/" + filepath.ToSlash(rel)
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func readLimited(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxContentBytes))
+}
+
+// accounts names the owners and groups of files. With a root, the names come
+// from the passwd and group files beneath it, so a mounted host's files are
+// reported with the host's names rather than the worker's.
+type accounts struct {
+	local         bool
+	users, groups map[string]string
+}
+
+func loadAccounts(root string) accounts {
+	if root == "" {
+		return accounts{local: true}
+	}
+	return accounts{
+		users:  readIDNames(root, "/etc/passwd"),
+		groups: readIDNames(root, "/etc/group"),
+	}
+}
+
+// readIDNames maps the numeric IDs in the third field of a passwd or group
+// file beneath root to the names in the first. A missing file gives no names.
+func readIDNames(root, path string) map[string]string {
+	names := map[string]string{}
+	target, err := resolveInRoot(root, resolvePath(root, path))
+	if err != nil {
+		return names
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		return names
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if _, seen := names[fields[2]]; !seen {
+			names[fields[2]] = fields[0]
+		}
+	}
+	return names
+}
+
+func (a accounts) userName(uid string) string {
+	if a.local {
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username
+		}
+	} else if name, ok := a.users[uid]; ok {
+		return name
+	}
+	return uid
+}
+
+func (a accounts) groupName(gid string) string {
+	if a.local {
+		if g, err := user.LookupGroupId(gid); err == nil {
+			return g.Name
+		}
+	} else if name, ok := a.groups[gid]; ok {
+		return name
+	}
+	return gid
 }
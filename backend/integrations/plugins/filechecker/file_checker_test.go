@@ -0,0 +1,209 @@
+package filechecker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+type checkOutput struct {
+	Message      string       `json:"message"`
+	FilesChecked int          `json:"files_checked"`
+	FilesFailed  int          `json:"files_failed"`
+	Results      []fileResult `json:"results"`
+}
+
+func runCheck(t *testing.T, rootPath, checkType string, params map[string]interface{}) (common.ExecutionResult, checkOutput) {
+	t.Helper()
+	cfg, _ := json.Marshal(fileSystemConfig{RootPath: rootPath})
+	ctx := common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+	}
+	var out checkOutput
+	res := plugintest.Run(t, New(), ctx, checkType, &out)
+	return res, out
+}
+
+func writeFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), mode))
+	require.NoError(t, os.Chmod(path, mode))
+}
+
+func TestFileExistsCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app.log"), "ok", 0o644)
+
+	res, _ := runCheck(t, "", CheckTypeKey_FileExists, map[string]interface{}{"file_path": filepath.Join(dir, "app.log"), "expected_outcome": "exists"})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+
+	res, _ = runCheck(t, "", CheckTypeKey_FileExists, map[string]interface{}{"file_path": filepath.Join(dir, "missing.log"), "expected_outcome": "exists"})
+	assert.Equal(t, common.StatusFailed, res.Status)
+
+	res, _ = runCheck(t, "", CheckTypeKey_FileExists, map[string]interface{}{"file_path": filepath.Join(dir, "*.bak"), "expected_outcome": "does_not_exist"})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+
+	res, out := runCheck(t, "", CheckTypeKey_FileExists, map[string]interface{}{"file_path": filepath.Join(dir, "*.log"), "expected_outcome": "does_not_exist"})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 1, out.FilesFailed)
+}
+
+func TestFileAttributesCheckPermissionsPerFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "cron.d", "a"), "x", 0o600)
+	writeFile(t, filepath.Join(dir, "cron.d", "b"), "x", 0o644)
+
+	res, out := runCheck(t, "", CheckTypeKey_FileAttributes, map[string]interface{}{
+		"file_path":            filepath.Join(dir, "cron.d", "*"),
+		"expected_permissions": "0600",
+	})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	require.Len(t, out.Results, 2)
+	assert.True(t, out.Results[0].Passed)
+	assert.Equal(t, "0600", out.Results[0].Mode)
+	assert.False(t, out.Results[1].Passed)
+	assert.Equal(t, "0644", out.Results[1].Mode)
+
+	res, _ = runCheck(t, "", CheckTypeKey_FileAttributes, map[string]interface{}{
+		"file_path":            filepath.Join(dir, "cron.d", "*"),
+		"expected_permissions": "644",
+		"permissions_match":    "at_most",
+	})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+}
+
+func TestFileAttributesCheckOwnerHashAndAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sshd_config")
+	writeFile(t, path, "PermitRootLogin no\n", 0o600)
+	sum := sha256.Sum256([]byte("PermitRootLogin no\n"))
+
+	res, out := runCheck(t, "", CheckTypeKey_FileAttributes, map[string]interface{}{
+		"file_path":       path,
+		"expected_owner":  strconv.Itoa(os.Getuid()),
+		"expected_group":  strconv.Itoa(os.Getgid()),
+		"expected_sha256": hex.EncodeToString(sum[:]),
+		"max_age_hours":   float64(1),
+	})
+	assert.Equal(t, common.StatusSuccess, res.Status, out.Message)
+	require.Len(t, out.Results, 1)
+	assert.Equal(t, hex.EncodeToString(sum[:]), out.Results[0].SHA256)
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+	res, out = runCheck(t, "", CheckTypeKey_FileAttributes, map[string]interface{}{
+		"file_path":       path,
+		"expected_sha256": "0000",
+		"max_age_hours":   "24",
+	})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	require.Len(t, out.Results, 1)
+	assert.Len(t, out.Results[0].Failures, 2)
+}
+
+func TestFileAttributesCheckRequiresAnAssertion(t *testing.T) {
+	ctx := common.CheckContext{TaskInstance: &models.CampaignTaskInstance{Parameters: map[string]interface{}{"file_path": "/etc/passwd"}}}
+	res, err := New().ExecuteCheck(ctx, CheckTypeKey_FileAttributes)
+	assert.Error(t, err)
+	assert.Equal(t, common.StatusError, res.Status)
+}
+
+func TestFileContentCheck(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "etc", "ssh", "sshd_config"), "Port 22\nPermitRootLogin no\nPasswordAuthentication yes\n", 0o600)
+
+	res, out := runCheck(t, root, CheckTypeKey_FileContent, map[string]interface{}{
+		"file_path":        "/etc/ssh/sshd_config",
+		"pattern":          `^\s*PermitRootLogin\s+no\b`,
+		"expected_outcome": "matches",
+	})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	require.Len(t, out.Results, 1)
+	assert.Equal(t, "/etc/ssh/sshd_config", out.Results[0].Path)
+	assert.Equal(t, []string{"PermitRootLogin no"}, out.Results[0].MatchedLines)
+
+	res, _ = runCheck(t, root, CheckTypeKey_FileContent, map[string]interface{}{
+		"file_path":        "/etc/ssh/sshd_config",
+		"pattern":          `^\s*PasswordAuthentication\s+yes`,
+		"expected_outcome": "does_not_match",
+	})
+	assert.Equal(t, common.StatusFailed, res.Status)
+}
+
+func TestResolvePathStaysWithinRoot(t *testing.T) {
+	assert.Equal(t, filepath.Join("/host", "etc", "passwd"), resolvePath("/host", "../../etc/passwd"))
+	assert.Equal(t, "/etc/passwd", displayPath("/host", filepath.Join("/host", "etc", "passwd")))
+}
+
+func TestSymlinksResolveWithinRoot(t *testing.T) {
+	root := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret")
+	writeFile(t, outside, "worker secret\n", 0o600)
+	writeFile(t, filepath.Join(root, "etc", "real.conf"), "host config\n", 0o600)
+	require.NoError(t, os.Symlink("/etc/real.conf", filepath.Join(root, "etc", "absolute.conf")))
+	require.NoError(t, os.Symlink("../../../../etc/real.conf", filepath.Join(root, "etc", "relative.conf")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "etc", "escape.conf")))
+
+	for _, name := range []string{"absolute.conf", "relative.conf"} {
+		res, out := runCheck(t, root, CheckTypeKey_FileContent, map[string]interface{}{
+			"file_path": "/etc/" + name,
+			"pattern":   "host config",
+		})
+		assert.Equal(t, common.StatusSuccess, res.Status, name)
+		require.Len(t, out.Results, 1)
+		assert.Equal(t, "/etc/"+name, out.Results[0].Path)
+	}
+
+	res, out := runCheck(t, root, CheckTypeKey_FileContent, map[string]interface{}{
+		"file_path": "/etc/escape.conf",
+		"pattern":   "worker secret",
+	})
+	assert.Equal(t, common.StatusFailed, res.Status, "the link target is looked up beneath the root")
+	require.Len(t, out.Results, 1)
+	assert.Empty(t, out.Results[0].MatchedLines)
+
+	res, _ = runCheck(t, root, CheckTypeKey_FileAttributes, map[string]interface{}{
+		"file_path":            "/etc/escape.conf",
+		"expected_permissions": "0600",
+	})
+	assert.Equal(t, common.StatusFailed, res.Status)
+}
+
+func TestOwnerNamesComeFromRoot(t *testing.T) {
+	root := t.TempDir()
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+	writeFile(t, filepath.Join(root, "etc", "passwd"), "hostuser:x:"+uid+":"+gid+"::/home/hostuser:/bin/sh\n", 0o644)
+	writeFile(t, filepath.Join(root, "etc", "group"), "hostgroup:x:"+gid+":\n", 0o644)
+	writeFile(t, filepath.Join(root, "etc", "app.conf"), "x", 0o600)
+
+	res, out := runCheck(t, root, CheckTypeKey_FileAttributes, map[string]interface{}{
+		"file_path":      "/etc/app.conf",
+		"expected_owner": "hostuser",
+		"expected_group": "hostgroup",
+	})
+	assert.Equal(t, common.StatusSuccess, res.Status, out.Message)
+	require.Len(t, out.Results, 1)
+	assert.Equal(t, "hostuser", out.Results[0].Owner)
+	assert.Equal(t, "hostgroup", out.Results[0].Group)
+
+	require.NoError(t, os.Remove(filepath.Join(root, "etc", "passwd")))
+	_, out = runCheck(t, root, CheckTypeKey_FileAttributes, map[string]interface{}{
+		"file_path":      "/etc/app.conf",
+		"expected_owner": "hostuser",
+	})
+	require.Len(t, out.Results, 1)
+	assert.Equal(t, uid, out.Results[0].Owner, "IDs without a name on the host stay numeric")
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package filechecker
+
+import "os"
+
+// fileOwnership is not supported outside Unix-like systems.
+func fileOwnership(info os.FileInfo) (string, string, bool) {
+	return "", "", false
+}
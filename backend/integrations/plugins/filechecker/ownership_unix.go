@@ -0,0 +1,18 @@
+//go:build unix
+
+package filechecker
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// fileOwnership returns the numeric owner and group IDs of a file.
+func fileOwnership(info os.FileInfo) (string, string, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", false
+	}
+	return strconv.FormatUint(uint64(st.Uid), 10), strconv.FormatUint(uint64(st.Gid), 10), true
+}
@@ -26,6 +26,10 @@ func (p *GCPBucketChecker) Name() string {
 	return "GCP Storage Bucket Encryption Checker"
 }
 
+func (p *GCPBucketChecker) Version() string {
+	return "1.0.0"
+}
+
 func (p *GCPBucketChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		"gcp_bucket_encryption": {
@@ -0,0 +1,432 @@
+package githubchecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	PluginID_GitHubChecker                 = "github_checker_v1"
+	PluginName_GitHubChecker               = "GitHub Organization Controls Checker"
+	PluginVersion_GitHubChecker            = "1.0.0"
+	CheckTypeKey_GitHubBranchProtection    = "github_branch_protection"
+	CheckTypeKey_GitHubOrg2FA              = "github_org_2fa"
+	CheckTypeKey_GitHubOutsideCollabAdmins = "github_outside_collaborator_admins"
+	CheckTypeKey_GitHubSecurityFeatures    = "github_security_features"
+	CheckTypeKey_GitHubStaleDeployKeys     = "github_stale_deploy_keys"
+
+	defaultTimeout = 5 * time.Minute
+)
+
+// GitHubSystemConfig matches the 'github' system type configuration.
+type GitHubSystemConfig struct {
+	PersonalAccessToken string `json:"personalAccessToken"`
+	Organization        string `json:"organization"`
+	BaseURL             string `json:"baseUrl"`
+}
+
+type GitHubChecker struct{}
+
+func New() *GitHubChecker {
+	return &GitHubChecker{}
+}
+
+func (p *GitHubChecker) ID() string {
+	return PluginID_GitHubChecker
+}
+
+func (p *GitHubChecker) Name() string {
+	return PluginName_GitHubChecker
+}
+
+func (p *GitHubChecker) Version() string {
+	return PluginVersion_GitHubChecker
+}
+
+func (p *GitHubChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	repositoriesParam := models.ParameterDefinition{
+		Name:        "repositories",
+		Label:       "Repositories (Optional)",
+		Type:        "text",
+		Required:    false,
+		Placeholder: "api, web, other-org/tooling",
+		HelpText:    "Comma-separated repository names. Defaults to every non-archived repository in the organization.",
+	}
+
+	return map[string]models.CheckTypeConfiguration{
+		CheckTypeKey_GitHubBranchProtection: {
+			Label:       "GitHub Default Branch Protection",
+			TargetType:  "connected_system",
+			TargetLabel: "GitHub Organization",
+			Parameters: []models.ParameterDefinition{
+				repositoriesParam,
+				{Name: "minApprovingReviews", Label: "Minimum Approving Reviews", Type: "number", Placeholder: "1", HelpText: "Required approving review count on pull requests. Defaults to 1."},
+				{Name: "requireStatusChecks", Label: "Require Status Checks", Type: "select", Options: []string{"true", "false"}, HelpText: "Whether at least one required status check must be configured. Defaults to true."},
+			},
+		},
+		CheckTypeKey_GitHubOrg2FA: {
+			Label:       "GitHub Organization 2FA Enforcement",
+			TargetType:  "connected_system",
+			TargetLabel: "GitHub Organization",
+			Parameters:  []models.ParameterDefinition{},
+		},
+		CheckTypeKey_GitHubOutsideCollabAdmins: {
+			Label:       "GitHub Outside Collaborators with Admin Rights",
+			TargetType:  "connected_system",
+			TargetLabel: "GitHub Organization",
+			Parameters:  []models.ParameterDefinition{repositoriesParam},
+		},
+		CheckTypeKey_GitHubSecurityFeatures: {
+			Label:       "GitHub Secret Scanning and Dependabot",
+			TargetType:  "connected_system",
+			TargetLabel: "GitHub Organization",
+			Parameters: []models.ParameterDefinition{
+				repositoriesParam,
+				{Name: "requirePushProtection", Label: "Require Push Protection", Type: "select", Options: []string{"false", "true"}, HelpText: "Also require secret scanning push protection. Defaults to false."},
+			},
+		},
+		CheckTypeKey_GitHubStaleDeployKeys: {
+			Label:       "GitHub Stale Deploy Keys",
+			TargetType:  "connected_system",
+			TargetLabel: "GitHub Organization",
+			Parameters: []models.ParameterDefinition{
+				repositoriesParam,
+				{Name: "maxUnusedDays", Label: "Maximum Unused Days", Type: "number", Placeholder: "90", HelpText: "Deploy keys not used (or, if never used, created) within this many days are stale. Defaults to 90."},
+			},
+		},
+	}
+}
+
+func (p *GitHubChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
+	var sysConfig GitHubSystemConfig
+	if err := json.Unmarshal(ctx.ConnectedSystem.Configuration, &sysConfig); err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Failed to parse GitHub system configuration"}, fmt.Errorf("unmarshal github config: %w", err)
+	}
+	if sysConfig.PersonalAccessToken == "" {
+		return common.ExecutionResult{Status: common.StatusError, Output: "GitHub personalAccessToken is required"}, fmt.Errorf("missing github token")
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx.Context(), defaultTimeout)
+	defer cancel()
+
+	params := ctx.TaskInstance.Parameters
+	gh := newGitHubClient(sysConfig.BaseURL, sysConfig.PersonalAccessToken)
+
+	var (
+		findings []common.Finding
+		err      error
+	)
+	switch checkTypeKey {
+	case CheckTypeKey_GitHubOrg2FA:
+		if sysConfig.Organization == "" {
+			return common.ExecutionResult{Status: common.StatusError, Output: "GitHub organization is required"}, fmt.Errorf("missing github organization")
+		}
+		findings, err = p.checkOrg2FA(runCtx, gh, sysConfig.Organization)
+	case CheckTypeKey_GitHubBranchProtection, CheckTypeKey_GitHubOutsideCollabAdmins, CheckTypeKey_GitHubSecurityFeatures, CheckTypeKey_GitHubStaleDeployKeys:
+		var repos []ghRepository
+		repos, err = p.resolveRepositories(runCtx, gh, sysConfig.Organization, params)
+		if err != nil {
+			break
+		}
+		findings, err = p.checkRepositories(runCtx, gh, checkTypeKey, repos, params)
+	default:
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if !f.Passed {
+			failed++
+		}
+	}
+	status := common.StatusSuccess
+	message := fmt.Sprintf("All %d checked resource(s) passed.", len(findings))
+	if failed > 0 {
+		status = common.StatusFailed
+		message = fmt.Sprintf("%d of %d checked resource(s) failed.", failed, len(findings))
+	}
+
+	result := map[string]interface{}{
+		"message":           message,
+		"organization":      sysConfig.Organization,
+		"resources_checked": len(findings),
+		"resources_failed":  failed,
+		"findings":          findings,
+	}
+	jsonOut, _ := json.Marshal(result)
+	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
+}
+
+// resolveRepositories returns the repositories named in the 'repositories'
+// parameter, or every non-archived repository in the organization.
+func (p *GitHubChecker) resolveRepositories(ctx context.Context, gh *githubClient, org string, params map[string]interface{}) ([]ghRepository, error) {
+	names, _ := params["repositories"].(string)
+	var repos []ghRepository
+	if strings.TrimSpace(names) != "" {
+		for _, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !strings.Contains(name, "/") {
+				if org == "" {
+					return nil, fmt.Errorf("repository %q must be given as owner/name when no organization is configured", name)
+				}
+				name = org + "/" + name
+			}
+			var repo ghRepository
+			if _, err := gh.get(ctx, "/repos/"+escapeFullName(name), &repo); err != nil {
+				return nil, fmt.Errorf("get repository %s: %w", name, err)
+			}
+			repos = append(repos, repo)
+		}
+		return repos, nil
+	}
+
+	if org == "" {
+		return nil, fmt.Errorf("either an organization or the repositories parameter is required")
+	}
+	all, err := listAll[ghRepository](ctx, gh, "/orgs/"+url.PathEscape(org)+"/repos?type=all&per_page=100")
+	if err != nil {
+		return nil, fmt.Errorf("list repositories for %s: %w", org, err)
+	}
+	for _, repo := range all {
+		if !repo.Archived {
+			repos = append(repos, repo)
+		}
+	}
+	return repos, nil
+}
+
+func (p *GitHubChecker) checkRepositories(ctx context.Context, gh *githubClient, checkTypeKey string, repos []ghRepository, params map[string]interface{}) ([]common.Finding, error) {
+	findings := make([]common.Finding, 0, len(repos))
+	for _, repo := range repos {
+		var (
+			f   common.Finding
+			err error
+		)
+		switch checkTypeKey {
+		case CheckTypeKey_GitHubBranchProtection:
+			f, err = p.checkBranchProtection(ctx, gh, repo, params)
+		case CheckTypeKey_GitHubOutsideCollabAdmins:
+			f, err = p.checkOutsideCollaborators(ctx, gh, repo)
+		case CheckTypeKey_GitHubSecurityFeatures:
+			f, err = p.checkSecurityFeatures(ctx, gh, repo, params)
+		case CheckTypeKey_GitHubStaleDeployKeys:
+			f, err = p.checkDeployKeys(ctx, gh, repo, params)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// A single inaccessible repository is reported, not fatal.
+			f = common.Finding{ResourceType: "repository", Resource: repo.FullName, Issues: []string{err.Error()}}
+		}
+		f.Passed = len(f.Issues) == 0
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+func (p *GitHubChecker) checkBranchProtection(ctx context.Context, gh *githubClient, repo ghRepository, params map[string]interface{}) (common.Finding, error) {
+	minReviews := common.IntParam(params, "minApprovingReviews", 1)
+	requireStatusChecks := common.BoolParam(params, "requireStatusChecks", true)
+
+	f := common.Finding{
+		ResourceType: "repository",
+		Resource:     repo.FullName,
+		Details:      map[string]interface{}{"default_branch": repo.DefaultBranch},
+	}
+
+	var protection ghBranchProtection
+	_, err := gh.get(ctx, "/repos/"+escapeFullName(repo.FullName)+"/branches/"+url.PathEscape(repo.DefaultBranch)+"/protection", &protection)
+	if isNotFound(err) {
+		f.Issues = append(f.Issues, fmt.Sprintf("default branch %s is not protected", repo.DefaultBranch))
+		f.Details["protected"] = false
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	f.Details["protected"] = true
+
+	reviews := 0
+	if protection.RequiredPullRequestReviews != nil {
+		reviews = protection.RequiredPullRequestReviews.RequiredApprovingReviewCount
+	}
+	f.Details["required_approving_reviews"] = reviews
+	if reviews < minReviews {
+		f.Issues = append(f.Issues, fmt.Sprintf("requires %d approving review(s), expected at least %d", reviews, minReviews))
+	}
+
+	checks := 0
+	if protection.RequiredStatusChecks != nil {
+		checks = len(protection.RequiredStatusChecks.Contexts)
+		if len(protection.RequiredStatusChecks.Checks) > checks {
+			checks = len(protection.RequiredStatusChecks.Checks)
+		}
+	}
+	f.Details["required_status_checks"] = checks
+	if requireStatusChecks && checks == 0 {
+		f.Issues = append(f.Issues, "no required status checks configured")
+	}
+
+	forcePush := protection.AllowForcePushes != nil && protection.AllowForcePushes.Enabled
+	f.Details["allow_force_pushes"] = forcePush
+	if forcePush {
+		f.Issues = append(f.Issues, "force pushes are allowed")
+	}
+	return f, nil
+}
+
+func (p *GitHubChecker) checkOrg2FA(ctx context.Context, gh *githubClient, org string) ([]common.Finding, error) {
+	var organization ghOrganization
+	if _, err := gh.get(ctx, "/orgs/"+url.PathEscape(org), &organization); err != nil {
+		return nil, fmt.Errorf("get organization %s: %w", org, err)
+	}
+
+	orgFinding := common.Finding{ResourceType: "organization", Resource: org, Details: map[string]interface{}{}}
+	if organization.TwoFactorRequirementEnabled == nil {
+		orgFinding.Issues = append(orgFinding.Issues, "2FA requirement is not visible; the token needs organization owner access")
+	} else {
+		orgFinding.Details["two_factor_requirement_enabled"] = *organization.TwoFactorRequirementEnabled
+		if !*organization.TwoFactorRequirementEnabled {
+			orgFinding.Issues = append(orgFinding.Issues, "organization does not require two-factor authentication")
+		}
+	}
+	orgFinding.Passed = len(orgFinding.Issues) == 0
+	findings := []common.Finding{orgFinding}
+
+	members, err := listAll[ghUser](ctx, gh, "/orgs/"+url.PathEscape(org)+"/members?filter=2fa_disabled&per_page=100")
+	if err != nil {
+		return nil, fmt.Errorf("list members without 2FA for %s: %w", org, err)
+	}
+	for _, m := range members {
+		findings = append(findings, common.Finding{
+			ResourceType: "member",
+			Resource:     m.Login,
+			Issues:       []string{"two-factor authentication is not enabled"},
+		})
+	}
+	return findings, nil
+}
+
+func (p *GitHubChecker) checkOutsideCollaborators(ctx context.Context, gh *githubClient, repo ghRepository) (common.Finding, error) {
+	f := common.Finding{ResourceType: "repository", Resource: repo.FullName}
+	collaborators, err := listAll[ghUser](ctx, gh, "/repos/"+escapeFullName(repo.FullName)+"/collaborators?affiliation=outside&per_page=100")
+	if err != nil {
+		return f, err
+	}
+
+	var admins []string
+	for _, c := range collaborators {
+		if c.RoleName == "admin" || (c.Permissions != nil && c.Permissions.Admin) {
+			admins = append(admins, c.Login)
+		}
+	}
+	f.Details = map[string]interface{}{
+		"outside_collaborators": len(collaborators),
+		"outside_admins":        admins,
+	}
+	for _, login := range admins {
+		f.Issues = append(f.Issues, fmt.Sprintf("outside collaborator %s has admin rights", login))
+	}
+	return f, nil
+}
+
+func (p *GitHubChecker) checkSecurityFeatures(ctx context.Context, gh *githubClient, repo ghRepository, params map[string]interface{}) (common.Finding, error) {
+	requirePushProtection := common.BoolParam(params, "requirePushProtection", false)
+	f := common.Finding{ResourceType: "repository", Resource: repo.FullName, Details: map[string]interface{}{}}
+
+	sa := repo.SecurityAndAnalysis
+	if sa == nil {
+		// List endpoints omit security_and_analysis; fetch the full repository.
+		var full ghRepository
+		if _, err := gh.get(ctx, "/repos/"+escapeFullName(repo.FullName), &full); err != nil {
+			return f, err
+		}
+		sa = full.SecurityAndAnalysis
+	}
+	if sa == nil {
+		sa = &ghSecurityAndAnalysis{}
+	}
+
+	f.Details["secret_scanning"] = sa.SecretScanning.enabled()
+	f.Details["secret_scanning_push_protection"] = sa.SecretScanningPushProtection.enabled()
+	f.Details["dependabot_security_updates"] = sa.DependabotSecurityUpdates.enabled()
+	if !sa.SecretScanning.enabled() {
+		f.Issues = append(f.Issues, "secret scanning is not enabled")
+	}
+	if requirePushProtection && !sa.SecretScanningPushProtection.enabled() {
+		f.Issues = append(f.Issues, "secret scanning push protection is not enabled")
+	}
+
+	// 204 means Dependabot alerts are enabled, 404 that they are disabled.
+	_, err := gh.get(ctx, "/repos/"+escapeFullName(repo.FullName)+"/vulnerability-alerts", nil)
+	switch {
+	case err == nil:
+		f.Details["dependabot_alerts"] = true
+	case isNotFound(err):
+		f.Details["dependabot_alerts"] = false
+		f.Issues = append(f.Issues, "Dependabot alerts are not enabled")
+	default:
+		return f, err
+	}
+	return f, nil
+}
+
+func (p *GitHubChecker) checkDeployKeys(ctx context.Context, gh *githubClient, repo ghRepository, params map[string]interface{}) (common.Finding, error) {
+	maxUnusedDays := common.IntParam(params, "maxUnusedDays", 90)
+	cutoff := time.Now().AddDate(0, 0, -maxUnusedDays)
+	f := common.Finding{ResourceType: "repository", Resource: repo.FullName}
+
+	keys, err := listAll[ghDeployKey](ctx, gh, "/repos/"+escapeFullName(repo.FullName)+"/keys?per_page=100")
+	if err != nil {
+		return f, err
+	}
+
+	var stale []map[string]interface{}
+	for _, k := range keys {
+		lastActivity := k.CreatedAt
+		if k.LastUsed != nil {
+			lastActivity = *k.LastUsed
+		}
+		if lastActivity.Before(cutoff) {
+			stale = append(stale, map[string]interface{}{
+				"id":         k.ID,
+				"title":      k.Title,
+				"read_only":  k.ReadOnly,
+				"created_at": k.CreatedAt,
+				"last_used":  k.LastUsed,
+			})
+			f.Issues = append(f.Issues, fmt.Sprintf("deploy key %q has not been used in over %d days", k.Title, maxUnusedDays))
+		}
+	}
+	f.Details = map[string]interface{}{
+		"deploy_keys": len(keys),
+		"stale_keys":  stale,
+	}
+	return f, nil
+}
+
+// escapeFullName escapes each segment of an owner/name repository path.
+func escapeFullName(fullName string) string {
+	parts := strings.SplitN(fullName, "/", 2)
+	for i := range parts {
+		parts[i] = url.PathEscape(parts[i])
+	}
+	return strings.Join(parts, "/")
+}
+
+var _ integrations.IntegrationPlugin = (*GitHubChecker)(nil)
@@ -0,0 +1,180 @@
+package githubchecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+// newStubServer serves a small GitHub organization "acme" with two repositories:
+// "api" is well configured and "web" is not.
+func newStubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	authed := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer test-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				writeJSON(w, map[string]string{"message": "Bad credentials"})
+				return
+			}
+			h(w, r)
+		}
+	}
+
+	var srvURL string
+	mux.HandleFunc("/orgs/acme/repos", authed(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(w, []map[string]interface{}{
+				{"name": "web", "full_name": "acme/web", "default_branch": "master"},
+				{"name": "legacy", "full_name": "acme/legacy", "default_branch": "main", "archived": true},
+			})
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/acme/repos?page=2>; rel="next"`, srvURL))
+		writeJSON(w, []map[string]interface{}{
+			{"name": "api", "full_name": "acme/api", "default_branch": "main"},
+		})
+	}))
+	mux.HandleFunc("/repos/acme/api/branches/main/protection", authed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"required_status_checks":        map[string]interface{}{"contexts": []string{"ci/build"}},
+			"required_pull_request_reviews": map[string]interface{}{"required_approving_review_count": 2},
+			"allow_force_pushes":            map[string]interface{}{"enabled": false},
+		})
+	}))
+	mux.HandleFunc("/repos/acme/web/branches/master/protection", authed(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, map[string]string{"message": "Branch not protected"})
+	}))
+	mux.HandleFunc("/orgs/acme", authed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"login": "acme", "two_factor_requirement_enabled": false})
+	}))
+	mux.HandleFunc("/orgs/acme/members", authed(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2fa_disabled", r.URL.Query().Get("filter"))
+		writeJSON(w, []map[string]interface{}{{"login": "mallory"}})
+	}))
+	mux.HandleFunc("/repos/acme/api/collaborators", authed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]interface{}{{"login": "contractor", "role_name": "write", "permissions": map[string]bool{"admin": false}}})
+	}))
+	mux.HandleFunc("/repos/acme/web/collaborators", authed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]interface{}{{"login": "vendor", "role_name": "admin", "permissions": map[string]bool{"admin": true}}})
+	}))
+	mux.HandleFunc("/repos/acme/api", authed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"name": "api", "full_name": "acme/api", "default_branch": "main",
+			"security_and_analysis": map[string]interface{}{
+				"secret_scanning":                 map[string]string{"status": "enabled"},
+				"secret_scanning_push_protection": map[string]string{"status": "enabled"},
+			},
+		})
+	}))
+	mux.HandleFunc("/repos/acme/web", authed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"name": "web", "full_name": "acme/web", "default_branch": "master",
+			"security_and_analysis": map[string]interface{}{"secret_scanning": map[string]string{"status": "disabled"}},
+		})
+	}))
+	mux.HandleFunc("/repos/acme/api/vulnerability-alerts", authed(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc("/repos/acme/web/vulnerability-alerts", authed(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	mux.HandleFunc("/repos/acme/api/keys", authed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]interface{}{
+			{"id": 1, "title": "ci", "read_only": true, "created_at": time.Now().AddDate(-1, 0, 0), "last_used": time.Now().AddDate(0, 0, -3)},
+		})
+	}))
+	mux.HandleFunc("/repos/acme/web/keys", authed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]interface{}{
+			{"id": 2, "title": "old-deploy", "read_only": false, "created_at": time.Now().AddDate(-2, 0, 0), "last_used": nil},
+		})
+	}))
+
+	srv := httptest.NewServer(mux)
+	srvURL = srv.URL
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func runCheck(t *testing.T, srv *httptest.Server, checkType string, params map[string]interface{}) (common.ExecutionResult, plugintest.FindingsOutput) {
+	t.Helper()
+	cfg, _ := json.Marshal(GitHubSystemConfig{PersonalAccessToken: "test-token", Organization: "acme", BaseURL: srv.URL})
+	var out plugintest.FindingsOutput
+	res := plugintest.Run(t, New(), common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+		StdContext:      context.Background(),
+	}, checkType, &out)
+	return res, out
+}
+
+func TestBranchProtectionPerRepository(t *testing.T) {
+	srv := newStubServer(t)
+	res, out := runCheck(t, srv, CheckTypeKey_GitHubBranchProtection, map[string]interface{}{})
+
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 2, out.ResourcesChecked, "archived repositories are skipped and pagination is followed")
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "acme/api").Passed)
+	web := plugintest.FindingFor(t, out.Findings, "acme/web")
+	assert.False(t, web.Passed)
+	assert.Contains(t, web.Issues, "default branch master is not protected")
+
+	res, out = runCheck(t, srv, CheckTypeKey_GitHubBranchProtection, map[string]interface{}{"repositories": "api", "minApprovingReviews": float64(3)})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Len(t, plugintest.FindingFor(t, out.Findings, "acme/api").Issues, 1)
+}
+
+func TestOrg2FA(t *testing.T) {
+	res, out := runCheck(t, newStubServer(t), CheckTypeKey_GitHubOrg2FA, map[string]interface{}{})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.False(t, plugintest.FindingFor(t, out.Findings, "acme").Passed)
+	assert.Equal(t, "member", plugintest.FindingFor(t, out.Findings, "mallory").ResourceType)
+}
+
+func TestOutsideCollaboratorAdmins(t *testing.T) {
+	res, out := runCheck(t, newStubServer(t), CheckTypeKey_GitHubOutsideCollabAdmins, map[string]interface{}{"repositories": "api,web"})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "acme/api").Passed)
+	assert.Equal(t, []string{"outside collaborator vendor has admin rights"}, plugintest.FindingFor(t, out.Findings, "acme/web").Issues)
+}
+
+func TestSecurityFeatures(t *testing.T) {
+	res, out := runCheck(t, newStubServer(t), CheckTypeKey_GitHubSecurityFeatures, map[string]interface{}{"requirePushProtection": "true"})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "acme/api").Passed)
+	assert.Len(t, plugintest.FindingFor(t, out.Findings, "acme/web").Issues, 3)
+}
+
+func TestStaleDeployKeys(t *testing.T) {
+	res, out := runCheck(t, newStubServer(t), CheckTypeKey_GitHubStaleDeployKeys, map[string]interface{}{"maxUnusedDays": "30"})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "acme/api").Passed)
+	assert.False(t, plugintest.FindingFor(t, out.Findings, "acme/web").Passed)
+}
+
+func TestBadCredentialsFailTheCheck(t *testing.T) {
+	srv := newStubServer(t)
+	cfg, _ := json.Marshal(GitHubSystemConfig{PersonalAccessToken: "wrong", Organization: "acme", BaseURL: srv.URL})
+	res, err := New().ExecuteCheck(common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: map[string]interface{}{}},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+	}, CheckTypeKey_GitHubBranchProtection)
+	assert.Error(t, err)
+	assert.Equal(t, common.StatusError, res.Status)
+	assert.Contains(t, res.Output, "Bad credentials")
+}
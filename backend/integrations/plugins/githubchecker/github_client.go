@@ -0,0 +1,188 @@
+package githubchecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+)
+
+const defaultAPIBaseURL = "https://api.github.com"
+
+// linkNextPattern extracts the rel="next" URL from a GitHub Link header.
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// apiError is returned for non-2xx responses from the GitHub API.
+type apiError struct {
+	StatusCode int
+	Path       string
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("github API %s returned %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*apiError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// githubClient is a minimal GitHub REST API client. BaseURL points at
+// api.github.com, a GitHub Enterprise Server /api/v3 endpoint or a test stub.
+type githubClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func newGitHubClient(baseURL, token string) *githubClient {
+	if baseURL == "" {
+		baseURL = defaultAPIBaseURL
+	}
+	return &githubClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// get fetches path (relative to the base URL, or an absolute URL from a Link
+// header) into out and returns the next page URL, if any.
+func (c *githubClient) get(ctx context.Context, path string, out interface{}) (string, error) {
+	url := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		url = c.baseURL + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("create github request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "compliance-automation/1.0")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", common.Unreachable(fmt.Errorf("github request %s: %w", path, err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read github response %s: %w", path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(body, &msg)
+		if msg.Message == "" {
+			msg.Message = resp.Status
+		}
+		return "", &apiError{StatusCode: resp.StatusCode, Path: path, Message: msg.Message}
+	}
+
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return "", fmt.Errorf("parse github response %s: %w", path, err)
+		}
+	}
+
+	var next string
+	if m := linkNextPattern.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+		next = m[1]
+	}
+	return next, nil
+}
+
+// listAll follows Link header pagination and returns every item of a list endpoint.
+func listAll[T any](ctx context.Context, c *githubClient, path string) ([]T, error) {
+	var all []T
+	for path != "" {
+		var page []T
+		next, err := c.get(ctx, path, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		path = next
+	}
+	return all, nil
+}
+
+type ghRepository struct {
+	Name                string                 `json:"name"`
+	FullName            string                 `json:"full_name"`
+	DefaultBranch       string                 `json:"default_branch"`
+	Archived            bool                   `json:"archived"`
+	Private             bool                   `json:"private"`
+	SecurityAndAnalysis *ghSecurityAndAnalysis `json:"security_and_analysis"`
+}
+
+type ghSecurityAndAnalysis struct {
+	SecretScanning               *ghFeatureStatus `json:"secret_scanning"`
+	SecretScanningPushProtection *ghFeatureStatus `json:"secret_scanning_push_protection"`
+	DependabotSecurityUpdates    *ghFeatureStatus `json:"dependabot_security_updates"`
+}
+
+type ghFeatureStatus struct {
+	Status string `json:"status"`
+}
+
+func (s *ghFeatureStatus) enabled() bool {
+	return s != nil && s.Status == "enabled"
+}
+
+type ghBranchProtection struct {
+	RequiredStatusChecks *struct {
+		Strict   bool     `json:"strict"`
+		Contexts []string `json:"contexts"`
+		Checks   []struct {
+			Context string `json:"context"`
+		} `json:"checks"`
+	} `json:"required_status_checks"`
+	RequiredPullRequestReviews *struct {
+		RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+		DismissStaleReviews          bool `json:"dismiss_stale_reviews"`
+		RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews"`
+	} `json:"required_pull_request_reviews"`
+	EnforceAdmins    *ghEnabled `json:"enforce_admins"`
+	AllowForcePushes *ghEnabled `json:"allow_force_pushes"`
+	AllowDeletions   *ghEnabled `json:"allow_deletions"`
+}
+
+type ghEnabled struct {
+	Enabled bool `json:"enabled"`
+}
+
+type ghOrganization struct {
+	Login                       string `json:"login"`
+	TwoFactorRequirementEnabled *bool  `json:"two_factor_requirement_enabled"`
+}
+
+type ghUser struct {
+	Login       string `json:"login"`
+	RoleName    string `json:"role_name"`
+	Permissions *struct {
+		Admin bool `json:"admin"`
+	} `json:"permissions"`
+}
+
+type ghDeployKey struct {
+	ID        int64      `json:"id"`
+	Title     string     `json:"title"`
+	ReadOnly  bool       `json:"read_only"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastUsed  *time.Time `json:"last_used"`
+}
@@ -27,6 +27,10 @@ func (p *Plugin) Name() string {
 	return "HTTP Health Check Integration"
 }
 
+func (p *Plugin) Version() string {
+	return "1.0.0"
+}
+
 func (p *Plugin) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		"http_get_check": { // This key must match what the frontend expects
@@ -80,7 +84,7 @@ func (p *Plugin) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (com
 
 	resp, err := http.Get(targetURL)
 	if err != nil {
-		return common.ExecutionResult{Status: common.StatusFailed, Output: fmt.Sprintf("Error making GET request to %s: %v", targetURL, err)}, err
+		return common.ExecutionResult{Status: common.StatusFailed, Output: fmt.Sprintf("Error making GET request to %s: %v", targetURL, err)}, common.Unreachable(err)
 	}
 	defer resp.Body.Close()
 
@@ -0,0 +1,508 @@
+package kuberneteschecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	PluginID_KubernetesChecker                 = "kubernetes_checker_v1"
+	PluginName_KubernetesChecker               = "Kubernetes Cluster Posture Checker"
+	PluginVersion_KubernetesChecker            = "1.0.0"
+	CheckTypeKey_K8sPrivilegedPods             = "k8s_privileged_pods"
+	CheckTypeKey_K8sRootContainers             = "k8s_root_containers"
+	CheckTypeKey_K8sMissingResourceLimits      = "k8s_missing_resource_limits"
+	CheckTypeKey_K8sHostPathMounts             = "k8s_hostpath_mounts"
+	CheckTypeKey_K8sNamespacesWithoutNetPolicy = "k8s_namespaces_without_network_policy"
+	CheckTypeKey_K8sClusterAdminBindings       = "k8s_cluster_admin_bindings"
+	CheckTypeKey_K8sWildcardRBAC               = "k8s_wildcard_rbac"
+
+	defaultTimeout = 2 * time.Minute
+	podPageSize    = 500
+)
+
+// KubernetesSystemConfig matches the 'kubernetes' system type configuration.
+// A kubeconfig, when given, takes precedence over the individual fields.
+type KubernetesSystemConfig struct {
+	APIServerURL          string `json:"apiServerUrl"`
+	BearerToken           string `json:"bearerToken"`
+	CACert                string `json:"caCert"`
+	Kubeconfig            string `json:"kubeconfig"`
+	InsecureSkipTLSVerify string `json:"insecureSkipTlsVerify"`
+}
+
+// clientFactory builds a Kubernetes client from the system configuration. It is
+// a field on KubernetesChecker so tests can substitute a fake clientset.
+type clientFactory func(cfg KubernetesSystemConfig) (kubernetes.Interface, error)
+
+type KubernetesChecker struct {
+	newClient clientFactory
+}
+
+func New() *KubernetesChecker {
+	return &KubernetesChecker{newClient: newClientset}
+}
+
+func (p *KubernetesChecker) ID() string {
+	return PluginID_KubernetesChecker
+}
+
+func (p *KubernetesChecker) Name() string {
+	return PluginName_KubernetesChecker
+}
+
+func (p *KubernetesChecker) Version() string {
+	return PluginVersion_KubernetesChecker
+}
+
+func (p *KubernetesChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	namespaceParams := []models.ParameterDefinition{
+		{Name: "namespaces", Label: "Namespaces (Optional)", Type: "text", Placeholder: "payments, web", HelpText: "Comma-separated namespaces to check. Defaults to all namespaces."},
+		{Name: "excludeNamespaces", Label: "Excluded Namespaces (Optional)", Type: "text", Placeholder: "kube-system, kube-public", HelpText: "Comma-separated namespaces to skip."},
+	}
+	withNamespaces := func(extra ...models.ParameterDefinition) []models.ParameterDefinition {
+		return append(append([]models.ParameterDefinition{}, namespaceParams...), extra...)
+	}
+	config := func(label string, params []models.ParameterDefinition) models.CheckTypeConfiguration {
+		return models.CheckTypeConfiguration{
+			Label:          label,
+			Parameters:     params,
+			TargetType:     "connected_system",
+			TargetLabel:    "Kubernetes Cluster",
+			TargetHelpText: "Select the Connected System of type Kubernetes representing the cluster to audit.",
+		}
+	}
+
+	return map[string]models.CheckTypeConfiguration{
+		CheckTypeKey_K8sPrivilegedPods: config("Kubernetes Privileged Pods", withNamespaces()),
+		CheckTypeKey_K8sRootContainers: config("Kubernetes Containers Running as Root", withNamespaces()),
+		CheckTypeKey_K8sMissingResourceLimits: config("Kubernetes Containers without Resource Limits", withNamespaces(
+			models.ParameterDefinition{Name: "requiredLimits", Label: "Required Limits", Type: "text", Placeholder: "cpu, memory", HelpText: "Comma-separated resource names every container must set a limit for. Defaults to cpu and memory."},
+		)),
+		CheckTypeKey_K8sHostPathMounts:             config("Kubernetes hostPath Volume Mounts", withNamespaces()),
+		CheckTypeKey_K8sNamespacesWithoutNetPolicy: config("Kubernetes Namespaces without NetworkPolicies", withNamespaces()),
+		CheckTypeKey_K8sClusterAdminBindings: config("Kubernetes cluster-admin ClusterRoleBindings", []models.ParameterDefinition{
+			{Name: "allowedSubjects", Label: "Allowed Subjects (Optional)", Type: "text", Placeholder: "system:masters, Group:platform-admins", HelpText: "Comma-separated subject names (optionally Kind:name) that may hold cluster-admin."},
+		}),
+		CheckTypeKey_K8sWildcardRBAC: config("Kubernetes RBAC Subjects with Wildcard Verbs", []models.ParameterDefinition{
+			{Name: "includeSystemRoles", Label: "Include system: Roles", Type: "select", Options: []string{"false", "true"}, HelpText: "Also report built-in roles and bindings whose names start with 'system:'. Defaults to false."},
+		}),
+	}
+}
+
+func (p *KubernetesChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
+	var sysConfig KubernetesSystemConfig
+	if err := json.Unmarshal(ctx.ConnectedSystem.Configuration, &sysConfig); err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Failed to parse Kubernetes system configuration"}, fmt.Errorf("unmarshal kubernetes config: %w", err)
+	}
+
+	clientset, err := p.newClient(sysConfig)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Failed to create Kubernetes client: %v", err)}, fmt.Errorf("create kubernetes client: %w", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx.Context(), defaultTimeout)
+	defer cancel()
+
+	params := ctx.TaskInstance.Parameters
+	scope := newNamespaceScope(params)
+
+	var findings []common.Finding
+	switch checkTypeKey {
+	case CheckTypeKey_K8sPrivilegedPods:
+		findings, err = checkPods(runCtx, clientset, scope, privilegedIssues)
+	case CheckTypeKey_K8sRootContainers:
+		findings, err = checkPods(runCtx, clientset, scope, rootIssues)
+	case CheckTypeKey_K8sMissingResourceLimits:
+		required := common.SplitList(common.StringParam(params, "requiredLimits"))
+		if len(required) == 0 {
+			required = []string{"cpu", "memory"}
+		}
+		findings, err = checkPods(runCtx, clientset, scope, func(pod *corev1.Pod) []string { return missingLimitIssues(pod, required) })
+	case CheckTypeKey_K8sHostPathMounts:
+		findings, err = checkPods(runCtx, clientset, scope, hostPathIssues)
+	case CheckTypeKey_K8sNamespacesWithoutNetPolicy:
+		findings, err = checkNetworkPolicies(runCtx, clientset, scope)
+	case CheckTypeKey_K8sClusterAdminBindings:
+		findings, err = checkClusterAdminBindings(runCtx, clientset, common.SplitList(common.StringParam(params, "allowedSubjects")))
+	case CheckTypeKey_K8sWildcardRBAC:
+		includeSystem, _ := strconv.ParseBool(common.StringParam(params, "includeSystemRoles"))
+		findings, err = checkWildcardRBAC(runCtx, clientset, includeSystem)
+	default:
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if !f.Passed {
+			failed++
+		}
+	}
+	status := common.StatusSuccess
+	message := fmt.Sprintf("All %d checked resource(s) passed.", len(findings))
+	if failed > 0 {
+		status = common.StatusFailed
+		message = fmt.Sprintf("%d of %d checked resource(s) failed.", failed, len(findings))
+	}
+
+	result := map[string]interface{}{
+		"message":           message,
+		"apiServer":         sysConfig.APIServerURL,
+		"resources_checked": len(findings),
+		"resources_failed":  failed,
+		"findings":          findings,
+	}
+	jsonOut, _ := json.Marshal(result)
+	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
+}
+
+// newClientset builds a clientset from a kubeconfig or from the API server
+// URL, bearer token and CA certificate.
+func newClientset(cfg KubernetesSystemConfig) (kubernetes.Interface, error) {
+	var (
+		restConfig *rest.Config
+		err        error
+	)
+	if strings.TrimSpace(cfg.Kubeconfig) != "" {
+		restConfig, err = clientcmd.RESTConfigFromKubeConfig([]byte(cfg.Kubeconfig))
+		if err != nil {
+			return nil, fmt.Errorf("parse kubeconfig: %w", err)
+		}
+	} else {
+		if cfg.APIServerURL == "" {
+			return nil, fmt.Errorf("apiServerUrl or kubeconfig is required")
+		}
+		insecure, _ := strconv.ParseBool(cfg.InsecureSkipTLSVerify)
+		restConfig = &rest.Config{
+			Host:        cfg.APIServerURL,
+			BearerToken: cfg.BearerToken,
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData:   []byte(cfg.CACert),
+				Insecure: insecure,
+			},
+		}
+	}
+	restConfig.Timeout = 30 * time.Second
+	restConfig.UserAgent = "compliance-automation/1.0"
+	restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper { return unreachableRoundTripper{rt} })
+	return kubernetes.NewForConfig(restConfig)
+}
+
+// unreachableRoundTripper marks transport errors talking to the API server.
+type unreachableRoundTripper struct{ next http.RoundTripper }
+
+func (t unreachableRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	return resp, common.Unreachable(err)
+}
+
+// namespaceScope applies the namespaces/excludeNamespaces parameters.
+type namespaceScope struct {
+	include map[string]bool
+	exclude map[string]bool
+}
+
+func newNamespaceScope(params map[string]interface{}) namespaceScope {
+	s := namespaceScope{include: map[string]bool{}, exclude: map[string]bool{}}
+	for _, ns := range common.SplitList(common.StringParam(params, "namespaces")) {
+		s.include[ns] = true
+	}
+	for _, ns := range common.SplitList(common.StringParam(params, "excludeNamespaces")) {
+		s.exclude[ns] = true
+	}
+	return s
+}
+
+func (s namespaceScope) allows(namespace string) bool {
+	if s.exclude[namespace] {
+		return false
+	}
+	return len(s.include) == 0 || s.include[namespace]
+}
+
+// checkPods lists running pods in scope and reports one finding per pod.
+func checkPods(ctx context.Context, clientset kubernetes.Interface, scope namespaceScope, issuesFor func(*corev1.Pod) []string) ([]common.Finding, error) {
+	findings := []common.Finding{}
+	opts := metav1.ListOptions{Limit: podPageSize}
+	for {
+		pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list pods: %w", err)
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if !scope.allows(pod.Namespace) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			issues := issuesFor(pod)
+			findings = append(findings, common.Finding{
+				ResourceType: "pod",
+				Resource:     pod.Namespace + "/" + pod.Name,
+				Passed:       len(issues) == 0,
+				Issues:       issues,
+			})
+		}
+		if pods.Continue == "" {
+			return findings, nil
+		}
+		opts.Continue = pods.Continue
+	}
+}
+
+func allContainers(pod *corev1.Pod) []corev1.Container {
+	return append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+}
+
+func privilegedIssues(pod *corev1.Pod) []string {
+	var issues []string
+	for _, c := range allContainers(pod) {
+		if c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
+			issues = append(issues, fmt.Sprintf("container %s is privileged", c.Name))
+		}
+	}
+	if pod.Spec.HostPID {
+		issues = append(issues, "pod shares the host PID namespace")
+	}
+	if pod.Spec.HostIPC {
+		issues = append(issues, "pod shares the host IPC namespace")
+	}
+	if pod.Spec.HostNetwork {
+		issues = append(issues, "pod uses the host network")
+	}
+	return issues
+}
+
+// rootIssues flags containers that run as UID 0 or do not enforce a non-root
+// user; without runAsNonRoot the image's USER decides, which may be root.
+func rootIssues(pod *corev1.Pod) []string {
+	var issues []string
+	podSC := pod.Spec.SecurityContext
+	for _, c := range allContainers(pod) {
+		var runAsUser *int64
+		var runAsNonRoot *bool
+		if podSC != nil {
+			runAsUser, runAsNonRoot = podSC.RunAsUser, podSC.RunAsNonRoot
+		}
+		if c.SecurityContext != nil {
+			if c.SecurityContext.RunAsUser != nil {
+				runAsUser = c.SecurityContext.RunAsUser
+			}
+			if c.SecurityContext.RunAsNonRoot != nil {
+				runAsNonRoot = c.SecurityContext.RunAsNonRoot
+			}
+		}
+		switch {
+		case runAsUser != nil && *runAsUser == 0:
+			issues = append(issues, fmt.Sprintf("container %s runs as UID 0", c.Name))
+		case runAsUser == nil && (runAsNonRoot == nil || !*runAsNonRoot):
+			issues = append(issues, fmt.Sprintf("container %s does not set runAsNonRoot or a non-root runAsUser", c.Name))
+		}
+	}
+	return issues
+}
+
+func missingLimitIssues(pod *corev1.Pod, required []string) []string {
+	var issues []string
+	for _, c := range pod.Spec.Containers {
+		var missing []string
+		for _, name := range required {
+			if _, ok := c.Resources.Limits[corev1.ResourceName(name)]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			issues = append(issues, fmt.Sprintf("container %s has no %s limit", c.Name, strings.Join(missing, "/")))
+		}
+	}
+	return issues
+}
+
+func hostPathIssues(pod *corev1.Pod) []string {
+	var issues []string
+	for _, v := range pod.Spec.Volumes {
+		if v.HostPath != nil {
+			issues = append(issues, fmt.Sprintf("volume %s mounts host path %s", v.Name, v.HostPath.Path))
+		}
+	}
+	return issues
+}
+
+func checkNetworkPolicies(ctx context.Context, clientset kubernetes.Interface, scope namespaceScope) ([]common.Finding, error) {
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+	policies, err := clientset.NetworkingV1().NetworkPolicies(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list network policies: %w", err)
+	}
+	counts := map[string]int{}
+	for _, np := range policies.Items {
+		counts[np.Namespace]++
+	}
+
+	findings := []common.Finding{}
+	for _, ns := range namespaces.Items {
+		if !scope.allows(ns.Name) {
+			continue
+		}
+		f := common.Finding{
+			ResourceType: "namespace",
+			Resource:     ns.Name,
+			Details:      map[string]interface{}{"network_policies": counts[ns.Name]},
+		}
+		if counts[ns.Name] == 0 {
+			f.Issues = []string{"namespace has no NetworkPolicy"}
+		}
+		f.Passed = len(f.Issues) == 0
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+func checkClusterAdminBindings(ctx context.Context, clientset kubernetes.Interface, allowed []string) ([]common.Finding, error) {
+	bindings, err := clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list cluster role bindings: %w", err)
+	}
+	allowedSet := map[string]bool{}
+	for _, a := range allowed {
+		allowedSet[a] = true
+	}
+
+	findings := []common.Finding{}
+	for _, b := range bindings.Items {
+		if b.RoleRef.Kind != "ClusterRole" || b.RoleRef.Name != "cluster-admin" {
+			continue
+		}
+		f := common.Finding{ResourceType: "clusterrolebinding", Resource: b.Name, Details: map[string]interface{}{"subjects": subjectNames(b.Subjects)}}
+		for _, s := range b.Subjects {
+			if allowedSet[s.Name] || allowedSet[s.Kind+":"+s.Name] {
+				continue
+			}
+			f.Issues = append(f.Issues, fmt.Sprintf("%s %s is bound to cluster-admin", s.Kind, subjectName(s)))
+		}
+		f.Passed = len(f.Issues) == 0
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+// checkWildcardRBAC reports, per binding, the subjects granted a role with a
+// wildcard verb.
+func checkWildcardRBAC(ctx context.Context, clientset kubernetes.Interface, includeSystem bool) ([]common.Finding, error) {
+	rbac := clientset.RbacV1()
+	clusterRoles, err := rbac.ClusterRoles().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list cluster roles: %w", err)
+	}
+	roles, err := rbac.Roles(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list roles: %w", err)
+	}
+	clusterRoleBindings, err := rbac.ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list cluster role bindings: %w", err)
+	}
+	roleBindings, err := rbac.RoleBindings(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list role bindings: %w", err)
+	}
+
+	wildcardClusterRoles := map[string]bool{}
+	for _, r := range clusterRoles.Items {
+		if hasWildcardVerb(r.Rules) {
+			wildcardClusterRoles[r.Name] = true
+		}
+	}
+	wildcardRoles := map[string]bool{}
+	for _, r := range roles.Items {
+		if hasWildcardVerb(r.Rules) {
+			wildcardRoles[r.Namespace+"/"+r.Name] = true
+		}
+	}
+
+	skip := func(bindingName, roleName string) bool {
+		return !includeSystem && (strings.HasPrefix(bindingName, "system:") || strings.HasPrefix(roleName, "system:"))
+	}
+
+	findings := []common.Finding{}
+	for _, b := range clusterRoleBindings.Items {
+		if skip(b.Name, b.RoleRef.Name) || !wildcardClusterRoles[b.RoleRef.Name] {
+			continue
+		}
+		findings = append(findings, wildcardFinding("clusterrolebinding", b.Name, b.RoleRef, b.Subjects))
+	}
+	for _, b := range roleBindings.Items {
+		if skip(b.Name, b.RoleRef.Name) {
+			continue
+		}
+		wildcard := (b.RoleRef.Kind == "ClusterRole" && wildcardClusterRoles[b.RoleRef.Name]) ||
+			(b.RoleRef.Kind == "Role" && wildcardRoles[b.Namespace+"/"+b.RoleRef.Name])
+		if !wildcard {
+			continue
+		}
+		findings = append(findings, wildcardFinding("rolebinding", b.Namespace+"/"+b.Name, b.RoleRef, b.Subjects))
+	}
+	return findings, nil
+}
+
+func wildcardFinding(resourceType, name string, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) common.Finding {
+	f := common.Finding{
+		ResourceType: resourceType,
+		Resource:     name,
+		Details:      map[string]interface{}{"role": roleRef.Kind + "/" + roleRef.Name, "subjects": subjectNames(subjects)},
+	}
+	for _, s := range subjects {
+		f.Issues = append(f.Issues, fmt.Sprintf("%s %s has wildcard verbs via %s %s", s.Kind, subjectName(s), roleRef.Kind, roleRef.Name))
+	}
+	f.Passed = len(f.Issues) == 0
+	return f
+}
+
+func hasWildcardVerb(rules []rbacv1.PolicyRule) bool {
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			if verb == rbacv1.VerbAll {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func subjectName(s rbacv1.Subject) string {
+	if s.Kind == rbacv1.ServiceAccountKind && s.Namespace != "" {
+		return s.Namespace + "/" + s.Name
+	}
+	return s.Name
+}
+
+func subjectNames(subjects []rbacv1.Subject) []string {
+	names := make([]string, 0, len(subjects))
+	for _, s := range subjects {
+		names = append(names, s.Kind+":"+subjectName(s))
+	}
+	return names
+}
+
+var _ integrations.IntegrationPlugin = (*KubernetesChecker)(nil)
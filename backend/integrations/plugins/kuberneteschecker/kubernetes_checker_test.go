@@ -0,0 +1,157 @@
+package kuberneteschecker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+func boolPtr(b bool) *bool    { return &b }
+func int64Ptr(i int64) *int64 { return &i }
+
+// newFakeCluster returns a cluster with a hardened "payments" namespace and a
+// permissive "legacy" namespace.
+func newFakeCluster() kubernetes.Interface {
+	limits := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("500m"),
+		corev1.ResourceMemory: resource.MustParse("256Mi"),
+	}
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "legacy"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "payments"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payments"},
+			Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: boolPtr(true)},
+				Containers:      []corev1.Container{{Name: "api", Resources: corev1.ResourceRequirements{Limits: limits}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "legacy"},
+			Spec: corev1.PodSpec{
+				HostNetwork: true,
+				Containers: []corev1.Container{{
+					Name:            "agent",
+					SecurityContext: &corev1.SecurityContext{Privileged: boolPtr(true), RunAsUser: int64Ptr(0)},
+				}},
+				Volumes: []corev1.Volume{{Name: "docker", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"}}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrate-done", Namespace: "legacy"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "migrate"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "view"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"get", "list"}, Resources: []string{"pods"}}}},
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "ops", Namespace: "legacy"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"*"}, Resources: []string{"secrets"}}}},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:masters"}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci-admin"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "ci"}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewers"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "auditors"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "ops-team", Namespace: "legacy"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "ops"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "alice"}},
+		},
+	}
+	return fake.NewSimpleClientset(objects...)
+}
+
+func runCheck(t *testing.T, checkType string, params map[string]interface{}) (common.ExecutionResult, plugintest.FindingsOutput) {
+	t.Helper()
+	clientset := newFakeCluster()
+	checker := &KubernetesChecker{newClient: func(KubernetesSystemConfig) (kubernetes.Interface, error) { return clientset, nil }}
+	cfg, _ := json.Marshal(KubernetesSystemConfig{APIServerURL: "https://k8s.example.com"})
+	var out plugintest.FindingsOutput
+	res := plugintest.Run(t, checker, common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+		StdContext:      context.Background(),
+	}, checkType, &out)
+	return res, out
+}
+
+func TestPodChecks(t *testing.T) {
+	res, out := runCheck(t, CheckTypeKey_K8sPrivilegedPods, map[string]interface{}{})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 2, out.ResourcesChecked, "completed pods are skipped")
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "payments/api").Passed)
+	assert.ElementsMatch(t, []string{"container agent is privileged", "pod uses the host network"}, plugintest.FindingFor(t, out.Findings, "legacy/agent").Issues)
+
+	_, out = runCheck(t, CheckTypeKey_K8sRootContainers, map[string]interface{}{})
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "payments/api").Passed)
+	assert.Equal(t, []string{"container agent runs as UID 0"}, plugintest.FindingFor(t, out.Findings, "legacy/agent").Issues)
+
+	_, out = runCheck(t, CheckTypeKey_K8sMissingResourceLimits, map[string]interface{}{})
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "payments/api").Passed)
+	assert.Equal(t, []string{"container agent has no cpu/memory limit"}, plugintest.FindingFor(t, out.Findings, "legacy/agent").Issues)
+
+	res, out = runCheck(t, CheckTypeKey_K8sHostPathMounts, map[string]interface{}{"excludeNamespaces": "legacy"})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	assert.Equal(t, 1, out.ResourcesChecked)
+}
+
+func TestNamespacesWithoutNetworkPolicy(t *testing.T) {
+	res, out := runCheck(t, CheckTypeKey_K8sNamespacesWithoutNetPolicy, map[string]interface{}{"namespaces": "payments, legacy"})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 2, out.ResourcesChecked)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "payments").Passed)
+	assert.False(t, plugintest.FindingFor(t, out.Findings, "legacy").Passed)
+}
+
+func TestClusterAdminBindings(t *testing.T) {
+	res, out := runCheck(t, CheckTypeKey_K8sClusterAdminBindings, map[string]interface{}{"allowedSubjects": "Group:system:masters"})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 2, out.ResourcesChecked)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "cluster-admin").Passed)
+	assert.Equal(t, []string{"ServiceAccount ci/deployer is bound to cluster-admin"}, plugintest.FindingFor(t, out.Findings, "ci-admin").Issues)
+}
+
+func TestWildcardRBAC(t *testing.T) {
+	res, out := runCheck(t, CheckTypeKey_K8sWildcardRBAC, map[string]interface{}{})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 3, out.ResourcesChecked, "read-only bindings are not reported")
+	assert.False(t, plugintest.FindingFor(t, out.Findings, "legacy/ops-team").Passed)
+	assert.False(t, plugintest.FindingFor(t, out.Findings, "ci-admin").Passed)
+}
+
+func TestNewClientsetRequiresEndpoint(t *testing.T) {
+	_, err := newClientset(KubernetesSystemConfig{})
+	assert.Error(t, err)
+
+	_, err = newClientset(KubernetesSystemConfig{Kubeconfig: "not: [valid"})
+	assert.Error(t, err)
+
+	_, err = newClientset(KubernetesSystemConfig{APIServerURL: "https://k8s.example.com:6443", BearerToken: "token"})
+	assert.NoError(t, err)
+}
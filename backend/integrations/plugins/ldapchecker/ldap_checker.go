@@ -0,0 +1,311 @@
+package ldapchecker
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	PluginID_LDAPChecker                   = "ldap_checker_v1"
+	PluginName_LDAPChecker                 = "LDAP / Active Directory Access Review"
+	PluginVersion_LDAPChecker              = "1.0.0"
+	CheckTypeKey_LDAPDisabledPrivileged    = "ldap_disabled_privileged_accounts"
+	CheckTypeKey_LDAPInactiveAccounts      = "ldap_inactive_accounts"
+	CheckTypeKey_LDAPAdminGroupMembership  = "ldap_admin_group_membership"
+	CheckTypeKey_LDAPPasswordNeverExpires  = "ldap_password_never_expires"
+	defaultMaxInactiveDays                 = 90
+	defaultActiveDirectoryPrivilegedGroups = "Domain Admins, Enterprise Admins, Schema Admins, Administrators"
+)
+
+// LDAPSystemConfig matches the 'ldap' system type configuration.
+type LDAPSystemConfig struct {
+	URL                string `json:"url"`
+	BindDN             string `json:"bindDn"`
+	BindPassword       string `json:"bindPassword"`
+	BaseDN             string `json:"baseDn"`
+	DirectoryType      string `json:"directoryType"`
+	StartTLS           string `json:"startTls"`
+	CACert             string `json:"caCert"`
+	InsecureSkipVerify string `json:"insecureSkipVerify"`
+	UserFilter         string `json:"userFilter"`
+}
+
+type LDAPChecker struct{}
+
+func New() *LDAPChecker {
+	return &LDAPChecker{}
+}
+
+func (p *LDAPChecker) ID() string {
+	return PluginID_LDAPChecker
+}
+
+func (p *LDAPChecker) Name() string {
+	return PluginName_LDAPChecker
+}
+
+func (p *LDAPChecker) Version() string {
+	return PluginVersion_LDAPChecker
+}
+
+func (p *LDAPChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	excludeParam := models.ParameterDefinition{Name: "excludeAccounts", Label: "Excluded Accounts (Optional)", Type: "text", Placeholder: "svc-backup, krbtgt", HelpText: "Comma-separated account names with an approved exception."}
+	config := func(label string, params ...models.ParameterDefinition) models.CheckTypeConfiguration {
+		return models.CheckTypeConfiguration{
+			Label:          label,
+			Parameters:     params,
+			TargetType:     "connected_system",
+			TargetLabel:    "Directory",
+			TargetHelpText: "Select the Connected System of type LDAP / Active Directory to review.",
+		}
+	}
+
+	return map[string]models.CheckTypeConfiguration{
+		CheckTypeKey_LDAPDisabledPrivileged: config("Disabled Accounts in Privileged Groups",
+			models.ParameterDefinition{Name: "privilegedGroups", Label: "Privileged Groups", Type: "text", Placeholder: defaultActiveDirectoryPrivilegedGroups, HelpText: "Comma-separated group names or DNs. Nested groups are expanded. Defaults to the built-in Active Directory admin groups."},
+		),
+		CheckTypeKey_LDAPInactiveAccounts: config("Accounts without Recent Login",
+			models.ParameterDefinition{Name: "maxInactiveDays", Label: "Max Days Since Last Login", Type: "number", Placeholder: strconv.Itoa(defaultMaxInactiveDays), HelpText: "Enabled accounts whose last login is older than this fail. Active Directory's lastLogonTimestamp can lag by up to 14 days."},
+			models.ParameterDefinition{Name: "includeDisabled", Label: "Include Disabled Accounts", Type: "select", Options: []string{"false", "true"}, HelpText: "Also evaluate disabled accounts. Defaults to false."},
+			excludeParam,
+		),
+		CheckTypeKey_LDAPAdminGroupMembership: config("Admin Group Membership Snapshot",
+			models.ParameterDefinition{Name: "adminGroups", Label: "Admin Groups", Type: "text", Required: true, Placeholder: "Domain Admins, cn=ops,ou=groups,dc=example,dc=com", HelpText: "Comma-separated group names or DNs. The full membership is exported as evidence."},
+			models.ParameterDefinition{Name: "allowedMembers", Label: "Approved Members (Optional)", Type: "textarea", Placeholder: "alice, bob", HelpText: "Comma-separated account names approved for membership. Any other member fails the check."},
+			models.ParameterDefinition{Name: "maxMembers", Label: "Max Members (Optional)", Type: "number", HelpText: "Fail a group with more members than this."},
+		),
+		CheckTypeKey_LDAPPasswordNeverExpires: config("Accounts with Non-Expiring Passwords",
+			models.ParameterDefinition{Name: "includeDisabled", Label: "Include Disabled Accounts", Type: "select", Options: []string{"false", "true"}, HelpText: "Also evaluate disabled accounts. Defaults to false."},
+			excludeParam,
+		),
+	}
+}
+
+func (p *LDAPChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
+	var sysConfig LDAPSystemConfig
+	if err := json.Unmarshal(ctx.ConnectedSystem.Configuration, &sysConfig); err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Failed to parse LDAP system configuration"}, fmt.Errorf("unmarshal ldap config: %w", err)
+	}
+
+	var run func(*directory, map[string]interface{}) ([]common.Finding, error)
+	switch checkTypeKey {
+	case CheckTypeKey_LDAPDisabledPrivileged:
+		run = checkDisabledPrivileged
+	case CheckTypeKey_LDAPInactiveAccounts:
+		run = checkInactiveAccounts
+	case CheckTypeKey_LDAPAdminGroupMembership:
+		run = checkAdminGroupMembership
+	case CheckTypeKey_LDAPPasswordNeverExpires:
+		run = checkPasswordNeverExpires
+	default:
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+
+	dir, err := connect(ctx.Context(), sysConfig)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Failed to connect to directory: %v", err)}, fmt.Errorf("ldap connect: %w", err)
+	}
+	defer dir.close()
+
+	findings, err := run(dir, ctx.TaskInstance.Parameters)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if !f.Passed {
+			failed++
+		}
+	}
+	status := common.StatusSuccess
+	message := fmt.Sprintf("All %d checked resource(s) passed.", len(findings))
+	if failed > 0 {
+		status = common.StatusFailed
+		message = fmt.Sprintf("%d of %d checked resource(s) failed.", failed, len(findings))
+	}
+
+	result := map[string]interface{}{
+		"message":           message,
+		"baseDn":            sysConfig.BaseDN,
+		"collected_at":      time.Now().UTC().Format(time.RFC3339),
+		"resources_checked": len(findings),
+		"resources_failed":  failed,
+		"findings":          findings,
+	}
+	jsonOut, _ := json.Marshal(result)
+	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
+}
+
+func checkDisabledPrivileged(dir *directory, params map[string]interface{}) ([]common.Finding, error) {
+	groups := common.SplitList(common.StringParam(params, "privilegedGroups"))
+	builtIn := len(groups) == 0
+	if builtIn {
+		if !dir.activeDirectory() {
+			return nil, fmt.Errorf("privilegedGroups is required for OpenLDAP directories")
+		}
+		groups = common.SplitList(defaultActiveDirectoryPrivilegedGroups)
+	}
+
+	// An account in several privileged groups is reported once.
+	byDN := map[string]*common.Finding{}
+	var order []string
+	// Configured groups that do not exist fail the check, since their members
+	// cannot be reviewed. Built-in groups such as Enterprise Admins only exist
+	// in the forest root domain, so they fail only if none of them exists.
+	var missing []common.Finding
+	for _, name := range groups {
+		group, err := dir.group(name)
+		if err != nil {
+			return nil, err
+		}
+		if group == nil {
+			missing = append(missing, common.Finding{ResourceType: "group", Resource: name, Issues: []string{"group not found"}})
+			continue
+		}
+		members, err := dir.members(group)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			if m.entry == nil {
+				continue
+			}
+			key := strings.ToLower(m.DN)
+			f, ok := byDN[key]
+			if !ok {
+				f = &common.Finding{ResourceType: "account", Resource: m.Account, Passed: true, Details: map[string]interface{}{"dn": m.DN, "disabled": m.Disabled}}
+				byDN[key] = f
+				order = append(order, key)
+			}
+			if m.Disabled {
+				f.Passed = false
+				f.Issues = append(f.Issues, fmt.Sprintf("disabled account is still a member of %s", group.DN))
+			}
+		}
+	}
+
+	findings := make([]common.Finding, 0, len(order)+len(missing))
+	for _, key := range order {
+		findings = append(findings, *byDN[key])
+	}
+	if !builtIn || len(missing) == len(groups) {
+		findings = append(findings, missing...)
+	}
+	return findings, nil
+}
+
+func checkInactiveAccounts(dir *directory, params map[string]interface{}) ([]common.Finding, error) {
+	maxDays := common.IntParam(params, "maxInactiveDays", defaultMaxInactiveDays)
+	includeDisabled, _ := strconv.ParseBool(common.StringParam(params, "includeDisabled"))
+	excluded := setOf(common.SplitList(common.StringParam(params, "excludeAccounts")))
+	cutoff := time.Now().AddDate(0, 0, -maxDays)
+
+	users, err := dir.users()
+	if err != nil {
+		return nil, err
+	}
+	findings := []common.Finding{}
+	for _, u := range users {
+		name := accountName(u)
+		if excluded[strings.ToLower(name)] || (!includeDisabled && isDisabled(u)) {
+			continue
+		}
+		f := common.Finding{ResourceType: "account", Resource: name, Details: map[string]interface{}{"dn": u.DN}}
+		if last, ok := lastLogon(u); ok {
+			f.Details["last_login"] = last.Format(time.RFC3339)
+			if last.Before(cutoff) {
+				f.Issues = append(f.Issues, fmt.Sprintf("no login for %d days", int(time.Since(last).Hours()/24)))
+			}
+		} else if created, ok := createdAt(u); !ok || created.Before(cutoff) {
+			// Accounts created within the window get the same grace period as a login.
+			f.Issues = append(f.Issues, "account has never logged in")
+		}
+		f.Passed = len(f.Issues) == 0
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+func checkAdminGroupMembership(dir *directory, params map[string]interface{}) ([]common.Finding, error) {
+	groups := common.SplitList(common.StringParam(params, "adminGroups"))
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("adminGroups parameter is required")
+	}
+	allowed := setOf(common.SplitList(common.StringParam(params, "allowedMembers")))
+	maxMembers := common.IntParam(params, "maxMembers", 0)
+
+	findings := []common.Finding{}
+	for _, name := range groups {
+		group, err := dir.group(name)
+		if err != nil {
+			return nil, err
+		}
+		if group == nil {
+			findings = append(findings, common.Finding{ResourceType: "group", Resource: name, Issues: []string{"group not found"}})
+			continue
+		}
+		members, err := dir.members(group)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].Account < members[j].Account })
+
+		f := common.Finding{ResourceType: "group", Resource: group.DN, Details: map[string]interface{}{"member_count": len(members), "members": members}}
+		if maxMembers > 0 && len(members) > maxMembers {
+			f.Issues = append(f.Issues, fmt.Sprintf("%d members exceeds the maximum of %d", len(members), maxMembers))
+		}
+		if len(allowed) > 0 {
+			for _, m := range members {
+				if !allowed[strings.ToLower(m.Account)] {
+					f.Issues = append(f.Issues, fmt.Sprintf("%s is not an approved member", m.Account))
+				}
+			}
+		}
+		f.Passed = len(f.Issues) == 0
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+func checkPasswordNeverExpires(dir *directory, params map[string]interface{}) ([]common.Finding, error) {
+	includeDisabled, _ := strconv.ParseBool(common.StringParam(params, "includeDisabled"))
+	excluded := setOf(common.SplitList(common.StringParam(params, "excludeAccounts")))
+
+	users, err := dir.users()
+	if err != nil {
+		return nil, err
+	}
+	findings := []common.Finding{}
+	for _, u := range users {
+		name := accountName(u)
+		if excluded[strings.ToLower(name)] || (!includeDisabled && isDisabled(u)) {
+			continue
+		}
+		f := common.Finding{ResourceType: "account", Resource: name, Passed: true, Details: map[string]interface{}{"dn": u.DN}}
+		if passwordNeverExpires(u) {
+			f.Passed = false
+			f.Issues = []string{"password is set to never expire"}
+		}
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+func setOf(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[strings.ToLower(v)] = true
+	}
+	return set
+}
+
+var _ integrations.IntegrationPlugin = (*LDAPChecker)(nil)
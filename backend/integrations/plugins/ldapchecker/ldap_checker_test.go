@@ -0,0 +1,309 @@
+package ldapchecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/jimlambrt/gldap"
+	"github.com/jimlambrt/gldap/testdirectory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	testBaseDN   = "dc=example,dc=com"
+	testBindDN   = "cn=reader,dc=example,dc=com"
+	testPassword = "s3cret"
+)
+
+// fileTime converts t to an Active Directory FILETIME string.
+func fileTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/100+fileTimeUnixEpochDelta, 10)
+}
+
+// testEntries is a small Active Directory style tree. Domain Admins contains
+// alice, a disabled bob and the nested "Tier0" group holding svc-backup.
+func testEntries() []*gldap.Entry {
+	now := time.Now()
+	user := func(name string, uac int, lastLogon time.Time, created time.Time) *gldap.Entry {
+		attrs := map[string][]string{
+			"objectClass":        {"top", "person", "user"},
+			"objectCategory":     {"person"},
+			"cn":                 {name},
+			"sAMAccountName":     {name},
+			"userAccountControl": {strconv.Itoa(uac)},
+			"whenCreated":        {created.UTC().Format("20060102150405") + ".0Z"},
+		}
+		if !lastLogon.IsZero() {
+			attrs["lastLogonTimestamp"] = []string{fileTime(lastLogon)}
+		}
+		return gldap.NewEntry(fmt.Sprintf("cn=%s,ou=users,%s", name, testBaseDN), attrs)
+	}
+	group := func(name string, members ...string) *gldap.Entry {
+		return gldap.NewEntry(fmt.Sprintf("cn=%s,ou=groups,%s", name, testBaseDN), map[string][]string{
+			"objectClass":    {"top", "group"},
+			"cn":             {name},
+			"sAMAccountName": {name},
+			"member":         members,
+		})
+	}
+	dn := func(name string) string { return fmt.Sprintf("cn=%s,ou=users,%s", name, testBaseDN) }
+
+	return []*gldap.Entry{
+		user("alice", 512, now.AddDate(0, 0, -2), now.AddDate(-2, 0, 0)),
+		user("bob", 514, now.AddDate(0, -6, 0), now.AddDate(-3, 0, 0)),
+		user("svc-backup", 512|uacDontExpirePassword, now.AddDate(0, 0, -1), now.AddDate(-1, 0, 0)),
+		user("carol", 512, now.AddDate(0, 0, -200), now.AddDate(-1, 0, 0)),
+		user("newhire", 512, time.Time{}, now.AddDate(0, 0, -3)),
+		user("ghost", 512, time.Time{}, now.AddDate(-1, 0, 0)),
+		group("Tier0", dn("svc-backup")),
+		group("Domain Admins", dn("alice"), dn("bob"), fmt.Sprintf("cn=Tier0,ou=groups,%s", testBaseDN), "cn=S-1-5-21-1,cn=ForeignSecurityPrincipals,"+testBaseDN),
+	}
+}
+
+// startDirectory runs a gldap server that serves entries with simple bind,
+// base and subtree searches and a minimal filter evaluator.
+func startDirectory(t *testing.T, entries []*gldap.Entry) string {
+	t.Helper()
+	srv, err := gldap.NewServer()
+	require.NoError(t, err)
+	mux, err := gldap.NewMux()
+	require.NoError(t, err)
+
+	require.NoError(t, mux.Bind(func(w *gldap.ResponseWriter, r *gldap.Request) {
+		resp := r.NewBindResponse(gldap.WithResponseCode(gldap.ResultInvalidCredentials))
+		defer w.Write(resp)
+		m, err := r.GetSimpleBindMessage()
+		if err == nil && m.UserName == testBindDN && string(m.Password) == testPassword {
+			resp.SetResultCode(gldap.ResultSuccess)
+		}
+	}))
+	require.NoError(t, mux.Search(func(w *gldap.ResponseWriter, r *gldap.Request) {
+		done := r.NewSearchDoneResponse(gldap.WithResponseCode(gldap.ResultSuccess))
+		defer w.Write(done)
+		m, err := r.GetSearchMessage()
+		if err != nil {
+			done.SetResultCode(gldap.ResultOperationsError)
+			return
+		}
+		filter, err := ldap.CompileFilter(m.Filter)
+		if err != nil {
+			done.SetResultCode(gldap.ResultOperationsError)
+			return
+		}
+		base := strings.ToLower(m.BaseDN)
+		found := false
+		for _, e := range entries {
+			dn := strings.ToLower(e.DN)
+			inScope := dn == base
+			if m.Scope != gldap.BaseObject {
+				inScope = inScope || strings.HasSuffix(dn, ","+base)
+			}
+			if dn == base {
+				found = true
+			}
+			if !inScope || !matchFilter(filter, e) {
+				continue
+			}
+			attrs := map[string][]string{}
+			for _, a := range e.Attributes {
+				attrs[a.Name] = a.Values
+			}
+			w.Write(r.NewSearchResponseEntry(e.DN, gldap.WithAttributes(attrs)))
+		}
+		if m.Scope == gldap.BaseObject && !found {
+			done.SetResultCode(gldap.ResultNoSuchObject)
+		}
+	}))
+	require.NoError(t, srv.Router(mux))
+
+	addr := fmt.Sprintf("127.0.0.1:%d", testdirectory.FreePort(t))
+	go srv.Run(addr)
+	t.Cleanup(func() { srv.Stop() })
+	for !srv.Ready() {
+		time.Sleep(time.Millisecond)
+	}
+	return "ldap://" + addr
+}
+
+// matchFilter evaluates the subset of RFC 4515 filters the checker sends:
+// and, or, not, equality and presence.
+func matchFilter(f *ber.Packet, e *gldap.Entry) bool {
+	values := func(name string) []string {
+		for _, a := range e.Attributes {
+			if strings.EqualFold(a.Name, name) {
+				return a.Values
+			}
+		}
+		return nil
+	}
+	switch f.Tag {
+	case ldap.FilterAnd:
+		for _, c := range f.Children {
+			if !matchFilter(c, e) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, c := range f.Children {
+			if matchFilter(c, e) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !matchFilter(f.Children[0], e)
+	case ldap.FilterEqualityMatch:
+		want := f.Children[1].Data.String()
+		for _, v := range values(f.Children[0].Data.String()) {
+			if strings.EqualFold(v, want) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(values(f.Data.String())) > 0
+	}
+	return false
+}
+
+func runCheck(t *testing.T, url string, checkType string, params map[string]interface{}) (common.ExecutionResult, plugintest.FindingsOutput) {
+	t.Helper()
+	cfg, _ := json.Marshal(LDAPSystemConfig{URL: url, BindDN: testBindDN, BindPassword: testPassword, BaseDN: testBaseDN, DirectoryType: directoryTypeActiveDirectory})
+	var out plugintest.FindingsOutput
+	res := plugintest.Run(t, New(), common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+	}, checkType, &out)
+	return res, out
+}
+
+func TestDisabledAccountsInPrivilegedGroups(t *testing.T) {
+	url := startDirectory(t, testEntries())
+	res, out := runCheck(t, url, CheckTypeKey_LDAPDisabledPrivileged, map[string]interface{}{})
+
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 3, out.ResourcesChecked, "nested members are expanded and unresolvable members skipped")
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "alice").Passed)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "svc-backup").Passed)
+	assert.Equal(t, []string{"disabled account is still a member of cn=Domain Admins,ou=groups," + testBaseDN}, plugintest.FindingFor(t, out.Findings, "bob").Issues)
+
+	res, out = runCheck(t, url, CheckTypeKey_LDAPDisabledPrivileged, map[string]interface{}{"privilegedGroups": "Tier0, Domian Admins"})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "svc-backup").Passed)
+	missing := plugintest.FindingFor(t, out.Findings, "Domian Admins")
+	assert.False(t, missing.Passed)
+	assert.Equal(t, []string{"group not found"}, missing.Issues)
+}
+
+func TestInactiveAccounts(t *testing.T) {
+	url := startDirectory(t, testEntries())
+	res, out := runCheck(t, url, CheckTypeKey_LDAPInactiveAccounts, map[string]interface{}{"maxInactiveDays": float64(90), "excludeAccounts": "svc-backup"})
+
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 4, out.ResourcesChecked, "disabled and excluded accounts are skipped")
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "alice").Passed)
+	assert.True(t, plugintest.FindingFor(t, out.Findings, "newhire").Passed, "recently created accounts get a grace period")
+	assert.Equal(t, []string{"account has never logged in"}, plugintest.FindingFor(t, out.Findings, "ghost").Issues)
+	assert.False(t, plugintest.FindingFor(t, out.Findings, "carol").Passed)
+
+	_, out = runCheck(t, url, CheckTypeKey_LDAPInactiveAccounts, map[string]interface{}{"includeDisabled": "true"})
+	assert.False(t, plugintest.FindingFor(t, out.Findings, "bob").Passed)
+}
+
+func TestAdminGroupMembershipSnapshot(t *testing.T) {
+	url := startDirectory(t, testEntries())
+	res, out := runCheck(t, url, CheckTypeKey_LDAPAdminGroupMembership, map[string]interface{}{"adminGroups": "Domain Admins"})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	group := plugintest.FindingFor(t, out.Findings, "cn=Domain Admins,ou=groups,"+testBaseDN)
+	assert.EqualValues(t, 4, group.Details["member_count"])
+	members, _ := json.Marshal(group.Details["members"])
+	assert.Contains(t, string(members), `"via":"cn=Tier0,ou=groups,dc=example,dc=com"`)
+
+	res, out = runCheck(t, url, CheckTypeKey_LDAPAdminGroupMembership, map[string]interface{}{
+		"adminGroups":    "Domain Admins, Missing Group",
+		"allowedMembers": "alice\nsvc-backup",
+	})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	issues := plugintest.FindingFor(t, out.Findings, "cn=Domain Admins,ou=groups,"+testBaseDN).Issues
+	assert.Contains(t, issues, "bob is not an approved member")
+	assert.Len(t, issues, 2, "bob and the unresolved foreign principal")
+	assert.Equal(t, []string{"group not found"}, plugintest.FindingFor(t, out.Findings, "Missing Group").Issues)
+}
+
+func TestPasswordNeverExpires(t *testing.T) {
+	url := startDirectory(t, testEntries())
+	res, out := runCheck(t, url, CheckTypeKey_LDAPPasswordNeverExpires, map[string]interface{}{})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 1, out.ResourcesFailed)
+	assert.False(t, plugintest.FindingFor(t, out.Findings, "svc-backup").Passed)
+
+	res, _ = runCheck(t, url, CheckTypeKey_LDAPPasswordNeverExpires, map[string]interface{}{"excludeAccounts": "SVC-BACKUP"})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+}
+
+func TestBindFailureIsAnError(t *testing.T) {
+	url := startDirectory(t, testEntries())
+	cfg, _ := json.Marshal(LDAPSystemConfig{URL: url, BindDN: testBindDN, BindPassword: "wrong", BaseDN: testBaseDN})
+	res, err := New().ExecuteCheck(common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: map[string]interface{}{}},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+	}, CheckTypeKey_LDAPPasswordNeverExpires)
+	assert.Error(t, err)
+	assert.Equal(t, common.StatusError, res.Status)
+	assert.Contains(t, res.Output, "Invalid Credentials")
+}
+
+func TestCheckStopsWhenContextIsDone(t *testing.T) {
+	// The server accepts connections but never answers, so the bind only ends
+	// when the check's context closes the connection.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	cfg, _ := json.Marshal(LDAPSystemConfig{URL: "ldap://" + ln.Addr().String(), BindDN: testBindDN, BindPassword: testPassword, BaseDN: testBaseDN})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	res, err := New().ExecuteCheck(common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: map[string]interface{}{}},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+		StdContext:      ctx,
+	}, CheckTypeKey_LDAPPasswordNeverExpires)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, common.StatusError, res.Status)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestParseGeneralizedTime(t *testing.T) {
+	want := time.Date(2024, 1, 31, 12, 30, 0, 0, time.UTC)
+	for _, v := range []string{"20240131123000Z", "20240131123000.0Z", "20240131143000+0200"} {
+		got, ok := parseGeneralizedTime(v)
+		assert.True(t, ok, v)
+		assert.True(t, want.Equal(got), v)
+	}
+	_, ok := parseGeneralizedTime("not a time")
+	assert.False(t, ok)
+}
@@ -0,0 +1,370 @@
+package ldapchecker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+)
+
+const (
+	directoryTypeActiveDirectory = "active_directory"
+	directoryTypeOpenLDAP        = "openldap"
+
+	searchPageSize = 500
+
+	// userAccountControl flags, see MS-ADTS 2.2.16.
+	uacAccountDisable     = 0x0002
+	uacDontExpirePassword = 0x10000
+
+	// shadowMaxNeverExpires is the conventional shadowMax for "no maximum age".
+	shadowMaxNeverExpires = 99999
+	// fileTimeUnixEpochDelta is 1970-01-01 in 100ns intervals since 1601-01-01.
+	fileTimeUnixEpochDelta = 116444736000000000
+)
+
+// accountAttributes are requested for every account and group lookup. They
+// cover both Active Directory and OpenLDAP; servers omit the ones they do not
+// know.
+var accountAttributes = []string{
+	"objectClass", "cn", "displayName", "mail",
+	"sAMAccountName", "userAccountControl", "lastLogonTimestamp", "whenCreated",
+	"uid", "pwdAccountLockedTime", "nsAccountLock", "authTimestamp", "createTimestamp", "shadowMax",
+	"member", "uniqueMember",
+}
+
+// directory wraps a bound LDAP connection together with the system settings
+// needed to interpret entries. The connection is closed when ctx is done,
+// which aborts any bind or search in flight.
+type directory struct {
+	ctx    context.Context
+	conn   *ldap.Conn
+	cfg    LDAPSystemConfig
+	baseDN string
+	closed chan struct{}
+}
+
+func connect(ctx context.Context, cfg LDAPSystemConfig) (*directory, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if cfg.BaseDN == "" {
+		return nil, fmt.Errorf("baseDn is required")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	tlsConfig, err := cfg.tlsConfig(u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dial(ctx, u, tlsConfig)
+	if err != nil {
+		return nil, common.Unreachable(fmt.Errorf("connect to %s: %w", cfg.URL, err))
+	}
+	conn.SetTimeout(30 * time.Second)
+
+	d := &directory{ctx: ctx, conn: conn, cfg: cfg, baseDN: cfg.BaseDN, closed: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-d.closed:
+		}
+	}()
+
+	if startTLS, _ := strconv.ParseBool(cfg.StartTLS); startTLS && u.Scheme != "ldaps" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			d.close()
+			return nil, d.wrap("start TLS", err)
+		}
+	}
+	if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+		d.close()
+		return nil, d.wrap(fmt.Sprintf("bind as %s", cfg.BindDN), err)
+	}
+	return d, nil
+}
+
+// dial opens a connection to an ldap:// or ldaps:// URL, giving up when ctx
+// is done.
+func dial(ctx context.Context, u *url.URL, tlsConfig *tls.Config) (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: 15 * time.Second}
+	port := u.Port()
+	var (
+		conn net.Conn
+		err  error
+	)
+	switch u.Scheme {
+	case "ldap":
+		if port == "" {
+			port = ldap.DefaultLdapPort
+		}
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	case "ldaps":
+		if port == "" {
+			port = ldap.DefaultLdapsPort
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q: use ldap:// or ldaps://", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := ldap.NewConn(conn, u.Scheme == "ldaps")
+	c.Start()
+	return c, nil
+}
+
+func (c LDAPSystemConfig) tlsConfig(serverName string) (*tls.Config, error) {
+	insecure, _ := strconv.ParseBool(c.InsecureSkipVerify)
+	tlsConfig := &tls.Config{ServerName: serverName, InsecureSkipVerify: insecure, MinVersion: tls.VersionTLS12}
+	if strings.TrimSpace(c.CACert) != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.CACert)) {
+			return nil, fmt.Errorf("caCert does not contain a valid PEM certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+func (d *directory) close() {
+	select {
+	case <-d.closed:
+	default:
+		close(d.closed)
+	}
+	d.conn.Close()
+}
+
+// wrap describes a failed operation, reporting the context's error instead
+// of the closed connection when the check was cancelled or timed out.
+func (d *directory) wrap(op string, err error) error {
+	if ctxErr := d.ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s: %w", op, ctxErr)
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func (d *directory) activeDirectory() bool {
+	return d.cfg.DirectoryType != directoryTypeOpenLDAP
+}
+
+func (d *directory) search(base string, scope int, filter string) ([]*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(base, scope, ldap.NeverDerefAliases, 0, 0, false, filter, accountAttributes, nil)
+	res, err := d.conn.SearchWithPaging(req, searchPageSize)
+	if err != nil {
+		return nil, d.wrap(fmt.Sprintf("search %s %s", base, filter), err)
+	}
+	return res.Entries, nil
+}
+
+// users returns all user accounts below the base DN.
+func (d *directory) users() ([]*ldap.Entry, error) {
+	filter := d.cfg.UserFilter
+	if filter == "" {
+		filter = "(|(objectClass=inetOrgPerson)(objectClass=posixAccount))"
+		if d.activeDirectory() {
+			filter = "(&(objectClass=user)(objectCategory=person))"
+		}
+	}
+	return d.search(d.baseDN, ldap.ScopeWholeSubtree, filter)
+}
+
+// lookup reads a single entry by DN, returning nil when it does not exist.
+func (d *directory) lookup(dn string) (*ldap.Entry, error) {
+	entries, err := d.search(dn, ldap.ScopeBaseObject, "(objectClass=*)")
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
+
+// group resolves a group given either its DN or its common name.
+func (d *directory) group(nameOrDN string) (*ldap.Entry, error) {
+	if strings.Contains(nameOrDN, "=") {
+		return d.lookup(nameOrDN)
+	}
+	name := ldap.EscapeFilter(nameOrDN)
+	filter := fmt.Sprintf("(&(|(objectClass=group)(objectClass=groupOfNames)(objectClass=groupOfUniqueNames))(|(cn=%s)(sAMAccountName=%s)))", name, name)
+	entries, err := d.search(d.baseDN, ldap.ScopeWholeSubtree, filter)
+	if err != nil {
+		return nil, err
+	}
+	switch len(entries) {
+	case 0:
+		return nil, nil
+	case 1:
+		return entries[0], nil
+	default:
+		return nil, fmt.Errorf("group name %q is ambiguous (%d matches); use its DN", nameOrDN, len(entries))
+	}
+}
+
+// member is an account that belongs to a group, directly or through nesting.
+type member struct {
+	DN       string `json:"dn"`
+	Account  string `json:"account"`
+	Disabled bool   `json:"disabled"`
+	Via      string `json:"via,omitempty"`
+	entry    *ldap.Entry
+}
+
+// members expands group membership recursively. Members that cannot be read
+// (for example foreign security principals) are reported with only their DN.
+func (d *directory) members(group *ldap.Entry) ([]member, error) {
+	var out []member
+	seen := map[string]bool{strings.ToLower(group.DN): true}
+	var walk func(g *ldap.Entry, via string) error
+	walk = func(g *ldap.Entry, via string) error {
+		for _, dn := range memberDNs(g) {
+			key := strings.ToLower(dn)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			entry, err := d.lookup(dn)
+			if err != nil {
+				return err
+			}
+			if entry != nil && isGroup(entry) {
+				if err := walk(entry, entry.DN); err != nil {
+					return err
+				}
+				continue
+			}
+			m := member{DN: dn, Account: dn, Via: via, entry: entry}
+			if entry != nil {
+				m.Account = accountName(entry)
+				m.Disabled = isDisabled(entry)
+			}
+			out = append(out, m)
+		}
+		return nil
+	}
+	if err := walk(group, ""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func memberDNs(group *ldap.Entry) []string {
+	dns := group.GetEqualFoldAttributeValues("member")
+	for _, v := range group.GetEqualFoldAttributeValues("uniqueMember") {
+		// uniqueMember values may carry an optional "#'bitstring'B" UID suffix.
+		if i := strings.LastIndex(v, "#'"); i > 0 {
+			v = v[:i]
+		}
+		dns = append(dns, v)
+	}
+	return dns
+}
+
+func isGroup(e *ldap.Entry) bool {
+	for _, oc := range e.GetEqualFoldAttributeValues("objectClass") {
+		switch strings.ToLower(oc) {
+		case "group", "groupofnames", "groupofuniquenames":
+			return true
+		}
+	}
+	return false
+}
+
+func accountName(e *ldap.Entry) string {
+	for _, attr := range []string{"sAMAccountName", "uid", "cn"} {
+		if v := e.GetEqualFoldAttributeValue(attr); v != "" {
+			return v
+		}
+	}
+	return e.DN
+}
+
+func userAccountControl(e *ldap.Entry) int64 {
+	v, _ := strconv.ParseInt(e.GetEqualFoldAttributeValue("userAccountControl"), 10, 64)
+	return v
+}
+
+// isDisabled recognises the Active Directory ACCOUNTDISABLE flag, OpenLDAP
+// ppolicy lockouts and the 389-DS/FreeIPA nsAccountLock attribute.
+func isDisabled(e *ldap.Entry) bool {
+	if userAccountControl(e)&uacAccountDisable != 0 {
+		return true
+	}
+	if e.GetEqualFoldAttributeValue("pwdAccountLockedTime") != "" {
+		return true
+	}
+	return strings.EqualFold(e.GetEqualFoldAttributeValue("nsAccountLock"), "true")
+}
+
+func passwordNeverExpires(e *ldap.Entry) bool {
+	if userAccountControl(e)&uacDontExpirePassword != 0 {
+		return true
+	}
+	if v := e.GetEqualFoldAttributeValue("shadowMax"); v != "" {
+		max, err := strconv.ParseInt(v, 10, 64)
+		return err == nil && (max < 0 || max >= shadowMaxNeverExpires)
+	}
+	return false
+}
+
+// lastLogon returns the last recorded authentication time: lastLogonTimestamp
+// on Active Directory, authTimestamp (ppolicy lastbind) on OpenLDAP.
+func lastLogon(e *ldap.Entry) (time.Time, bool) {
+	if v := e.GetEqualFoldAttributeValue("lastLogonTimestamp"); v != "" {
+		ft, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ft <= 0 {
+			return time.Time{}, false
+		}
+		return time.Unix(0, (ft-fileTimeUnixEpochDelta)*100).UTC(), true
+	}
+	return parseGeneralizedTime(e.GetEqualFoldAttributeValue("authTimestamp"))
+}
+
+func createdAt(e *ldap.Entry) (time.Time, bool) {
+	if v := e.GetEqualFoldAttributeValue("whenCreated"); v != "" {
+		return parseGeneralizedTime(v)
+	}
+	return parseGeneralizedTime(e.GetEqualFoldAttributeValue("createTimestamp"))
+}
+
+// parseGeneralizedTime parses the LDAP GeneralizedTime syntax, e.g.
+// "20240131120000Z" or "20240131120000.0Z", dropping fractional seconds.
+func parseGeneralizedTime(v string) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+	if i := strings.IndexByte(v, '.'); i >= 0 {
+		j := i + 1
+		for j < len(v) && v[j] >= '0' && v[j] <= '9' {
+			j++
+		}
+		v = v[:i] + v[j:]
+	}
+	if strings.HasSuffix(v, "Z") {
+		v = strings.TrimSuffix(v, "Z") + "+0000"
+	}
+	t, err := time.Parse("20060102150405-0700", v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
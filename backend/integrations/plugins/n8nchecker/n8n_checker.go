@@ -2,10 +2,14 @@ package n8nchecker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/vdparikh/compliance-automation/backend/integrations"
@@ -16,13 +20,28 @@ import (
 const (
 	PluginID_N8NChecker               = "n8n_checker_v1"
 	PluginName_N8NChecker             = "n8n Workflow Execution Checker"
+	PluginVersion_N8NChecker          = "1.0.0"
 	CheckTypeKey_N8NWorkflowExecution = "n8n_workflow_execution"
+
+	// apiKeyHeader is the header n8n's public API expects the API key in.
+	apiKeyHeader = "X-N8N-API-KEY"
+
+	defaultTimeout      = 5 * time.Minute
+	defaultPollInterval = 2 * time.Second
 )
 
-type N8NChecker struct{}
+type N8NChecker struct {
+	httpClient   *http.Client
+	pollInterval time.Duration
+}
 
+// New returns an N8NChecker. Its HTTP client has no timeout of its own: each
+// check bounds its requests by the timeoutSeconds parameter instead.
 func New() *N8NChecker {
-	return &N8NChecker{}
+	return &N8NChecker{
+		httpClient:   &http.Client{},
+		pollInterval: defaultPollInterval,
+	}
 }
 
 func (p *N8NChecker) ID() string {
@@ -33,6 +52,10 @@ func (p *N8NChecker) Name() string {
 	return PluginName_N8NChecker
 }
 
+func (p *N8NChecker) Version() string {
+	return PluginVersion_N8NChecker
+}
+
 func (p *N8NChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		CheckTypeKey_N8NWorkflowExecution: {
@@ -45,7 +68,7 @@ func (p *N8NChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeCon
 					Label:    "Webhook URL",
 					Type:     "text",
 					Required: true,
-					HelpText: "The webhook URL for the n8n workflow to execute.",
+					HelpText: "The webhook URL for the n8n workflow to execute. Must belong to the n8n instance's base URL; a path such as /webhook/my-check is resolved against it.",
 				},
 				{
 					Name:     "inputData",
@@ -54,6 +77,22 @@ func (p *N8NChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeCon
 					Required: false,
 					HelpText: "Optional JSON input data to pass to the workflow via webhook.",
 				},
+				{
+					Name:     "waitForCompletion",
+					Label:    "Wait for Completion",
+					Type:     "select",
+					Options:  []string{"false", "true"},
+					Required: false,
+					HelpText: "Poll the n8n executions API until the workflow finishes. The webhook response must include the execution ID as 'executionId' (e.g. {{$execution.id}} in a Respond to Webhook node).",
+				},
+				{
+					Name:        "timeoutSeconds",
+					Label:       "Timeout (seconds)",
+					Type:        "number",
+					Required:    false,
+					Placeholder: "300",
+					HelpText:    "Maximum time to wait for the webhook and execution to finish. Defaults to 300 seconds.",
+				},
 			},
 		},
 	}
@@ -65,12 +104,33 @@ type N8NSystemConfig struct {
 }
 
 type n8nWorkflowExecutionResponse struct {
-	ID        string      `json:"id"`
-	Status    string      `json:"status"`
-	Data      interface{} `json:"data"`
-	Error     string      `json:"error,omitempty"`
-	StartTime string      `json:"startTime"`
-	EndTime   string      `json:"endTime"`
+	ID          string      `json:"id"`
+	ExecutionID string      `json:"executionId"`
+	Status      string      `json:"status"`
+	Data        interface{} `json:"data"`
+	Error       string      `json:"error,omitempty"`
+	StartTime   string      `json:"startTime"`
+	EndTime     string      `json:"endTime"`
+}
+
+// n8nExecution is the subset of the n8n public API execution resource we use.
+type n8nExecution struct {
+	ID         json.Number `json:"id"`
+	Finished   bool        `json:"finished"`
+	Mode       string      `json:"mode"`
+	Status     string      `json:"status"`
+	StartedAt  string      `json:"startedAt"`
+	StoppedAt  string      `json:"stoppedAt"`
+	WorkflowID json.Number `json:"workflowId"`
+}
+
+// succeeded reports whether a finished execution succeeded. Older n8n
+// versions report no status, only whether the execution finished.
+func (e *n8nExecution) succeeded() bool {
+	if e.Status == "" {
+		return e.Finished
+	}
+	return e.Status == "success"
 }
 
 func (p *N8NChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
@@ -87,11 +147,16 @@ func (p *N8NChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string)
 		return common.ExecutionResult{Status: common.StatusError, Output: "n8n BaseURL is required"}, fmt.Errorf("missing n8n base URL")
 	}
 
-	webhookUrl, ok := ctx.TaskInstance.Parameters["webhookUrl"].(string)
-	if !ok || webhookUrl == "" {
+	rawWebhookUrl, ok := ctx.TaskInstance.Parameters["webhookUrl"].(string)
+	if !ok || rawWebhookUrl == "" {
 		return common.ExecutionResult{Status: common.StatusError, Output: "Missing webhookUrl parameter"}, fmt.Errorf("missing webhookUrl parameter")
 	}
 
+	webhookUrl, err := resolveWebhookURL(sysConfig.BaseURL, rawWebhookUrl)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Invalid webhookUrl: %v", err)}, fmt.Errorf("invalid webhookUrl: %w", err)
+	}
+
 	// Get input data if provided
 	var inputData interface{}
 	if inputDataStr, ok := ctx.TaskInstance.Parameters["inputData"].(string); ok && inputDataStr != "" {
@@ -100,21 +165,32 @@ func (p *N8NChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string)
 		}
 	}
 
+	timeout, err := timeoutParam(ctx.TaskInstance.Parameters["timeoutSeconds"])
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+	waitForCompletion := common.BoolParam(ctx.TaskInstance.Parameters, "waitForCompletion", false)
+
+	runCtx, cancel := context.WithTimeout(ctx.Context(), timeout)
+	defer cancel()
+
 	// Execute the workflow via webhook
-	executionResult, err := p.executeN8NWebhook(webhookUrl, inputData)
+	ctx.Logf(common.LogLevelInfo, "Calling n8n webhook %s", webhookUrl)
+	executionResult, err := p.executeN8NWebhook(runCtx, sysConfig, webhookUrl, inputData)
 	if err != nil {
+		ctx.Logf(common.LogLevelError, "n8n webhook failed: %v", err)
 		return common.ExecutionResult{Status: common.StatusFailed, Output: err.Error()}, err
 	}
 
-	// Determine status based on n8n execution result
-	status := common.StatusSuccess
-	if executionResult.Status == "error" {
-		status = common.StatusFailed
+	executionID := executionResult.ExecutionID
+	if executionID == "" {
+		executionID = executionResult.ID
 	}
+	ctx.Logf(common.LogLevelInfo, "n8n accepted the webhook (execution %q, status %q)", executionID, executionResult.Status)
 
 	result := map[string]interface{}{
 		"webhookUrl":  webhookUrl,
-		"executionId": executionResult.ID,
+		"executionId": executionID,
 		"status":      executionResult.Status,
 		"data":        executionResult.Data,
 		"startTime":   executionResult.StartTime,
@@ -126,15 +202,44 @@ func (p *N8NChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string)
 		result["error"] = executionResult.Error
 	}
 
+	// Determine status based on n8n execution result
+	status := common.StatusSuccess
+	if executionResult.Status == "error" {
+		status = common.StatusFailed
+	}
+
+	if waitForCompletion {
+		if executionID == "" {
+			result["error"] = "webhook response did not include an executionId to poll"
+			jsonOut, _ := json.Marshal(result)
+			return common.ExecutionResult{Status: common.StatusError, Output: string(jsonOut)}, fmt.Errorf("webhook response did not include an executionId")
+		}
+		ctx.Logf(common.LogLevelInfo, "Waiting up to %s for n8n execution %s to finish", timeout, executionID)
+		execution, err := p.waitForExecution(runCtx, sysConfig, executionID)
+		if err != nil {
+			ctx.Logf(common.LogLevelError, "Waiting for n8n execution failed: %v", err)
+			result["error"] = err.Error()
+			jsonOut, _ := json.Marshal(result)
+			return common.ExecutionResult{Status: common.StatusError, Output: string(jsonOut)}, err
+		}
+		result["status"] = execution.Status
+		result["finished"] = execution.Finished
+		result["workflowId"] = execution.WorkflowID.String()
+		result["startTime"] = execution.StartedAt
+		result["endTime"] = execution.StoppedAt
+		ctx.Logf(common.LogLevelInfo, "n8n execution %s finished with status %q", executionID, execution.Status)
+
+		status = common.StatusSuccess
+		if !execution.succeeded() {
+			status = common.StatusFailed
+		}
+	}
+
 	jsonOut, _ := json.Marshal(result)
 	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
 }
 
-func (p *N8NChecker) executeN8NWebhook(webhookUrl string, inputData interface{}) (*n8nWorkflowExecutionResponse, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-
-	fmt.Printf("Executing n8n webhook: %s\n", webhookUrl)
-
+func (p *N8NChecker) executeN8NWebhook(ctx context.Context, sysConfig N8NSystemConfig, webhookUrl string, inputData interface{}) (*n8nWorkflowExecutionResponse, error) {
 	// Prepare the webhook payload
 	var requestBody []byte
 	var err error
@@ -150,20 +255,19 @@ func (p *N8NChecker) executeN8NWebhook(webhookUrl string, inputData interface{})
 	}
 
 	// Create HTTP request to the webhook URL
-	req, err := http.NewRequest("POST", webhookUrl, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookUrl, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("create webhook request: %w", err)
 	}
 
 	// Add headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "compliance-automation/1.0")
+	p.setCommonHeaders(req, sysConfig)
 
 	// Execute the webhook
-	resp, err := client.Do(req)
+	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("execute webhook request: %w", err)
+		return nil, common.Unreachable(fmt.Errorf("execute webhook request: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -172,27 +276,146 @@ func (p *N8NChecker) executeN8NWebhook(webhookUrl string, inputData interface{})
 		return nil, fmt.Errorf("read webhook response body: %w", err)
 	}
 
-	fmt.Printf("Webhook response: Status=%d, Body=%s\n", resp.StatusCode, string(body))
-
 	// Accept 200, 201, 202 as success status codes
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		// Try to parse the response if it's JSON
-		var executionResp n8nWorkflowExecutionResponse
-		if err := json.Unmarshal(body, &executionResp); err == nil {
-			fmt.Printf("Successfully parsed webhook response\n")
-			return &executionResp, nil
-		} else {
-			fmt.Printf("Could not parse webhook response as JSON, treating as success\n")
-			// If we can't parse the response but got a success status, consider it successful
-			return &n8nWorkflowExecutionResponse{
-				ID:     webhookUrl,
-				Status: "success",
-				Data:   string(body),
-			}, nil
-		}
-	} else {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("webhook failed: %s - %s", resp.Status, string(body))
 	}
+
+	// Try to parse the response if it's JSON
+	var executionResp n8nWorkflowExecutionResponse
+	if err := json.Unmarshal(body, &executionResp); err == nil {
+		return &executionResp, nil
+	}
+	// If we can't parse the response but got a success status, consider it successful
+	return &n8nWorkflowExecutionResponse{
+		Status: "success",
+		Data:   string(body),
+	}, nil
+}
+
+// waitForExecution polls the n8n executions API until the execution leaves a
+// running state or ctx is done.
+func (p *N8NChecker) waitForExecution(ctx context.Context, sysConfig N8NSystemConfig, executionID string) (*n8nExecution, error) {
+	if sysConfig.APIKey == "" {
+		return nil, fmt.Errorf("n8n apiKey is required to poll execution status")
+	}
+
+	for {
+		execution, err := p.getExecution(ctx, sysConfig, executionID)
+		if err != nil {
+			return nil, err
+		}
+		if execution.Finished || isTerminalStatus(execution.Status) {
+			return execution, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for n8n execution %s (last status %q): %w", executionID, execution.Status, ctx.Err())
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
+
+func (p *N8NChecker) getExecution(ctx context.Context, sysConfig N8NSystemConfig, executionID string) (*n8nExecution, error) {
+	endpoint := strings.TrimRight(sysConfig.BaseURL, "/") + "/api/v1/executions/" + url.PathEscape(executionID)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create execution request: %w", err)
+	}
+	p.setCommonHeaders(req, sysConfig)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, common.Unreachable(fmt.Errorf("get n8n execution %s: %w", executionID, err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read execution response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get n8n execution %s failed: %s - %s", executionID, resp.Status, string(body))
+	}
+
+	var execution n8nExecution
+	if err := json.Unmarshal(body, &execution); err != nil {
+		return nil, fmt.Errorf("parse n8n execution response: %w", err)
+	}
+	return &execution, nil
+}
+
+func (p *N8NChecker) setCommonHeaders(req *http.Request, sysConfig N8NSystemConfig) {
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "compliance-automation/1.0")
+	if sysConfig.APIKey != "" {
+		req.Header.Set(apiKeyHeader, sysConfig.APIKey)
+	}
+}
+
+// resolveWebhookURL resolves webhookUrl against baseURL and rejects URLs that
+// point outside the configured n8n instance, so task parameters cannot be used
+// to make the worker (and its API key) call arbitrary hosts.
+func resolveWebhookURL(baseURL, webhookUrl string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Host == "" {
+		return "", fmt.Errorf("n8n base URL %q is not an absolute URL", baseURL)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return "", fmt.Errorf("n8n base URL must use http or https")
+	}
+
+	ref, err := url.Parse(webhookUrl)
+	if err != nil {
+		return "", fmt.Errorf("parse webhook URL: %w", err)
+	}
+	resolved := base.ResolveReference(ref)
+
+	if resolved.Scheme != base.Scheme || !strings.EqualFold(resolved.Host, base.Host) {
+		return "", fmt.Errorf("webhook URL must be on the configured n8n instance %s://%s", base.Scheme, base.Host)
+	}
+	if resolved.User != nil {
+		return "", fmt.Errorf("webhook URL must not contain credentials")
+	}
+	basePath := strings.TrimRight(base.Path, "/")
+	if basePath != "" && resolved.Path != basePath && !strings.HasPrefix(resolved.Path, basePath+"/") {
+		return "", fmt.Errorf("webhook URL must be under the n8n base path %s", basePath)
+	}
+	return resolved.String(), nil
+}
+
+func isTerminalStatus(status string) bool {
+	switch status {
+	case "success", "error", "crashed", "canceled", "cancelled":
+		return true
+	}
+	return false
+}
+
+func timeoutParam(v interface{}) (time.Duration, error) {
+	var seconds float64
+	switch t := v.(type) {
+	case nil:
+		return defaultTimeout, nil
+	case float64:
+		seconds = t
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return defaultTimeout, nil
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timeoutSeconds %q", t)
+		}
+		seconds = parsed
+	default:
+		return 0, fmt.Errorf("invalid timeoutSeconds %v", v)
+	}
+	if seconds <= 0 {
+		return 0, fmt.Errorf("timeoutSeconds must be positive")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
 }
 
 var _ integrations.IntegrationPlugin = (*N8NChecker)(nil)
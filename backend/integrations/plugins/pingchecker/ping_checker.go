@@ -26,6 +26,10 @@ func (p *PingChecker) Name() string {
 	return "Ping Host Checker"
 }
 
+func (p *PingChecker) Version() string {
+	return "1.0.0"
+}
+
 func (p *PingChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		"ping_host": {
@@ -25,6 +25,10 @@ func (p *Plugin) Name() string {
 	return "Port Scanner Integration"
 }
 
+func (p *Plugin) Version() string {
+	return "1.0.0"
+}
+
 type portScannerSystemConfig struct {
 	HostAddress string `json:"hostAddress"` // e.g., "192.168.1.100" or "example.com"
 }
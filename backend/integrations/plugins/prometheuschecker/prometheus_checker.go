@@ -0,0 +1,376 @@
+package prometheuschecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	PluginID_PrometheusChecker      = "prometheus_checker_v1"
+	PluginName_PrometheusChecker    = "Prometheus Metrics Query Checker"
+	PluginVersion_PrometheusChecker = "1.0.0"
+	CheckTypeKey_PrometheusInstant  = "prometheus_instant_query"
+	CheckTypeKey_PrometheusRange    = "prometheus_range_query"
+
+	defaultTimeout = 3 * time.Minute
+	// defaultRangePoints sets the step when none is given: window / 300.
+	defaultRangePoints = 300
+	// maxOutputSamples caps the samples attached per series in the output.
+	maxOutputSamples = 1000
+)
+
+var (
+	operators  = []string{">=", ">", "<=", "<", "==", "!="}
+	reducers   = []string{"avg", "min", "max", "last", "sum", "count"}
+	seriesMode = []string{"all", "any"}
+)
+
+// PrometheusSystemConfig matches the 'prometheus' system type configuration.
+type PrometheusSystemConfig struct {
+	BaseURL               string `json:"baseUrl"`
+	BearerToken           string `json:"bearerToken"`
+	Username              string `json:"username"`
+	Password              string `json:"password"`
+	TenantID              string `json:"tenantId"`
+	InsecureSkipTLSVerify string `json:"insecureSkipTlsVerify"`
+}
+
+// assertion is "value <operator> threshold", applied to every series (all) or
+// at least one (any).
+type assertion struct {
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	Mode      string  `json:"series_mode"`
+	OnEmpty   string  `json:"on_empty"`
+}
+
+func (a assertion) String() string {
+	return fmt.Sprintf("value %s %s for %s series", a.Operator, strconv.FormatFloat(a.Threshold, 'f', -1, 64), a.Mode)
+}
+
+func (a assertion) holds(v float64) bool {
+	if math.IsNaN(v) {
+		return false
+	}
+	switch a.Operator {
+	case ">=":
+		return v >= a.Threshold
+	case ">":
+		return v > a.Threshold
+	case "<=":
+		return v <= a.Threshold
+	case "<":
+		return v < a.Threshold
+	case "==":
+		return v == a.Threshold
+	case "!=":
+		return v != a.Threshold
+	}
+	return false
+}
+
+// seriesResult is the per-series outcome reported in the check output.
+type seriesResult struct {
+	Metric           map[string]string `json:"metric"`
+	Value            interface{}       `json:"value"`
+	Passed           bool              `json:"passed"`
+	Samples          [][2]interface{}  `json:"samples,omitempty"`
+	SampleCount      int               `json:"sample_count,omitempty"`
+	SamplesTruncated bool              `json:"samples_truncated,omitempty"`
+}
+
+type PrometheusChecker struct {
+	now func() time.Time
+}
+
+func New() *PrometheusChecker {
+	return &PrometheusChecker{now: time.Now}
+}
+
+func (p *PrometheusChecker) ID() string {
+	return PluginID_PrometheusChecker
+}
+
+func (p *PrometheusChecker) Name() string {
+	return PluginName_PrometheusChecker
+}
+
+func (p *PrometheusChecker) Version() string {
+	return PluginVersion_PrometheusChecker
+}
+
+func (p *PrometheusChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	assertionParams := []models.ParameterDefinition{
+		{Name: "operator", Label: "Operator", Type: "select", Required: true, Options: operators, HelpText: "Comparison applied as: value <operator> threshold."},
+		{Name: "threshold", Label: "Threshold", Type: "number", Required: true, Placeholder: "99.9"},
+		{Name: "seriesMode", Label: "Series Must Match", Type: "select", Options: seriesMode, HelpText: "Whether every returned series (all) or at least one (any) must satisfy the assertion. Defaults to all."},
+		{Name: "onEmpty", Label: "When No Data", Type: "select", Options: []string{"fail", "pass"}, HelpText: "Outcome when the query returns no series. Defaults to fail."},
+	}
+	queryParam := models.ParameterDefinition{Name: "query", Label: "PromQL Query", Type: "textarea", Required: true, Placeholder: `avg_over_time(up{job="api"}[30d]) * 100`}
+
+	return map[string]models.CheckTypeConfiguration{
+		CheckTypeKey_PrometheusInstant: {
+			Label:       "Prometheus Instant Query",
+			TargetType:  "connected_system",
+			TargetLabel: "Prometheus Endpoint",
+			Parameters: append([]models.ParameterDefinition{
+				queryParam,
+				{Name: "evaluationTime", Label: "Evaluation Time (Optional)", Type: "text", Placeholder: "2024-06-30T00:00:00Z", HelpText: "RFC 3339 time to evaluate the query at. Defaults to now."},
+			}, assertionParams...),
+		},
+		CheckTypeKey_PrometheusRange: {
+			Label:       "Prometheus Range Query",
+			TargetType:  "connected_system",
+			TargetLabel: "Prometheus Endpoint",
+			Parameters: append([]models.ParameterDefinition{
+				queryParam,
+				{Name: "window", Label: "Time Window", Type: "text", Required: true, Placeholder: "30d", HelpText: "How far back from now to query, e.g. 24h, 7d or 30d."},
+				{Name: "step", Label: "Step (Optional)", Type: "text", Placeholder: "1h", HelpText: "Resolution of the range query. Defaults to the window divided into 300 points."},
+				{Name: "reduce", Label: "Reduce Series To", Type: "select", Options: reducers, HelpText: "How each series' samples are reduced to the value that is asserted on. Defaults to avg."},
+			}, assertionParams...),
+		},
+	}
+}
+
+func (p *PrometheusChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
+	var sysConfig PrometheusSystemConfig
+	if err := json.Unmarshal(ctx.ConnectedSystem.Configuration, &sysConfig); err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Failed to parse Prometheus system configuration"}, fmt.Errorf("unmarshal prometheus config: %w", err)
+	}
+	if sysConfig.BaseURL == "" {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Prometheus baseUrl is required"}, fmt.Errorf("missing prometheus baseUrl")
+	}
+
+	params := ctx.TaskInstance.Parameters
+	query := common.StringParam(params, "query")
+	if query == "" {
+		return common.ExecutionResult{Status: common.StatusError, Output: "query parameter is required"}, fmt.Errorf("query parameter is required")
+	}
+	want, err := assertionFromParams(params)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx.Context(), defaultTimeout)
+	defer cancel()
+
+	client := newPromClient(sysConfig)
+	output := map[string]interface{}{"query": query, "assertion": want}
+
+	var (
+		result *queryResult
+		reduce string
+	)
+	switch checkTypeKey {
+	case CheckTypeKey_PrometheusInstant:
+		at := p.now()
+		if s := common.StringParam(params, "evaluationTime"); s != "" {
+			if at, err = time.Parse(time.RFC3339, s); err != nil {
+				return common.ExecutionResult{Status: common.StatusError, Output: "evaluationTime must be an RFC 3339 time"}, fmt.Errorf("invalid evaluationTime: %w", err)
+			}
+		}
+		output["query_type"] = "instant"
+		output["evaluation_time"] = at.UTC()
+		result, err = client.instantQuery(runCtx, query, at)
+	case CheckTypeKey_PrometheusRange:
+		var window time.Duration
+		if window, err = parseDuration(common.StringParam(params, "window")); err != nil {
+			return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+		}
+		step := window / defaultRangePoints
+		if s := common.StringParam(params, "step"); s != "" {
+			if step, err = parseDuration(s); err != nil {
+				return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+			}
+		}
+		if step < time.Second {
+			step = time.Second
+		}
+		reduce = common.StringParam(params, "reduce")
+		if reduce == "" {
+			reduce = "avg"
+		}
+		if !slices.Contains(reducers, reduce) {
+			err := fmt.Errorf("reduce must be one of %s", strings.Join(reducers, ", "))
+			return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+		}
+		end := p.now().UTC()
+		start := end.Add(-window)
+		output["query_type"] = "range"
+		output["window"] = common.StringParam(params, "window")
+		output["start"] = start
+		output["end"] = end
+		output["step"] = step.String()
+		output["reduce"] = reduce
+		result, err = client.rangeQuery(runCtx, query, start, end, step)
+	default:
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	results := make([]seriesResult, 0, len(result.Series))
+	passedCount := 0
+	for _, s := range result.Series {
+		r := seriesResult{Metric: s.Metric}
+		var value float64
+		if s.Value != nil {
+			value = s.Value.Value
+		} else {
+			value = reduceSamples(reduce, s.Values)
+			r.SampleCount = len(s.Values)
+			samples := s.Values
+			if len(samples) > maxOutputSamples {
+				samples = samples[len(samples)-maxOutputSamples:]
+				r.SamplesTruncated = true
+			}
+			for _, smp := range samples {
+				r.Samples = append(r.Samples, [2]interface{}{smp.Time.Unix(), jsonValue(smp.Value)})
+			}
+		}
+		r.Value = jsonValue(value)
+		r.Passed = want.holds(value)
+		if r.Passed {
+			passedCount++
+		}
+		results = append(results, r)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return labelString(results[i].Metric) < labelString(results[j].Metric) })
+
+	var passed bool
+	var message string
+	switch {
+	case len(results) == 0:
+		passed = want.OnEmpty == "pass"
+		message = "Query returned no series."
+	case want.Mode == "any":
+		passed = passedCount > 0
+		message = fmt.Sprintf("%d of %d series satisfy %s.", passedCount, len(results), want)
+	default:
+		passed = passedCount == len(results)
+		message = fmt.Sprintf("%d of %d series satisfy %s.", passedCount, len(results), want)
+	}
+
+	status := common.StatusFailed
+	if passed {
+		status = common.StatusSuccess
+	}
+	output["message"] = message
+	output["result_type"] = result.ResultType
+	output["series_checked"] = len(results)
+	output["series_failed"] = len(results) - passedCount
+	output["series"] = results
+	if len(result.Warnings) > 0 {
+		output["warnings"] = result.Warnings
+	}
+	jsonOut, err := json.Marshal(output)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, fmt.Errorf("marshal prometheus output: %w", err)
+	}
+	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
+}
+
+func assertionFromParams(params map[string]interface{}) (assertion, error) {
+	a := assertion{
+		Operator: common.StringParam(params, "operator"),
+		Mode:     common.StringParam(params, "seriesMode"),
+		OnEmpty:  common.StringParam(params, "onEmpty"),
+	}
+	if !slices.Contains(operators, a.Operator) {
+		return a, fmt.Errorf("operator must be one of %s", strings.Join(operators, " "))
+	}
+	threshold, ok, err := common.FloatParam(params, "threshold")
+	if err != nil || !ok {
+		return a, fmt.Errorf("threshold parameter is required and must be a number")
+	}
+	a.Threshold = threshold
+	if a.Mode == "" {
+		a.Mode = "all"
+	}
+	if !slices.Contains(seriesMode, a.Mode) {
+		return a, fmt.Errorf("seriesMode must be all or any")
+	}
+	if a.OnEmpty == "" {
+		a.OnEmpty = "fail"
+	}
+	if a.OnEmpty != "fail" && a.OnEmpty != "pass" {
+		return a, fmt.Errorf("onEmpty must be fail or pass")
+	}
+	return a, nil
+}
+
+// reduceSamples collapses a range series to one value. NaN samples (e.g. from
+// a division by zero) are skipped; an empty series reduces to NaN.
+func reduceSamples(reduce string, samples []sample) float64 {
+	var values []float64
+	for _, s := range samples {
+		if !math.IsNaN(s.Value) {
+			values = append(values, s.Value)
+		}
+	}
+	if reduce == "count" {
+		return float64(len(values))
+	}
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	switch reduce {
+	case "min":
+		m := values[0]
+		for _, v := range values[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	case "max":
+		m := values[0]
+		for _, v := range values[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	case "last":
+		return values[len(values)-1]
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	if reduce == "sum" {
+		return sum
+	}
+	return sum / float64(len(values))
+}
+
+// jsonValue keeps NaN and ±Inf, which encoding/json rejects, as strings.
+func jsonValue(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return v
+}
+
+func labelString(metric map[string]string) string {
+	keys := make([]string, 0, len(metric))
+	for k := range metric {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%q", k, metric[k])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+var _ integrations.IntegrationPlugin = (*PrometheusChecker)(nil)
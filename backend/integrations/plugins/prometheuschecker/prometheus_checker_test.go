@@ -0,0 +1,221 @@
+package prometheuschecker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+type checkOutput struct {
+	Message       string         `json:"message"`
+	QueryType     string         `json:"query_type"`
+	ResultType    string         `json:"result_type"`
+	Step          string         `json:"step"`
+	SeriesChecked int            `json:"series_checked"`
+	SeriesFailed  int            `json:"series_failed"`
+	Series        []seriesResult `json:"series"`
+	Warnings      []string       `json:"warnings"`
+}
+
+var now = time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+// promStub serves canned Prometheus HTTP API responses keyed by query and
+// remembers the last request it received.
+type promStub struct {
+	responses map[string]string
+	last      *http.Request
+}
+
+func (s *promStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.last = r
+	if r.URL.Path != "/api/v1/query" && r.URL.Path != "/api/v1/query_range" {
+		http.NotFound(w, r)
+		return
+	}
+	body, ok := s.responses[r.URL.Query().Get("query")]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		body = `{"status":"error","errorType":"bad_data","error":"1:1: parse error: unexpected character"}`
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(body))
+}
+
+func startPrometheus(t *testing.T) (*promStub, string) {
+	t.Helper()
+	stub := &promStub{responses: map[string]string{
+		`avg_over_time(up{job="api"}[30d]) * 100`: `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"instance":"api-1:9100","job":"api"},"value":[1719748800,"99.95"]},
+			{"metric":{"instance":"api-2:9100","job":"api"},"value":[1719748800,"99.42"]}]}}`,
+		`increase(backup_success_total[24h])`: `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"pg-backup"},"values":[[1719662400,"0"],[1719705600,"1"],[1719748800,"0"]]},
+			{"metric":{"job":"redis-backup"},"values":[[1719662400,"0"],[1719705600,"0"],[1719748800,"0"]]}]},
+			"warnings":["query touched a partially available store"]}`,
+		`absent_metric`:      `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		`scalar(vector(42))`: `{"status":"success","data":{"resultType":"scalar","result":[1719748800,"42"]}}`,
+		`errors / requests`:  `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1719748800,"NaN"]}]}}`,
+	}}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	return stub, srv.URL
+}
+
+func runCheck(t *testing.T, cfg PrometheusSystemConfig, checkType string, params map[string]interface{}) (common.ExecutionResult, checkOutput) {
+	t.Helper()
+	raw, _ := json.Marshal(cfg)
+	checker := &PrometheusChecker{now: func() time.Time { return now }}
+	var out checkOutput
+	res := plugintest.Run(t, checker, common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: raw},
+		StdContext:      context.Background(),
+	}, checkType, &out)
+	return res, out
+}
+
+func TestInstantQueryAssertion(t *testing.T) {
+	stub, baseURL := startPrometheus(t)
+	cfg := PrometheusSystemConfig{BaseURL: baseURL, BearerToken: "s3cret", TenantID: "team-a"}
+	params := map[string]interface{}{"query": `avg_over_time(up{job="api"}[30d]) * 100`, "operator": ">=", "threshold": 99.9}
+
+	res, out := runCheck(t, cfg, CheckTypeKey_PrometheusInstant, params)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, "instant", out.QueryType)
+	assert.Equal(t, 2, out.SeriesChecked)
+	assert.Equal(t, 1, out.SeriesFailed)
+	assert.Equal(t, "api-2:9100", out.Series[1].Metric["instance"])
+	assert.Equal(t, 99.42, out.Series[1].Value)
+	assert.False(t, out.Series[1].Passed)
+	assert.Equal(t, "1 of 2 series satisfy value >= 99.9 for all series.", out.Message)
+
+	assert.Equal(t, "Bearer s3cret", stub.last.Header.Get("Authorization"))
+	assert.Equal(t, "team-a", stub.last.Header.Get("X-Scope-OrgID"))
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), stub.last.URL.Query().Get("time"))
+
+	params["seriesMode"] = "any"
+	res, _ = runCheck(t, cfg, CheckTypeKey_PrometheusInstant, params)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+
+	params["seriesMode"] = "all"
+	params["threshold"] = "99"
+	params["evaluationTime"] = "2024-06-01T00:00:00Z"
+	res, _ = runCheck(t, cfg, CheckTypeKey_PrometheusInstant, params)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	assert.Equal(t, "1717200000", stub.last.URL.Query().Get("time"))
+}
+
+func TestRangeQueryReducesSeries(t *testing.T) {
+	stub, baseURL := startPrometheus(t)
+	cfg := PrometheusSystemConfig{BaseURL: baseURL, Username: "grafana", Password: "pw"}
+	params := map[string]interface{}{"query": "increase(backup_success_total[24h])", "window": "24h", "reduce": "sum", "operator": ">", "threshold": float64(0)}
+
+	res, out := runCheck(t, cfg, CheckTypeKey_PrometheusRange, params)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, "matrix", out.ResultType)
+	assert.Equal(t, "4m48s", out.Step, "window / 300 by default")
+	assert.Equal(t, []string{"query touched a partially available store"}, out.Warnings)
+	require.Len(t, out.Series, 2)
+	assert.True(t, out.Series[0].Passed, "pg-backup succeeded once")
+	assert.False(t, out.Series[1].Passed, "redis-backup never succeeded")
+	assert.Equal(t, 3, out.Series[0].SampleCount)
+	assert.Len(t, out.Series[0].Samples, 3)
+
+	q := stub.last.URL.Query()
+	assert.Equal(t, "/api/v1/query_range", stub.last.URL.Path)
+	assert.Equal(t, strconv.FormatInt(now.Add(-24*time.Hour).Unix(), 10), q.Get("start"))
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), q.Get("end"))
+	assert.Equal(t, "288", q.Get("step"))
+	user, pass, ok := stub.last.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "grafana", user)
+	assert.Equal(t, "pw", pass)
+
+	params["step"] = "1h"
+	params["reduce"] = "max"
+	params["seriesMode"] = "any"
+	res, out = runCheck(t, cfg, CheckTypeKey_PrometheusRange, params)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	assert.Equal(t, "1h0m0s", out.Step)
+}
+
+func TestEmptyScalarAndNaNResults(t *testing.T) {
+	_, baseURL := startPrometheus(t)
+	cfg := PrometheusSystemConfig{BaseURL: baseURL}
+
+	res, out := runCheck(t, cfg, CheckTypeKey_PrometheusInstant, map[string]interface{}{"query": "absent_metric", "operator": ">", "threshold": float64(0)})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, "Query returned no series.", out.Message)
+	res, _ = runCheck(t, cfg, CheckTypeKey_PrometheusInstant, map[string]interface{}{"query": "absent_metric", "operator": ">", "threshold": float64(0), "onEmpty": "pass"})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+
+	res, out = runCheck(t, cfg, CheckTypeKey_PrometheusInstant, map[string]interface{}{"query": "scalar(vector(42))", "operator": "==", "threshold": float64(42)})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	assert.Equal(t, "scalar", out.ResultType)
+
+	res, out = runCheck(t, cfg, CheckTypeKey_PrometheusInstant, map[string]interface{}{"query": "errors / requests", "operator": "<", "threshold": float64(1)})
+	assert.Equal(t, common.StatusFailed, res.Status, "NaN never satisfies an assertion")
+	assert.Equal(t, "NaN", out.Series[0].Value)
+}
+
+func TestQueryErrorsAndBadParameters(t *testing.T) {
+	_, baseURL := startPrometheus(t)
+	raw, _ := json.Marshal(PrometheusSystemConfig{BaseURL: baseURL})
+	execute := func(checkType string, params map[string]interface{}) (common.ExecutionResult, error) {
+		return New().ExecuteCheck(common.CheckContext{
+			TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+			ConnectedSystem: &models.ConnectedSystem{Configuration: raw},
+		}, checkType)
+	}
+
+	res, err := execute(CheckTypeKey_PrometheusInstant, map[string]interface{}{"query": "up{", "operator": ">", "threshold": float64(0)})
+	require.Error(t, err)
+	assert.Equal(t, common.StatusError, res.Status)
+	assert.Contains(t, res.Output, "bad_data")
+
+	for _, params := range []map[string]interface{}{
+		{"operator": ">", "threshold": float64(0)},
+		{"query": "up", "operator": "~", "threshold": float64(0)},
+		{"query": "up", "operator": ">"},
+		{"query": "up", "operator": ">", "threshold": float64(0), "window": "forever"},
+		{"query": "up", "operator": ">", "threshold": float64(0), "window": "1d", "reduce": "median"},
+	} {
+		res, err := execute(CheckTypeKey_PrometheusRange, params)
+		assert.Error(t, err, params)
+		assert.Equal(t, common.StatusError, res.Status)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"1w":    7 * 24 * time.Hour,
+		"1h30m": 90 * time.Minute,
+		"500ms": 500 * time.Millisecond,
+	} {
+		got, err := parseDuration(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "30", "d", "1x", "0s", "1h 30m"} {
+		_, err := parseDuration(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestSampleDecodesFractionalTimestamps(t *testing.T) {
+	var s sample
+	require.NoError(t, json.Unmarshal([]byte(`[1435781451.781,"+Inf"]`), &s))
+	assert.Equal(t, time.Date(2015, 7, 1, 20, 10, 51, 781000000, time.UTC), s.Time.Round(time.Millisecond))
+	assert.Equal(t, "+Inf", jsonValue(s.Value))
+}
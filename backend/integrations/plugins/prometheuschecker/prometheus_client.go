@@ -0,0 +1,208 @@
+package prometheuschecker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+)
+
+// promClient talks to the Prometheus HTTP API (/api/v1/query and
+// /api/v1/query_range). Thanos, Mimir, Cortex and VictoriaMetrics serve the
+// same API.
+type promClient struct {
+	baseURL    string
+	cfg        PrometheusSystemConfig
+	httpClient *http.Client
+}
+
+func newPromClient(cfg PrometheusSystemConfig) *promClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure, _ := strconv.ParseBool(cfg.InsecureSkipTLSVerify); insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &promClient{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 2 * time.Minute, Transport: transport},
+	}
+}
+
+// sample is one (timestamp, value) pair. Prometheus encodes values as strings
+// so NaN and ±Inf survive JSON.
+type sample struct {
+	Time  time.Time
+	Value float64
+}
+
+func (s *sample) UnmarshalJSON(b []byte) error {
+	var pair [2]json.RawMessage
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return err
+	}
+	var ts float64
+	if err := json.Unmarshal(pair[0], &ts); err != nil {
+		return fmt.Errorf("sample timestamp: %w", err)
+	}
+	var raw string
+	if err := json.Unmarshal(pair[1], &raw); err != nil {
+		return fmt.Errorf("sample value: %w", err)
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fmt.Errorf("sample value %q: %w", raw, err)
+	}
+	sec := int64(ts)
+	s.Time = time.Unix(sec, int64((ts-float64(sec))*1e9)).UTC()
+	s.Value = v
+	return nil
+}
+
+// series is one element of a vector (Value set) or matrix (Values set) result.
+type series struct {
+	Metric map[string]string `json:"metric"`
+	Value  *sample           `json:"value,omitempty"`
+	Values []sample          `json:"values,omitempty"`
+}
+
+type queryResult struct {
+	ResultType string
+	Series     []series
+	Warnings   []string
+}
+
+type apiResponse struct {
+	Status    string   `json:"status"`
+	ErrorType string   `json:"errorType"`
+	Error     string   `json:"error"`
+	Warnings  []string `json:"warnings"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+func (c *promClient) instantQuery(ctx context.Context, query string, at time.Time) (*queryResult, error) {
+	params := url.Values{"query": {query}}
+	if !at.IsZero() {
+		params.Set("time", formatTime(at))
+	}
+	return c.do(ctx, "/api/v1/query", params)
+}
+
+func (c *promClient) rangeQuery(ctx context.Context, query string, start, end time.Time, step time.Duration) (*queryResult, error) {
+	params := url.Values{
+		"query": {query},
+		"start": {formatTime(start)},
+		"end":   {formatTime(end)},
+		"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+	}
+	return c.do(ctx, "/api/v1/query_range", params)
+}
+
+func (c *promClient) do(ctx context.Context, path string, params url.Values) (*queryResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create prometheus request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.cfg.BearerToken)
+	case c.cfg.Username != "":
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+	if c.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", c.cfg.TenantID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, common.Unreachable(fmt.Errorf("prometheus request %s: %w", path, err))
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read prometheus response: %w", err)
+	}
+
+	// Query errors come back as 400/422 with a JSON error body.
+	var parsed apiResponse
+	if jsonErr := json.Unmarshal(body, &parsed); jsonErr != nil {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("prometheus %s returned %s", path, resp.Status)
+		}
+		return nil, fmt.Errorf("parse prometheus response: %w", jsonErr)
+	}
+	if parsed.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed (%s): %s", parsed.ErrorType, parsed.Error)
+	}
+
+	result := &queryResult{ResultType: parsed.Data.ResultType, Warnings: parsed.Warnings}
+	switch parsed.Data.ResultType {
+	case "vector", "matrix":
+		if err := json.Unmarshal(parsed.Data.Result, &result.Series); err != nil {
+			return nil, fmt.Errorf("parse %s result: %w", parsed.Data.ResultType, err)
+		}
+	case "scalar":
+		var s sample
+		if err := json.Unmarshal(parsed.Data.Result, &s); err != nil {
+			return nil, fmt.Errorf("parse scalar result: %w", err)
+		}
+		result.Series = []series{{Metric: map[string]string{}, Value: &s}}
+	default:
+		return nil, fmt.Errorf("unsupported result type %q; the query must return a number", parsed.Data.ResultType)
+	}
+	return result, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+var durationTerm = regexp.MustCompile(`(\d+)(ms|s|m|h|d|w|y)`)
+
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// parseDuration accepts Prometheus style durations such as "30d", "1w" or "1h30m".
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	terms := durationTerm.FindAllStringSubmatch(s, -1)
+	if len(terms) == 0 || strings.Join(flatten(terms), "") != s {
+		return 0, fmt.Errorf("invalid duration %q; use values like 15m, 24h or 30d", s)
+	}
+	var d time.Duration
+	for _, term := range terms {
+		n, _ := strconv.Atoi(term[1])
+		d += time.Duration(n) * durationUnits[term[2]]
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", s)
+	}
+	return d, nil
+}
+
+func flatten(terms [][]string) []string {
+	out := make([]string, len(terms))
+	for i, term := range terms {
+		out[i] = term[0]
+	}
+	return out
+}
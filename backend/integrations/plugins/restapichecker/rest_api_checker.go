@@ -0,0 +1,479 @@
+package restapichecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/jmespath/go-jmespath"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	PluginID_RESTAPIChecker             = "rest_api_checker_v1"
+	PluginName_RESTAPIChecker           = "Generic REST API Checker"
+	PluginVersion_RESTAPIChecker        = "1.0.0"
+	CheckTypeKey_RESTItemAssertion      = "rest_api_item_assertion"
+	CheckTypeKey_RESTAggregateAssertion = "rest_api_aggregate_assertion"
+
+	defaultTimeout  = 5 * time.Minute
+	defaultMaxPages = 50
+	// maxReportedFindings caps the failing items attached to the output.
+	maxReportedFindings = 500
+)
+
+var (
+	paginationTypes = []string{"none", "link_header", "cursor", "page", "offset"}
+	// idFields are tried in order to name an item when no itemIdExpression is set.
+	idFields = []string{"id", "key", "name", "email", "login", "username"}
+)
+
+// GenericAPISystemConfig matches the 'generic_api' system type configuration.
+// Configurations saved before authType existed are treated as API key auth
+// when an apiKey is present.
+type GenericAPISystemConfig struct {
+	BaseURL          string `json:"baseUrl"`
+	AuthType         string `json:"authType"`
+	APIKey           string `json:"apiKey"`
+	AuthHeader       string `json:"authHeader"`
+	AuthValuePrefix  string `json:"authValuePrefix"`
+	APIKeyQueryParam string `json:"apiKeyQueryParam"`
+	BearerToken      string `json:"bearerToken"`
+	Username         string `json:"username"`
+	Password         string `json:"password"`
+	TokenURL         string `json:"tokenUrl"`
+	ClientID         string `json:"clientId"`
+	ClientSecret     string `json:"clientSecret"`
+	Scopes           string `json:"scopes"`
+}
+
+func (c GenericAPISystemConfig) authType() string {
+	if c.AuthType != "" {
+		return c.AuthType
+	}
+	if c.APIKey != "" {
+		return AuthTypeAPIKey
+	}
+	return AuthTypeNone
+}
+
+// extractor evaluates a JSONPath or JMESPath expression against decoded JSON.
+type extractor func(doc interface{}) (interface{}, error)
+
+// compileExpression treats expressions starting with "$" as JSONPath and
+// everything else as JMESPath. An empty expression returns the document.
+func compileExpression(expr string) (extractor, error) {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case expr == "" || expr == "@":
+		return func(doc interface{}) (interface{}, error) { return doc, nil }, nil
+	case strings.HasPrefix(expr, "$"):
+		eval, err := jsonpath.New(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+		}
+		return func(doc interface{}) (interface{}, error) {
+			return eval(context.Background(), doc)
+		}, nil
+	default:
+		compiled, err := jmespath.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JMESPath %q: %w", expr, err)
+		}
+		return compiled.Search, nil
+	}
+}
+
+// truthy follows JMESPath truthiness: false, null, "" and empty lists or
+// objects are false.
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case []interface{}:
+		return len(t) > 0
+	case map[string]interface{}:
+		return len(t) > 0
+	}
+	return true
+}
+
+type RESTAPIChecker struct{}
+
+func New() *RESTAPIChecker {
+	return &RESTAPIChecker{}
+}
+
+func (r *RESTAPIChecker) ID() string {
+	return PluginID_RESTAPIChecker
+}
+
+func (r *RESTAPIChecker) Name() string {
+	return PluginName_RESTAPIChecker
+}
+
+func (r *RESTAPIChecker) Version() string {
+	return PluginVersion_RESTAPIChecker
+}
+
+func (r *RESTAPIChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	requestParams := []models.ParameterDefinition{
+		{Name: "path", Label: "Request Path", Type: "text", Required: true, Placeholder: "/users?status=active", HelpText: "Path appended to the system's base URL."},
+		{Name: "method", Label: "HTTP Method", Type: "select", Options: []string{http.MethodGet, http.MethodPost}, HelpText: "Defaults to GET. Use POST for search endpoints."},
+		{Name: "requestBody", Label: "Request Body (Optional)", Type: "textarea", Placeholder: `{"filter": {"active": true}}`, HelpText: "JSON body sent with POST requests."},
+		{Name: "itemsExpression", Label: "Items Expression", Type: "text", Placeholder: "$.data[*]", HelpText: "Selects the list of items from each response page. Expressions starting with $ are JSONPath, others JMESPath (e.g. data.users). Defaults to the whole response."},
+		{Name: "paginationType", Label: "Pagination", Type: "select", Options: paginationTypes, HelpText: "How further pages are found: the Link header, a cursor in the body, or page/offset query parameters. Defaults to none."},
+		{Name: "cursorExpression", Label: "Cursor Expression", Type: "text", Placeholder: "meta.next_cursor", HelpText: "For cursor pagination: where the next cursor (or next page URL) is in the response. Empty or null ends pagination."},
+		{Name: "cursorParam", Label: "Cursor Query Parameter", Type: "text", Placeholder: "cursor", HelpText: "For cursor pagination: query parameter that carries the cursor. Leave empty when the cursor is a full next page URL."},
+		{Name: "pageParam", Label: "Page/Offset Query Parameter", Type: "text", Placeholder: "page", HelpText: "For page pagination defaults to page (starting at 1); for offset pagination defaults to offset (starting at 0)."},
+		{Name: "pageSizeParam", Label: "Page Size Query Parameter", Type: "text", Placeholder: "per_page"},
+		{Name: "pageSize", Label: "Page Size", Type: "number", Placeholder: "100", HelpText: "Sent as the page size parameter. A page with fewer items ends page and offset pagination."},
+		{Name: "maxPages", Label: "Max Pages", Type: "number", Placeholder: "50", HelpText: "The check errors instead of evaluating a partial list when more pages remain."},
+	}
+
+	return map[string]models.CheckTypeConfiguration{
+		CheckTypeKey_RESTItemAssertion: {
+			Label:       "REST API: Every Item Matches",
+			TargetType:  "connected_system",
+			TargetLabel: "API Endpoint",
+			Parameters: append(requestParams,
+				models.ParameterDefinition{Name: "itemAssertion", Label: "Item Assertion (JMESPath)", Type: "text", Required: true, Placeholder: "mfa_enabled == `true`", HelpText: "Evaluated against each item; the item fails when the result is false, null or empty."},
+				models.ParameterDefinition{Name: "itemFilter", Label: "Item Filter (JMESPath, Optional)", Type: "text", Placeholder: "status == 'active'", HelpText: "Only items for which this is true are checked."},
+				models.ParameterDefinition{Name: "itemIdExpression", Label: "Item ID Expression (Optional)", Type: "text", Placeholder: "email", HelpText: "Names each failing item. Defaults to the first of id, key, name, email, login or username."},
+				models.ParameterDefinition{Name: "maxFailingItems", Label: "Max Failing Items", Type: "number", Placeholder: "0", HelpText: "The check passes while at most this many items fail. Defaults to 0."},
+				models.ParameterDefinition{Name: "onEmpty", Label: "When No Items", Type: "select", Options: []string{"fail", "pass"}, HelpText: "Outcome when no items are checked. Defaults to fail."},
+			),
+		},
+		CheckTypeKey_RESTAggregateAssertion: {
+			Label:       "REST API: Aggregate Assertion",
+			TargetType:  "connected_system",
+			TargetLabel: "API Endpoint",
+			Parameters: append(requestParams,
+				models.ParameterDefinition{Name: "assertion", Label: "Assertion (JMESPath)", Type: "text", Required: true, Placeholder: "length(items[?admin]) <= `5`", HelpText: "Evaluated against {\"items\": [...]} holding the items of every page."},
+				models.ParameterDefinition{Name: "reportItemsExpression", Label: "Report Items (JMESPath, Optional)", Type: "text", Placeholder: "items[?admin]", HelpText: "Items listed individually in the result, e.g. the ones the assertion counts."},
+				models.ParameterDefinition{Name: "itemIdExpression", Label: "Item ID Expression (Optional)", Type: "text", Placeholder: "email"},
+			),
+		},
+	}
+}
+
+func (r *RESTAPIChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
+	if checkTypeKey != CheckTypeKey_RESTItemAssertion && checkTypeKey != CheckTypeKey_RESTAggregateAssertion {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+	var sysConfig GenericAPISystemConfig
+	if err := json.Unmarshal(ctx.ConnectedSystem.Configuration, &sysConfig); err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Failed to parse API system configuration"}, fmt.Errorf("unmarshal generic api config: %w", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx.Context(), defaultTimeout)
+	defer cancel()
+
+	client, err := newAPIClient(runCtx, sysConfig)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	params := ctx.TaskInstance.Parameters
+	req, err := requestFromParams(params)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+	idOf, err := itemNamer(common.StringParam(params, "itemIdExpression"))
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	var evaluate func(items []interface{}) (bool, map[string]interface{}, error)
+	switch checkTypeKey {
+	case CheckTypeKey_RESTItemAssertion:
+		evaluate, err = itemAssertion(params, idOf)
+	case CheckTypeKey_RESTAggregateAssertion:
+		evaluate, err = aggregateAssertion(params, idOf)
+	}
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	items, pages, err := client.collect(runCtx, req.method, req.path, req.body, req.items, req.pagination)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+	passed, output, err := evaluate(items)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+	output["request"] = map[string]interface{}{"method": req.method, "path": req.path, "pagination": req.pagination.Type}
+	output["pages_fetched"] = pages
+	output["items_total"] = len(items)
+
+	status := common.StatusFailed
+	if passed {
+		status = common.StatusSuccess
+	}
+	jsonOut, err := json.Marshal(output)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, fmt.Errorf("marshal rest api output: %w", err)
+	}
+	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
+}
+
+type requestSpec struct {
+	method     string
+	path       string
+	body       []byte
+	items      extractor
+	pagination pagination
+}
+
+func requestFromParams(params map[string]interface{}) (requestSpec, error) {
+	req := requestSpec{path: common.StringParam(params, "path"), method: strings.ToUpper(common.StringParam(params, "method"))}
+	if req.path == "" {
+		return req, fmt.Errorf("path parameter is required")
+	}
+	if req.method == "" {
+		req.method = http.MethodGet
+	}
+	if req.method != http.MethodGet && req.method != http.MethodPost {
+		return req, fmt.Errorf("method must be GET or POST")
+	}
+	if body := common.StringParam(params, "requestBody"); body != "" {
+		if !json.Valid([]byte(body)) {
+			return req, fmt.Errorf("requestBody must be valid JSON")
+		}
+		req.body = []byte(body)
+	}
+	var err error
+	if req.items, err = compileExpression(common.StringParam(params, "itemsExpression")); err != nil {
+		return req, err
+	}
+
+	p := pagination{
+		Type:          common.StringParam(params, "paginationType"),
+		CursorParam:   common.StringParam(params, "cursorParam"),
+		PageSizeParam: common.StringParam(params, "pageSizeParam"),
+		PageSize:      common.IntParam(params, "pageSize", 0),
+		MaxPages:      common.IntParam(params, "maxPages", defaultMaxPages),
+	}
+	if p.Type == "" {
+		p.Type = "none"
+	}
+	if !contains(paginationTypes, p.Type) {
+		return req, fmt.Errorf("paginationType must be one of %s", strings.Join(paginationTypes, ", "))
+	}
+	if p.MaxPages < 1 {
+		return req, fmt.Errorf("maxPages must be at least 1")
+	}
+	switch p.Type {
+	case "cursor":
+		expr := common.StringParam(params, "cursorExpression")
+		if expr == "" {
+			return req, fmt.Errorf("cursorExpression is required for cursor pagination")
+		}
+		if p.Cursor, err = compileExpression(expr); err != nil {
+			return req, err
+		}
+	case "page":
+		p.PageParam = common.StringParam(params, "pageParam")
+		if p.PageParam == "" {
+			p.PageParam = "page"
+		}
+		p.StartPage = 1
+	case "offset":
+		p.OffsetParam = common.StringParam(params, "pageParam")
+		if p.OffsetParam == "" {
+			p.OffsetParam = "offset"
+		}
+	}
+	req.pagination = p
+	return req, nil
+}
+
+// itemNamer returns a function naming an item for findings.
+func itemNamer(expr string) (func(item interface{}, index int) string, error) {
+	var id extractor
+	if expr != "" {
+		var err error
+		if id, err = compileExpression(expr); err != nil {
+			return nil, fmt.Errorf("itemIdExpression: %w", err)
+		}
+	}
+	return func(item interface{}, index int) string {
+		if id != nil {
+			if v, err := id(item); err == nil && v != nil {
+				return scalarString(v)
+			}
+		} else if obj, ok := item.(map[string]interface{}); ok {
+			for _, field := range idFields {
+				if v, ok := obj[field]; ok && v != nil {
+					return scalarString(v)
+				}
+			}
+		}
+		return "item[" + strconv.Itoa(index) + "]"
+	}, nil
+}
+
+// itemAssertion checks every (filtered) item and reports each failing one.
+func itemAssertion(params map[string]interface{}, idOf func(interface{}, int) string) (func([]interface{}) (bool, map[string]interface{}, error), error) {
+	expr := common.StringParam(params, "itemAssertion")
+	if expr == "" {
+		return nil, fmt.Errorf("itemAssertion parameter is required")
+	}
+	assertFn, err := compileExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	filterExpr := common.StringParam(params, "itemFilter")
+	var filter extractor
+	if filterExpr != "" {
+		if filter, err = compileExpression(filterExpr); err != nil {
+			return nil, err
+		}
+	}
+	maxFailing := common.IntParam(params, "maxFailingItems", 0)
+	onEmpty := common.StringParam(params, "onEmpty")
+	if onEmpty == "" {
+		onEmpty = "fail"
+	}
+	if onEmpty != "fail" && onEmpty != "pass" {
+		return nil, fmt.Errorf("onEmpty must be fail or pass")
+	}
+
+	return func(items []interface{}) (bool, map[string]interface{}, error) {
+		findings := []common.Finding{}
+		checked, failed := 0, 0
+		for i, item := range items {
+			if filter != nil {
+				v, err := filter(item)
+				if err != nil {
+					return false, nil, fmt.Errorf("itemFilter on %s: %w", idOf(item, i), err)
+				}
+				if !truthy(v) {
+					continue
+				}
+			}
+			checked++
+			v, err := assertFn(item)
+			if err != nil {
+				return false, nil, fmt.Errorf("itemAssertion on %s: %w", idOf(item, i), err)
+			}
+			if truthy(v) {
+				continue
+			}
+			failed++
+			if len(findings) < maxReportedFindings {
+				findings = append(findings, common.Finding{
+					ResourceType: "item",
+					Resource:     idOf(item, i),
+					Issues:       []string{fmt.Sprintf("assertion %s is not true", expr)},
+					Details:      map[string]interface{}{"item": item, "result": v},
+				})
+			}
+		}
+
+		var passed bool
+		var message string
+		if checked == 0 {
+			passed = onEmpty == "pass"
+			message = "No items to check."
+		} else {
+			passed = failed <= maxFailing
+			message = fmt.Sprintf("%d of %d items fail %s", failed, checked, expr)
+			if maxFailing > 0 {
+				message += fmt.Sprintf(" (at most %d allowed)", maxFailing)
+			}
+			message += "."
+		}
+		output := map[string]interface{}{
+			"message":       message,
+			"assertion":     expr,
+			"items_checked": checked,
+			"items_failed":  failed,
+			"findings":      findings,
+		}
+		if filterExpr != "" {
+			output["filter"] = filterExpr
+		}
+		if failed > len(findings) {
+			output["findings_truncated"] = true
+		}
+		return passed, output, nil
+	}, nil
+}
+
+// aggregateAssertion evaluates one expression over all items, e.g.
+// "length(items[?admin]) <= `5`".
+func aggregateAssertion(params map[string]interface{}, idOf func(interface{}, int) string) (func([]interface{}) (bool, map[string]interface{}, error), error) {
+	expr := common.StringParam(params, "assertion")
+	if expr == "" {
+		return nil, fmt.Errorf("assertion parameter is required")
+	}
+	assertFn, err := compileExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	var report extractor
+	if reportExpr := common.StringParam(params, "reportItemsExpression"); reportExpr != "" {
+		if report, err = compileExpression(reportExpr); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(items []interface{}) (bool, map[string]interface{}, error) {
+		if items == nil {
+			items = []interface{}{}
+		}
+		doc := map[string]interface{}{"items": items}
+		v, err := assertFn(doc)
+		if err != nil {
+			return false, nil, fmt.Errorf("assertion: %w", err)
+		}
+		passed := truthy(v)
+		output := map[string]interface{}{
+			"assertion":        expr,
+			"assertion_result": v,
+			"message":          fmt.Sprintf("%s is %t over %d items.", expr, passed, len(items)),
+		}
+		if report != nil {
+			reported, err := extractItems(report, doc)
+			if err != nil {
+				return false, nil, fmt.Errorf("reportItemsExpression: %w", err)
+			}
+			findings := []common.Finding{}
+			for i, item := range reported {
+				if i == maxReportedFindings {
+					output["findings_truncated"] = true
+					break
+				}
+				findings = append(findings, common.Finding{ResourceType: "item", Resource: idOf(item, i), Passed: passed, Details: map[string]interface{}{"item": item}})
+			}
+			output["items_reported"] = len(reported)
+			output["findings"] = findings
+		}
+		return passed, output, nil
+	}, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+var _ integrations.IntegrationPlugin = (*RESTAPIChecker)(nil)
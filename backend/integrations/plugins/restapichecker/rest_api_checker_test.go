@@ -0,0 +1,307 @@
+package restapichecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+type checkOutput struct {
+	Message           string           `json:"message"`
+	PagesFetched      int              `json:"pages_fetched"`
+	ItemsTotal        int              `json:"items_total"`
+	ItemsChecked      int              `json:"items_checked"`
+	ItemsFailed       int              `json:"items_failed"`
+	ItemsReported     int              `json:"items_reported"`
+	AssertionResult   interface{}      `json:"assertion_result"`
+	Findings          []common.Finding `json:"findings"`
+	FindingsTruncated bool             `json:"findings_truncated"`
+}
+
+// users is the fixture served by every pagination style, pageSize per page.
+var users = []map[string]interface{}{
+	{"id": 1, "email": "ana@example.com", "mfa_enabled": true, "admin": true, "status": "active"},
+	{"id": 2, "email": "bo@example.com", "mfa_enabled": false, "admin": false, "status": "active"},
+	{"id": 3, "email": "cy@example.com", "mfa_enabled": true, "admin": true, "status": "active"},
+	{"id": 4, "email": "di@example.com", "mfa_enabled": false, "admin": false, "status": "suspended"},
+	{"id": 5, "email": "ed@example.com", "mfa_enabled": true, "admin": false, "status": "active"},
+	{"id": 6, "email": "fa@example.com", "mfa_enabled": true, "admin": true, "status": "active"},
+	{"id": 7, "email": "gu@example.com", "mfa_enabled": false, "admin": true, "status": "active"},
+}
+
+const pageSize = 3
+
+// apiStub serves users under several pagination styles and records the
+// requests it saw.
+type apiStub struct {
+	srv      *httptest.Server
+	requests []*http.Request
+	tokens   int
+}
+
+func startAPI(t *testing.T) *apiStub {
+	t.Helper()
+	stub := &apiStub{}
+	page := func(start int) ([]map[string]interface{}, int) {
+		end := start + pageSize
+		if end > len(users) {
+			end = len(users)
+		}
+		if start >= len(users) {
+			return []map[string]interface{}{}, -1
+		}
+		if end == len(users) {
+			return users[start:end], -1
+		}
+		return users[start:end], end
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		stub.tokens++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"issued-%s","token_type":"Bearer","expires_in":3600}`, r.FormValue("scope"))
+	})
+	mux.HandleFunc("/v1/", func(w http.ResponseWriter, r *http.Request) {
+		stub.requests = append(stub.requests, r)
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/users":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": users})
+		case "/v1/link":
+			start, _ := strconv.Atoi(q.Get("start"))
+			items, next := page(start)
+			if next >= 0 {
+				w.Header().Set("Link", fmt.Sprintf(`<%s/v1/link?start=%d>; rel="next", <%s/v1/link?start=0>; rel="first"`, stub.srv.URL, next, stub.srv.URL))
+			}
+			json.NewEncoder(w).Encode(items)
+		case "/v1/cursor":
+			start, _ := strconv.Atoi(q.Get("cursor"))
+			items, next := page(start)
+			meta := map[string]interface{}{"next_cursor": nil}
+			if next >= 0 {
+				meta["next_cursor"] = strconv.Itoa(next)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"users": items, "meta": meta})
+		case "/v1/next-url":
+			start, _ := strconv.Atoi(q.Get("after"))
+			items, next := page(start)
+			body := map[string]interface{}{"results": items}
+			if next >= 0 {
+				body["next"] = fmt.Sprintf("/v1/next-url?after=%d", next)
+			}
+			json.NewEncoder(w).Encode(body)
+		case "/v1/paged":
+			n, _ := strconv.Atoi(q.Get("page"))
+			items, _ := page((n - 1) * pageSize)
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+		case "/v1/offset":
+			start, _ := strconv.Atoi(q.Get("offset"))
+			items, _ := page(start)
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+		case "/v1/endless":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/v1/endless?n=%d>; rel="next"`, stub.srv.URL, len(stub.requests)))
+			json.NewEncoder(w).Encode(users[:1])
+		case "/v1/elsewhere":
+			w.Header().Set("Link", `<https://attacker.example/steal>; rel="next"`)
+			json.NewEncoder(w).Encode(users[:1])
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	stub.srv = httptest.NewServer(mux)
+	t.Cleanup(stub.srv.Close)
+	return stub
+}
+
+func (s *apiStub) last() *http.Request {
+	return s.requests[len(s.requests)-1]
+}
+
+func execute(cfg GenericAPISystemConfig, checkType string, params map[string]interface{}) (common.ExecutionResult, error) {
+	raw, _ := json.Marshal(cfg)
+	return New().ExecuteCheck(common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: raw},
+		StdContext:      context.Background(),
+	}, checkType)
+}
+
+func runCheck(t *testing.T, cfg GenericAPISystemConfig, checkType string, params map[string]interface{}) (common.ExecutionResult, checkOutput) {
+	t.Helper()
+	res, err := execute(cfg, checkType, params)
+	require.NoError(t, err, res.Output)
+	var out checkOutput
+	plugintest.Decode(t, res, &out)
+	return res, out
+}
+
+func TestItemAssertionReportsFailingItems(t *testing.T) {
+	stub := startAPI(t)
+	cfg := GenericAPISystemConfig{BaseURL: stub.srv.URL + "/v1"}
+	params := map[string]interface{}{"path": "/users", "itemsExpression": "$.data[*]", "itemAssertion": "mfa_enabled == `true`"}
+
+	res, out := runCheck(t, cfg, CheckTypeKey_RESTItemAssertion, params)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 7, out.ItemsTotal)
+	assert.Equal(t, 3, out.ItemsFailed)
+	require.Len(t, out.Findings, 3)
+	assert.Equal(t, "2", out.Findings[0].Resource, "id is used when no itemIdExpression is set")
+	assert.Equal(t, "item", out.Findings[0].ResourceType)
+	assert.Equal(t, "3 of 7 items fail mfa_enabled == `true`.", out.Message)
+
+	params["itemFilter"] = "status == 'active'"
+	params["itemIdExpression"] = "email"
+	params["itemsExpression"] = "data"
+	res, out = runCheck(t, cfg, CheckTypeKey_RESTItemAssertion, params)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 6, out.ItemsChecked)
+	assert.Equal(t, []string{"bo@example.com", "gu@example.com"}, []string{out.Findings[0].Resource, out.Findings[1].Resource})
+
+	params["maxFailingItems"] = float64(2)
+	res, _ = runCheck(t, cfg, CheckTypeKey_RESTItemAssertion, params)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+
+	params["itemFilter"] = "status == 'deleted'"
+	res, out = runCheck(t, cfg, CheckTypeKey_RESTItemAssertion, params)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, "No items to check.", out.Message)
+	params["onEmpty"] = "pass"
+	res, _ = runCheck(t, cfg, CheckTypeKey_RESTItemAssertion, params)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+}
+
+func TestAggregateAssertion(t *testing.T) {
+	stub := startAPI(t)
+	cfg := GenericAPISystemConfig{BaseURL: stub.srv.URL + "/v1"}
+	params := map[string]interface{}{
+		"path":                  "/users",
+		"itemsExpression":       "data",
+		"assertion":             "length(items[?admin]) <= `3`",
+		"reportItemsExpression": "items[?admin]",
+		"itemIdExpression":      "email",
+	}
+
+	res, out := runCheck(t, cfg, CheckTypeKey_RESTAggregateAssertion, params)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, false, out.AssertionResult)
+	assert.Equal(t, 4, out.ItemsReported)
+	require.Len(t, out.Findings, 4)
+	assert.Equal(t, "ana@example.com", out.Findings[0].Resource)
+	assert.False(t, out.Findings[0].Passed)
+
+	params["assertion"] = "length(items[?admin]) <= `5`"
+	res, _ = runCheck(t, cfg, CheckTypeKey_RESTAggregateAssertion, params)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+}
+
+func TestPagination(t *testing.T) {
+	stub := startAPI(t)
+	cfg := GenericAPISystemConfig{BaseURL: stub.srv.URL + "/v1"}
+	// Offset runs last so the final request can be inspected below.
+	for _, tc := range []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"link header", map[string]interface{}{"path": "/link", "paginationType": "link_header"}},
+		{"cursor param", map[string]interface{}{"path": "/cursor", "itemsExpression": "users", "paginationType": "cursor", "cursorExpression": "meta.next_cursor", "cursorParam": "cursor"}},
+		{"cursor url", map[string]interface{}{"path": "/next-url", "itemsExpression": "$.results[*]", "paginationType": "cursor", "cursorExpression": "next"}},
+		{"page", map[string]interface{}{"path": "/paged", "itemsExpression": "items", "paginationType": "page", "pageSizeParam": "per_page", "pageSize": float64(pageSize)}},
+		{"offset", map[string]interface{}{"path": "/offset", "itemsExpression": "items", "paginationType": "offset", "pageSizeParam": "limit", "pageSize": "3"}},
+	} {
+		tc.params["assertion"] = "length(items) == `7`"
+		res, out := runCheck(t, cfg, CheckTypeKey_RESTAggregateAssertion, tc.params)
+		assert.Equal(t, common.StatusSuccess, res.Status, tc.name)
+		assert.Equal(t, 7, out.ItemsTotal, tc.name)
+		assert.Equal(t, 3, out.PagesFetched, tc.name)
+	}
+	assert.Equal(t, "3", stub.last().URL.Query().Get("limit"))
+	assert.Equal(t, "6", stub.last().URL.Query().Get("offset"))
+
+	_, err := execute(cfg, CheckTypeKey_RESTAggregateAssertion, map[string]interface{}{"path": "/endless", "paginationType": "link_header", "maxPages": float64(4), "assertion": "items"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stopped after 4 pages")
+
+	_, err = execute(cfg, CheckTypeKey_RESTAggregateAssertion, map[string]interface{}{"path": "/elsewhere", "paginationType": "link_header", "assertion": "items"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refusing to follow")
+}
+
+func TestAuthentication(t *testing.T) {
+	stub := startAPI(t)
+	base := stub.srv.URL + "/v1"
+	params := map[string]interface{}{"path": "users", "itemsExpression": "data", "assertion": "items"}
+
+	runCheck(t, GenericAPISystemConfig{BaseURL: base, APIKey: "k1"}, CheckTypeKey_RESTAggregateAssertion, params)
+	assert.Equal(t, "k1", stub.last().Header.Get("Authorization"), "configurations without authType keep sending apiKey")
+
+	runCheck(t, GenericAPISystemConfig{BaseURL: base, AuthType: AuthTypeAPIKey, APIKey: "k2", AuthHeader: "X-Api-Token", AuthValuePrefix: "Token "}, CheckTypeKey_RESTAggregateAssertion, params)
+	assert.Equal(t, "Token k2", stub.last().Header.Get("X-Api-Token"))
+
+	runCheck(t, GenericAPISystemConfig{BaseURL: base, AuthType: AuthTypeAPIKey, APIKey: "k3", APIKeyQueryParam: "api_key"}, CheckTypeKey_RESTAggregateAssertion, params)
+	assert.Equal(t, "k3", stub.last().URL.Query().Get("api_key"))
+	assert.Empty(t, stub.last().Header.Get("Authorization"))
+
+	runCheck(t, GenericAPISystemConfig{BaseURL: base, AuthType: AuthTypeBearer, BearerToken: "t0k"}, CheckTypeKey_RESTAggregateAssertion, params)
+	assert.Equal(t, "Bearer t0k", stub.last().Header.Get("Authorization"))
+
+	runCheck(t, GenericAPISystemConfig{BaseURL: base, AuthType: AuthTypeBasic, Username: "audit", Password: "pw"}, CheckTypeKey_RESTAggregateAssertion, params)
+	user, pass, ok := stub.last().BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "audit:pw", user+":"+pass)
+
+	oauth := GenericAPISystemConfig{BaseURL: base, AuthType: AuthTypeOAuth2Client, TokenURL: stub.srv.URL + "/oauth/token", ClientID: "client", ClientSecret: "secret", Scopes: "users.read"}
+	runCheck(t, oauth, CheckTypeKey_RESTAggregateAssertion, map[string]interface{}{"path": "/link", "paginationType": "link_header", "assertion": "items"})
+	assert.Equal(t, "Bearer issued-users.read", stub.last().Header.Get("Authorization"))
+	assert.Equal(t, 1, stub.tokens, "the token is reused across pages")
+
+	oauth.ClientSecret = "wrong"
+	res, err := execute(oauth, CheckTypeKey_RESTAggregateAssertion, params)
+	assert.Error(t, err)
+	assert.Equal(t, common.StatusError, res.Status)
+}
+
+func TestBadParameters(t *testing.T) {
+	stub := startAPI(t)
+	cfg := GenericAPISystemConfig{BaseURL: stub.srv.URL + "/v1"}
+	for _, params := range []map[string]interface{}{
+		{"itemAssertion": "mfa_enabled"},
+		{"path": "/users"},
+		{"path": "/users", "itemAssertion": "mfa_enabled ==="},
+		{"path": "/users", "itemAssertion": "mfa_enabled", "itemsExpression": "$.data[?("},
+		{"path": "/users", "itemAssertion": "mfa_enabled", "method": "DELETE"},
+		{"path": "/users", "itemAssertion": "mfa_enabled", "method": "POST", "requestBody": "{not json"},
+		{"path": "/users", "itemAssertion": "mfa_enabled", "paginationType": "cursor"},
+		{"path": "/users", "itemAssertion": "mfa_enabled", "paginationType": "scroll"},
+		{"path": "/missing", "itemAssertion": "mfa_enabled"},
+	} {
+		res, err := execute(cfg, CheckTypeKey_RESTItemAssertion, params)
+		assert.Error(t, err, params)
+		assert.Equal(t, common.StatusError, res.Status)
+	}
+
+	for _, bad := range []GenericAPISystemConfig{
+		{BaseURL: "api.example.com"},
+		{BaseURL: stub.srv.URL, AuthType: "kerberos"},
+		{BaseURL: stub.srv.URL, AuthType: AuthTypeOAuth2Client, ClientID: "client"},
+	} {
+		_, err := execute(bad, CheckTypeKey_RESTItemAssertion, map[string]interface{}{"path": "/v1/users", "itemAssertion": "mfa_enabled"})
+		assert.Error(t, err, bad)
+	}
+}
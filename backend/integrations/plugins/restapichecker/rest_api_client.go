@@ -0,0 +1,298 @@
+package restapichecker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	AuthTypeNone         = "none"
+	AuthTypeAPIKey       = "apikey"
+	AuthTypeBearer       = "bearer"
+	AuthTypeBasic        = "basic"
+	AuthTypeOAuth2Client = "oauth2_client_credentials"
+
+	defaultAPIKeyHeader = "Authorization"
+	maxResponseBytes    = 32 << 20
+)
+
+// linkNextPattern extracts the rel="next" URL from an RFC 8288 Link header.
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;[^,]*rel="?next"?`)
+
+// apiClient sends authenticated JSON requests to one base URL. Pagination
+// links are only followed when they stay on the same scheme and host, so
+// credentials are never sent elsewhere.
+type apiClient struct {
+	baseURL    *url.URL
+	cfg        GenericAPISystemConfig
+	httpClient *http.Client
+}
+
+func newAPIClient(ctx context.Context, cfg GenericAPISystemConfig) (*apiClient, error) {
+	base, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("baseUrl %q is not an absolute URL", cfg.BaseURL)
+	}
+	httpClient := &http.Client{Timeout: time.Minute}
+
+	switch cfg.authType() {
+	case AuthTypeNone, AuthTypeBearer, AuthTypeBasic:
+	case AuthTypeAPIKey:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("apiKey is required for API key authentication")
+		}
+	case AuthTypeOAuth2Client:
+		if cfg.TokenURL == "" || cfg.ClientID == "" || cfg.ClientSecret == "" {
+			return nil, fmt.Errorf("tokenUrl, clientId and clientSecret are required for OAuth2 client credentials")
+		}
+		cc := clientcredentials.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			TokenURL:     cfg.TokenURL,
+			Scopes:       common.SplitList(strings.ReplaceAll(cfg.Scopes, " ", ",")),
+		}
+		// The token source keeps using this context for refreshes.
+		httpClient = cc.Client(context.WithValue(ctx, oauth2.HTTPClient, httpClient))
+		httpClient.Timeout = time.Minute
+	default:
+		return nil, fmt.Errorf("unsupported authType %q", cfg.AuthType)
+	}
+	return &apiClient{baseURL: base, cfg: cfg, httpClient: httpClient}, nil
+}
+
+// resolve turns a path, relative link or absolute URL into a URL on the base host.
+func (c *apiClient) resolve(target string) (*url.URL, error) {
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") && !strings.HasPrefix(target, "/") {
+		target = "/" + target
+	}
+	var u *url.URL
+	ref, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", target, err)
+	}
+	if ref.IsAbs() {
+		u = ref
+	} else if strings.HasPrefix(ref.Path, c.baseURL.Path+"/") || ref.Path == c.baseURL.Path {
+		// Already includes the base path, as next links usually do.
+		u = c.baseURL.ResolveReference(ref)
+	} else {
+		joined := *c.baseURL
+		joined.Path = c.baseURL.Path + ref.Path
+		joined.RawQuery = ref.RawQuery
+		u = &joined
+	}
+	if u.Scheme != c.baseURL.Scheme || u.Host != c.baseURL.Host {
+		return nil, fmt.Errorf("refusing to follow %s: it is not on %s://%s", u.Redacted(), c.baseURL.Scheme, c.baseURL.Host)
+	}
+	return u, nil
+}
+
+// fetch sends one request and decodes the JSON response.
+func (c *apiClient) fetch(ctx context.Context, method string, u *url.URL, body []byte) (interface{}, http.Header, error) {
+	target := *u
+	if c.cfg.authType() == AuthTypeAPIKey && c.cfg.APIKeyQueryParam != "" {
+		q := target.Query()
+		q.Set(c.cfg.APIKeyQueryParam, c.cfg.APIKey)
+		target.RawQuery = q.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "compliance-automation/1.0")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch c.cfg.authType() {
+	case AuthTypeAPIKey:
+		if c.cfg.APIKeyQueryParam == "" {
+			header := c.cfg.AuthHeader
+			if header == "" {
+				header = defaultAPIKeyHeader
+			}
+			req.Header.Set(header, c.cfg.AuthValuePrefix+c.cfg.APIKey)
+		}
+	case AuthTypeBearer:
+		token := c.cfg.BearerToken
+		if token == "" {
+			token = c.cfg.APIKey
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case AuthTypeBasic:
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, common.Unreachable(fmt.Errorf("%s %s: %w", method, u.Redacted(), err))
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read response from %s: %w", u.Redacted(), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet := strings.TrimSpace(string(raw))
+		if len(snippet) > 300 {
+			snippet = snippet[:300] + "..."
+		}
+		return nil, nil, fmt.Errorf("%s %s returned %s: %s", method, u.Redacted(), resp.Status, snippet)
+	}
+	var decoded interface{}
+	if len(bytes.TrimSpace(raw)) > 0 {
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil, nil, fmt.Errorf("response from %s is not JSON: %w", u.Redacted(), err)
+		}
+	}
+	return decoded, resp.Header, nil
+}
+
+// pagination describes how to walk a list endpoint.
+type pagination struct {
+	Type          string // none, link_header, cursor, page, offset
+	Cursor        extractor
+	CursorParam   string
+	PageParam     string
+	StartPage     int
+	OffsetParam   string
+	PageSizeParam string
+	PageSize      int
+	MaxPages      int
+}
+
+// collect requests path, following pagination, and returns the items every
+// page yields.
+func (c *apiClient) collect(ctx context.Context, method, path string, body []byte, items extractor, p pagination) ([]interface{}, int, error) {
+	first, err := c.resolve(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	setParam := func(u *url.URL, name string, value string) *url.URL {
+		next := *u
+		q := next.Query()
+		q.Set(name, value)
+		next.RawQuery = q.Encode()
+		return &next
+	}
+	if p.PageSizeParam != "" && p.PageSize > 0 {
+		first = setParam(first, p.PageSizeParam, strconv.Itoa(p.PageSize))
+	}
+
+	var all []interface{}
+	seen := map[string]bool{}
+	current := first
+	page, offset := p.StartPage, 0
+	for pages := 0; ; pages++ {
+		if pages == p.MaxPages {
+			return nil, pages, fmt.Errorf("stopped after %d pages without reaching the end of the list; raise maxPages", p.MaxPages)
+		}
+		switch p.Type {
+		case "page":
+			current = setParam(first, p.PageParam, strconv.Itoa(page))
+		case "offset":
+			current = setParam(first, p.OffsetParam, strconv.Itoa(offset))
+		}
+		if seen[current.String()] {
+			return nil, pages, fmt.Errorf("pagination loops back to %s", current.Redacted())
+		}
+		seen[current.String()] = true
+
+		doc, header, err := c.fetch(ctx, method, current, body)
+		if err != nil {
+			return nil, pages, err
+		}
+		pageItems, err := extractItems(items, doc)
+		if err != nil {
+			return nil, pages, err
+		}
+		all = append(all, pageItems...)
+
+		short := len(pageItems) == 0 || (p.PageSize > 0 && len(pageItems) < p.PageSize)
+		switch p.Type {
+		case "link_header":
+			m := linkNextPattern.FindStringSubmatch(header.Get("Link"))
+			if m == nil {
+				return all, pages + 1, nil
+			}
+			if current, err = c.resolve(m[1]); err != nil {
+				return nil, pages, err
+			}
+		case "cursor":
+			v, err := p.Cursor(doc)
+			if err != nil {
+				return nil, pages, fmt.Errorf("cursorExpression: %w", err)
+			}
+			cursor := scalarString(v)
+			if cursor == "" {
+				return all, pages + 1, nil
+			}
+			if p.CursorParam != "" {
+				current = setParam(first, p.CursorParam, cursor)
+			} else if current, err = c.resolve(cursor); err != nil {
+				return nil, pages, err
+			}
+		case "page":
+			if short {
+				return all, pages + 1, nil
+			}
+			page++
+		case "offset":
+			if short {
+				return all, pages + 1, nil
+			}
+			offset += len(pageItems)
+		default:
+			return all, pages + 1, nil
+		}
+	}
+}
+
+// extractItems applies the items expression to a page. A list yields its
+// elements, a single value yields itself and null yields nothing.
+func extractItems(items extractor, doc interface{}) ([]interface{}, error) {
+	v, err := items(doc)
+	if err != nil {
+		return nil, fmt.Errorf("itemsExpression: %w", err)
+	}
+	switch list := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return list, nil
+	default:
+		return []interface{}{list}, nil
+	}
+}
+
+func scalarString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(s)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
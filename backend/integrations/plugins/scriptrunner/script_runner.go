@@ -25,6 +25,10 @@ func (p *Plugin) Name() string {
 	return "Remote Script Execution Integration"
 }
 
+func (p *Plugin) Version() string {
+	return "1.0.0"
+}
+
 func (p *Plugin) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		"script_run_check": {
@@ -0,0 +1,326 @@
+package sshchecker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	PluginID_SSHChecker         = "ssh_checker_v1"
+	PluginName_SSHChecker       = "SSH Server Hardening Checker"
+	PluginVersion_SSHChecker    = "1.0.0"
+	CheckTypeKey_SSHHardening   = "ssh_server_hardening"
+	defaultSSHPort              = 22
+	defaultFailOnSeverity       = SeverityMedium
+	probeUser                   = "compliance-audit"
+	connectTimeout              = 15 * time.Second
+	customDisallowedSeverity    = SeverityHigh
+	passwordAuthSeverity        = SeverityHigh
+	keyboardInteractiveSeverity = SeverityMedium
+)
+
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severityRank = map[string]int{SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3, SeverityCritical: 4}
+
+// weakAlgorithms is the built-in policy: advertised algorithms that are
+// reported, by category, with their severity. Names may be path.Match globs.
+var weakAlgorithms = map[string]map[string]string{
+	"kex": {
+		"diffie-hellman-group1-sha1":         SeverityHigh,
+		"rsa1024-sha1":                       SeverityHigh,
+		"gss-gex-sha1-*":                     SeverityMedium,
+		"gss-group1-sha1-*":                  SeverityHigh,
+		"gss-group14-sha1-*":                 SeverityMedium,
+		"diffie-hellman-group14-sha1":        SeverityMedium,
+		"diffie-hellman-group-exchange-sha1": SeverityMedium,
+		"ecdh-sha2-nistp*":                   SeverityLow,
+	},
+	"cipher": {
+		"none":                        SeverityCritical,
+		"3des-cbc":                    SeverityHigh,
+		"arcfour*":                    SeverityHigh,
+		"blowfish-cbc":                SeverityHigh,
+		"cast128-cbc":                 SeverityHigh,
+		"rijndael-cbc@lysator.liu.se": SeverityHigh,
+		"aes*-cbc":                    SeverityMedium,
+	},
+	"mac": {
+		"none":                      SeverityCritical,
+		"hmac-md5*":                 SeverityHigh,
+		"hmac-sha1-96*":             SeverityMedium,
+		"umac-64*":                  SeverityMedium,
+		"hmac-sha1":                 SeverityLow,
+		"hmac-sha1-etm@openssh.com": SeverityLow,
+		"hmac-ripemd160*":           SeverityLow,
+	},
+	"host_key": {
+		"ssh-dss*":                     SeverityHigh,
+		"ssh-rsa":                      SeverityMedium,
+		"ssh-rsa-cert-v01@openssh.com": SeverityMedium,
+	},
+}
+
+// SSHSystemConfig matches the connected system configuration, as used by the
+// port scanner.
+type SSHSystemConfig struct {
+	HostAddress string      `json:"hostAddress"`
+	Port        json.Number `json:"port"`
+}
+
+// finding is a weak algorithm or authentication method offered by the server.
+type finding struct {
+	Category  string `json:"category"`
+	Algorithm string `json:"algorithm"`
+	Severity  string `json:"severity"`
+	Issue     string `json:"issue"`
+}
+
+type SSHChecker struct{}
+
+func New() *SSHChecker {
+	return &SSHChecker{}
+}
+
+func (p *SSHChecker) ID() string {
+	return PluginID_SSHChecker
+}
+
+func (p *SSHChecker) Name() string {
+	return PluginName_SSHChecker
+}
+
+func (p *SSHChecker) Version() string {
+	return PluginVersion_SSHChecker
+}
+
+func (p *SSHChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	return map[string]models.CheckTypeConfiguration{
+		CheckTypeKey_SSHHardening: {
+			Label: "SSH Server Hardening",
+			Parameters: []models.ParameterDefinition{
+				{Name: "port", Label: "Port", Type: "number", Placeholder: strconv.Itoa(defaultSSHPort), HelpText: "SSH port. Defaults to the system's 'port' setting or 22."},
+				{Name: "failOnSeverity", Label: "Fail On Severity", Type: "select", Options: []string{SeverityMedium, SeverityLow, SeverityHigh, SeverityCritical}, HelpText: "Lowest finding severity that fails the check. Lower findings are still reported."},
+				{Name: "disallowedAlgorithms", Label: "Additional Disallowed Algorithms (Optional)", Type: "text", Placeholder: "ecdh-sha2-nistp256, aes128-ctr", HelpText: "Comma-separated algorithm names or globs to report as high severity, in addition to the built-in weak list."},
+				{Name: "allowedAlgorithms", Label: "Allowed Exceptions (Optional)", Type: "text", Placeholder: "ssh-rsa", HelpText: "Comma-separated algorithm names or globs never reported."},
+				{Name: "checkPasswordAuth", Label: "Check Password Authentication", Type: "select", Options: []string{"true", "false"}, HelpText: "Report password and keyboard-interactive authentication if the server offers them. No credentials are sent."},
+			},
+			TargetType:     "connected_system",
+			TargetLabel:    "SSH Server",
+			TargetHelpText: "Select the Connected System. Its configuration should contain 'hostAddress' (e.g. {\"hostAddress\": \"bastion.example.com\"}).",
+		},
+	}
+}
+
+func (p *SSHChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
+	if checkTypeKey != CheckTypeKey_SSHHardening {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+	var sysConfig SSHSystemConfig
+	if err := json.Unmarshal(ctx.ConnectedSystem.Configuration, &sysConfig); err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Failed to parse SSH system configuration"}, fmt.Errorf("unmarshal ssh config: %w", err)
+	}
+	if sysConfig.HostAddress == "" {
+		return common.ExecutionResult{Status: common.StatusError, Output: "hostAddress is missing from the system configuration"}, fmt.Errorf("hostAddress is required")
+	}
+
+	params := ctx.TaskInstance.Parameters
+	port := common.IntParam(params, "port", 0)
+	if port == 0 {
+		if port, _ = strconv.Atoi(sysConfig.Port.String()); port == 0 {
+			port = defaultSSHPort
+		}
+	}
+	failOn := strings.ToLower(common.StringParam(params, "failOnSeverity"))
+	if failOn == "" {
+		failOn = defaultFailOnSeverity
+	}
+	if _, ok := severityRank[failOn]; !ok {
+		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Invalid failOnSeverity %q", failOn)}, fmt.Errorf("invalid failOnSeverity %q", failOn)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx.Context(), time.Minute)
+	defer cancel()
+
+	addr := net.JoinHostPort(sysConfig.HostAddress, strconv.Itoa(port))
+	kex, err := readKexInit(runCtx, addr, connectTimeout)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Failed to read SSH algorithms from %s: %v", addr, err)}, err
+	}
+
+	findings := evaluateAlgorithms(kex, common.SplitList(common.StringParam(params, "disallowedAlgorithms")), common.SplitList(common.StringParam(params, "allowedAlgorithms")))
+	result := map[string]interface{}{
+		"target":     addr,
+		"advertised": kex,
+	}
+
+	if checkAuth, err := strconv.ParseBool(common.StringParam(params, "checkPasswordAuth")); err != nil || checkAuth {
+		methods, err := probeAuthMethods(runCtx, addr)
+		if err != nil {
+			// Algorithms were read; only the auth probe is inconclusive.
+			result["auth_probe_error"] = err.Error()
+		}
+		if methods["password"] {
+			findings = append(findings, finding{Category: "authentication", Algorithm: "password", Severity: passwordAuthSeverity, Issue: "password authentication is offered"})
+		}
+		if methods["keyboard-interactive"] {
+			findings = append(findings, finding{Category: "authentication", Algorithm: "keyboard-interactive", Severity: keyboardInteractiveSeverity, Issue: "keyboard-interactive authentication is offered and may accept passwords"})
+		}
+		result["auth_methods_offered"] = methods
+	}
+
+	failing := 0
+	for _, f := range findings {
+		if severityRank[f.Severity] >= severityRank[failOn] {
+			failing++
+		}
+	}
+	status := common.StatusSuccess
+	message := fmt.Sprintf("No findings at or above %s severity (%d reported).", failOn, len(findings))
+	if failing > 0 {
+		status = common.StatusFailed
+		message = fmt.Sprintf("%d finding(s) at or above %s severity.", failing, failOn)
+	}
+	result["message"] = message
+	result["fail_on_severity"] = failOn
+	result["findings"] = findings
+	result["findings_failing"] = failing
+
+	jsonOut, _ := json.Marshal(result)
+	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
+}
+
+func evaluateAlgorithms(kex *serverKexInit, disallowed, allowed []string) []finding {
+	categories := []struct {
+		name       string
+		label      string
+		advertised []string
+	}{
+		{"kex", "key exchange", kex.KeyExchanges},
+		{"cipher", "cipher", kex.Ciphers},
+		{"mac", "MAC", kex.MACs},
+		{"host_key", "host key algorithm", kex.HostKeys},
+	}
+
+	findings := []finding{}
+	for _, c := range categories {
+		for _, alg := range c.advertised {
+			if matchAny(allowed, alg) {
+				continue
+			}
+			severity := ""
+			for pattern, s := range weakAlgorithms[c.name] {
+				if ok, _ := path.Match(pattern, alg); ok && severityRank[s] > severityRank[severity] {
+					severity = s
+				}
+			}
+			if matchAny(disallowed, alg) && severityRank[customDisallowedSeverity] > severityRank[severity] {
+				severity = customDisallowedSeverity
+			}
+			if severity == "" {
+				continue
+			}
+			findings = append(findings, finding{
+				Category:  c.name,
+				Algorithm: alg,
+				Severity:  severity,
+				Issue:     fmt.Sprintf("weak %s %s is advertised", c.label, alg),
+			})
+		}
+	}
+	return findings
+}
+
+var errProbeDone = errors.New("auth method probe complete")
+
+// probeAuthMethods reports whether the server offers password and
+// keyboard-interactive authentication. The callbacks only run when the server
+// lists the method after the initial "none" request, and they abort before
+// any credential is sent. Each method needs its own connection because an
+// aborted method ends the authentication attempt.
+func probeAuthMethods(ctx context.Context, addr string) (map[string]bool, error) {
+	offered := map[string]bool{"password": false, "keyboard-interactive": false}
+	probes := map[string]ssh.AuthMethod{
+		"password": ssh.PasswordCallback(func() (string, error) {
+			offered["password"] = true
+			return "", errProbeDone
+		}),
+		"keyboard-interactive": ssh.KeyboardInteractive(func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+			offered["keyboard-interactive"] = true
+			return nil, errProbeDone
+		}),
+	}
+	for _, method := range []string{"password", "keyboard-interactive"} {
+		if err := probeAuth(ctx, addr, probes[method]); err != nil && !offered[method] && !isAuthFailure(err) {
+			return offered, err
+		}
+	}
+	return offered, nil
+}
+
+func probeAuth(ctx context.Context, addr string, method ssh.AuthMethod) error {
+	supported, insecure := ssh.SupportedAlgorithms(), ssh.InsecureAlgorithms()
+	config := &ssh.ClientConfig{
+		User: probeUser,
+		Auth: []ssh.AuthMethod{method},
+		// The probe never authenticates, so the host key is not trusted for anything.
+		HostKeyCallback:   ssh.InsecureIgnoreHostKey(),
+		HostKeyAlgorithms: append(supported.HostKeys, insecure.HostKeys...),
+		Timeout:           connectTimeout,
+		ClientVersion:     clientVersion,
+		Config: ssh.Config{
+			KeyExchanges: append(supported.KeyExchanges, insecure.KeyExchanges...),
+			Ciphers:      append(supported.Ciphers, insecure.Ciphers...),
+			MACs:         append(supported.MACs, insecure.MACs...),
+		},
+	}
+
+	dialer := &net.Dialer{Timeout: connectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return common.Unreachable(fmt.Errorf("connect to %s: %w", addr, err))
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(connectTimeout))
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		return err
+	}
+	// Authentication should never succeed; close the session if it somehow does.
+	ssh.NewClient(c, chans, reqs).Close()
+	return nil
+}
+
+func isAuthFailure(err error) bool {
+	return errors.Is(err, errProbeDone) || strings.Contains(err.Error(), "unable to authenticate")
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+var _ integrations.IntegrationPlugin = (*SSHChecker)(nil)
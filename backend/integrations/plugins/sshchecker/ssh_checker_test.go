@@ -0,0 +1,215 @@
+package sshchecker
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+type checkOutput struct {
+	Advertised         serverKexInit   `json:"advertised"`
+	AuthMethodsOffered map[string]bool `json:"auth_methods_offered"`
+	AuthProbeError     string          `json:"auth_probe_error"`
+	Findings           []finding       `json:"findings"`
+	FindingsFailing    int             `json:"findings_failing"`
+}
+
+// startServer runs an x/crypto/ssh server with the given algorithm config and
+// returns its port. Password login is enabled when allowPassword is set.
+func startServer(t *testing.T, algorithms ssh.Config, allowPassword bool) int {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		Config: algorithms,
+		PublicKeyCallback: func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, assert.AnError
+		},
+	}
+	if allowPassword {
+		config.PasswordCallback = func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) {
+			t.Error("the probe must never send a password")
+			return nil, assert.AnError
+		}
+	}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if _, _, _, err := ssh.NewServerConn(conn, config); err == nil {
+					t.Error("probe unexpectedly authenticated")
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func runCheck(t *testing.T, port int, params map[string]interface{}) (common.ExecutionResult, checkOutput) {
+	t.Helper()
+	cfg, _ := json.Marshal(SSHSystemConfig{HostAddress: "127.0.0.1", Port: json.Number(strconv.Itoa(port))})
+	var out checkOutput
+	res := plugintest.Run(t, New(), common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+		StdContext:      context.Background(),
+	}, CheckTypeKey_SSHHardening, &out)
+	return res, out
+}
+
+func algorithmsOf(findings []finding) map[string]string {
+	out := map[string]string{}
+	for _, f := range findings {
+		out[f.Algorithm] = f.Severity
+	}
+	return out
+}
+
+func TestHardenedServerPasses(t *testing.T) {
+	port := startServer(t, ssh.Config{
+		KeyExchanges: []string{ssh.KeyExchangeCurve25519},
+		Ciphers:      []string{ssh.CipherChaCha20Poly1305, ssh.CipherAES256GCM},
+		MACs:         []string{ssh.HMACSHA256ETM},
+	}, false)
+
+	res, out := runCheck(t, port, map[string]interface{}{})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	assert.Empty(t, out.Findings)
+	assert.Empty(t, out.AuthProbeError)
+	assert.Equal(t, map[string]bool{"password": false, "keyboard-interactive": false}, out.AuthMethodsOffered)
+	assert.Contains(t, out.Advertised.KeyExchanges, ssh.KeyExchangeCurve25519)
+	assert.Equal(t, []string{ssh.KeyAlgoED25519}, out.Advertised.HostKeys)
+	assert.Contains(t, out.Advertised.Banner, "SSH-2.0-")
+}
+
+func TestWeakAlgorithmsAndPasswordAuth(t *testing.T) {
+	port := startServer(t, ssh.Config{
+		KeyExchanges: []string{ssh.KeyExchangeCurve25519, ssh.InsecureKeyExchangeDH14SHA1, ssh.KeyExchangeECDHP256},
+		Ciphers:      []string{ssh.CipherAES128CTR, ssh.InsecureCipherTripleDESCBC, ssh.InsecureCipherAES128CBC},
+		MACs:         []string{ssh.HMACSHA256, ssh.HMACSHA1},
+	}, true)
+
+	res, out := runCheck(t, port, map[string]interface{}{})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, map[string]string{
+		ssh.InsecureKeyExchangeDH14SHA1: SeverityMedium,
+		ssh.KeyExchangeECDHP256:         SeverityLow,
+		ssh.InsecureCipherTripleDESCBC:  SeverityHigh,
+		ssh.InsecureCipherAES128CBC:     SeverityMedium,
+		ssh.HMACSHA1:                    SeverityLow,
+		"password":                      SeverityHigh,
+	}, algorithmsOf(out.Findings))
+	assert.Equal(t, 4, out.FindingsFailing, "low findings are reported but do not fail at the default threshold")
+	assert.True(t, out.AuthMethodsOffered["password"])
+
+	// Exceptions and a stricter custom policy.
+	res, out = runCheck(t, port, map[string]interface{}{
+		"allowedAlgorithms":    "diffie-hellman-group14-sha1, *-cbc",
+		"disallowedAlgorithms": "aes128-ctr",
+		"checkPasswordAuth":    "false",
+		"failOnSeverity":       SeverityHigh,
+	})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, map[string]string{
+		ssh.KeyExchangeECDHP256: SeverityLow,
+		ssh.CipherAES128CTR:     SeverityHigh,
+		ssh.HMACSHA1:            SeverityLow,
+	}, algorithmsOf(out.Findings))
+	assert.Nil(t, out.AuthMethodsOffered)
+}
+
+func TestUnreachableHostIsAnError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	cfg, _ := json.Marshal(SSHSystemConfig{HostAddress: "127.0.0.1"})
+	res, err := New().ExecuteCheck(common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: map[string]interface{}{"port": float64(port)}},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: cfg},
+	}, CheckTypeKey_SSHHardening)
+	assert.ErrorIs(t, err, common.ErrSystemUnreachable)
+	assert.Equal(t, common.StatusError, res.Status)
+}
+
+// serveRaw answers the first connection with an SSH version line and one
+// unencrypted packet carrying payload.
+func serveRaw(t *testing.T, payload []byte) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		const padding = 4
+		packet := make([]byte, 5, 5+len(payload)+padding)
+		binary.BigEndian.PutUint32(packet, uint32(1+len(payload)+padding))
+		packet[4] = padding
+		packet = append(append(packet, payload...), make([]byte, padding)...)
+		conn.Write(append([]byte("SSH-2.0-Fake\r\n"), packet...))
+		io.Copy(io.Discard, conn)
+	}()
+	return ln.Addr().String()
+}
+
+func TestReadKexInitRejectsMalformedPackets(t *testing.T) {
+	for name, payload := range map[string][]byte{
+		"empty":            {},
+		"truncated cookie": {msgKexInit, 1, 2, 3},
+		"truncated lists":  append([]byte{msgKexInit}, make([]byte, 18)...),
+		"other message":    {1, 2, 3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := readKexInit(context.Background(), serveRaw(t, payload), time.Second)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReadKexInitRejectsNonSSHService(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+		conn.Close()
+	}()
+	_, err = readKexInit(context.Background(), ln.Addr().String(), time.Second)
+	assert.Error(t, err)
+}
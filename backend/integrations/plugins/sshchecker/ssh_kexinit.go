@@ -0,0 +1,160 @@
+package sshchecker
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+)
+
+const (
+	clientVersion    = "SSH-2.0-ComplianceAudit_1.0"
+	msgKexInit       = 20
+	maxPacketLength  = 35000
+	maxBannerLines   = 32
+	maxBannerLineLen = 1024
+)
+
+// serverKexInit holds what a server advertises in its SSH_MSG_KEXINIT
+// (RFC 4253 section 7.1). Algorithm lists for both directions are merged.
+type serverKexInit struct {
+	Banner       string   `json:"banner"`
+	KeyExchanges []string `json:"kex_algorithms"`
+	HostKeys     []string `json:"host_key_algorithms"`
+	Ciphers      []string `json:"ciphers"`
+	MACs         []string `json:"macs"`
+	Compressions []string `json:"compression_algorithms"`
+}
+
+// readKexInit performs the version exchange and reads the server's first,
+// unencrypted, KEXINIT packet. No key exchange is started.
+func readKexInit(ctx context.Context, addr string, timeout time.Duration) (*serverKexInit, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, common.Unreachable(fmt.Errorf("connect to %s: %w", addr, err))
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := io.WriteString(conn, clientVersion+"\r\n"); err != nil {
+		return nil, fmt.Errorf("send version: %w", err)
+	}
+
+	r := bufio.NewReader(conn)
+	banner, err := readServerVersion(r)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := readPacket(r)
+	if err != nil {
+		return nil, fmt.Errorf("read KEXINIT: %w", err)
+	}
+	if len(payload) == 0 {
+		return nil, errors.New("expected KEXINIT, got an empty packet")
+	}
+	if payload[0] != msgKexInit {
+		return nil, fmt.Errorf("expected KEXINIT, got message type %d", payload[0])
+	}
+	// Skip the message type and the 16 byte cookie.
+	if len(payload) < 17 {
+		return nil, fmt.Errorf("parse KEXINIT: packet of %d bytes is too short", len(payload))
+	}
+	rest := payload[17:]
+	lists := make([][]string, 10)
+	for i := range lists {
+		lists[i], rest, err = readNameList(rest)
+		if err != nil {
+			return nil, fmt.Errorf("parse KEXINIT: %w", err)
+		}
+	}
+	return &serverKexInit{
+		Banner:       banner,
+		KeyExchanges: lists[0],
+		HostKeys:     lists[1],
+		Ciphers:      union(lists[2], lists[3]),
+		MACs:         union(lists[4], lists[5]),
+		Compressions: union(lists[6], lists[7]),
+	}, nil
+}
+
+// readServerVersion skips any pre-banner lines and returns the "SSH-" line.
+func readServerVersion(r *bufio.Reader) (string, error) {
+	for i := 0; i < maxBannerLines; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("read server version: %w", err)
+		}
+		if len(line) > maxBannerLineLen {
+			return "", errors.New("server version line too long")
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, "SSH-") {
+			if !strings.HasPrefix(line, "SSH-2.0-") && !strings.HasPrefix(line, "SSH-1.99-") {
+				return line, fmt.Errorf("server does not support SSH protocol 2: %s", line)
+			}
+			return line, nil
+		}
+	}
+	return "", errors.New("no SSH version line received")
+}
+
+func readPacket(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	padding := uint32(header[4])
+	if length > maxPacketLength || padding+1 > length {
+		return nil, fmt.Errorf("invalid packet length %d", length)
+	}
+	body := make([]byte, length-1)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body[:length-1-padding], nil
+}
+
+func readNameList(b []byte) ([]string, []byte, error) {
+	if len(b) < 4 {
+		return nil, nil, errors.New("truncated name-list")
+	}
+	n := binary.BigEndian.Uint32(b)
+	b = b[4:]
+	if uint32(len(b)) < n {
+		return nil, nil, errors.New("truncated name-list")
+	}
+	var names []string
+	if n > 0 {
+		names = strings.Split(string(b[:n]), ",")
+	}
+	return names, b[n:], nil
+}
+
+func union(a, b []string) []string {
+	out := append([]string{}, a...)
+	for _, s := range b {
+		if !contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -27,6 +27,10 @@ func (p *SSLChecker) Name() string {
 	return "SSL Certificate Expiry Checker"
 }
 
+func (p *SSLChecker) Version() string {
+	return "1.0.0"
+}
+
 func (p *SSLChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
 	return map[string]models.CheckTypeConfiguration{
 		"ssl_cert_expiry": {
@@ -74,7 +78,7 @@ func (p *SSLChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string)
 	dialer := &net.Dialer{Timeout: 5 * time.Second}
 	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
 	if err != nil {
-		return common.ExecutionResult{Status: common.StatusFailed, Output: err.Error()}, err
+		return common.ExecutionResult{Status: common.StatusFailed, Output: err.Error()}, common.Unreachable(err)
 	}
 	defer conn.Close()
 	certs := conn.ConnectionState().PeerCertificates
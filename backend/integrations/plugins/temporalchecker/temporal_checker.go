@@ -1,16 +1,22 @@
 package temporalchecker
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os/exec"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+
 	"github.com/vdparikh/compliance-automation/backend/integrations"
 	"github.com/vdparikh/compliance-automation/backend/integrations/common"
 	"github.com/vdparikh/compliance-automation/backend/models"
@@ -19,13 +25,26 @@ import (
 const (
 	PluginID_TemporalChecker               = "temporal_checker_v1"
 	PluginName_TemporalChecker             = "Temporal Workflow Execution Checker"
+	PluginVersion_TemporalChecker          = "1.0.0"
 	CheckTypeKey_TemporalWorkflowExecution = "temporal_workflow_execution"
+	CheckTypeKey_TemporalWorkflowStatus    = "temporal_workflow_status"
+
+	defaultTimeout = 300 * time.Second
+	// cancelTimeout bounds the best-effort cancellation request sent when a
+	// started workflow is abandoned.
+	cancelTimeout = 5 * time.Second
 )
 
-type TemporalChecker struct{}
+// dialFunc creates a Temporal client. It is a field on TemporalChecker so tests
+// can substitute a mock client.
+type dialFunc func(ctx context.Context, options client.Options) (client.Client, error)
+
+type TemporalChecker struct {
+	dial dialFunc
+}
 
 func New() *TemporalChecker {
-	return &TemporalChecker{}
+	return &TemporalChecker{dial: client.DialContext}
 }
 
 func (p *TemporalChecker) ID() string {
@@ -36,21 +55,35 @@ func (p *TemporalChecker) Name() string {
 	return PluginName_TemporalChecker
 }
 
+func (p *TemporalChecker) Version() string {
+	return PluginVersion_TemporalChecker
+}
+
 func (p *TemporalChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	workflowIDParam := models.ParameterDefinition{
+		Name:        "workflowId",
+		Label:       "Workflow ID",
+		Type:        "text",
+		Required:    true,
+		Placeholder: "my-workflow-id",
+		HelpText:    "The ID of the Temporal workflow.",
+	}
+	timeoutParam := models.ParameterDefinition{
+		Name:        "timeout",
+		Label:       "Timeout (seconds)",
+		Type:        "number",
+		Required:    false,
+		Placeholder: "300",
+		HelpText:    "Optional timeout in seconds for the check. Defaults to 300 seconds.",
+	}
+
 	return map[string]models.CheckTypeConfiguration{
 		CheckTypeKey_TemporalWorkflowExecution: {
 			Label:       "Temporal Workflow Execution",
 			TargetType:  "connected_system",
 			TargetLabel: "Temporal Instance",
 			Parameters: []models.ParameterDefinition{
-				{
-					Name:        "workflowId",
-					Label:       "Workflow ID",
-					Type:        "text",
-					Required:    true,
-					Placeholder: "my-workflow-id",
-					HelpText:    "The ID of the Temporal workflow to execute.",
-				},
+				workflowIDParam,
 				{
 					Name:        "taskQueue",
 					Label:       "Task Queue",
@@ -75,49 +108,67 @@ func (p *TemporalChecker) GetCheckTypeConfigurations() map[string]models.CheckTy
 					Placeholder: `{"key": "value"}`,
 					HelpText:    "Optional JSON input data to pass to the workflow.",
 				},
+				timeoutParam,
+			},
+		},
+		CheckTypeKey_TemporalWorkflowStatus: {
+			Label:       "Temporal Workflow Status",
+			TargetType:  "connected_system",
+			TargetLabel: "Temporal Instance",
+			Parameters: []models.ParameterDefinition{
+				workflowIDParam,
 				{
-					Name:        "timeout",
-					Label:       "Timeout (seconds)",
-					Type:        "number",
-					Required:    false,
-					Placeholder: "300",
-					HelpText:    "Optional timeout in seconds for workflow execution. Defaults to 300 seconds.",
+					Name:     "runId",
+					Label:    "Run ID",
+					Type:     "text",
+					Required: false,
+					HelpText: "Optional run ID. Defaults to the latest run of the workflow.",
 				},
+				{
+					Name:     "expectedStatus",
+					Label:    "Expected Status",
+					Type:     "select",
+					Options:  []string{"Completed", "Running", "NotFailed"},
+					Required: false,
+					HelpText: "Status the workflow must be in for the check to pass. 'NotFailed' accepts running or successfully closed workflows. Defaults to Completed.",
+				},
+				timeoutParam,
 			},
 		},
 	}
 }
 
+// TemporalSystemConfig is the ConnectedSystem configuration for a Temporal
+// frontend. ServerURL is accepted for configurations created before HostPort
+// existed; only its host and port are used.
 type TemporalSystemConfig struct {
-	ServerURL string `json:"serverUrl"`
-	Namespace string `json:"namespace"`
+	HostPort      string `json:"hostPort"`
+	ServerURL     string `json:"serverUrl"`
+	Namespace     string `json:"namespace"`
+	APIKey        string `json:"apiKey"`
+	TLS           string `json:"tls"`
+	TLSCACert     string `json:"tlsCaCert"`
+	TLSClientCert string `json:"tlsClientCert"`
+	TLSClientKey  string `json:"tlsClientKey"`
+	TLSServerName string `json:"tlsServerName"`
+	UIURL         string `json:"uiUrl"`
 }
 
 type temporalWorkflowExecutionResponse struct {
 	WorkflowID    string      `json:"workflowId"`
 	RunID         string      `json:"runId"`
+	WorkflowType  string      `json:"workflowType,omitempty"`
+	TaskQueue     string      `json:"taskQueue,omitempty"`
 	Status        string      `json:"status"`
 	Result        interface{} `json:"result,omitempty"`
 	Error         string      `json:"error,omitempty"`
-	StartTime     time.Time   `json:"startTime"`
-	EndTime       time.Time   `json:"endTime"`
-	ExecutionTime string      `json:"executionTime"`
-}
-
-type temporalStartWorkflowRequest struct {
-	WorkflowID   string      `json:"workflowId"`
-	WorkflowType string      `json:"workflowType"`
-	TaskQueue    string      `json:"taskQueue"`
-	Input        interface{} `json:"input,omitempty"`
-}
-
-type temporalStartWorkflowResponse struct {
-	WorkflowID string `json:"workflowId"`
-	RunID      string `json:"runId"`
+	StartTime     *time.Time  `json:"startTime,omitempty"`
+	EndTime       *time.Time  `json:"endTime,omitempty"`
+	ExecutionTime string      `json:"executionTime,omitempty"`
 }
 
 func (p *TemporalChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
-	if checkTypeKey != CheckTypeKey_TemporalWorkflowExecution {
+	if checkTypeKey != CheckTypeKey_TemporalWorkflowExecution && checkTypeKey != CheckTypeKey_TemporalWorkflowStatus {
 		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
 	}
 
@@ -126,80 +177,66 @@ func (p *TemporalChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey str
 		return common.ExecutionResult{Status: common.StatusError, Output: "Failed to parse Temporal system configuration"}, fmt.Errorf("unmarshal temporal config: %w", err)
 	}
 
-	if sysConfig.ServerURL == "" {
-		return common.ExecutionResult{Status: common.StatusError, Output: "Temporal ServerURL is required"}, fmt.Errorf("missing temporal server URL")
+	clientOptions, err := sysConfig.clientOptions()
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
 	}
 
-	// Debug: Print all received parameters
-	fmt.Printf("=== TEMPORAL DEBUG ===\n")
-	fmt.Printf("All task parameters: %+v\n", ctx.TaskInstance.Parameters)
-	fmt.Printf("Task instance ID: %s\n", ctx.TaskInstance.ID)
-	fmt.Printf("Connected system config: %+v\n", sysConfig)
-
-	// Get workflow parameters
 	workflowID, ok := ctx.TaskInstance.Parameters["workflowId"].(string)
 	if !ok || workflowID == "" {
 		return common.ExecutionResult{Status: common.StatusError, Output: "Missing workflowId parameter"}, fmt.Errorf("missing workflowId parameter")
 	}
 
-	taskQueue, ok := ctx.TaskInstance.Parameters["taskQueue"].(string)
-	if !ok || taskQueue == "" {
-		return common.ExecutionResult{Status: common.StatusError, Output: "Missing taskQueue parameter"}, fmt.Errorf("missing taskQueue parameter")
-	}
-
-	workflowType, ok := ctx.TaskInstance.Parameters["workflowType"].(string)
-	if !ok || workflowType == "" {
-		return common.ExecutionResult{Status: common.StatusError, Output: "Missing workflowType parameter"}, fmt.Errorf("missing workflowType parameter")
+	timeout, err := timeoutFromParams(ctx.TaskInstance.Parameters)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
 	}
 
-	// Debug: Print extracted parameters
-	fmt.Printf("Extracted parameters:\n")
-	fmt.Printf("  workflowID: '%s'\n", workflowID)
-	fmt.Printf("  taskQueue: '%s'\n", taskQueue)
-	fmt.Printf("  workflowType: '%s'\n", workflowType)
+	parent := ctx.Context()
+	runCtx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
 
-	// Get optional parameters
-	var inputData interface{}
-	if inputDataStr, ok := ctx.TaskInstance.Parameters["inputData"].(string); ok && inputDataStr != "" {
-		if err := json.Unmarshal([]byte(inputDataStr), &inputData); err != nil {
-			return common.ExecutionResult{Status: common.StatusError, Output: "Invalid inputData JSON"}, fmt.Errorf("invalid input data JSON: %w", err)
-		}
-		fmt.Printf("  inputData: %+v\n", inputData)
+	ctx.Logf(common.LogLevelInfo, "Connecting to Temporal at %s (namespace %s)", clientOptions.HostPort, clientOptions.Namespace)
+	c, err := p.dial(runCtx, clientOptions)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Failed to connect to Temporal at %s: %v", clientOptions.HostPort, err)}, common.Unreachable(fmt.Errorf("dial temporal %s: %w", clientOptions.HostPort, err))
 	}
+	defer c.Close()
 
-	timeout := 300 * time.Second // Default 5 minutes
-	if timeoutStr, ok := ctx.TaskInstance.Parameters["timeout"].(float64); ok && timeoutStr > 0 {
-		timeout = time.Duration(timeoutStr) * time.Second
+	var (
+		executionResult *temporalWorkflowExecutionResponse
+		status          string
+	)
+	if checkTypeKey == CheckTypeKey_TemporalWorkflowExecution {
+		ctx.Logf(common.LogLevelInfo, "Starting workflow %s and waiting up to %s for it to close", workflowID, timeout)
+		executionResult, status, err = p.startAndAwait(runCtx, c, workflowID, ctx.TaskInstance.Parameters)
+	} else {
+		ctx.Logf(common.LogLevelInfo, "Describing workflow %s", workflowID)
+		executionResult, status, err = p.queryStatus(runCtx, c, workflowID, ctx.TaskInstance.Parameters)
 	}
-	fmt.Printf("  timeout: %v\n", timeout)
-	fmt.Printf("=== END DEBUG ===\n")
-
-	// Execute the Temporal workflow
-	executionResult, err := p.executeTemporalWorkflow(sysConfig, workflowID, workflowType, taskQueue, inputData, timeout)
-	if err != nil {
-		return common.ExecutionResult{Status: common.StatusFailed, Output: err.Error()}, err
+	// Cancellation of the worker's context is not a check outcome; surface it as an error.
+	if parent.Err() != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: fmt.Sprintf("Temporal check cancelled: %v", parent.Err())}, parent.Err()
 	}
-
-	// Determine status based on Temporal execution result
-	status := common.StatusSuccess
-	if executionResult.Status == "failed" || executionResult.Error != "" {
-		status = common.StatusFailed
+	if err != nil {
+		ctx.Logf(common.LogLevelError, "Temporal check failed: %v", err)
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
 	}
-
-	// Generate Temporal UI URL
-	temporalUIURL := p.generateTemporalUIURL(sysConfig.ServerURL, sysConfig.Namespace, executionResult.WorkflowID, executionResult.RunID)
+	ctx.Logf(common.LogLevelInfo, "Workflow %s run %s is %s", executionResult.WorkflowID, executionResult.RunID, executionResult.Status)
 
 	result := map[string]interface{}{
 		"workflowId":     executionResult.WorkflowID,
 		"runId":          executionResult.RunID,
+		"workflowType":   executionResult.WorkflowType,
+		"taskQueue":      executionResult.TaskQueue,
 		"status":         executionResult.Status,
 		"result":         executionResult.Result,
 		"startTime":      executionResult.StartTime,
 		"endTime":        executionResult.EndTime,
 		"executionTime":  executionResult.ExecutionTime,
-		"temporalServer": sysConfig.ServerURL,
-		"namespace":      sysConfig.Namespace,
-		"temporalUIURL":  temporalUIURL,
+		"temporalServer": clientOptions.HostPort,
+		"namespace":      clientOptions.Namespace,
+		"temporalUIURL":  p.generateTemporalUIURL(sysConfig, clientOptions, executionResult.WorkflowID, executionResult.RunID),
 	}
 
 	if executionResult.Error != "" {
@@ -210,531 +247,247 @@ func (p *TemporalChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey str
 	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
 }
 
-func (p *TemporalChecker) executeTemporalWorkflow(config TemporalSystemConfig, workflowID, workflowType, taskQueue string, inputData interface{}, timeout time.Duration) (*temporalWorkflowExecutionResponse, error) {
-	startTime := time.Now()
-
-	// First try REST API
-	restResult, err := p.tryRESTAPI(config, workflowID, workflowType, taskQueue, inputData, timeout)
-	if err == nil {
-		return restResult, nil
-	}
-
-	fmt.Printf("REST API failed: %v, trying CLI fallback\n", err)
-
-	// Fallback to CLI
-	cliResult, err := p.tryCLI(config, workflowID, workflowType, taskQueue, inputData, timeout)
-	if err != nil {
-		endTime := time.Now()
-		return &temporalWorkflowExecutionResponse{
-			WorkflowID:    workflowID,
-			Status:        "failed",
-			Error:         fmt.Sprintf("both REST API and CLI failed. REST error: %v, CLI error: %v", restResult.Error, err),
-			StartTime:     startTime,
-			EndTime:       endTime,
-			ExecutionTime: endTime.Sub(startTime).String(),
-		}, nil
-	}
-
-	return cliResult, nil
-}
-
-func (p *TemporalChecker) tryRESTAPI(config TemporalSystemConfig, workflowID, workflowType, taskQueue string, inputData interface{}, timeout time.Duration) (*temporalWorkflowExecutionResponse, error) {
-	client := &http.Client{Timeout: timeout}
-
-	// Prepare the workflow start request
-	startRequest := temporalStartWorkflowRequest{
-		WorkflowID:   workflowID,
-		WorkflowType: workflowType,
-		TaskQueue:    taskQueue,
-		Input:        inputData,
-	}
-
-	requestBody, err := json.Marshal(startRequest)
-	if err != nil {
-		return nil, fmt.Errorf("marshal workflow start request: %w", err)
+// startAndAwait starts a workflow and blocks until it closes or ctx is done.
+func (p *TemporalChecker) startAndAwait(ctx context.Context, c client.Client, workflowID string, params map[string]interface{}) (*temporalWorkflowExecutionResponse, string, error) {
+	taskQueue, ok := params["taskQueue"].(string)
+	if !ok || taskQueue == "" {
+		return nil, "", fmt.Errorf("missing taskQueue parameter")
 	}
-
-	// Try different possible Temporal API endpoints
-	endpoints := []string{
-		fmt.Sprintf("%s/api/v1/namespaces/%s/workflows", config.ServerURL, config.Namespace),
-		fmt.Sprintf("%s/namespaces/%s/workflows", config.ServerURL, config.Namespace),
-		fmt.Sprintf("%s/api/namespaces/%s/workflows", config.ServerURL, config.Namespace),
+	workflowType, ok := params["workflowType"].(string)
+	if !ok || workflowType == "" {
+		return nil, "", fmt.Errorf("missing workflowType parameter")
 	}
 
-	for _, url := range endpoints {
-		fmt.Printf("Trying Temporal REST endpoint: %s\n", url)
-
-		// Create HTTP request to start workflow
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
-		if err != nil {
-			continue
-		}
-
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Accept", "application/json")
-		req.Header.Set("User-Agent", "compliance-automation/1.0")
-
-		// Execute the request
-		resp, err := client.Do(req)
-		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			continue
-		}
-
-		fmt.Printf("REST Response from %s: Status=%d, Body=%s\n", url, resp.StatusCode, string(body))
-
-		// Check if the request was successful
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			// Try to parse the response
-			var startResponse temporalStartWorkflowResponse
-			if err := json.Unmarshal(body, &startResponse); err == nil {
-				return &temporalWorkflowExecutionResponse{
-					WorkflowID:    startResponse.WorkflowID,
-					RunID:         startResponse.RunID,
-					Status:        "started",
-					StartTime:     time.Now(),
-					EndTime:       time.Now(),
-					ExecutionTime: "0s",
-				}, nil
-			} else {
-				// If we can't parse the response but got a success status, consider it successful
-				return &temporalWorkflowExecutionResponse{
-					WorkflowID:    workflowID,
-					Status:        "started",
-					Result:        string(body),
-					StartTime:     time.Now(),
-					EndTime:       time.Now(),
-					ExecutionTime: "0s",
-				}, nil
-			}
+	var args []interface{}
+	if inputDataStr, ok := params["inputData"].(string); ok && inputDataStr != "" {
+		var inputData interface{}
+		if err := json.Unmarshal([]byte(inputDataStr), &inputData); err != nil {
+			return nil, "", fmt.Errorf("invalid input data JSON: %w", err)
 		}
+		args = append(args, inputData)
 	}
 
-	return nil, fmt.Errorf("all REST API endpoints failed")
-}
-
-func (p *TemporalChecker) tryCLI(config TemporalSystemConfig, workflowID, workflowType, taskQueue string, inputData interface{}, timeout time.Duration) (*temporalWorkflowExecutionResponse, error) {
 	startTime := time.Now()
-
-	// Debug: Print what we're about to execute
-	fmt.Printf("=== CLI DEBUG ===\n")
-	fmt.Printf("Building CLI command with:\n")
-	fmt.Printf("  workflowID: '%s'\n", workflowID)
-	fmt.Printf("  workflowType: '%s'\n", workflowType)
-	fmt.Printf("  taskQueue: '%s'\n", taskQueue)
-	fmt.Printf("  inputData: %+v\n", inputData)
-	fmt.Printf("  namespace: '%s'\n", config.Namespace)
-
-	// Build the temporal CLI command
-	args := []string{
-		"workflow", "start",
-		"--workflow-id", workflowID,
-		"--task-queue", taskQueue,
-		"--type", workflowType,
-	}
-
-	// Add namespace if specified
-	if config.Namespace != "" {
-		args = append(args, "--namespace", config.Namespace)
-	}
-
-	// Add input data if provided
-	if inputData != nil {
-		inputJSON, err := json.Marshal(inputData)
-		if err == nil {
-			args = append(args, "--input", string(inputJSON))
-		}
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: taskQueue,
+	}, workflowType, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("start workflow %s: %w", workflowID, err)
 	}
 
-	fmt.Printf("Final CLI command: temporal %s\n", strings.Join(args, " "))
-	fmt.Printf("=== END CLI DEBUG ===\n")
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	// Execute the command
-	cmd := exec.CommandContext(ctx, "temporal", args...)
-	output, err := cmd.CombinedOutput()
+	response := &temporalWorkflowExecutionResponse{
+		WorkflowID:   run.GetID(),
+		RunID:        run.GetRunID(),
+		WorkflowType: workflowType,
+		TaskQueue:    taskQueue,
+		StartTime:    &startTime,
+	}
 
+	var workflowResult interface{}
+	err = run.Get(ctx, &workflowResult)
 	endTime := time.Now()
-	executionTime := endTime.Sub(startTime).String()
+	response.EndTime = &endTime
+	response.ExecutionTime = endTime.Sub(startTime).String()
 
-	if err != nil {
-		return &temporalWorkflowExecutionResponse{
-			WorkflowID:    workflowID,
-			Status:        "failed",
-			Error:         fmt.Sprintf("CLI execution failed: %v, output: %s", err, string(output)),
-			StartTime:     startTime,
-			EndTime:       endTime,
-			ExecutionTime: executionTime,
-		}, nil
+	if err == nil {
+		response.Status = enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED.String()
+		response.Result = workflowResult
+		return response, common.StatusSuccess, nil
 	}
 
-	// Parse the CLI output to extract workflow run ID
-	outputStr := string(output)
-	runID := p.extractRunIDFromOutput(outputStr)
-
-	fmt.Printf("CLI output: %s\n", outputStr)
-	fmt.Printf("Extracted run ID: '%s'\n", runID)
-
-	// If we got a run ID, wait for workflow completion and get the result
-	if runID != "" {
-		return p.waitForWorkflowCompletion(config, workflowID, runID, startTime, timeout)
-	}
-
-	// If no run ID found, return what we have
-	return &temporalWorkflowExecutionResponse{
-		WorkflowID:    workflowID,
-		RunID:         runID,
-		Status:        "started",
-		Result:        outputStr,
-		StartTime:     startTime,
-		EndTime:       endTime,
-		ExecutionTime: executionTime,
-	}, nil
-}
-
-// extractRunIDFromOutput tries to extract the run ID from Temporal CLI output
-func (p *TemporalChecker) extractRunIDFromOutput(output string) string {
-	lines := strings.Split(output, "\n")
-
-	// Look for various patterns in the output
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Pattern 1: "Run ID: xyz123"
-		if strings.Contains(line, "Run ID:") {
-			parts := strings.Split(line, "Run ID:")
-			if len(parts) > 1 {
-				runID := strings.TrimSpace(parts[1])
-				if runID != "" {
-					return runID
-				}
-			}
-		}
-
-		// Pattern 2: "Started Workflow Id: abc, Run Id: xyz123"
-		if strings.Contains(line, "Run Id:") {
-			parts := strings.Split(line, "Run Id:")
-			if len(parts) > 1 {
-				runID := strings.TrimSpace(parts[1])
-				// Remove any trailing text after the run ID
-				if commaIndex := strings.Index(runID, ","); commaIndex != -1 {
-					runID = runID[:commaIndex]
-				}
-				if runID != "" {
-					return runID
-				}
-			}
-		}
-
-		// Pattern 3: Look for UUID-like patterns
-		if strings.Contains(line, "-") && len(line) > 20 {
-			// This might be a run ID (UUID format)
-			parts := strings.Fields(line)
-			for _, part := range parts {
-				if strings.Contains(part, "-") && len(part) > 20 {
-					return part
-				}
-			}
-		}
+	if ctx.Err() != nil {
+		// Don't leave an abandoned run behind when the check gives up on it.
+		cancelCtx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+		defer cancel()
+		_ = c.CancelWorkflow(cancelCtx, response.WorkflowID, response.RunID)
+		response.Status = "TimedOut"
+		response.Error = fmt.Sprintf("workflow did not complete before the check timed out: %v", ctx.Err())
+		return response, common.StatusFailed, nil
 	}
 
-	return ""
+	response.Status = statusFromError(err)
+	response.Error = err.Error()
+	return response, common.StatusFailed, nil
 }
 
-// waitForWorkflowCompletion waits for the workflow to complete and returns the result
-func (p *TemporalChecker) waitForWorkflowCompletion(config TemporalSystemConfig, workflowID, runID string, startTime time.Time, timeout time.Duration) (*temporalWorkflowExecutionResponse, error) {
-	fmt.Printf("Waiting for workflow completion: %s (run: %s)\n", workflowID, runID)
-
-	// Calculate remaining timeout
-	elapsed := time.Since(startTime)
-	remainingTimeout := timeout - elapsed
-	if remainingTimeout <= 0 {
-		return &temporalWorkflowExecutionResponse{
-			WorkflowID:    workflowID,
-			RunID:         runID,
-			Status:        "timeout",
-			Error:         "Workflow execution timed out",
-			StartTime:     startTime,
-			EndTime:       time.Now(),
-			ExecutionTime: time.Since(startTime).String(),
-		}, nil
+// queryStatus describes an existing workflow run and compares its status to expectedStatus.
+func (p *TemporalChecker) queryStatus(ctx context.Context, c client.Client, workflowID string, params map[string]interface{}) (*temporalWorkflowExecutionResponse, string, error) {
+	runID, _ := params["runId"].(string)
+	expectedStatus, _ := params["expectedStatus"].(string)
+	if expectedStatus == "" {
+		expectedStatus = "Completed"
 	}
-
-	// Build the describe command to get workflow status and result
-	args := []string{
-		"workflow", "describe",
-		"--workflow-id", workflowID,
-		"--run-id", runID,
+	if expectedStatus != "Completed" && expectedStatus != "Running" && expectedStatus != "NotFailed" {
+		return nil, "", fmt.Errorf("invalid expectedStatus %q", expectedStatus)
 	}
 
-	if config.Namespace != "" {
-		args = append(args, "--namespace", config.Namespace)
-	}
-
-	// Poll for completion
-	pollInterval := 2 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), remainingTimeout)
-	defer cancel()
-
-	for {
-		select {
-		case <-ctx.Done():
+	resp, err := c.DescribeWorkflowExecution(ctx, workflowID, runID)
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
 			return &temporalWorkflowExecutionResponse{
-				WorkflowID:    workflowID,
-				RunID:         runID,
-				Status:        "timeout",
-				Error:         "Workflow polling timed out",
-				StartTime:     startTime,
-				EndTime:       time.Now(),
-				ExecutionTime: time.Since(startTime).String(),
-			}, nil
-
-		case <-time.After(pollInterval):
-			// Check workflow status
-			cmd := exec.CommandContext(ctx, "temporal", args...)
-			output, err := cmd.CombinedOutput()
-
-			if err != nil {
-				fmt.Printf("Error checking workflow status: %v\n", err)
-				continue
-			}
-
-			outputStr := string(output)
-			fmt.Printf("Workflow status check output: %s\n", outputStr)
-
-			// Try to parse JSON output first
-			status := p.parseStatusFromJSON(outputStr)
-			if status != "" {
-				fmt.Printf("Parsed status from JSON: %s\n", status)
-
-				switch status {
-				case "Completed":
-					// Get the result
-					result := p.extractResultFromJSON(outputStr)
-					return &temporalWorkflowExecutionResponse{
-						WorkflowID:    workflowID,
-						RunID:         runID,
-						Status:        "completed",
-						Result:        result,
-						StartTime:     startTime,
-						EndTime:       time.Now(),
-						ExecutionTime: time.Since(startTime).String(),
-					}, nil
-				case "Failed":
-					errorMsg := p.extractErrorFromJSON(outputStr)
-					return &temporalWorkflowExecutionResponse{
-						WorkflowID:    workflowID,
-						RunID:         runID,
-						Status:        "failed",
-						Error:         errorMsg,
-						StartTime:     startTime,
-						EndTime:       time.Now(),
-						ExecutionTime: time.Since(startTime).String(),
-					}, nil
-				case "Terminated":
-					return &temporalWorkflowExecutionResponse{
-						WorkflowID:    workflowID,
-						RunID:         runID,
-						Status:        "terminated",
-						StartTime:     startTime,
-						EndTime:       time.Now(),
-						ExecutionTime: time.Since(startTime).String(),
-					}, nil
-				case "TimedOut":
-					return &temporalWorkflowExecutionResponse{
-						WorkflowID:    workflowID,
-						RunID:         runID,
-						Status:        "timedout",
-						Error:         "Workflow timed out",
-						StartTime:     startTime,
-						EndTime:       time.Now(),
-						ExecutionTime: time.Since(startTime).String(),
-					}, nil
-				}
-			}
-
-			// Fallback to text parsing for older CLI versions
-			if strings.Contains(outputStr, "ExecutionStatus: COMPLETED") {
-				// Get the result
-				result := p.extractResultFromOutput(outputStr)
-				return &temporalWorkflowExecutionResponse{
-					WorkflowID:    workflowID,
-					RunID:         runID,
-					Status:        "completed",
-					Result:        result,
-					StartTime:     startTime,
-					EndTime:       time.Now(),
-					ExecutionTime: time.Since(startTime).String(),
-				}, nil
-			} else if strings.Contains(outputStr, "ExecutionStatus: FAILED") {
-				errorMsg := p.extractErrorFromOutput(outputStr)
-				return &temporalWorkflowExecutionResponse{
-					WorkflowID:    workflowID,
-					RunID:         runID,
-					Status:        "failed",
-					Error:         errorMsg,
-					StartTime:     startTime,
-					EndTime:       time.Now(),
-					ExecutionTime: time.Since(startTime).String(),
-				}, nil
-			} else if strings.Contains(outputStr, "ExecutionStatus: TERMINATED") {
-				return &temporalWorkflowExecutionResponse{
-					WorkflowID:    workflowID,
-					RunID:         runID,
-					Status:        "terminated",
-					StartTime:     startTime,
-					EndTime:       time.Now(),
-					ExecutionTime: time.Since(startTime).String(),
-				}, nil
-			}
-
-			// Workflow is still running, continue polling
-			fmt.Printf("Workflow still running, polling again in %v...\n", pollInterval)
+				WorkflowID: workflowID,
+				RunID:      runID,
+				Status:     "NotFound",
+				Error:      err.Error(),
+			}, common.StatusFailed, nil
+		}
+		return nil, "", fmt.Errorf("describe workflow %s: %w", workflowID, err)
+	}
+
+	info := resp.GetWorkflowExecutionInfo()
+	response := &temporalWorkflowExecutionResponse{
+		WorkflowID:   info.GetExecution().GetWorkflowId(),
+		RunID:        info.GetExecution().GetRunId(),
+		WorkflowType: info.GetType().GetName(),
+		TaskQueue:    info.GetTaskQueue(),
+		Status:       info.GetStatus().String(),
+	}
+	if info.GetStartTime() != nil {
+		t := info.GetStartTime().AsTime()
+		response.StartTime = &t
+	}
+	if info.GetCloseTime() != nil {
+		t := info.GetCloseTime().AsTime()
+		response.EndTime = &t
+		if response.StartTime != nil {
+			response.ExecutionTime = t.Sub(*response.StartTime).String()
 		}
 	}
-}
 
-// parseStatusFromJSON extracts the workflow status from JSON output
-func (p *TemporalChecker) parseStatusFromJSON(output string) string {
-	var result map[string]interface{}
-	if err := json.Unmarshal([]byte(output), &result); err != nil {
-		return ""
+	if statusMatches(info.GetStatus(), expectedStatus) {
+		return response, common.StatusSuccess, nil
 	}
+	response.Error = fmt.Sprintf("workflow status is %s, expected %s", response.Status, expectedStatus)
+	return response, common.StatusFailed, nil
+}
 
-	// Navigate to workflowExecutionInfo.status
-	if workflowInfo, ok := result["workflowExecutionInfo"].(map[string]interface{}); ok {
-		if status, ok := workflowInfo["status"].(string); ok {
-			return status
+func statusMatches(status enumspb.WorkflowExecutionStatus, expected string) bool {
+	switch expected {
+	case "Running":
+		return status == enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING
+	case "NotFailed":
+		switch status {
+		case enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+			enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED,
+			enumspb.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW:
+			return true
 		}
+		return false
+	default:
+		return status == enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED
 	}
-
-	return ""
 }
 
-// extractResultFromJSON tries to extract the workflow result from JSON output
-func (p *TemporalChecker) extractResultFromJSON(output string) string {
-	var result map[string]interface{}
-	if err := json.Unmarshal([]byte(output), &result); err != nil {
-		return "Result available in Temporal UI"
+// statusFromError maps the error returned by WorkflowRun.Get to a workflow status name.
+func statusFromError(err error) string {
+	var (
+		canceledErr   *temporal.CanceledError
+		terminatedErr *temporal.TerminatedError
+		timeoutErr    *temporal.TimeoutError
+	)
+	switch {
+	case errors.As(err, &canceledErr):
+		return enumspb.WORKFLOW_EXECUTION_STATUS_CANCELED.String()
+	case errors.As(err, &terminatedErr):
+		return enumspb.WORKFLOW_EXECUTION_STATUS_TERMINATED.String()
+	case errors.As(err, &timeoutErr):
+		return enumspb.WORKFLOW_EXECUTION_STATUS_TIMED_OUT.String()
+	default:
+		return enumspb.WORKFLOW_EXECUTION_STATUS_FAILED.String()
 	}
+}
 
-	// Look for result in various possible locations
-	if workflowInfo, ok := result["workflowExecutionInfo"].(map[string]interface{}); ok {
-		// Check if there's a result field
-		if resultData, ok := workflowInfo["result"].(string); ok && resultData != "" {
-			return resultData
+// clientOptions builds Temporal client options from the ConnectedSystem configuration.
+func (cfg TemporalSystemConfig) clientOptions() (client.Options, error) {
+	hostPort := cfg.HostPort
+	if hostPort == "" && cfg.ServerURL != "" {
+		if u, err := url.Parse(cfg.ServerURL); err == nil && u.Host != "" {
+			hostPort = u.Host
+		} else {
+			hostPort = cfg.ServerURL
 		}
 	}
-
-	// If no result found in JSON, return a helpful message
-	return "Workflow completed successfully - check Temporal UI for detailed result"
-}
-
-// extractErrorFromJSON tries to extract the error message from JSON output
-func (p *TemporalChecker) extractErrorFromJSON(output string) string {
-	var result map[string]interface{}
-	if err := json.Unmarshal([]byte(output), &result); err != nil {
-		return "Workflow failed - check Temporal UI for details"
+	if hostPort == "" {
+		return client.Options{}, fmt.Errorf("temporal hostPort is required")
 	}
 
-	// Look for error in various possible locations
-	if workflowInfo, ok := result["workflowExecutionInfo"].(map[string]interface{}); ok {
-		// Check if there's a failure field
-		if failure, ok := workflowInfo["failure"].(string); ok && failure != "" {
-			return failure
-		}
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = "default"
 	}
 
-	return "Workflow failed - check Temporal UI for details"
-}
-
-// extractResultFromOutput tries to extract the workflow result from the describe output (text format)
-func (p *TemporalChecker) extractResultFromOutput(output string) string {
-	lines := strings.Split(output, "\n")
+	options := client.Options{
+		HostPort:  hostPort,
+		Namespace: namespace,
+	}
 
-	for i, line := range lines {
-		if strings.Contains(line, "Result:") {
-			// Get the result line and potentially the next few lines
-			result := strings.TrimSpace(strings.TrimPrefix(line, "Result:"))
-			if result != "" {
-				return result
+	tlsEnabled, _ := strconv.ParseBool(cfg.TLS)
+	if tlsEnabled || cfg.APIKey != "" || cfg.TLSCACert != "" || cfg.TLSClientCert != "" {
+		tlsConfig := &tls.Config{ServerName: cfg.TLSServerName, MinVersion: tls.VersionTLS12}
+		if cfg.TLSCACert != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(cfg.TLSCACert)) {
+				return client.Options{}, fmt.Errorf("temporal tlsCaCert does not contain a valid PEM certificate")
 			}
-
-			// If result is empty, check next lines for multi-line result
-			for j := i + 1; j < len(lines) && j < i+5; j++ {
-				nextLine := strings.TrimSpace(lines[j])
-				if nextLine != "" && !strings.Contains(nextLine, ":") {
-					return nextLine
-				}
+			tlsConfig.RootCAs = pool
+		}
+		if cfg.TLSClientCert != "" || cfg.TLSClientKey != "" {
+			cert, err := tls.X509KeyPair([]byte(cfg.TLSClientCert), []byte(cfg.TLSClientKey))
+			if err != nil {
+				return client.Options{}, fmt.Errorf("load temporal client certificate: %w", err)
 			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
 		}
+		options.ConnectionOptions.TLS = tlsConfig
 	}
-
-	return "Result available in Temporal UI"
+	if cfg.APIKey != "" {
+		options.Credentials = client.NewAPIKeyStaticCredentials(cfg.APIKey)
+	}
+	return options, nil
 }
 
-// extractErrorFromOutput tries to extract the error message from the describe output (text format)
-func (p *TemporalChecker) extractErrorFromOutput(output string) string {
-	lines := strings.Split(output, "\n")
-
-	for i, line := range lines {
-		if strings.Contains(line, "Failure:") {
-			// Get the error line and potentially the next few lines
-			error := strings.TrimSpace(strings.TrimPrefix(line, "Failure:"))
-			if error != "" {
-				return error
-			}
-
-			// If error is empty, check next lines for multi-line error
-			for j := i + 1; j < len(lines) && j < i+5; j++ {
-				nextLine := strings.TrimSpace(lines[j])
-				if nextLine != "" && !strings.Contains(nextLine, ":") {
-					return nextLine
-				}
-			}
+func timeoutFromParams(params map[string]interface{}) (time.Duration, error) {
+	switch v := params["timeout"].(type) {
+	case nil:
+		return defaultTimeout, nil
+	case float64:
+		if v <= 0 {
+			return defaultTimeout, nil
+		}
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return defaultTimeout, nil
 		}
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || seconds <= 0 {
+			return 0, fmt.Errorf("invalid timeout %q", v)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("invalid timeout %v", v)
 	}
-
-	return "Workflow failed - check Temporal UI for details"
 }
 
-// generateTemporalUIURL creates a URL to view the workflow execution in the Temporal web UI
-func (p *TemporalChecker) generateTemporalUIURL(serverURL, namespace, workflowID, runID string) string {
-	// Extract the base URL and port from the server URL
-	baseURL := serverURL
-
-	// If the server URL contains a port, try to replace it with the UI port (8233)
-	if strings.Contains(baseURL, ":") {
-		// Replace any port with 8233 (Temporal UI port)
-		if colonIndex := strings.LastIndex(baseURL, ":"); colonIndex != -1 {
-			baseURL = baseURL[:colonIndex] + ":8233"
-		}
-	} else {
-		// If no port specified, assume localhost and add UI port
-		if strings.HasPrefix(baseURL, "http://") {
-			baseURL = strings.TrimSuffix(baseURL, "/") + ":8233"
-		} else {
-			baseURL = "http://localhost:8233"
+// generateTemporalUIURL links to the workflow in the Temporal Web UI. Without an
+// explicit uiUrl the UI is assumed to run on the frontend host at port 8233.
+func (p *TemporalChecker) generateTemporalUIURL(cfg TemporalSystemConfig, options client.Options, workflowID, runID string) string {
+	baseURL := strings.TrimRight(cfg.UIURL, "/")
+	if baseURL == "" {
+		host := options.HostPort
+		if i := strings.LastIndex(host, ":"); i != -1 {
+			host = host[:i]
 		}
+		baseURL = "http://" + host + ":8233"
 	}
 
-	// Construct the workflow execution URL
-	// Format: http://localhost:8233/namespaces/{namespace}/workflows/{workflowId}/runs/{runId}
-	if namespace != "" && runID != "" {
-		return fmt.Sprintf("%s/namespaces/%s/workflows/%s/runs/%s", baseURL, namespace, workflowID, runID)
-	} else if namespace != "" {
-		return fmt.Sprintf("%s/namespaces/%s/workflows/%s", baseURL, namespace, workflowID)
-	} else {
-		return fmt.Sprintf("%s/workflows/%s", baseURL, workflowID)
+	u := fmt.Sprintf("%s/namespaces/%s/workflows/%s", baseURL, url.PathEscape(options.Namespace), url.PathEscape(workflowID))
+	if runID != "" {
+		u += "/" + url.PathEscape(runID)
 	}
+	return u
 }
 
 var _ integrations.IntegrationPlugin = (*TemporalChecker)(nil)
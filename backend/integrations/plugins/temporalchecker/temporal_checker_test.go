@@ -0,0 +1,219 @@
+package temporalchecker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/mocks"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+func checkContext(t *testing.T, stdCtx context.Context, cfg TemporalSystemConfig, params map[string]interface{}) common.CheckContext {
+	t.Helper()
+	raw, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	return common.CheckContext{
+		TaskInstance:    &models.CampaignTaskInstance{Parameters: params},
+		ConnectedSystem: &models.ConnectedSystem{Configuration: raw},
+		StdContext:      stdCtx,
+	}
+}
+
+func mockChecker(c client.Client) *TemporalChecker {
+	return &TemporalChecker{dial: func(ctx context.Context, options client.Options) (client.Client, error) {
+		return c, nil
+	}}
+}
+
+func decodeOutput(t *testing.T, res common.ExecutionResult) map[string]interface{} {
+	t.Helper()
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(res.Output), &out))
+	return out
+}
+
+func TestWorkflowStatusCheckWithMockClient(t *testing.T) {
+	c := mocks.NewClient(t)
+	c.On("Close").Return()
+	c.On("DescribeWorkflowExecution", mock.Anything, "nightly-backup", "").Return(&workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: "nightly-backup", RunId: "run-1"},
+			Type:      &commonpb.WorkflowType{Name: "BackupWorkflow"},
+			Status:    enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED,
+			StartTime: timestamppb.New(time.Now().Add(-time.Hour)),
+			CloseTime: timestamppb.New(time.Now()),
+			TaskQueue: "backups",
+		},
+	}, nil)
+
+	res, err := mockChecker(c).ExecuteCheck(checkContext(t, context.Background(), TemporalSystemConfig{HostPort: "temporal:7233"}, map[string]interface{}{
+		"workflowId": "nightly-backup",
+	}), CheckTypeKey_TemporalWorkflowStatus)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	out := decodeOutput(t, res)
+	assert.Equal(t, "Completed", out["status"])
+	assert.Equal(t, "run-1", out["runId"])
+	assert.Equal(t, "http://temporal:8233/namespaces/default/workflows/nightly-backup/run-1", out["temporalUIURL"])
+}
+
+func TestWorkflowStatusCheckNotFound(t *testing.T) {
+	c := mocks.NewClient(t)
+	c.On("Close").Return()
+	c.On("DescribeWorkflowExecution", mock.Anything, "missing", "").Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	res, err := mockChecker(c).ExecuteCheck(checkContext(t, context.Background(), TemporalSystemConfig{HostPort: "temporal:7233"}, map[string]interface{}{
+		"workflowId":     "missing",
+		"expectedStatus": "NotFailed",
+	}), CheckTypeKey_TemporalWorkflowStatus)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, "NotFound", decodeOutput(t, res)["status"])
+}
+
+func TestStartAndAwaitReportsWorkflowFailure(t *testing.T) {
+	c := mocks.NewClient(t)
+	run := mocks.NewWorkflowRun(t)
+	c.On("Close").Return()
+	c.On("ExecuteWorkflow", mock.Anything, mock.MatchedBy(func(o client.StartWorkflowOptions) bool {
+		return o.ID == "wf-1" && o.TaskQueue == "checks"
+	}), "AccessReview", map[string]interface{}{"team": "infra"}).Return(run, nil)
+	run.On("GetID").Return("wf-1")
+	run.On("GetRunID").Return("run-1")
+	run.On("Get", mock.Anything, mock.Anything).Return(temporal.NewCanceledError())
+
+	res, err := mockChecker(c).ExecuteCheck(checkContext(t, context.Background(), TemporalSystemConfig{HostPort: "temporal:7233"}, map[string]interface{}{
+		"workflowId":   "wf-1",
+		"taskQueue":    "checks",
+		"workflowType": "AccessReview",
+		"inputData":    `{"team":"infra"}`,
+	}), CheckTypeKey_TemporalWorkflowExecution)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, "Canceled", decodeOutput(t, res)["status"])
+}
+
+func TestStartAndAwaitPropagatesCancellation(t *testing.T) {
+	c := mocks.NewClient(t)
+	run := mocks.NewWorkflowRun(t)
+	c.On("Close").Return()
+	c.On("ExecuteWorkflow", mock.Anything, mock.Anything, "SlowWorkflow").Return(run, nil)
+	c.On("CancelWorkflow", mock.Anything, "wf-slow", "run-1").Return(nil)
+	run.On("GetID").Return("wf-slow")
+	run.On("GetRunID").Return("run-1")
+	run.On("Get", mock.Anything, mock.Anything).Return(func(ctx context.Context, _ interface{}) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	stdCtx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	res, err := mockChecker(c).ExecuteCheck(checkContext(t, stdCtx, TemporalSystemConfig{HostPort: "temporal:7233"}, map[string]interface{}{
+		"workflowId":   "wf-slow",
+		"taskQueue":    "checks",
+		"workflowType": "SlowWorkflow",
+	}), CheckTypeKey_TemporalWorkflowExecution)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, common.StatusError, res.Status)
+}
+
+func TestClientOptionsFromConfig(t *testing.T) {
+	opts, err := TemporalSystemConfig{ServerURL: "http://temporal.internal:7233", Namespace: "compliance"}.clientOptions()
+	require.NoError(t, err)
+	assert.Equal(t, "temporal.internal:7233", opts.HostPort)
+	assert.Equal(t, "compliance", opts.Namespace)
+	assert.Nil(t, opts.ConnectionOptions.TLS)
+
+	opts, err = TemporalSystemConfig{HostPort: "ns.tmprl.cloud:7233", APIKey: "key"}.clientOptions()
+	require.NoError(t, err)
+	assert.NotNil(t, opts.ConnectionOptions.TLS)
+	assert.NotNil(t, opts.Credentials)
+
+	_, err = TemporalSystemConfig{HostPort: "h:7233", TLSCACert: "not a pem"}.clientOptions()
+	assert.Error(t, err)
+
+	_, err = TemporalSystemConfig{}.clientOptions()
+	assert.Error(t, err)
+}
+
+// TestAgainstDevServer runs both check types against the SDK's dev server. It
+// uses the Temporal CLI at TEMPORAL_CLI_PATH, or downloads it, and is skipped
+// when neither is possible.
+func TestAgainstDevServer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping Temporal dev server test in short mode")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	server, err := testsuite.StartDevServer(ctx, testsuite.DevServerOptions{
+		ExistingPath: os.Getenv("TEMPORAL_CLI_PATH"),
+		LogLevel:     "error",
+	})
+	if err != nil {
+		t.Skipf("Temporal dev server unavailable: %v", err)
+	}
+	defer server.Stop()
+
+	const taskQueue = "compliance-checks"
+	w := worker.New(server.Client(), taskQueue, worker.Options{})
+	w.RegisterWorkflowWithOptions(func(ctx workflow.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"compliant": true, "team": input["team"]}, nil
+	}, workflow.RegisterOptions{Name: "PassingCheck"})
+	w.RegisterWorkflowWithOptions(func(ctx workflow.Context) error {
+		return temporal.NewNonRetryableApplicationError("control failed", "ControlFailure", errors.New("mfa disabled"))
+	}, workflow.RegisterOptions{Name: "FailingCheck"})
+	require.NoError(t, w.Start())
+	defer w.Stop()
+
+	cfg := TemporalSystemConfig{HostPort: server.FrontendHostPort(), Namespace: "default"}
+	checker := New()
+
+	res, err := checker.ExecuteCheck(checkContext(t, ctx, cfg, map[string]interface{}{
+		"workflowId":   "passing-check",
+		"taskQueue":    taskQueue,
+		"workflowType": "PassingCheck",
+		"inputData":    `{"team":"infra"}`,
+		"timeout":      float64(30),
+	}), CheckTypeKey_TemporalWorkflowExecution)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	assert.Equal(t, map[string]interface{}{"compliant": true, "team": "infra"}, decodeOutput(t, res)["result"])
+
+	res, err = checker.ExecuteCheck(checkContext(t, ctx, cfg, map[string]interface{}{
+		"workflowId": "passing-check",
+	}), CheckTypeKey_TemporalWorkflowStatus)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusSuccess, res.Status)
+
+	res, err = checker.ExecuteCheck(checkContext(t, ctx, cfg, map[string]interface{}{
+		"workflowId":   "failing-check",
+		"taskQueue":    taskQueue,
+		"workflowType": "FailingCheck",
+		"timeout":      float64(30),
+	}), CheckTypeKey_TemporalWorkflowExecution)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, "Failed", decodeOutput(t, res)["status"])
+}
@@ -0,0 +1,132 @@
+package vulnscanchecker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/store"
+	"github.com/vdparikh/compliance-automation/backend/vulnscan"
+)
+
+const (
+	PluginID_VulnScanChecker         = "vulnerability_report_checker_v1"
+	PluginName_VulnScanChecker       = "Vulnerability Scanner Report Checker"
+	PluginVersion_VulnScanChecker    = "1.0.0"
+	CheckTypeKey_VulnerabilityPolicy = "vulnerability_report_policy"
+)
+
+// reportSource is the part of the store the checker reads. Reports are
+// written by the ingestion endpoint, not by this plugin.
+type reportSource interface {
+	GetLatestVulnerabilityReportByInstanceID(instanceID string) (*models.VulnerabilityReport, error)
+	GetVulnerabilityFindingsByReportID(reportID string) ([]models.VulnerabilityFinding, error)
+}
+
+// VulnScanChecker re-evaluates the most recent scanner report ingested for a
+// task instance, so an unchanged report starts failing once findings age past
+// the policy.
+type VulnScanChecker struct {
+	now func() time.Time
+}
+
+func New() *VulnScanChecker {
+	return &VulnScanChecker{now: time.Now}
+}
+
+func (p *VulnScanChecker) ID() string {
+	return PluginID_VulnScanChecker
+}
+
+func (p *VulnScanChecker) Name() string {
+	return PluginName_VulnScanChecker
+}
+
+func (p *VulnScanChecker) Version() string {
+	return PluginVersion_VulnScanChecker
+}
+
+func (p *VulnScanChecker) GetCheckTypeConfigurations() map[string]models.CheckTypeConfiguration {
+	return map[string]models.CheckTypeConfiguration{
+		CheckTypeKey_VulnerabilityPolicy: {
+			Label: "Vulnerability Report Policy",
+			Parameters: []models.ParameterDefinition{
+				{Name: "policy", Label: "Remediation Policy", Type: "text", Placeholder: vulnscan.DefaultPolicy, HelpText: "Maximum days a finding may stay open per severity, counted from when it was first seen on the asset. 0 fails any open finding of that severity."},
+				{Name: "maxReportAgeDays", Label: "Maximum Report Age (Days)", Type: "number", Placeholder: "7", HelpText: "Fail when the latest report is older than this. Leave empty to accept any age."},
+			},
+			TargetType:     "connected_system",
+			TargetLabel:    "Scanner",
+			TargetHelpText: "Reports are uploaded to the task via POST /campaign-task-instances/:id/vulnerability-reports; the connected system only identifies the scanner.",
+		},
+	}
+}
+
+func (p *VulnScanChecker) ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error) {
+	if checkTypeKey != CheckTypeKey_VulnerabilityPolicy {
+		return common.ExecutionResult{Status: common.StatusError, Output: "Unsupported check type"}, fmt.Errorf("unsupported check type: %s", checkTypeKey)
+	}
+	if ctx.Store == nil || ctx.TaskInstance == nil {
+		err := errors.New("vulnerability report checker needs the store and task instance in its context")
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+	return p.evaluate(ctx.Store, ctx.TaskInstance)
+}
+
+func (p *VulnScanChecker) evaluate(src reportSource, taskInstance *models.CampaignTaskInstance) (common.ExecutionResult, error) {
+	params := taskInstance.Parameters
+	policy, err := vulnscan.PolicyFromParameters(params)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	report, err := src.GetLatestVulnerabilityReportByInstanceID(taskInstance.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		jsonOut, _ := json.Marshal(map[string]interface{}{
+			"message": "No vulnerability report has been ingested for this task.",
+		})
+		return common.ExecutionResult{Status: common.StatusFailed, Output: string(jsonOut)}, nil
+	}
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+	findings, err := src.GetVulnerabilityFindingsByReportID(report.ID)
+	if err != nil {
+		return common.ExecutionResult{Status: common.StatusError, Output: err.Error()}, err
+	}
+
+	now := p.now()
+	evaluation := policy.Evaluate(findings, now)
+
+	var issues []string
+	reportAgeDays := int(now.Sub(report.CreatedAt).Hours() / 24)
+	if maxAge := common.IntParam(params, "maxReportAgeDays", 0); maxAge > 0 && reportAgeDays > maxAge {
+		issues = append(issues, fmt.Sprintf("latest report is %d days old; at most %d allowed", reportAgeDays, maxAge))
+	}
+	if len(evaluation.Violations) > 0 {
+		issues = append(issues, fmt.Sprintf("%d findings exceed policy %s", len(evaluation.Violations), evaluation.Policy))
+	}
+
+	status := common.StatusSuccess
+	message := fmt.Sprintf("%d findings across %d assets are within policy %s.", evaluation.TotalFindings, len(evaluation.Assets), evaluation.Policy)
+	if len(issues) > 0 {
+		status = common.StatusFailed
+		message = strings.Join(issues, "; ")
+	}
+	result := map[string]interface{}{
+		"message":         message,
+		"report_id":       report.ID,
+		"report_format":   report.Format,
+		"report_age_days": reportAgeDays,
+		"evidence_id":     report.EvidenceID,
+		"evaluation":      evaluation,
+	}
+	jsonOut, _ := json.Marshal(result)
+	return common.ExecutionResult{Status: status, Output: string(jsonOut)}, nil
+}
+
+var _ integrations.IntegrationPlugin = (*VulnScanChecker)(nil)
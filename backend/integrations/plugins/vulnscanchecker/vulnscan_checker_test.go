@@ -0,0 +1,101 @@
+package vulnscanchecker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/integrations/plugintest"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/store"
+	"github.com/vdparikh/compliance-automation/backend/vulnscan"
+)
+
+type checkOutput struct {
+	Message       string              `json:"message"`
+	ReportID      string              `json:"report_id"`
+	ReportAgeDays int                 `json:"report_age_days"`
+	Evaluation    vulnscan.Evaluation `json:"evaluation"`
+}
+
+type fakeSource struct {
+	report   *models.VulnerabilityReport
+	findings []models.VulnerabilityFinding
+}
+
+func (f *fakeSource) GetLatestVulnerabilityReportByInstanceID(string) (*models.VulnerabilityReport, error) {
+	if f.report == nil {
+		return nil, store.ErrNotFound
+	}
+	return f.report, nil
+}
+
+func (f *fakeSource) GetVulnerabilityFindingsByReportID(string) ([]models.VulnerabilityFinding, error) {
+	return f.findings, nil
+}
+
+var now = time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+func runCheck(t *testing.T, src reportSource, params map[string]interface{}) (common.ExecutionResult, checkOutput) {
+	t.Helper()
+	checker := &VulnScanChecker{now: func() time.Time { return now }}
+	res, err := checker.evaluate(src, &models.CampaignTaskInstance{ID: "cti-1", Parameters: params})
+	require.NoError(t, err)
+	var out checkOutput
+	plugintest.Decode(t, res, &out)
+	return res, out
+}
+
+func newSource(reportAge time.Duration, firstSeen ...time.Duration) *fakeSource {
+	src := &fakeSource{report: &models.VulnerabilityReport{ID: "report-1", Format: vulnscan.FormatTrivy, CreatedAt: now.Add(-reportAge)}}
+	for _, age := range firstSeen {
+		src.findings = append(src.findings, models.VulnerabilityFinding{
+			Asset: "api:1.4", VulnerabilityID: "CVE-2024-0001", Severity: vulnscan.SeverityCritical, PackageName: "openssl", FirstSeenAt: now.Add(-age),
+		})
+	}
+	return src
+}
+
+const day = 24 * time.Hour
+
+func TestFindingsAgePastPolicy(t *testing.T) {
+	res, out := runCheck(t, newSource(day, 10*day), map[string]interface{}{})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+	assert.Equal(t, "report-1", out.ReportID)
+	assert.Equal(t, "critical:15, high:30", out.Evaluation.Policy)
+
+	res, out = runCheck(t, newSource(day, 20*day), map[string]interface{}{})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	require.Len(t, out.Evaluation.Violations, 1)
+	assert.Equal(t, 20, out.Evaluation.Violations[0].AgeDays)
+
+	res, _ = runCheck(t, newSource(day, 20*day), map[string]interface{}{"policy": "critical:30"})
+	assert.Equal(t, common.StatusSuccess, res.Status)
+}
+
+func TestStaleReportFails(t *testing.T) {
+	res, out := runCheck(t, newSource(10*day), map[string]interface{}{"maxReportAgeDays": float64(7)})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Equal(t, 10, out.ReportAgeDays)
+	assert.Equal(t, "latest report is 10 days old; at most 7 allowed", out.Message)
+}
+
+func TestNoReportFails(t *testing.T) {
+	res, out := runCheck(t, &fakeSource{}, map[string]interface{}{})
+	assert.Equal(t, common.StatusFailed, res.Status)
+	assert.Contains(t, out.Message, "No vulnerability report")
+}
+
+func TestInvalidPolicyIsAnError(t *testing.T) {
+	checker := New()
+	res, err := checker.evaluate(newSource(day), &models.CampaignTaskInstance{Parameters: map[string]interface{}{"policy": "critical:soon"}})
+	assert.Error(t, err)
+	assert.Equal(t, common.StatusError, res.Status)
+
+	res, err = checker.ExecuteCheck(common.CheckContext{TaskInstance: &models.CampaignTaskInstance{}}, CheckTypeKey_VulnerabilityPolicy)
+	assert.Error(t, err, "the store is required")
+	assert.Equal(t, common.StatusError, res.Status)
+}
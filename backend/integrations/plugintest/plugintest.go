@@ -0,0 +1,54 @@
+// Package plugintest holds helpers shared by the tests of integration
+// plugins.
+package plugintest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+)
+
+// Checker is the part of an integration plugin the helpers run.
+type Checker interface {
+	ExecuteCheck(ctx common.CheckContext, checkTypeKey string) (common.ExecutionResult, error)
+}
+
+// FindingsOutput is the output of plugins that report one finding per
+// resource checked.
+type FindingsOutput struct {
+	Message          string           `json:"message"`
+	ResourcesChecked int              `json:"resources_checked"`
+	ResourcesFailed  int              `json:"resources_failed"`
+	Findings         []common.Finding `json:"findings"`
+}
+
+// Run executes a check, fails the test if the plugin returns an error and
+// decodes the JSON output into out.
+func Run(t *testing.T, checker Checker, ctx common.CheckContext, checkTypeKey string, out interface{}) common.ExecutionResult {
+	t.Helper()
+	res, err := checker.ExecuteCheck(ctx, checkTypeKey)
+	require.NoError(t, err, res.Output)
+	Decode(t, res, out)
+	return res
+}
+
+// Decode unmarshals the JSON output of a check into out.
+func Decode(t *testing.T, res common.ExecutionResult, out interface{}) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal([]byte(res.Output), out), res.Output)
+}
+
+// FindingFor returns the finding for resource, failing the test if there is
+// none.
+func FindingFor(t *testing.T, findings []common.Finding, resource string) common.Finding {
+	t.Helper()
+	for _, f := range findings {
+		if f.Resource == resource {
+			return f
+		}
+	}
+	t.Fatalf("no finding for %s in %+v", resource, findings)
+	return common.Finding{}
+}
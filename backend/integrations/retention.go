@@ -0,0 +1,74 @@
+package integrations
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+)
+
+// RetentionPolicy bounds the execution history kept by the worker. A zero
+// age disables that part of the policy.
+type RetentionPolicy struct {
+	// CompactResultsAfter is the age after which identical consecutive
+	// successes of a task instance are folded into the first of their run.
+	CompactResultsAfter time.Duration
+	// PurgeExecutionsAfter is the age after which completed queue entries and
+	// their log lines are deleted. Stored results are not affected.
+	PurgeExecutionsAfter time.Duration
+	// Interval is how often the policy is applied.
+	Interval time.Duration
+}
+
+// DefaultRetentionPolicy compacts results after 30 days and purges queue
+// entries after 90, once a day.
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		CompactResultsAfter:  30 * 24 * time.Hour,
+		PurgeExecutionsAfter: 90 * 24 * time.Hour,
+		Interval:             24 * time.Hour,
+	}
+}
+
+// RunRetention applies the policy at start and then every Interval until ctx is done.
+func RunRetention(ctx context.Context, s WorkerStore, q queue.Queue, policy RetentionPolicy) {
+	if policy.Interval <= 0 || (policy.CompactResultsAfter <= 0 && policy.PurgeExecutionsAfter <= 0) {
+		return
+	}
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
+	for {
+		applyRetention(ctx, s, q, policy)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func applyRetention(ctx context.Context, s WorkerStore, q queue.Queue, policy RetentionPolicy) {
+	now := time.Now()
+	if policy.CompactResultsAfter > 0 {
+		removed, err := s.CompactCampaignTaskInstanceResults(now.Add(-policy.CompactResultsAfter), common.StatusSuccess)
+		if err != nil {
+			log.Printf("Retention: %v", err)
+		} else if removed > 0 {
+			log.Printf("Retention: compacted %d repeated successful results", removed)
+		}
+	}
+	if policy.PurgeExecutionsAfter > 0 {
+		cutoff := now.Add(-policy.PurgeExecutionsAfter)
+		purged, err := q.PurgeCompletedTasks(ctx, cutoff)
+		if err != nil {
+			log.Printf("Retention: failed to purge completed executions: %v", err)
+		} else if purged > 0 {
+			log.Printf("Retention: purged %d completed executions", purged)
+		}
+		if _, err := s.DeleteTaskExecutionLogsBefore(cutoff); err != nil {
+			log.Printf("Retention: %v", err)
+		}
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
-	"strconv"
 	"time"
 
 	"github.com/vdparikh/compliance-automation/backend/queue"
@@ -204,53 +202,6 @@ func (s *IntegrationService) processTask(ctx context.Context, task *queue.TaskEx
 	}
 }
 
-func (s *IntegrationService) executeFilePermissionCheck(task *queue.TaskExecutionRequest) (interface{}, error) {
-	filePath, ok := task.Parameters["file_path"].(string)
-	if !ok {
-		return nil, fmt.Errorf("file_path parameter is required and must be a string")
-	}
-
-	expectedPerms, ok := task.Parameters["expected_permissions"].(string)
-	if !ok {
-		return nil, fmt.Errorf("expected_permissions parameter is required and must be a string")
-	}
-
-	// Get file info
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file info: %w", err)
-	}
-
-	// Get file permissions
-	actualPerms := fileInfo.Mode().Perm()
-	actualPermsStr := fmt.Sprintf("%o", actualPerms)
-
-	// Convert expected permissions to octal
-	expectedPermsInt, err := strconv.ParseInt(expectedPerms, 8, 32)
-	if err != nil {
-		return nil, fmt.Errorf("invalid expected permissions format: %w", err)
-	}
-
-	// Compare permissions
-	success := actualPerms == os.FileMode(expectedPermsInt)
-
-	result := map[string]interface{}{
-		"message": fmt.Sprintf("File permission check %s", map[bool]string{true: "passed", false: "failed"}[success]),
-		"details": map[string]interface{}{
-			"file_path":            filePath,
-			"expected_permissions": expectedPerms,
-			"actual_permissions":   actualPermsStr,
-			"success":              success,
-		},
-	}
-
-	if !success {
-		return result, fmt.Errorf("file permissions mismatch: expected %s, got %s", expectedPerms, actualPermsStr)
-	}
-
-	return result, nil
-}
-
 func (s *IntegrationService) executeAWSIAMCheck(task *queue.TaskExecutionRequest) (interface{}, error) {
 	// TODO: Implement AWS IAM check logic
 	return nil, fmt.Errorf("AWS IAM check not implemented")
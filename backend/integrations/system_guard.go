@@ -0,0 +1,71 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+	"github.com/vdparikh/compliance-automation/backend/systemguard"
+)
+
+// guardExecution asks the system guard whether the task may run against the
+// connected system now. deferred means the task went back to the queue and
+// must not be processed further. A non-nil fastFail means the system's
+// circuit is open and the task should be recorded as failed with that error.
+func (s *TaskExecutionService) guardExecution(ctx context.Context, task *queue.TaskExecutionRequest, system *models.ConnectedSystem) (deferred bool, fastFail error) {
+	decision := s.guard.Admit(system.ID, system.SystemType)
+	if decision.Allowed {
+		return false, nil
+	}
+
+	var reason string
+	if decision.CircuitOpen {
+		reason = fmt.Sprintf("Circuit open for connected system %s after %d consecutive failures; next attempt after %s",
+			system.Name, decision.Failures, decision.RetryAfter.Format(time.RFC3339))
+		if decision.OnOpen == systemguard.OnOpenFail {
+			return false, fmt.Errorf("%s", reason)
+		}
+	} else {
+		reason = fmt.Sprintf("Rate limit reached for connected system %s; deferred until %s",
+			system.Name, decision.RetryAfter.Format(time.RFC3339))
+	}
+
+	if err := s.queue.DeferTask(ctx, task.ID, decision.RetryAfter, reason); err != nil {
+		log.Printf("Error deferring task %s: %v", task.ID, err)
+		return false, fmt.Errorf("%s (deferring the execution failed: %v)", reason, err)
+	}
+	log.Printf("Deferred task %s: %s", task.ID, reason)
+	return true, nil
+}
+
+// recordSystemOutcome feeds an execution's outcome to the circuit breaker and
+// updates the connected system's status when its circuit opens or closes.
+// Only errors the plugin marked with common.ErrSystemUnreachable count as
+// failures. Other errors, such as invalid task parameters, say nothing about
+// the system; a check that ran and found it non-compliant is a success here.
+func (s *TaskExecutionService) recordSystemOutcome(ctx context.Context, system *models.ConnectedSystem, cancelled bool, pluginErr error) {
+	if cancelled || ctx.Err() != nil || (pluginErr != nil && !errors.Is(pluginErr, common.ErrSystemUnreachable)) {
+		s.guard.Release(system.ID)
+		return
+	}
+
+	var status string
+	switch s.guard.Record(system.ID, system.SystemType, pluginErr != nil) {
+	case systemguard.Opened:
+		status = systemguard.SystemStatusCircuitOpen
+		log.Printf("Circuit opened for connected system %s (%s)", system.Name, system.ID)
+	case systemguard.Closed:
+		status = systemguard.SystemStatusConnected
+		log.Printf("Circuit closed for connected system %s (%s)", system.Name, system.ID)
+	default:
+		return
+	}
+	if err := s.store.UpdateConnectedSystemStatus(system.ID, time.Now(), status); err != nil {
+		log.Printf("Error updating status of connected system %s: %v", system.ID, err)
+	}
+}
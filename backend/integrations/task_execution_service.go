@@ -2,33 +2,38 @@ package integrations
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	"github.com/vdparikh/compliance-automation/backend/campaignstate"
 	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/policy"
 	"github.com/vdparikh/compliance-automation/backend/queue"
-	"github.com/vdparikh/compliance-automation/backend/store"
+	"github.com/vdparikh/compliance-automation/backend/systemguard"
 )
 
 // TaskExecutionService handles the execution of integration tasks from the queue
 type TaskExecutionService struct {
-	db             *sql.DB
 	queue          queue.Queue
-	store          *store.DBStore
+	store          WorkerStore
 	pluginRegistry PluginRegistry // Use the interface defined in the integrations package
+	guard          *systemguard.Guard
 }
 
 // NewTaskExecutionService creates a new task execution service
-func NewTaskExecutionService(db *sql.DB, queue queue.Queue, store *store.DBStore, pluginRegistry PluginRegistry) *TaskExecutionService {
+// with rate limits and circuit breakers per connected system configured by limits.
+func NewTaskExecutionService(queue queue.Queue, store WorkerStore, pluginRegistry PluginRegistry, limits systemguard.Config) *TaskExecutionService {
 	// executor.InitExecutors() // This is part of the old executor system and can be removed
 	return &TaskExecutionService{
-		db:             db,
 		queue:          queue,
 		store:          store,
 		pluginRegistry: pluginRegistry,
+		guard:          systemguard.New(limits),
 	}
 }
 
@@ -58,6 +63,11 @@ func (s *TaskExecutionService) Start(ctx context.Context) error {
 func (s *TaskExecutionService) processTask(goCtx context.Context, task *queue.TaskExecutionRequest) { // Renamed ctx to goCtx to avoid conflict
 	log.Printf("Processing task %s of type %s", task.ID, task.TaskType)
 
+	if task.Mode == queue.ModeTest {
+		s.processTestTask(goCtx, task)
+		return
+	}
+
 	// Create a result object for the queue
 	queueResult := &queue.TaskExecutionResult{
 		ID:          task.ID,
@@ -76,6 +86,16 @@ func (s *TaskExecutionService) processTask(goCtx context.Context, task *queue.Ta
 		return
 	}
 
+	// Completed and archived campaigns keep the results they were signed off with.
+	if status := s.frozenCampaignStatus(taskInstance.ID); status != "" {
+		queueResult.Status = common.StatusCancelled
+		queueResult.ErrorMessage = fmt.Sprintf("Campaign is %s; execution discarded", status)
+		if err := s.queue.UpdateTaskResult(goCtx, queueResult); err != nil {
+			log.Printf("Error updating task result in queue for task %s: %v", task.ID, err)
+		}
+		return
+	}
+
 	// Check if target (connected system) is set
 	if taskInstance.Target == nil || *taskInstance.Target == "" {
 		queueResult.Status = "failed"
@@ -88,6 +108,9 @@ func (s *TaskExecutionService) processTask(goCtx context.Context, task *queue.Ta
 
 	// Get the connected system
 	connectedSystem, err := s.store.GetConnectedSystemByID(*taskInstance.Target)
+	if err == nil && connectedSystem == nil {
+		err = fmt.Errorf("not found")
+	}
 	if err != nil {
 		queueResult.Status = "failed"
 		queueResult.ErrorMessage = fmt.Sprintf("Failed to get connected system %s: %v", *taskInstance.Target, err)
@@ -108,21 +131,46 @@ func (s *TaskExecutionService) processTask(goCtx context.Context, task *queue.Ta
 		return
 	}
 
+	deferred, circuitErr := s.guardExecution(goCtx, task, connectedSystem)
+	if deferred {
+		return
+	}
+
+	// The run context is cancelled when a user cancels the execution.
+	runCtx, stopRun := context.WithCancel(goCtx)
+	defer stopRun()
+	cancelRequested := s.watchForCancellation(runCtx, task.ID, stopRun)
+
 	// Create the check context for the plugin
 	checkCtx := common.CheckContext{
 		TaskInstance:    taskInstance,
 		ConnectedSystem: connectedSystem,
-		Store:           s.store,
-		StdContext:      goCtx,
+		Store:           newScopedPluginStore(s.store, taskInstance.ID),
+		StdContext:      runCtx,
+		Logger:          &storeExecutionLogger{store: s.store, executionID: task.ID.String()},
 	}
 
 	// Override task instance parameters with the ones from the execution request
 	taskInstance.Parameters = task.Parameters
 
-	// Execute the task
-	pluginExecResult, pluginErr := plugin.ExecuteCheck(checkCtx, task.TaskType)
+	// Execute the task, unless the system's circuit is open
+	var pluginExecResult common.ExecutionResult
+	var pluginErr error
+	if circuitErr != nil {
+		checkCtx.Logf(common.LogLevelError, "%v", circuitErr)
+		pluginExecResult, pluginErr = common.ExecutionResult{Status: common.StatusError}, circuitErr
+	} else {
+		checkCtx.Logf(common.LogLevelInfo, "Running %s against %s", task.TaskType, connectedSystem.Name)
+		pluginExecResult, pluginErr = plugin.ExecuteCheck(checkCtx, task.TaskType)
+		s.recordSystemOutcome(goCtx, connectedSystem, cancelRequested.Load(), pluginErr)
+	}
 
-	if pluginErr != nil {
+	if cancelRequested.Load() {
+		queueResult.Status = common.StatusCancelled
+		queueResult.ErrorMessage = "Execution cancelled by user"
+		pluginErr = errors.New(queueResult.ErrorMessage) // Skips snapshots and the result policy below
+		checkCtx.Logf(common.LogLevelWarn, "Execution cancelled by user")
+	} else if pluginErr != nil {
 		queueResult.Status = common.StatusFailed // Definitive status for the queue
 		queueResult.ErrorMessage = pluginErr.Error()
 		log.Printf("Task execution failed for task %s: %v", task.ID, pluginErr)
@@ -132,35 +180,34 @@ func (s *TaskExecutionService) processTask(goCtx context.Context, task *queue.Ta
 	} else {
 		queueResult.Status = pluginExecResult.Status // Use status from plugin if no error
 	}
+	stopRun()
+
+	// The campaign may have been completed or archived while the check ran.
+	frozenStatus := s.frozenCampaignStatus(taskInstance.ID)
+	if frozenStatus != "" {
+		queueResult.Status = common.StatusCancelled
+		queueResult.ErrorMessage = fmt.Sprintf("Campaign is %s; execution discarded", frozenStatus)
+		pluginErr = errors.New(queueResult.ErrorMessage) // Skips snapshots and the result policy below
+		checkCtx.Logf(common.LogLevelWarn, "%s", queueResult.ErrorMessage)
+	}
 
 	// Ensure we have valid JSON output
 	// Construct the JSON output for storage
 	var resultJSON []byte
-	var outputForStorage map[string]interface{}
+	outputForStorage := parsePluginOutput(task.ID.String(), pluginExecResult)
 
-	if pluginExecResult.Output != "" {
-		var pluginOutputObj interface{}
-		if unmarshalErr := json.Unmarshal([]byte(pluginExecResult.Output), &pluginOutputObj); unmarshalErr != nil {
-			log.Printf("Plugin output for task %s was not valid JSON: %v. Raw: %s", task.ID, unmarshalErr, pluginExecResult.Output)
-			outputForStorage = map[string]interface{}{
-				"parsing_error":     "Plugin output was not valid JSON: " + unmarshalErr.Error(),
-				"raw_plugin_output": pluginExecResult.Output,
-				"plugin_status":     pluginExecResult.Status, // Status reported by plugin
-			}
-		} else {
-			// If plugin output is already a map, use it as base
-			if mapOutput, ok := pluginOutputObj.(map[string]interface{}); ok {
-				outputForStorage = mapOutput
-			} else { // Otherwise, wrap it
-				outputForStorage = map[string]interface{}{"data": pluginOutputObj}
-			}
-			// Ensure the plugin's reported status is in the output
-			if _, ok := outputForStorage["plugin_status"]; !ok {
-				outputForStorage["plugin_status"] = pluginExecResult.Status
-			}
+	if pluginErr == nil && pluginExecResult.Snapshot != nil {
+		if summary := s.recordConfigSnapshot(taskInstance, connectedSystem.ID, task.TaskType, task.ID.String(), pluginExecResult.Snapshot); summary != nil {
+			outputForStorage["config_drift"] = summary
+		}
+	}
+
+	// A master task result policy, when present, replaces the plugin's verdict.
+	if pluginErr == nil && pluginExecResult.Status != common.StatusError {
+		if status, errMsg := s.applyResultPolicy(goCtx, taskInstance, pluginExecResult.Status, outputForStorage); status != "" {
+			queueResult.Status = status
+			queueResult.ErrorMessage = errMsg
 		}
-	} else {
-		outputForStorage = make(map[string]interface{})
 	}
 
 	// Add overall execution status and error message (if any) to the stored output
@@ -192,89 +239,131 @@ func (s *TaskExecutionService) processTask(goCtx context.Context, task *queue.Ta
 	log.Printf("Final JSON to be stored for task %s: %s", task.ID, string(resultJSON))
 	log.Printf("Queue result status for task %s: %s", task.ID, queueResult.Status)
 
-	// Update the task result in the queue
+	if queueResult.Status != common.StatusCancelled {
+		checkCtx.Logf(common.LogLevelInfo, "Finished with status %s", queueResult.Status)
+	}
+
+	if frozenStatus == "" {
+		s.storeTaskInstanceResult(taskInstance, task, plugin, queueResult, resultJSON)
+	}
+
+	// Update the task result in the queue last: it ends the worker's lease on
+	// the execution, and with it access to the task instance.
 	if err := s.queue.UpdateTaskResult(goCtx, queueResult); err != nil {
 		log.Printf("Error updating task result in queue for task %s: %v", task.ID, err)
-		return
 	}
+}
 
+// storeTaskInstanceResult records an execution's outcome on its task instance.
+func (s *TaskExecutionService) storeTaskInstanceResult(taskInstance *models.CampaignTaskInstance, task *queue.TaskExecutionRequest, plugin IntegrationPlugin, queueResult *queue.TaskExecutionResult, resultJSON []byte) {
 	// Update the task instance status
 	now := time.Now()
-	taskInstance.LastCheckedAt = &now
-	taskInstance.LastCheckStatus = &queueResult.Status
-	if err := s.store.UpdateCampaignTaskInstance(taskInstance); err != nil {
+	if err := s.store.UpdateCampaignTaskInstanceCheckStatus(taskInstance.ID, now, queueResult.Status); err != nil {
 		log.Printf("Error updating task instance status in DB for task %s: %v", taskInstance.ID, err)
 	}
 
 	// Insert a new row into campaign_task_instance_results
-	insertQuery := `
-		INSERT INTO campaign_task_instance_results (
-			campaign_task_instance_id, task_execution_id, executed_by_user_id, timestamp, status, output
-		) VALUES ($1, $2, $3, $4, $5, $6)
-	`
-	_, err = s.db.ExecContext(goCtx, insertQuery,
-		task.TaskInstanceID.String(),
-		task.ID.String(),
-		nil, // executed_by_user_id (set to nil if not available)
-		now,
-		queueResult.Status,
-		string(resultJSON), // Use the validated JSON
-	)
-	if err != nil {
+	pluginID, pluginVersion, schemaVersion := pluginProvenance(plugin, task.TaskType)
+	executionID := task.ID.String()
+	result := &models.CampaignTaskInstanceResult{
+		CampaignTaskInstanceID: taskInstance.ID,
+		TaskExecutionID:        &executionID,
+		Timestamp:              now,
+		Status:                 queueResult.Status,
+		Output:                 string(resultJSON), // Use the validated JSON
+		PluginID:               &pluginID,
+		PluginVersion:          &pluginVersion,
+		CheckTypeSchemaVersion: &schemaVersion,
+	}
+	if err := s.store.CreateCampaignTaskInstanceResult(result); err != nil {
 		log.Printf("Error inserting into campaign_task_instance_results for task %s: %v", task.ID, err)
 	}
 }
 
-// executeFilePermissionCheck and other specific check methods can be removed from here
-// if their logic is now within plugins.
-/*
-func (s *TaskExecutionService) executeFilePermissionCheck(task *queue.TaskExecutionRequest) (interface{}, error) {
-	filePath, ok := task.Parameters["file_path"].(string)
-	if !ok {
-		return nil, fmt.Errorf("file_path parameter is required and must be a string")
+// frozenCampaignStatus returns the status of the task instance's campaign if
+// it is completed or archived, and "" otherwise.
+func (s *TaskExecutionService) frozenCampaignStatus(ctiID string) string {
+	status, err := s.store.GetCampaignStatusForTaskInstance(ctiID)
+	if err != nil {
+		log.Printf("Error checking campaign status of task instance %s: %v", ctiID, err)
+		return ""
 	}
-
-	expectedPerms, ok := task.Parameters["expected_permissions"].(string)
-	if !ok {
-		return nil, fmt.Errorf("expected_permissions parameter is required and must be a string")
+	if campaignstate.IsFrozen(status) {
+		return status
 	}
+	return ""
+}
 
-	// Get file info
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file info: %w", err)
-	}
+// pluginProvenance identifies the plugin version and check type schema
+// version behind a result.
+func pluginProvenance(plugin IntegrationPlugin, checkTypeKey string) (string, string, int) {
+	config := plugin.GetCheckTypeConfigurations()[checkTypeKey]
+	return plugin.ID(), plugin.Version(), config.CurrentSchemaVersion()
+}
 
-	// Get file permissions
-	actualPerms := fileInfo.Mode().Perm()
-	actualPermsStr := fmt.Sprintf("%o", actualPerms)
+// parsePluginOutput turns a plugin's raw output into the map stored as the
+// result, wrapping output that is not a JSON object and always recording the
+// plugin's own status.
+func parsePluginOutput(executionID string, pluginExecResult common.ExecutionResult) map[string]interface{} {
+	if pluginExecResult.Output == "" {
+		return make(map[string]interface{})
+	}
+	var outputForStorage map[string]interface{}
+	var pluginOutputObj interface{}
+	if unmarshalErr := json.Unmarshal([]byte(pluginExecResult.Output), &pluginOutputObj); unmarshalErr != nil {
+		log.Printf("Plugin output for task %s was not valid JSON: %v. Raw: %s", executionID, unmarshalErr, pluginExecResult.Output)
+		return map[string]interface{}{
+			"parsing_error":     "Plugin output was not valid JSON: " + unmarshalErr.Error(),
+			"raw_plugin_output": pluginExecResult.Output,
+			"plugin_status":     pluginExecResult.Status, // Status reported by plugin
+		}
+	}
+	// If plugin output is already a map, use it as base
+	if mapOutput, ok := pluginOutputObj.(map[string]interface{}); ok {
+		outputForStorage = mapOutput
+	} else { // Otherwise, wrap it
+		outputForStorage = map[string]interface{}{"data": pluginOutputObj}
+	}
+	// Ensure the plugin's reported status is in the output
+	if _, ok := outputForStorage["plugin_status"]; !ok {
+		outputForStorage["plugin_status"] = pluginExecResult.Status
+	}
+	return outputForStorage
+}
 
-	// Convert expected permissions to octal
-	expectedPermsInt, err := strconv.ParseInt(expectedPerms, 8, 32)
+// applyResultPolicy evaluates the master task's result policy, if it has one,
+// and records the decision in output. It returns an empty status when no
+// policy applies.
+func (s *TaskExecutionService) applyResultPolicy(ctx context.Context, taskInstance *models.CampaignTaskInstance, pluginStatus string, output map[string]interface{}) (string, string) {
+	if taskInstance.MasterTaskID == nil {
+		return "", ""
+	}
+	masterTask, err := s.store.GetTaskByID(*taskInstance.MasterTaskID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid expected permissions format: %w", err)
+		log.Printf("Error loading master task %s for result policy: %v", *taskInstance.MasterTaskID, err)
+		return common.StatusError, "Failed to load result policy: " + err.Error()
 	}
-
-	// Compare permissions
-	success := actualPerms == os.FileMode(expectedPermsInt)
-
-	result := map[string]interface{}{
-		"message": fmt.Sprintf("File permission check %s", map[bool]string{true: "passed", false: "failed"}[success]),
-		"details": map[string]interface{}{
-			"file_path":            filePath,
-			"expected_permissions": expectedPerms,
-			"actual_permissions":   actualPermsStr,
-			"success":              success,
-		},
+	if masterTask == nil || masterTask.ResultPolicy == nil || strings.TrimSpace(*masterTask.ResultPolicy) == "" {
+		return "", ""
 	}
-
-	if !success {
-		return result, fmt.Errorf("file permissions mismatch: expected %s, got %s", expectedPerms, actualPermsStr)
+	language := ""
+	if masterTask.ResultPolicyLanguage != nil {
+		language = *masterTask.ResultPolicyLanguage
+	}
+	if _, invalid := output["parsing_error"]; invalid {
+		msg := "Result policy not evaluated: plugin output was not valid JSON"
+		output["policy_evaluation"] = map[string]interface{}{"language": language, "error": msg}
+		return common.StatusError, msg
 	}
 
-	return result, nil
+	decision, err := policy.Evaluate(ctx, language, *masterTask.ResultPolicy, policy.NewInput(output, pluginStatus, taskInstance.Parameters))
+	if err != nil {
+		output["policy_evaluation"] = map[string]interface{}{"language": language, "error": err.Error()}
+		return common.StatusError, "Result policy evaluation failed: " + err.Error()
+	}
+	output["policy_evaluation"] = decision
+	return decision.Status, ""
 }
-*/
 
 /*
 func (s *TaskExecutionService) executeAWSIAMCheck(task *queue.TaskExecutionRequest) (interface{}, error) {
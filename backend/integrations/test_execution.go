@@ -0,0 +1,138 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+)
+
+// TestExecutionTimeout bounds how long a test execution may run in the worker.
+const TestExecutionTimeout = 60 * time.Second
+
+// processTestTask runs a check for the master task editor. The plugin sees a
+// transient task instance built from the request, and the outcome is written
+// only to the queue row: no result, snapshot or task status is stored.
+func (s *TaskExecutionService) processTestTask(goCtx context.Context, task *queue.TaskExecutionRequest) {
+	queueResult := &queue.TaskExecutionResult{ID: task.ID}
+	finish := func() {
+		queueResult.CompletedAt = time.Now()
+		if err := s.queue.UpdateTaskResult(goCtx, queueResult); err != nil {
+			log.Printf("Error updating test execution result in queue for task %s: %v", task.ID, err)
+		}
+	}
+
+	if task.ConnectedSystemID == nil {
+		queueResult.Status = common.StatusFailed
+		queueResult.ErrorMessage = "No target (connected system) given for this test execution"
+		finish()
+		return
+	}
+	connectedSystem, err := s.store.GetConnectedSystemByID(task.ConnectedSystemID.String())
+	if err == nil && connectedSystem == nil {
+		err = fmt.Errorf("not found")
+	}
+	if err != nil {
+		queueResult.Status = common.StatusFailed
+		queueResult.ErrorMessage = fmt.Sprintf("Failed to get connected system %s: %v", task.ConnectedSystemID, err)
+		finish()
+		return
+	}
+	plugin, exists := s.pluginRegistry.GetPluginForCheckType(task.TaskType)
+	if !exists {
+		queueResult.Status = common.StatusFailed
+		queueResult.ErrorMessage = fmt.Sprintf("No plugin found for task type (check type key): %s", task.TaskType)
+		finish()
+		return
+	}
+
+	systemID := connectedSystem.ID
+	checkType := task.TaskType
+	taskInstance := &models.CampaignTaskInstance{
+		ID:         "test-" + task.ID.String(),
+		Title:      "Test execution",
+		CheckType:  &checkType,
+		Target:     &systemID,
+		Parameters: task.Parameters,
+	}
+	if task.MasterTaskID != nil {
+		masterTaskID := task.MasterTaskID.String()
+		taskInstance.MasterTaskID = &masterTaskID
+	}
+
+	ctx, cancel := context.WithTimeout(goCtx, TestExecutionTimeout)
+	defer cancel()
+	checkCtx := common.CheckContext{
+		TaskInstance:    taskInstance,
+		ConnectedSystem: connectedSystem,
+		Store:           newScopedPluginStore(s.store, taskInstance.ID),
+		StdContext:      ctx,
+	}
+
+	// Plugins that ignore the context must not hold the worker past the timeout.
+	type outcome struct {
+		result common.ExecutionResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		res, err := plugin.ExecuteCheck(checkCtx, task.TaskType)
+		done <- outcome{res, err}
+	}()
+	var pluginExecResult common.ExecutionResult
+	var pluginErr error
+	select {
+	case o := <-done:
+		pluginExecResult, pluginErr = o.result, o.err
+	case <-ctx.Done():
+		pluginExecResult = common.ExecutionResult{Status: common.StatusError}
+		pluginErr = fmt.Errorf("test execution timed out after %s", TestExecutionTimeout)
+	}
+
+	if pluginErr != nil {
+		queueResult.Status = common.StatusFailed
+		queueResult.ErrorMessage = pluginErr.Error()
+	} else {
+		queueResult.Status = pluginExecResult.Status
+	}
+
+	output := parsePluginOutput(task.ID.String(), pluginExecResult)
+	pluginID, pluginVersion, schemaVersion := pluginProvenance(plugin, task.TaskType)
+	output["provenance"] = map[string]interface{}{
+		"plugin_id":                 pluginID,
+		"plugin_version":            pluginVersion,
+		"check_type_schema_version": schemaVersion,
+	}
+	if pluginExecResult.Snapshot != nil {
+		output["snapshot_resource_count"] = len(pluginExecResult.Snapshot)
+	}
+	if pluginErr == nil && pluginExecResult.Status != common.StatusError {
+		if status, errMsg := s.applyResultPolicy(ctx, taskInstance, pluginExecResult.Status, output); status != "" {
+			queueResult.Status = status
+			queueResult.ErrorMessage = errMsg
+		}
+	}
+	output["overall_execution_status"] = queueResult.Status
+	if queueResult.ErrorMessage != "" {
+		output["execution_error_message"] = queueResult.ErrorMessage
+	}
+	if pluginExecResult.Status != "" {
+		if _, ok := output["plugin_status"]; !ok {
+			output["plugin_status"] = pluginExecResult.Status
+		}
+	}
+
+	resultJSON, err := json.Marshal(output)
+	if err != nil {
+		queueResult.Status = common.StatusError
+		queueResult.ErrorMessage = "Failed to marshal execution output: " + err.Error()
+	} else {
+		queueResult.Result = resultJSON
+	}
+	finish()
+}
@@ -0,0 +1,81 @@
+package integrations
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/store"
+)
+
+// WorkerStore is the data access of the integrations worker. *store.DBStore
+// implements it directly; internalapi.Client implements it over the
+// authenticated internal API, so the worker needs no database credentials.
+type WorkerStore interface {
+	common.PluginStore
+
+	GetCampaignTaskInstanceByID(ctiID string) (*models.CampaignTaskInstance, error)
+	GetCampaignStatusForTaskInstance(ctiID string) (string, error)
+	UpdateCampaignTaskInstanceCheckStatus(ctiID string, checkedAt time.Time, status string) error
+	CreateCampaignTaskInstanceResult(result *models.CampaignTaskInstanceResult) error
+	GetConnectedSystemByID(id string) (*models.ConnectedSystem, error)
+	UpdateConnectedSystemStatus(id string, lastCheckedAt time.Time, status string) error
+	GetTaskByID(taskID string) (*models.Task, error)
+	AppendTaskExecutionLog(executionID, level, message string) error
+
+	CreateConfigSnapshot(snapshot *models.ConfigSnapshot) error
+	GetPreviousConfigSnapshot(current *models.ConfigSnapshot) (*models.ConfigSnapshot, error)
+	GetRequirementIDsForTaskInstance(instanceID string) ([]string, error)
+	CreateConfigDriftEvents(events []models.ConfigDriftEvent) error
+
+	CompactCampaignTaskInstanceResults(before time.Time, successStatus string) (int64, error)
+	DeleteTaskExecutionLogsBefore(before time.Time) (int64, error)
+
+	GetActiveCheckTypeConfigurations() (map[string]models.CheckTypeConfiguration, error)
+	CreateOrUpdateRegisteredPlugin(pluginID string, pluginName string, version string, checkConfigs map[string]models.CheckTypeConfiguration) error
+	SetRegisteredPluginActiveStatus(pluginID string, isActive bool) error
+}
+
+// scopedPluginStore limits a plugin to the data of the task instance it runs
+// for: the latest report of that instance and the findings of that report.
+type scopedPluginStore struct {
+	store      common.PluginStore
+	instanceID string
+
+	mu        sync.Mutex
+	reportIDs map[string]bool
+}
+
+func newScopedPluginStore(store common.PluginStore, instanceID string) *scopedPluginStore {
+	return &scopedPluginStore{store: store, instanceID: instanceID, reportIDs: map[string]bool{}}
+}
+
+func (s *scopedPluginStore) GetLatestVulnerabilityReportByInstanceID(instanceID string) (*models.VulnerabilityReport, error) {
+	if instanceID != s.instanceID {
+		return nil, fmt.Errorf("plugins may only read data of task instance %s", s.instanceID)
+	}
+	report, err := s.store.GetLatestVulnerabilityReportByInstanceID(instanceID)
+	if err == nil && report != nil {
+		s.mu.Lock()
+		s.reportIDs[report.ID] = true
+		s.mu.Unlock()
+	}
+	return report, err
+}
+
+func (s *scopedPluginStore) GetVulnerabilityFindingsByReportID(reportID string) ([]models.VulnerabilityFinding, error) {
+	s.mu.Lock()
+	allowed := s.reportIDs[reportID]
+	s.mu.Unlock()
+	if !allowed {
+		return nil, fmt.Errorf("plugins may only read reports of task instance %s", s.instanceID)
+	}
+	return s.store.GetVulnerabilityFindingsByReportID(reportID)
+}
+
+var (
+	_ common.PluginStore = (*scopedPluginStore)(nil)
+	_ WorkerStore        = (*store.DBStore)(nil)
+)
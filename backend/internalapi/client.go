@@ -0,0 +1,245 @@
+package internalapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+	"github.com/vdparikh/compliance-automation/backend/services"
+	"github.com/vdparikh/compliance-automation/backend/store"
+)
+
+// ErrNotAvailable is returned for operations the worker may not perform.
+var ErrNotAvailable = errors.New("operation is not available through the internal API")
+
+const clientTimeout = 30 * time.Second
+
+// Client is the worker's side of the internal API. It implements
+// integrations.WorkerStore, and Queue returns its queue.Queue.
+type Client struct {
+	baseURL string
+	secret  []byte
+	http    *http.Client
+}
+
+// NewClient returns a Client for the internal API at baseURL. tlsConfig may
+// carry the worker's client certificate; nil uses the default TLS settings.
+func NewClient(baseURL string, secret []byte, tlsConfig *tls.Config) (*Client, error) {
+	if err := checkSecret(secret); err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
+		http:    &http.Client{Transport: transport, Timeout: clientTimeout},
+	}, nil
+}
+
+// call invokes method with args and decodes its JSON result into out, if not nil.
+func (c *Client) call(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	body, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("internal API %s: failed to encode arguments: %w", method, err)
+	}
+	token, err := SignRequest(c.secret, WorkerIdentity, method, body)
+	if err != nil {
+		return fmt.Errorf("internal API %s: failed to sign request: %w", method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/internal/v1/"+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("internal API %s: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("internal API %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("internal API %s: failed to read response: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr errorResponse
+		if json.Unmarshal(respBody, &apiErr) != nil || apiErr.Error == "" {
+			apiErr.Error = resp.Status
+		}
+		switch apiErr.Code {
+		case codeNotFound:
+			return fmt.Errorf("internal API %s: %s: %w", method, apiErr.Error, store.ErrNotFound)
+		case codeNoRows:
+			return fmt.Errorf("internal API %s: %s: %w", method, apiErr.Error, sql.ErrNoRows)
+		}
+		return fmt.Errorf("internal API %s: %s", method, apiErr.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("internal API %s: failed to decode response: %w", method, err)
+	}
+	return nil
+}
+
+func (c *Client) do(method string, out interface{}, args ...interface{}) error {
+	return c.call(context.Background(), method, out, args...)
+}
+
+func (c *Client) GetCampaignTaskInstanceByID(ctiID string) (*models.CampaignTaskInstance, error) {
+	var cti *models.CampaignTaskInstance
+	return cti, c.do("GetCampaignTaskInstanceByID", &cti, ctiID)
+}
+
+func (c *Client) GetCampaignStatusForTaskInstance(ctiID string) (string, error) {
+	var status string
+	return status, c.do("GetCampaignStatusForTaskInstance", &status, ctiID)
+}
+
+func (c *Client) UpdateCampaignTaskInstanceCheckStatus(ctiID string, checkedAt time.Time, status string) error {
+	return c.do("UpdateCampaignTaskInstanceCheckStatus", nil, ctiID, checkedAt, status)
+}
+
+func (c *Client) CreateCampaignTaskInstanceResult(result *models.CampaignTaskInstanceResult) error {
+	return c.do("CreateCampaignTaskInstanceResult", result, result)
+}
+
+func (c *Client) GetConnectedSystemByID(id string) (*models.ConnectedSystem, error) {
+	var system *models.ConnectedSystem
+	return system, c.do("GetConnectedSystemByID", &system, id)
+}
+
+func (c *Client) UpdateConnectedSystemStatus(id string, lastCheckedAt time.Time, status string) error {
+	return c.do("UpdateConnectedSystemStatus", nil, id, lastCheckedAt, status)
+}
+
+func (c *Client) GetTaskByID(taskID string) (*models.Task, error) {
+	var task *models.Task
+	return task, c.do("GetTaskByID", &task, taskID)
+}
+
+func (c *Client) AppendTaskExecutionLog(executionID, level, message string) error {
+	return c.do("AppendTaskExecutionLog", nil, executionID, level, message)
+}
+
+func (c *Client) CreateConfigSnapshot(snapshot *models.ConfigSnapshot) error {
+	return c.do("CreateConfigSnapshot", snapshot, snapshot)
+}
+
+func (c *Client) GetPreviousConfigSnapshot(current *models.ConfigSnapshot) (*models.ConfigSnapshot, error) {
+	var snapshot *models.ConfigSnapshot
+	return snapshot, c.do("GetPreviousConfigSnapshot", &snapshot, current)
+}
+
+func (c *Client) GetRequirementIDsForTaskInstance(instanceID string) ([]string, error) {
+	var ids []string
+	return ids, c.do("GetRequirementIDsForTaskInstance", &ids, instanceID)
+}
+
+func (c *Client) CreateConfigDriftEvents(events []models.ConfigDriftEvent) error {
+	return c.do("CreateConfigDriftEvents", nil, events)
+}
+
+func (c *Client) CompactCampaignTaskInstanceResults(before time.Time, successStatus string) (int64, error) {
+	var n int64
+	return n, c.do("CompactCampaignTaskInstanceResults", &n, before, successStatus)
+}
+
+func (c *Client) DeleteTaskExecutionLogsBefore(before time.Time) (int64, error) {
+	var n int64
+	return n, c.do("DeleteTaskExecutionLogsBefore", &n, before)
+}
+
+func (c *Client) GetActiveCheckTypeConfigurations() (map[string]models.CheckTypeConfiguration, error) {
+	var configs map[string]models.CheckTypeConfiguration
+	return configs, c.do("GetActiveCheckTypeConfigurations", &configs)
+}
+
+func (c *Client) CreateOrUpdateRegisteredPlugin(pluginID string, pluginName string, version string, checkConfigs map[string]models.CheckTypeConfiguration) error {
+	return c.do("CreateOrUpdateRegisteredPlugin", nil, pluginID, pluginName, version, checkConfigs)
+}
+
+// SetRegisteredPluginActiveStatus is left to the API server: the worker only
+// registers the plugins it runs.
+func (c *Client) SetRegisteredPluginActiveStatus(pluginID string, isActive bool) error {
+	return ErrNotAvailable
+}
+
+func (c *Client) GetLatestVulnerabilityReportByInstanceID(instanceID string) (*models.VulnerabilityReport, error) {
+	var report *models.VulnerabilityReport
+	return report, c.do("GetLatestVulnerabilityReportByInstanceID", &report, instanceID)
+}
+
+func (c *Client) GetVulnerabilityFindingsByReportID(reportID string) ([]models.VulnerabilityFinding, error) {
+	var findings []models.VulnerabilityFinding
+	return findings, c.do("GetVulnerabilityFindingsByReportID", &findings, reportID)
+}
+
+// Queue returns the task queue as seen by the worker. Enqueueing and
+// cancelling are left to the API server.
+func (c *Client) Queue() queue.Queue {
+	return &queueClient{c}
+}
+
+type queueClient struct{ c *Client }
+
+func (q *queueClient) EnqueueTask(ctx context.Context, request *queue.TaskExecutionRequest) error {
+	return ErrNotAvailable
+}
+
+func (q *queueClient) DequeueTask(ctx context.Context) (*queue.TaskExecutionRequest, error) {
+	var task *queue.TaskExecutionRequest
+	return task, q.c.call(ctx, "DequeueTask", &task)
+}
+
+func (q *queueClient) UpdateTaskResult(ctx context.Context, result *queue.TaskExecutionResult) error {
+	return q.c.call(ctx, "UpdateTaskResult", nil, result)
+}
+
+func (q *queueClient) GetTaskStatus(ctx context.Context, taskID uuid.UUID) (*queue.TaskExecutionRequest, error) {
+	var task *queue.TaskExecutionRequest
+	return task, q.c.call(ctx, "GetTaskStatus", &task, taskID)
+}
+
+func (q *queueClient) CancelTask(ctx context.Context, taskID uuid.UUID) (string, error) {
+	return "", ErrNotAvailable
+}
+
+func (q *queueClient) DeferTask(ctx context.Context, taskID uuid.UUID, until time.Time, reason string) error {
+	return q.c.call(ctx, "DeferTask", nil, taskID, until, reason)
+}
+
+func (q *queueClient) LeasedTasks(ctx context.Context) ([]*queue.TaskExecutionRequest, error) {
+	return nil, ErrNotAvailable
+}
+
+func (q *queueClient) PurgeCompletedTasks(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	return n, q.c.call(ctx, "PurgeCompletedTasks", &n, before)
+}
+
+var (
+	_ integrations.WorkerStore = (*Client)(nil)
+	_ services.RegistryStore   = (*Client)(nil)
+	_ queue.Queue              = (*queueClient)(nil)
+)
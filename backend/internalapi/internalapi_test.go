@@ -0,0 +1,213 @@
+package internalapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+	"github.com/vdparikh/compliance-automation/backend/store"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// fakeStore implements the few WorkerStore methods the tests call; any other
+// call panics on the nil embedded interface.
+type fakeStore struct {
+	integrations.WorkerStore
+	statuses map[string]string
+	results  []models.CampaignTaskInstanceResult
+	owners   map[string]string
+}
+
+func (f *fakeStore) GetCheckTypePluginIDs() (map[string]string, error) {
+	return f.owners, nil
+}
+
+func (f *fakeStore) CreateOrUpdateRegisteredPlugin(pluginID string, pluginName string, version string, checkConfigs map[string]models.CheckTypeConfiguration) error {
+	for key := range checkConfigs {
+		f.owners[key] = pluginID
+	}
+	return nil
+}
+
+func (f *fakeStore) GetTaskByID(taskID string) (*models.Task, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) GetCampaignTaskInstanceByID(ctiID string) (*models.CampaignTaskInstance, error) {
+	if ctiID != leasedInstanceID.String() {
+		return nil, store.ErrNotFound
+	}
+	target := "system-1"
+	return &models.CampaignTaskInstance{ID: ctiID, Target: &target}, nil
+}
+
+func (f *fakeStore) GetConnectedSystemByID(id string) (*models.ConnectedSystem, error) {
+	return &models.ConnectedSystem{ID: id}, nil
+}
+
+func (f *fakeStore) UpdateCampaignTaskInstanceCheckStatus(ctiID string, checkedAt time.Time, status string) error {
+	f.statuses[ctiID] = status
+	return nil
+}
+
+func (f *fakeStore) CreateCampaignTaskInstanceResult(result *models.CampaignTaskInstanceResult) error {
+	result.ID = "result-1"
+	f.results = append(f.results, *result)
+	return nil
+}
+
+// The fake queue has one execution leased, for leasedInstanceID.
+var (
+	leasedExecutionID = uuid.MustParse("6f1c2f4e-1f5a-4c61-9d36-0d2b8f1f7a01")
+	leasedInstanceID  = uuid.MustParse("9a4d6b3c-8e2f-4b7a-a1c5-3e9f0d2c6b02")
+)
+
+type fakeQueue struct {
+	queue.Queue
+	deferred map[uuid.UUID]string
+}
+
+func (f *fakeQueue) LeasedTasks(ctx context.Context) ([]*queue.TaskExecutionRequest, error) {
+	return []*queue.TaskExecutionRequest{{ID: leasedExecutionID, TaskInstanceID: leasedInstanceID, Mode: queue.ModeRun}}, nil
+}
+
+func (f *fakeQueue) DeferTask(ctx context.Context, taskID uuid.UUID, until time.Time, reason string) error {
+	f.deferred[taskID] = reason
+	return nil
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeStore, *fakeQueue) {
+	t.Helper()
+	fs := &fakeStore{statuses: map[string]string{}, owners: map[string]string{}}
+	fq := &fakeQueue{deferred: map[uuid.UUID]string{}}
+	server, err := NewServer(fs, fq, testSecret, "")
+	require.NoError(t, err)
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	client, err := NewClient(ts.URL, testSecret, nil)
+	require.NoError(t, err)
+	return client, fs, fq
+}
+
+func TestSignAndVerifyRequest(t *testing.T) {
+	body := []byte(`["task-1"]`)
+	token, err := SignRequest(testSecret, WorkerIdentity, "GetTaskByID", body)
+	require.NoError(t, err)
+
+	claims, err := VerifyRequest(testSecret, token, "GetTaskByID", body)
+	require.NoError(t, err)
+	assert.Equal(t, WorkerIdentity, claims.Subject)
+
+	_, err = VerifyRequest(testSecret, token, "GetTaskByID", []byte(`["task-2"]`))
+	assert.Error(t, err, "tampered body")
+
+	_, err = VerifyRequest(testSecret, token, "CreateConfigDriftEvents", body)
+	assert.Error(t, err, "wrong method")
+
+	_, err = VerifyRequest([]byte("fedcba9876543210fedcba9876543210"), token, "GetTaskByID", body)
+	assert.Error(t, err, "wrong secret")
+
+	_, err = NewClient("http://localhost", []byte("short"), nil)
+	assert.Error(t, err)
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	client, fs, fq := newTestClient(t)
+
+	task, err := client.GetTaskByID("task-1")
+	require.NoError(t, err)
+	assert.Nil(t, task)
+
+	cti, err := client.GetCampaignTaskInstanceByID(leasedInstanceID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "system-1", *cti.Target)
+
+	require.NoError(t, client.UpdateCampaignTaskInstanceCheckStatus(leasedInstanceID.String(), time.Now(), "Success"))
+	assert.Equal(t, "Success", fs.statuses[leasedInstanceID.String()])
+
+	userID := "user-1"
+	result := &models.CampaignTaskInstanceResult{CampaignTaskInstanceID: leasedInstanceID.String(), ExecutedByUserID: &userID, Status: "Success"}
+	require.NoError(t, client.CreateCampaignTaskInstanceResult(result))
+	assert.Equal(t, "result-1", result.ID)
+	require.Len(t, fs.results, 1)
+	assert.Nil(t, fs.results[0].ExecutedByUserID, "the worker cannot attribute results to users")
+
+	require.NoError(t, client.Queue().DeferTask(context.Background(), leasedExecutionID, time.Now().Add(time.Minute), "rate limited"))
+	assert.Equal(t, "rate limited", fq.deferred[leasedExecutionID])
+
+	assert.ErrorIs(t, client.Queue().EnqueueTask(context.Background(), &queue.TaskExecutionRequest{}), ErrNotAvailable)
+}
+
+func TestServerRejectsUnsignedRequests(t *testing.T) {
+	server, err := NewServer(&fakeStore{}, &fakeQueue{}, testSecret, "")
+	require.NoError(t, err)
+
+	body := []byte(`["task-1"]`)
+	token, err := SignRequest(testSecret, WorkerIdentity, "GetCampaignTaskInstanceByID", body)
+	require.NoError(t, err)
+
+	for name, header := range map[string]string{
+		"missing":      "",
+		"other method": "Bearer " + token,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/internal/v1/GetTaskByID", bytes.NewReader(body))
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+	}
+
+	_, err = NewServer(&fakeStore{}, &fakeQueue{}, []byte("short"), "")
+	assert.Error(t, err)
+}
+
+func TestServerScopesCallsToLeasedExecutions(t *testing.T) {
+	client, fs, fq := newTestClient(t)
+
+	system, err := client.GetConnectedSystemByID("system-1")
+	require.NoError(t, err)
+	assert.Equal(t, "system-1", system.ID)
+
+	_, err = client.GetConnectedSystemByID("system-2")
+	assert.ErrorContains(t, err, "not targeted by an execution leased to the worker")
+
+	_, err = client.GetCampaignTaskInstanceByID(uuid.NewString())
+	assert.ErrorContains(t, err, "has no execution leased to the worker")
+	assert.False(t, errors.Is(err, store.ErrNotFound))
+
+	other := &models.CampaignTaskInstanceResult{CampaignTaskInstanceID: uuid.NewString(), Status: "Success"}
+	assert.Error(t, client.CreateCampaignTaskInstanceResult(other))
+	assert.Empty(t, fs.results)
+
+	otherExecution := uuid.New()
+	assert.Error(t, client.Queue().DeferTask(context.Background(), otherExecution, time.Now(), "rate limited"))
+	assert.NotContains(t, fq.deferred, otherExecution)
+}
+
+func TestServerRestrictsPluginRegistration(t *testing.T) {
+	client, fs, _ := newTestClient(t)
+	fs.owners["http_get_check"] = "http-plugin"
+
+	configs := map[string]models.CheckTypeConfiguration{"http_get_check": {}}
+	assert.ErrorContains(t, client.CreateOrUpdateRegisteredPlugin("rogue-plugin", "Rogue", "1.0", configs), "registered by plugin http-plugin")
+	assert.Equal(t, "http-plugin", fs.owners["http_get_check"])
+
+	require.NoError(t, client.CreateOrUpdateRegisteredPlugin("http-plugin", "HTTP", "1.1", configs))
+	require.NoError(t, client.CreateOrUpdateRegisteredPlugin("dns-plugin", "DNS", "1.0", map[string]models.CheckTypeConfiguration{"dns_check": {}}))
+	assert.Equal(t, "dns-plugin", fs.owners["dns_check"])
+
+	assert.ErrorIs(t, client.SetRegisteredPluginActiveStatus("http-plugin", false), ErrNotAvailable)
+}
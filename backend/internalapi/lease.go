@@ -0,0 +1,129 @@
+package internalapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+)
+
+// scopeError reports a call for data outside the executions leased to the
+// worker.
+type scopeError struct{ msg string }
+
+func (e *scopeError) Error() string { return e.msg }
+
+// leases returns the executions the worker has dequeued and not yet
+// finished. Every call for task instance or connected system data must be
+// covered by one of them.
+func (s *Server) leases(ctx context.Context) ([]*queue.TaskExecutionRequest, error) {
+	tasks, err := s.queue.LeasedTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load leased executions: %w", err)
+	}
+	return tasks, nil
+}
+
+// requireLeasedExecution rejects calls about an execution the worker does
+// not hold.
+func (s *Server) requireLeasedExecution(ctx context.Context, executionID uuid.UUID) error {
+	tasks, err := s.leases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.ID == executionID {
+			return nil
+		}
+	}
+	return &scopeError{fmt.Sprintf("execution %s is not leased to the worker", executionID)}
+}
+
+// requireLeasedTaskInstance rejects calls about a campaign task instance no
+// leased execution runs for.
+func (s *Server) requireLeasedTaskInstance(ctx context.Context, ctiID string) error {
+	tasks, err := s.leases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.Mode != queue.ModeTest && task.TaskInstanceID.String() == ctiID {
+			return nil
+		}
+	}
+	return &scopeError{fmt.Sprintf("task instance %s has no execution leased to the worker", ctiID)}
+}
+
+// requireLeasedSystem rejects calls about a connected system no leased
+// execution targets: the system of a test execution, or the target of the
+// task instance of a regular one.
+func (s *Server) requireLeasedSystem(ctx context.Context, systemID string) error {
+	tasks, err := s.leases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.Mode == queue.ModeTest && task.ConnectedSystemID != nil && task.ConnectedSystemID.String() == systemID {
+			return nil
+		}
+	}
+	for _, task := range tasks {
+		if task.Mode == queue.ModeTest {
+			continue
+		}
+		cti, err := s.store.GetCampaignTaskInstanceByID(task.TaskInstanceID.String())
+		if err != nil || cti == nil {
+			continue
+		}
+		if cti.Target != nil && *cti.Target == systemID {
+			return nil
+		}
+	}
+	return &scopeError{fmt.Sprintf("connected system %s is not targeted by an execution leased to the worker", systemID)}
+}
+
+// requireLeasedReport rejects reads of a vulnerability report that is not
+// the latest report of a task instance with a leased execution.
+func (s *Server) requireLeasedReport(ctx context.Context, reportID string) error {
+	tasks, err := s.leases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.Mode == queue.ModeTest {
+			continue
+		}
+		report, err := s.store.GetLatestVulnerabilityReportByInstanceID(task.TaskInstanceID.String())
+		if err == nil && report != nil && report.ID == reportID {
+			return nil
+		}
+	}
+	return &scopeError{fmt.Sprintf("vulnerability report %s does not belong to an execution leased to the worker", reportID)}
+}
+
+// requireOwnCheckTypes rejects a plugin registration that would take over
+// check types another active plugin registered.
+func (s *Server) requireOwnCheckTypes(pluginID string, checkTypeKeys []string) error {
+	owners, err := s.store.GetCheckTypePluginIDs()
+	if err != nil {
+		return err
+	}
+	for _, key := range checkTypeKeys {
+		if owner, ok := owners[key]; ok && owner != pluginID {
+			return &scopeError{fmt.Sprintf("check type %s is registered by plugin %s", key, owner)}
+		}
+	}
+	return nil
+}
+
+// requireLeasedSnapshot rejects configuration snapshots and drift events of
+// a system, or task instance, no leased execution covers.
+func (s *Server) requireLeasedSnapshot(ctx context.Context, systemID string, ctiID *string) error {
+	if ctiID != nil {
+		if err := s.requireLeasedTaskInstance(ctx, *ctiID); err != nil {
+			return err
+		}
+	}
+	return s.requireLeasedSystem(ctx, systemID)
+}
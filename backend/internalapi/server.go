@@ -0,0 +1,418 @@
+package internalapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/integrations"
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/queue"
+	"github.com/vdparikh/compliance-automation/backend/store"
+)
+
+// Error codes returned to the client for sentinel errors it must recognise.
+const (
+	codeNotFound = "not_found" // store.ErrNotFound
+	codeNoRows   = "no_rows"   // sql.ErrNoRows
+)
+
+const maxRequestBody = 10 << 20
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// ServerStore is the data access behind the internal API: the worker's store
+// and what the server needs to keep the worker within its leased executions.
+type ServerStore interface {
+	integrations.WorkerStore
+	GetCheckTypePluginIDs() (map[string]string, error)
+}
+
+// method decodes a call's arguments and runs it.
+type method func(s *Server, c *gin.Context, args argDecoder) (interface{}, error)
+
+// Server serves the worker's store and queue operations at
+// POST /internal/v1/:method. The arguments are a JSON array, the response
+// the JSON result. Calls about a task instance, connected system or
+// execution are only served while the worker holds an execution covering it.
+type Server struct {
+	store  ServerStore
+	queue  queue.Queue
+	secret []byte
+	// clientName, when set, is the Common Name the verified client
+	// certificate of every request must carry.
+	clientName string
+}
+
+// NewServer returns a Server backed by the application's store and queue.
+func NewServer(workerStore ServerStore, q queue.Queue, secret []byte, clientName string) (*Server, error) {
+	if err := checkSecret(secret); err != nil {
+		return nil, err
+	}
+	return &Server{store: workerStore, queue: q, secret: secret, clientName: clientName}, nil
+}
+
+// Handler returns the HTTP handler of the internal API.
+func (s *Server) Handler() http.Handler {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.POST("/internal/v1/:method", s.handleCall)
+	return router
+}
+
+func (s *Server) handleCall(c *gin.Context) {
+	name := c.Param("method")
+	fn, ok := methods[name]
+	if !ok {
+		c.JSON(http.StatusNotFound, errorResponse{Error: "unknown method " + name})
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRequestBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Error: "failed to read request body"})
+		return
+	}
+	if err := s.authenticate(c.Request, name, body); err != nil {
+		log.Printf("Internal API: rejected %s call from %s: %v", name, c.ClientIP(), err)
+		c.JSON(http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+		return
+	}
+
+	result, err := fn(s, c, newArgDecoder(body))
+	if err != nil {
+		var argErr *argumentError
+		var scopeErr *scopeError
+		switch {
+		case errors.As(err, &argErr):
+			c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		case errors.As(err, &scopeErr):
+			log.Printf("Internal API: refused %s call: %v", name, err)
+			c.JSON(http.StatusForbidden, errorResponse{Error: err.Error()})
+		case errors.Is(err, store.ErrNotFound):
+			c.JSON(http.StatusNotFound, errorResponse{Error: err.Error(), Code: codeNotFound})
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, errorResponse{Error: err.Error(), Code: codeNoRows})
+		default:
+			log.Printf("Internal API: %s failed: %v", name, err)
+			c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) authenticate(r *http.Request, name string, body []byte) error {
+	if s.clientName != "" {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return errors.New("no verified client certificate")
+		}
+		if cn := r.TLS.VerifiedChains[0][0].Subject.CommonName; cn != s.clientName {
+			return fmt.Errorf("client certificate is for %q", cn)
+		}
+	}
+	header := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return errors.New("missing service token")
+	}
+	claims, err := VerifyRequest(s.secret, token, name, body)
+	if err != nil {
+		return err
+	}
+	if claims.Subject != WorkerIdentity {
+		return fmt.Errorf("unexpected token subject %q", claims.Subject)
+	}
+	return nil
+}
+
+// argumentError reports arguments that do not match the method.
+type argumentError struct{ msg string }
+
+func (e *argumentError) Error() string { return e.msg }
+
+// argDecoder decodes a call's positional arguments into targets.
+type argDecoder func(targets ...interface{}) error
+
+func newArgDecoder(body []byte) argDecoder {
+	return func(targets ...interface{}) error {
+		var raw []json.RawMessage
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &raw); err != nil {
+				return &argumentError{"arguments must be a JSON array: " + err.Error()}
+			}
+		}
+		if len(raw) != len(targets) {
+			return &argumentError{fmt.Sprintf("expected %d arguments, got %d", len(targets), len(raw))}
+		}
+		for i, target := range targets {
+			if err := json.Unmarshal(raw[i], target); err != nil {
+				return &argumentError{fmt.Sprintf("argument %d: %v", i+1, err)}
+			}
+		}
+		return nil
+	}
+}
+
+// methods are the operations open to the worker, named after the
+// integrations.WorkerStore and queue.Queue methods they call. Those about a
+// task instance, connected system or execution check it against the worker's
+// leased executions first.
+var methods = map[string]method{
+	"GetCampaignTaskInstanceByID": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id string
+		if err := args(&id); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedTaskInstance(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		cti, err := s.store.GetCampaignTaskInstanceByID(id)
+		return cti, err
+	},
+	"GetCampaignStatusForTaskInstance": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id string
+		if err := args(&id); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedTaskInstance(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		status, err := s.store.GetCampaignStatusForTaskInstance(id)
+		return status, err
+	},
+	"UpdateCampaignTaskInstanceCheckStatus": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id, status string
+		var checkedAt time.Time
+		if err := args(&id, &checkedAt, &status); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedTaskInstance(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		return nil, s.store.UpdateCampaignTaskInstanceCheckStatus(id, checkedAt, status)
+	},
+	"CreateCampaignTaskInstanceResult": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var result models.CampaignTaskInstanceResult
+		if err := args(&result); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedTaskInstance(c.Request.Context(), result.CampaignTaskInstanceID); err != nil {
+			return nil, err
+		}
+		// Results from the worker are never attributed to a user.
+		result.ExecutedByUserID = nil
+		if err := s.store.CreateCampaignTaskInstanceResult(&result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	},
+	"GetConnectedSystemByID": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id string
+		if err := args(&id); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedSystem(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		system, err := s.store.GetConnectedSystemByID(id)
+		return system, err
+	},
+	"UpdateConnectedSystemStatus": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id, status string
+		var checkedAt time.Time
+		if err := args(&id, &checkedAt, &status); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedSystem(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		return nil, s.store.UpdateConnectedSystemStatus(id, checkedAt, status)
+	},
+	"GetTaskByID": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id string
+		if err := args(&id); err != nil {
+			return nil, err
+		}
+		task, err := s.store.GetTaskByID(id)
+		return task, err
+	},
+	"AppendTaskExecutionLog": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var executionID uuid.UUID
+		var level, message string
+		if err := args(&executionID, &level, &message); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedExecution(c.Request.Context(), executionID); err != nil {
+			return nil, err
+		}
+		return nil, s.store.AppendTaskExecutionLog(executionID.String(), level, message)
+	},
+	"CreateConfigSnapshot": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var snapshot models.ConfigSnapshot
+		if err := args(&snapshot); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedSnapshot(c.Request.Context(), snapshot.ConnectedSystemID, snapshot.CampaignTaskInstanceID); err != nil {
+			return nil, err
+		}
+		if err := s.store.CreateConfigSnapshot(&snapshot); err != nil {
+			return nil, err
+		}
+		return snapshot, nil
+	},
+	"GetPreviousConfigSnapshot": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var current models.ConfigSnapshot
+		if err := args(&current); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedSnapshot(c.Request.Context(), current.ConnectedSystemID, current.CampaignTaskInstanceID); err != nil {
+			return nil, err
+		}
+		snapshot, err := s.store.GetPreviousConfigSnapshot(&current)
+		return snapshot, err
+	},
+	"GetRequirementIDsForTaskInstance": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id string
+		if err := args(&id); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedTaskInstance(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		ids, err := s.store.GetRequirementIDsForTaskInstance(id)
+		return ids, err
+	},
+	"CreateConfigDriftEvents": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var events []models.ConfigDriftEvent
+		if err := args(&events); err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			if err := s.requireLeasedSnapshot(c.Request.Context(), event.ConnectedSystemID, event.CampaignTaskInstanceID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, s.store.CreateConfigDriftEvents(events)
+	},
+	"CompactCampaignTaskInstanceResults": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var before time.Time
+		var successStatus string
+		if err := args(&before, &successStatus); err != nil {
+			return nil, err
+		}
+		n, err := s.store.CompactCampaignTaskInstanceResults(before, successStatus)
+		return n, err
+	},
+	"DeleteTaskExecutionLogsBefore": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var before time.Time
+		if err := args(&before); err != nil {
+			return nil, err
+		}
+		n, err := s.store.DeleteTaskExecutionLogsBefore(before)
+		return n, err
+	},
+	"GetActiveCheckTypeConfigurations": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		if err := args(); err != nil {
+			return nil, err
+		}
+		configs, err := s.store.GetActiveCheckTypeConfigurations()
+		return configs, err
+	},
+	"CreateOrUpdateRegisteredPlugin": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var pluginID, name, version string
+		var configs map[string]models.CheckTypeConfiguration
+		if err := args(&pluginID, &name, &version, &configs); err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(configs))
+		for key := range configs {
+			keys = append(keys, key)
+		}
+		if err := s.requireOwnCheckTypes(pluginID, keys); err != nil {
+			return nil, err
+		}
+		return nil, s.store.CreateOrUpdateRegisteredPlugin(pluginID, name, version, configs)
+	},
+	"GetLatestVulnerabilityReportByInstanceID": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id string
+		if err := args(&id); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedTaskInstance(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		report, err := s.store.GetLatestVulnerabilityReportByInstanceID(id)
+		return report, err
+	},
+	"GetVulnerabilityFindingsByReportID": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id string
+		if err := args(&id); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedReport(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		findings, err := s.store.GetVulnerabilityFindingsByReportID(id)
+		return findings, err
+	},
+
+	"DequeueTask": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		if err := args(); err != nil {
+			return nil, err
+		}
+		task, err := s.queue.DequeueTask(c.Request.Context())
+		return task, err
+	},
+	"UpdateTaskResult": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var result queue.TaskExecutionResult
+		if err := args(&result); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedExecution(c.Request.Context(), result.ID); err != nil {
+			return nil, err
+		}
+		return nil, s.queue.UpdateTaskResult(c.Request.Context(), &result)
+	},
+	"GetTaskStatus": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id uuid.UUID
+		if err := args(&id); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedExecution(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		task, err := s.queue.GetTaskStatus(c.Request.Context(), id)
+		return task, err
+	},
+	"DeferTask": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var id uuid.UUID
+		var until time.Time
+		var reason string
+		if err := args(&id, &until, &reason); err != nil {
+			return nil, err
+		}
+		if err := s.requireLeasedExecution(c.Request.Context(), id); err != nil {
+			return nil, err
+		}
+		return nil, s.queue.DeferTask(c.Request.Context(), id, until, reason)
+	},
+	"PurgeCompletedTasks": func(s *Server, c *gin.Context, args argDecoder) (interface{}, error) {
+		var before time.Time
+		if err := args(&before); err != nil {
+			return nil, err
+		}
+		n, err := s.queue.PurgeCompletedTasks(c.Request.Context(), before)
+		return n, err
+	},
+}
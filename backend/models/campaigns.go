@@ -25,6 +25,92 @@ type Campaign struct {
 	RequirementsCount int          `json:"requirements_count,omitempty" db:"requirements_count"` // If calculated by DB query
 	TaskSummary       *TaskSummary `json:"task_summary,omitempty"`                               // Pointer to allow null if no tasks
 
+	SignOff            *CampaignSignOff `json:"sign_off,omitempty"`            // Set once the campaign is completed
+	Frozen             bool             `json:"frozen"`                        // Completed and archived campaigns are read-only
+	AllowedTransitions []string         `json:"allowed_transitions,omitempty"` // Statuses the campaign may move to next
+
+	ClonedFromCampaignID *string `json:"cloned_from_campaign_id,omitempty"` // Campaign of the previous period, for rollovers
+
+	// StandardIDs are all standards the campaign is scoped to, StandardID
+	// first. Standards carries their names.
+	StandardIDs []string           `json:"standard_ids,omitempty"`
+	Standards   []CampaignStandard `json:"standards,omitempty"`
+}
+
+type CampaignStandard struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ShortName string `json:"short_name"`
+}
+
+// CampaignComparison sets a cloned campaign's task instances next to their
+// counterparts in the campaign of the previous period.
+type CampaignComparison struct {
+	CampaignID           string                       `json:"campaign_id"`
+	PreviousCampaignID   *string                      `json:"previous_campaign_id,omitempty"`
+	PreviousCampaignName *string                      `json:"previous_campaign_name,omitempty"`
+	Instances            []CampaignInstanceComparison `json:"instances"`
+}
+
+type CampaignInstanceComparison struct {
+	InstanceID              string  `json:"instance_id"`
+	Title                   string  `json:"title"`
+	Status                  string  `json:"status"`
+	LastCheckStatus         *string `json:"last_check_status,omitempty"`
+	EvidenceCount           int     `json:"evidence_count"`
+	PreviousInstanceID      *string `json:"previous_instance_id,omitempty"`
+	PreviousStatus          *string `json:"previous_status,omitempty"`
+	PreviousLastCheckStatus *string `json:"previous_last_check_status,omitempty"`
+	PreviousEvidenceCount   *int    `json:"previous_evidence_count,omitempty"`
+}
+
+// CampaignComplianceRollup derives the compliance status of each of a
+// campaign's selected requirements from the task instances covering them.
+type CampaignComplianceRollup struct {
+	CampaignID   string              `json:"campaign_id"`
+	StatusCounts map[string]int      `json:"status_counts"` // Requirements per status
+	Requirements []RequirementRollup `json:"requirements"`
+	Sections     []SectionCoverage   `json:"sections"`
+	// UnmappedRequirements are applicable requirements no master task is
+	// linked to, so the campaign has no task instance for them.
+	UnmappedRequirements []RequirementRollup `json:"unmapped_requirements"`
+}
+
+type RequirementRollup struct {
+	CampaignSelectedRequirementID string   `json:"campaign_selected_requirement_id"`
+	RequirementID                 string   `json:"requirement_id"`
+	ControlIDReference            string   `json:"control_id_reference"`
+	RequirementText               string   `json:"requirement_text"`
+	StandardID                    string   `json:"standard_id"`
+	StandardName                  string   `json:"standard_name"`
+	Section                       string   `json:"section"`
+	IsApplicable                  bool     `json:"is_applicable"`
+	Status                        string   `json:"status"`
+	HasMasterTasks                bool     `json:"has_master_tasks"`
+	TaskInstanceIDs               []string `json:"task_instance_ids"`
+}
+
+// SectionCoverage sums up the applicable requirements of one section of a
+// standard.
+type SectionCoverage struct {
+	StandardName       string  `json:"standard_name"`
+	Section            string  `json:"section"`
+	Requirements       int     `json:"requirements"`
+	Compliant          int     `json:"compliant"`
+	PartiallyCompliant int     `json:"partially_compliant"`
+	NonCompliant       int     `json:"non_compliant"`
+	NotStarted         int     `json:"not_started"`
+	Unmapped           int     `json:"unmapped"`
+	CoveragePercent    float64 `json:"coverage_percent"`  // Requirements with task instances
+	CompliantPercent   float64 `json:"compliant_percent"` // Requirements that are compliant
+}
+
+// CampaignSignOff records the approver who completed a campaign.
+type CampaignSignOff struct {
+	UserID      *string   `json:"user_id,omitempty"`
+	UserName    *string   `json:"user_name,omitempty"`
+	SignedOffAt time.Time `json:"signed_off_at"`
+	Comment     *string   `json:"comment,omitempty"`
 }
 
 type TaskSummary struct {
@@ -44,6 +130,8 @@ type CampaignSelectedRequirement struct {
 	IsApplicable       bool   `json:"is_applicable"`
 	ControlIDReference string `json:"control_id_reference,omitempty"`
 	RequirementText    string `json:"requirement_text,omitempty"`
+	StandardID         string `json:"standard_id,omitempty"`
+	StandardName       string `json:"standard_name,omitempty"`
 }
 
 type UserBasicInfo struct {
@@ -97,6 +185,11 @@ type CampaignTaskInstance struct {
 	AssigneeTeam *TeamBasicInfo `json:"assignee_team,omitempty" db:"assigneeteam"` // For sqlx struct scan
 
 	RequirementControlIDReference *string `json:"requirement_control_id_reference,omitempty" db:"requirement_control_id_reference"`
+	PreviousInstanceID            *string `json:"previous_instance_id,omitempty" db:"previous_instance_id"` // Same task in the previous period's campaign
+
+	// CampaignSelectedRequirementIDs are all selected requirements the
+	// instance covers, CampaignSelectedRequirementID among them.
+	CampaignSelectedRequirementIDs []string `json:"campaign_selected_requirement_ids,omitempty" db:"-"`
 
 	DefaultPriority       *string  `json:"defaultPriority,omitempty" db:"default_priority"`
 	EvidenceTypesExpected []string `json:"evidenceTypesExpected,omitempty" db:"evidence_types_expected"`
@@ -116,4 +209,15 @@ type CampaignTaskInstanceResult struct {
 	Status                 string         `json:"status" db:"status"`
 	Output                 string         `json:"output" db:"output"`
 	ExecutedByUser         *UserBasicInfo `json:"executedByUser,omitempty"`
+
+	// Provenance of automated results: the plugin and version that produced
+	// them and the check type schema version the parameters were read with.
+	PluginID               *string `json:"pluginId,omitempty" db:"plugin_id"`
+	PluginVersion          *string `json:"pluginVersion,omitempty" db:"plugin_version"`
+	CheckTypeSchemaVersion *int    `json:"checkTypeSchemaVersion,omitempty" db:"check_type_schema_version"`
+
+	// Retention folds later identical successes into the first of their run;
+	// RepeatCount says how many, and RepeatedUntil when the last one ran.
+	RepeatCount   int        `json:"repeatCount" db:"repeat_count"`
+	RepeatedUntil *time.Time `json:"repeatedUntil,omitempty" db:"repeated_until"`
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"sort"
 	"time"
 )
 
@@ -23,10 +24,54 @@ type CheckTypeConfiguration struct {
 	TargetType     string                `json:"targetType"` // e.g., "connected_system", "none"
 	TargetLabel    string                `json:"targetLabel,omitempty"`
 	TargetHelpText string                `json:"targetHelpText,omitempty"`
+	// SchemaVersion is bumped by the plugin whenever Parameters change in a way
+	// that existing master tasks need revisiting. Zero is treated as 1.
+	SchemaVersion int `json:"schemaVersion,omitempty"`
 	// You might add an IntegrationID here if needed for frontend/backend correlation
 	// IntegrationID string `json:"integrationId"`
 }
 
+// CurrentSchemaVersion returns SchemaVersion, defaulting to 1.
+func (c CheckTypeConfiguration) CurrentSchemaVersion() int {
+	if c.SchemaVersion < 1 {
+		return 1
+	}
+	return c.SchemaVersion
+}
+
+// ParameterMismatches lists the required parameters missing from params and
+// the params the check type does not define, both sorted.
+func (c CheckTypeConfiguration) ParameterMismatches(params map[string]interface{}) (missing, unknown []string) {
+	defined := make(map[string]bool, len(c.Parameters))
+	for _, p := range c.Parameters {
+		defined[p.Name] = true
+		if !p.Required {
+			continue
+		}
+		if v, ok := params[p.Name]; !ok || v == nil || v == "" {
+			missing = append(missing, p.Name)
+		}
+	}
+	for name := range params {
+		if !defined[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unknown)
+	return missing, unknown
+}
+
+// RegisteredPluginVersion is one version of a plugin seen at registration,
+// with the check type configurations it declared.
+type RegisteredPluginVersion struct {
+	PluginID                string                            `json:"pluginId" db:"plugin_id"`
+	Version                 string                            `json:"version" db:"version"`
+	CheckTypeConfigurations map[string]CheckTypeConfiguration `json:"checkTypeConfigurations" db:"-"`
+	FirstRegisteredAt       time.Time                         `json:"firstRegisteredAt" db:"first_registered_at"`
+	LastRegisteredAt        time.Time                         `json:"lastRegisteredAt" db:"last_registered_at"`
+}
+
 type ComplianceStandard struct {
 	ID           string  `json:"id"`
 	Name         string  `json:"name"`
@@ -69,6 +114,26 @@ type Task struct {
 	EvidenceTypesExpected []string `json:"evidenceTypesExpected,omitempty" db:"evidence_types_expected"`
 	DefaultPriority       *string  `json:"defaultPriority,omitempty" db:"default_priority"`
 
+	// ResultPolicy, when set, decides the final status of automated checks
+	// from the plugin output instead of the plugin's own verdict.
+	ResultPolicyLanguage *string `json:"resultPolicyLanguage,omitempty" db:"result_policy_language"` // "cel" or "rego"
+	ResultPolicy         *string `json:"resultPolicy,omitempty" db:"result_policy"`
+
+	// CheckTypeSchemaVersion is the schema version of CheckType that Parameters
+	// were last saved against.
+	CheckTypeSchemaVersion *int `json:"checkTypeSchemaVersion,omitempty" db:"check_type_schema_version"`
+
+	// Defaults for the task instances generated from this task. An assignee
+	// team without an assignee user is resolved to one of its members by the
+	// team's assignment strategy. The due date is DueDateOffsetDays from the
+	// campaign's start or end, as chosen by DueDateAnchor.
+	DefaultOwnerTeamID    *string `json:"defaultOwnerTeamId,omitempty" db:"default_owner_team_id"`
+	DefaultAssigneeTeamID *string `json:"defaultAssigneeTeamId,omitempty" db:"default_assignee_team_id"`
+	DefaultOwnerUserID    *string `json:"defaultOwnerUserId,omitempty" db:"default_owner_user_id"`
+	DefaultAssigneeUserID *string `json:"defaultAssigneeUserId,omitempty" db:"default_assignee_user_id"`
+	DueDateAnchor         *string `json:"dueDateAnchor,omitempty" db:"due_date_anchor"` // "start" or "end"
+	DueDateOffsetDays     *int    `json:"dueDateOffsetDays,omitempty" db:"due_date_offset_days"`
+
 	// Requirements related fields
 	RequirementIDs []string      `json:"requirementIds,omitempty" db:"-"`
 	Requirements   []Requirement `json:"requirements,omitempty" db:"-"`
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// ConfigSnapshot is the set of resources a plugin saw on a connected system at one point in time.
+// Scope is the check type that produced it, so only snapshots of the same kind are compared.
+type ConfigSnapshot struct {
+	ID                     string             `json:"id" db:"id"`
+	ConnectedSystemID      string             `json:"connectedSystemId" db:"connected_system_id"`
+	Scope                  string             `json:"scope" db:"scope"`
+	CampaignTaskInstanceID *string            `json:"campaignTaskInstanceId,omitempty" db:"campaign_task_instance_id"`
+	TaskExecutionID        *string            `json:"taskExecutionId,omitempty" db:"task_execution_id"`
+	ResourceCount          int                `json:"resourceCount" db:"resource_count"`
+	CapturedAt             time.Time          `json:"capturedAt" db:"captured_at"`
+	Resources              []SnapshotResource `json:"resources,omitempty" db:"-"`
+}
+
+// SnapshotResource is one normalized resource, e.g. an S3 bucket with its
+// encryption and public access settings.
+type SnapshotResource struct {
+	ResourceType string                 `json:"resourceType" db:"resource_type"`
+	ResourceID   string                 `json:"resourceId" db:"resource_id"`
+	Attributes   map[string]interface{} `json:"attributes"`
+}
+
+// ResourceChange is a resource present in both snapshots whose attributes differ.
+type ResourceChange struct {
+	ResourceType      string                 `json:"resourceType"`
+	ResourceID        string                 `json:"resourceId"`
+	ChangedAttributes []string               `json:"changedAttributes"`
+	Before            map[string]interface{} `json:"before"`
+	After             map[string]interface{} `json:"after"`
+}
+
+// SnapshotDiff lists what was added, removed and changed between two snapshots.
+type SnapshotDiff struct {
+	FromSnapshotID string             `json:"fromSnapshotId,omitempty"`
+	ToSnapshotID   string             `json:"toSnapshotId,omitempty"`
+	Added          []SnapshotResource `json:"added"`
+	Removed        []SnapshotResource `json:"removed"`
+	Changed        []ResourceChange   `json:"changed"`
+}
+
+// Empty reports whether the two snapshots hold the same resources.
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// ConfigDriftEvent records one resource that drifted between consecutive snapshots,
+// tagged with the requirements mapped to the task that captured it.
+type ConfigDriftEvent struct {
+	ID                     string    `json:"id" db:"id"`
+	ConnectedSystemID      string    `json:"connectedSystemId" db:"connected_system_id"`
+	Scope                  string    `json:"scope" db:"scope"`
+	SnapshotID             string    `json:"snapshotId" db:"snapshot_id"`
+	PreviousSnapshotID     string    `json:"previousSnapshotId" db:"previous_snapshot_id"`
+	CampaignTaskInstanceID *string   `json:"campaignTaskInstanceId,omitempty" db:"campaign_task_instance_id"`
+	ResourceType           string    `json:"resourceType" db:"resource_type"`
+	ResourceID             string    `json:"resourceId" db:"resource_id"`
+	ChangeType             string    `json:"changeType" db:"change_type"` // added, removed or changed
+	ChangedAttributes      []string  `json:"changedAttributes,omitempty" db:"changed_attributes"`
+	RequirementIDs         []string  `json:"requirementIds,omitempty" db:"requirement_ids"`
+	DetectedAt             time.Time `json:"detectedAt" db:"detected_at"`
+}
@@ -26,6 +26,10 @@ type Evidence struct {
 	ReviewedAt       *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
 	ReviewComments   *string    `json:"review_comments,omitempty" db:"review_comments"`
 
+	// CarriedForwardFromID is the evidence of a previous campaign period this
+	// item references; it shares that evidence's file.
+	CarriedForwardFromID *string `json:"carried_forward_from_id,omitempty" db:"carried_forward_from_id"`
+
 	// Fields for JOINs - these will be populated by sqlx if db tags match aliased columns
 	UploadedByUser *User `json:"uploadedByUser,omitempty" db:"uploadedbyuser"` // Example, adjust db tag based on actual JOIN alias
 	ReviewedByUser *User `json:"reviewedByUser,omitempty" db:"reviewedbyuser"` // Example, adjust db tag based on actual JOIN alias
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ResultTrendPoint counts the execution results of one period. Compacted
+// repeats count as separate passes in the period of the result they were
+// folded into, which compaction keeps within the same day.
+type ResultTrendPoint struct {
+	Period   time.Time `json:"period" db:"period"`
+	Passed   int       `json:"passed" db:"passed"`
+	Failed   int       `json:"failed" db:"failed"`
+	Errored  int       `json:"errored" db:"errored"`
+	Other    int       `json:"other" db:"-"`
+	Total    int       `json:"total" db:"total"`
+	PassRate float64   `json:"passRate" db:"-"`
+}
+
+// ResultTrendFilter selects the results a trend covers. Empty fields are not
+// filtered on; From and To bound the result timestamps.
+type ResultTrendFilter struct {
+	CampaignTaskInstanceID string
+	MasterTaskID           string
+	ConnectedSystemID      string
+	From                   *time.Time
+	To                     *time.Time
+	// Interval is "day", "week" or "month".
+	Interval string
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// TaskExecutionLog is one progress line reported while an execution ran.
+type TaskExecutionLog struct {
+	ID          int64     `json:"id" db:"id"`
+	ExecutionID string    `json:"executionId" db:"execution_id"`
+	Level       string    `json:"level" db:"level"`
+	Message     string    `json:"message" db:"message"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
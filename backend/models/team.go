@@ -5,12 +5,13 @@ import (
 )
 
 type Team struct {
-	ID          string    `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description *string   `json:"description,omitempty" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-	Members     []User    `json:"members,omitempty"` // Populated by JOINs or separate queries
+	ID                 string    `json:"id" db:"id"`
+	Name               string    `json:"name" db:"name"`
+	Description        *string   `json:"description,omitempty" db:"description"`
+	AssignmentStrategy string    `json:"assignment_strategy" db:"assignment_strategy"` // "lead", "round_robin" or "least_loaded"; see package assignment
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	Members            []User    `json:"members,omitempty"` // Populated by JOINs or separate queries
 }
 
 type TeamMember struct { // Used if directly managing the join table, often not exposed as a primary model
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// VulnerabilityReport is one scanner report ingested against a campaign task instance.
+// The raw file is kept as evidence; the normalized findings live in vulnerability_findings.
+type VulnerabilityReport struct {
+	ID                     string    `json:"id" db:"id"`
+	CampaignTaskInstanceID *string   `json:"campaignTaskInstanceId,omitempty" db:"campaign_task_instance_id"`
+	EvidenceID             *string   `json:"evidenceId,omitempty" db:"evidence_id"`
+	Format                 string    `json:"format" db:"format"` // trivy, grype, nessus or sarif
+	UploadedByUserID       *string   `json:"uploadedByUserId,omitempty" db:"uploaded_by_user_id"`
+	FindingsCount          int       `json:"findingsCount" db:"findings_count"`
+	CreatedAt              time.Time `json:"createdAt" db:"created_at"`
+}
+
+// VulnerabilityFinding is a single normalized finding from a report.
+// FirstSeenAt is carried over from earlier reports for the same asset, vulnerability and package,
+// so a finding that keeps coming back across campaigns keeps its original age.
+type VulnerabilityFinding struct {
+	ID               string    `json:"id" db:"id"`
+	ReportID         string    `json:"reportId" db:"report_id"`
+	Asset            string    `json:"asset" db:"asset"`
+	VulnerabilityID  string    `json:"vulnerabilityId" db:"vulnerability_id"` // CVE where the scanner provides one
+	Severity         string    `json:"severity" db:"severity"`
+	PackageName      string    `json:"packageName" db:"package_name"` // Package, file or, for network scans, protocol/port
+	InstalledVersion *string   `json:"installedVersion,omitempty" db:"installed_version"`
+	FixedVersion     *string   `json:"fixedVersion,omitempty" db:"fixed_version"`
+	Title            *string   `json:"title,omitempty" db:"title"`
+	FirstSeenAt      time.Time `json:"firstSeenAt" db:"first_seen_at"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+
+	// Populated for asset history queries
+	CampaignTaskInstanceID *string `json:"campaignTaskInstanceId,omitempty" db:"campaign_task_instance_id"`
+	CampaignID             *string `json:"campaignId,omitempty" db:"campaign_id"`
+	CampaignName           *string `json:"campaignName,omitempty" db:"campaign_name"`
+}
@@ -0,0 +1,217 @@
+// Package policy evaluates master task result policies written in CEL or Rego
+// against a plugin's JSON output, so pass/fail thresholds can change without
+// touching plugin code.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+)
+
+const (
+	LanguageCEL  = "cel"
+	LanguageRego = "rego"
+
+	// RegoQuery is the document a Rego policy must define: a boolean "pass"
+	// and optionally "reasons", a set or array of strings.
+	RegoQuery = "data.compliance"
+
+	// celCostLimit bounds the work a single CEL expression may do.
+	celCostLimit = 1_000_000
+	// regoTimeout bounds a single Rego evaluation, which has no cost limit.
+	regoTimeout = 2 * time.Second
+)
+
+// deniedRegoBuiltins reach the network or the host. Policies are written by
+// users and run in the API server and the worker, so they may only compute
+// over their input.
+var deniedRegoBuiltins = map[string]bool{
+	"http.send":          true,
+	"net.lookup_ip_addr": true,
+	"opa.runtime":        true,
+}
+
+// regoCapabilities are OPA's capabilities without deniedRegoBuiltins and with
+// no hosts reachable.
+var regoCapabilities = func() *ast.Capabilities {
+	caps := ast.CapabilitiesForThisVersion()
+	builtins := caps.Builtins[:0]
+	for _, b := range caps.Builtins {
+		if !deniedRegoBuiltins[b.Name] {
+			builtins = append(builtins, b)
+		}
+	}
+	caps.Builtins = builtins
+	caps.AllowNet = []string{}
+	return caps
+}()
+
+// reservedOutputKeys are written by the task execution service next to the
+// plugin output. They are stripped before evaluation so a stored result is
+// evaluated exactly as the live run was.
+var reservedOutputKeys = []string{"plugin_status", "overall_execution_status", "execution_error_message", "policy_evaluation"}
+
+// Input is the data a policy sees. CEL exposes the fields as the variables
+// output, plugin_status and parameters; Rego as input.output and so on.
+type Input struct {
+	Output       map[string]interface{} `json:"output"`
+	PluginStatus string                 `json:"plugin_status"`
+	Parameters   map[string]interface{} `json:"parameters"`
+}
+
+// NewInput builds an Input from a stored or freshly built result output.
+// The plugin status recorded in the output wins over fallbackStatus.
+func NewInput(output map[string]interface{}, fallbackStatus string, params map[string]interface{}) Input {
+	in := Input{Output: make(map[string]interface{}, len(output)), PluginStatus: fallbackStatus, Parameters: params}
+	for k, v := range output {
+		in.Output[k] = v
+	}
+	if s, ok := output["plugin_status"].(string); ok && s != "" {
+		in.PluginStatus = s
+	}
+	for _, k := range reservedOutputKeys {
+		delete(in.Output, k)
+	}
+	if in.Parameters == nil {
+		in.Parameters = map[string]interface{}{}
+	}
+	return in
+}
+
+// Decision is the outcome of a policy evaluation.
+type Decision struct {
+	Language     string   `json:"language"`
+	Passed       bool     `json:"passed"`
+	Status       string   `json:"status"`
+	PluginStatus string   `json:"plugin_status"`
+	Reasons      []string `json:"reasons,omitempty"`
+}
+
+// Validate compiles a policy without evaluating it.
+func Validate(language, source string) error {
+	switch language {
+	case LanguageCEL:
+		_, err := compileCEL(source)
+		return err
+	case LanguageRego:
+		_, err := prepareRego(context.Background(), source)
+		return err
+	}
+	return fmt.Errorf("unsupported policy language %q; use %s or %s", language, LanguageCEL, LanguageRego)
+}
+
+// Evaluate runs a policy against in and maps the result to a check status.
+func Evaluate(ctx context.Context, language, source string, in Input) (*Decision, error) {
+	d := &Decision{Language: language, PluginStatus: in.PluginStatus}
+	switch language {
+	case LanguageCEL:
+		prg, err := compileCEL(source)
+		if err != nil {
+			return nil, err
+		}
+		val, _, err := prg.ContextEval(ctx, map[string]interface{}{
+			"output":        in.Output,
+			"plugin_status": in.PluginStatus,
+			"parameters":    in.Parameters,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("evaluate CEL policy: %w", err)
+		}
+		passed, ok := val.Value().(bool)
+		if !ok {
+			return nil, fmt.Errorf("CEL policy must return a bool, got %s", val.Type().TypeName())
+		}
+		d.Passed = passed
+	case LanguageRego:
+		ctx, cancel := context.WithTimeout(ctx, regoTimeout)
+		defer cancel()
+		query, err := prepareRego(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := query.Eval(ctx, rego.EvalInput(map[string]interface{}{
+			"output":        in.Output,
+			"plugin_status": in.PluginStatus,
+			"parameters":    in.Parameters,
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("evaluate Rego policy: %w", err)
+		}
+		if d.Passed, d.Reasons, err = regoDecision(rs); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported policy language %q; use %s or %s", language, LanguageCEL, LanguageRego)
+	}
+
+	d.Status = common.StatusFailed
+	if d.Passed {
+		d.Status = common.StatusSuccess
+	}
+	return d, nil
+}
+
+func compileCEL(source string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("output", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("plugin_status", cel.StringType),
+		cel.Variable("parameters", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create CEL environment: %w", err)
+	}
+	ast, iss := env.Compile(source)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("invalid CEL policy: %w", iss.Err())
+	}
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return nil, fmt.Errorf("CEL policy must return a bool, not %s", t)
+	}
+	prg, err := env.Program(ast, cel.CostLimit(celCostLimit), cel.InterruptCheckFrequency(100))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEL policy: %w", err)
+	}
+	return prg, nil
+}
+
+func prepareRego(ctx context.Context, source string) (rego.PreparedEvalQuery, error) {
+	query, err := rego.New(
+		rego.Query(RegoQuery),
+		rego.Module("policy.rego", source),
+		rego.StrictBuiltinErrors(true),
+		rego.Capabilities(regoCapabilities),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return query, fmt.Errorf("invalid Rego policy: %w", err)
+	}
+	return query, nil
+}
+
+// regoDecision reads pass and reasons from the data.compliance document.
+func regoDecision(rs rego.ResultSet) (bool, []string, error) {
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return false, nil, fmt.Errorf("Rego policy must be in package compliance and define pass")
+	}
+	doc, _ := rs[0].Expressions[0].Value.(map[string]interface{})
+	passed, ok := doc["pass"].(bool)
+	if !ok {
+		return false, nil, fmt.Errorf("Rego policy did not produce a boolean pass; add `default pass := false`")
+	}
+	var reasons []string
+	if list, ok := doc["reasons"].([]interface{}); ok {
+		for _, r := range list {
+			reasons = append(reasons, strings.TrimSpace(fmt.Sprint(r)))
+		}
+		sort.Strings(reasons)
+	}
+	return passed, reasons, nil
+}
@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/integrations/common"
+)
+
+// sslOutput is shaped like the SSL checker's output.
+var sslOutput = map[string]interface{}{
+	"message":                  "Certificate expires in 21 days",
+	"days_left":                float64(21),
+	"issuer":                   "R3",
+	"plugin_status":            common.StatusSuccess,
+	"overall_execution_status": common.StatusSuccess,
+}
+
+func TestNewInputStripsBookkeeping(t *testing.T) {
+	in := NewInput(sslOutput, common.StatusFailed, nil)
+	assert.Equal(t, common.StatusSuccess, in.PluginStatus, "the recorded plugin status wins")
+	assert.NotContains(t, in.Output, "plugin_status")
+	assert.NotContains(t, in.Output, "overall_execution_status")
+	assert.Equal(t, float64(21), in.Output["days_left"])
+	assert.Contains(t, sslOutput, "plugin_status", "the caller's map is not modified")
+	assert.NotNil(t, in.Parameters)
+}
+
+func TestCELPolicy(t *testing.T) {
+	in := NewInput(sslOutput, "", map[string]interface{}{"minDays": float64(30)})
+
+	d, err := Evaluate(context.Background(), LanguageCEL, `output.days_left >= parameters.minDays`, in)
+	require.NoError(t, err)
+	assert.False(t, d.Passed)
+	assert.Equal(t, common.StatusFailed, d.Status)
+	assert.Equal(t, common.StatusSuccess, d.PluginStatus)
+
+	d, err = Evaluate(context.Background(), LanguageCEL, `plugin_status == "Success" && output.days_left > 14.0`, in)
+	require.NoError(t, err)
+	assert.True(t, d.Passed)
+	assert.Equal(t, common.StatusSuccess, d.Status)
+
+	_, err = Evaluate(context.Background(), LanguageCEL, `output.days_left`, in)
+	assert.ErrorContains(t, err, "must return a bool")
+	_, err = Evaluate(context.Background(), LanguageCEL, `output.missing > 1`, in)
+	assert.Error(t, err)
+}
+
+func TestRegoPolicy(t *testing.T) {
+	source := `package compliance
+
+default pass := false
+
+pass if {
+	input.output.days_left >= input.parameters.minDays
+	count(reasons) == 0
+}
+
+reasons contains msg if {
+	input.output.issuer != "R3"
+	msg := sprintf("unexpected issuer %s", [input.output.issuer])
+}
+
+reasons contains msg if {
+	input.output.days_left < input.parameters.minDays
+	msg := sprintf("expires in %d days", [input.output.days_left])
+}
+`
+	d, err := Evaluate(context.Background(), LanguageRego, source, NewInput(sslOutput, "", map[string]interface{}{"minDays": float64(30)}))
+	require.NoError(t, err)
+	assert.False(t, d.Passed)
+	assert.Equal(t, []string{"expires in 21 days"}, d.Reasons)
+
+	d, err = Evaluate(context.Background(), LanguageRego, source, NewInput(sslOutput, "", map[string]interface{}{"minDays": float64(14)}))
+	require.NoError(t, err)
+	assert.True(t, d.Passed)
+	assert.Empty(t, d.Reasons)
+
+	_, err = Evaluate(context.Background(), LanguageRego, "package other\n\npass := true\n", NewInput(sslOutput, "", nil))
+	assert.ErrorContains(t, err, "package compliance")
+	_, err = Evaluate(context.Background(), LanguageRego, "package compliance\n\npass if input.output.days_left > 90\n", NewInput(sslOutput, "", nil))
+	assert.ErrorContains(t, err, "default pass := false")
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(LanguageCEL, `size(output) > 0`))
+	assert.Error(t, Validate(LanguageCEL, `output.days_left >`))
+	assert.Error(t, Validate(LanguageCEL, `"text"`))
+	assert.NoError(t, Validate(LanguageRego, "package compliance\n\npass := true\n"))
+	assert.Error(t, Validate(LanguageRego, "package compliance\n\npass := \n"))
+	assert.Error(t, Validate("python", "True"))
+}
+
+func TestRegoCannotReachNetworkOrHost(t *testing.T) {
+	for _, source := range []string{
+		"package compliance\n\npass if http.send({\"method\": \"get\", \"url\": \"http://169.254.169.254/\"}).status_code == 200\n",
+		"package compliance\n\npass if count(net.lookup_ip_addr(\"example.com\")) > 0\n",
+		"package compliance\n\npass if opa.runtime().env.HOME != \"\"\n",
+	} {
+		assert.ErrorContains(t, Validate(LanguageRego, source), "undefined function", source)
+	}
+	assert.NoError(t, Validate(LanguageRego, "package compliance\n\npass if time.now_ns() > 0\n"))
+}
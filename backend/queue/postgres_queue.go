@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
@@ -50,6 +51,17 @@ func NewPostgresQueue(config map[string]interface{}) (*PostgresQueue, error) {
 		return nil, err
 	}
 
+	// Columns added after the table was first created.
+	_, err = db.Exec(`
+		ALTER TABLE task_executions ADD COLUMN IF NOT EXISTS mode VARCHAR(20) NOT NULL DEFAULT 'run';
+		ALTER TABLE task_executions ADD COLUMN IF NOT EXISTS connected_system_id UUID;
+		ALTER TABLE task_executions ADD COLUMN IF NOT EXISTS master_task_id UUID;
+		ALTER TABLE task_executions ADD COLUMN IF NOT EXISTS run_after TIMESTAMP WITH TIME ZONE;
+	`)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PostgresQueue{db: db}, nil
 }
 
@@ -57,10 +69,15 @@ func (q *PostgresQueue) EnqueueTask(ctx context.Context, request *TaskExecutionR
 	query := `
 		INSERT INTO task_executions (
 			id, task_instance_id, task_type, parameters, system_config,
-			created_at, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			created_at, status, mode, connected_system_id, master_task_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
+	mode := request.Mode
+	if mode == "" {
+		mode = ModeRun
+	}
+
 	paramsJSON, err := json.Marshal(request.Parameters)
 	if err != nil {
 		return err
@@ -79,6 +96,9 @@ func (q *PostgresQueue) EnqueueTask(ctx context.Context, request *TaskExecutionR
 		systemConfigJSON,
 		request.CreatedAt,
 		request.Status,
+		mode,
+		request.ConnectedSystemID,
+		request.MasterTaskID,
 	)
 
 	return err
@@ -103,12 +123,14 @@ func (q *PostgresQueue) DequeueTask(ctx context.Context) (*TaskExecutionRequest,
 			FROM task_executions
 			WHERE status = 'pending'
 			AND (locked_at IS NULL OR locked_at < NOW() - INTERVAL '5 minutes')
-			ORDER BY created_at ASC
+			AND (run_after IS NULL OR run_after <= NOW())
+			ORDER BY (mode = 'test') DESC, created_at ASC
 			FOR UPDATE SKIP LOCKED
 			LIMIT 1
 		)
 		RETURNING id, task_instance_id, task_type, parameters, system_config,
-				  created_at, status, error_message, result, completed_at
+				  created_at, status, error_message, result, completed_at,
+				  mode, connected_system_id, master_task_id
 	`
 
 	var request TaskExecutionRequest
@@ -126,6 +148,9 @@ func (q *PostgresQueue) DequeueTask(ctx context.Context) (*TaskExecutionRequest,
 		&errorMessage,
 		&resultJSON,
 		&request.CompletedAt,
+		&request.Mode,
+		&request.ConnectedSystemID,
+		&request.MasterTaskID,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil // No tasks available
@@ -213,7 +238,8 @@ func (q *PostgresQueue) UpdateTaskResult(ctx context.Context, result *TaskExecut
 func (q *PostgresQueue) GetTaskStatus(ctx context.Context, taskID uuid.UUID) (*TaskExecutionRequest, error) {
 	query := `
 		SELECT id, task_instance_id, task_type, parameters, system_config,
-			   created_at, status, error_message, result, completed_at
+			   created_at, status, error_message, result, completed_at,
+			   mode, connected_system_id, master_task_id
 		FROM task_executions
 		WHERE id = $1
 	`
@@ -233,6 +259,9 @@ func (q *PostgresQueue) GetTaskStatus(ctx context.Context, taskID uuid.UUID) (*T
 		&errorMessage,
 		&resultJSON,
 		&request.CompletedAt,
+		&request.Mode,
+		&request.ConnectedSystemID,
+		&request.MasterTaskID,
 	)
 	if err != nil {
 		return nil, err
@@ -256,3 +285,65 @@ func (q *PostgresQueue) GetTaskStatus(ctx context.Context, taskID uuid.UUID) (*T
 
 	return &request, nil
 }
+
+func (q *PostgresQueue) CancelTask(ctx context.Context, taskID uuid.UUID) (string, error) {
+	query := `
+		UPDATE task_executions
+		SET status = CASE WHEN status = 'pending' THEN $2 ELSE $3 END,
+			completed_at = CASE WHEN status = 'pending' THEN NOW() ELSE completed_at END
+		WHERE id = $1 AND status IN ('pending', 'processing', $3)
+		RETURNING status
+	`
+	var status string
+	err := q.db.QueryRowContext(ctx, query, taskID, StatusCancelled, StatusCancelling).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrNotCancellable
+	}
+	return status, err
+}
+
+func (q *PostgresQueue) DeferTask(ctx context.Context, taskID uuid.UUID, until time.Time, reason string) error {
+	query := `
+		UPDATE task_executions
+		SET status = 'pending',
+			run_after = $2,
+			error_message = $3,
+			locked_at = NULL,
+			locked_by = NULL
+		WHERE id = $1 AND status = 'processing'
+	`
+	_, err := q.db.ExecContext(ctx, query, taskID, until, reason)
+	return err
+}
+
+func (q *PostgresQueue) LeasedTasks(ctx context.Context) ([]*TaskExecutionRequest, error) {
+	query := `
+		SELECT id, task_instance_id, task_type, mode, connected_system_id, master_task_id
+		FROM task_executions
+		WHERE status IN ('processing', $1)
+	`
+	rows, err := q.db.QueryContext(ctx, query, StatusCancelling)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*TaskExecutionRequest
+	for rows.Next() {
+		var request TaskExecutionRequest
+		if err := rows.Scan(&request.ID, &request.TaskInstanceID, &request.TaskType, &request.Mode,
+			&request.ConnectedSystemID, &request.MasterTaskID); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &request)
+	}
+	return tasks, rows.Err()
+}
+
+func (q *PostgresQueue) PurgeCompletedTasks(ctx context.Context, before time.Time) (int64, error) {
+	res, err := q.db.ExecContext(ctx, `DELETE FROM task_executions WHERE completed_at IS NOT NULL AND completed_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -2,15 +2,34 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Execution modes. Test executions are dequeued ahead of regular ones and
+// their results are kept only on the queue row: no campaign task instance
+// result is written and no task status changes.
+const (
+	ModeRun  = "run"
+	ModeTest = "test"
+)
+
+// Cancellation statuses. A pending execution is cancelled at once; a running
+// one is marked cancelling until the worker stops it and records Cancelled.
+const (
+	StatusCancelling = "cancelling"
+	StatusCancelled  = "Cancelled"
+)
+
+// ErrNotCancellable is returned by CancelTask when the execution has already finished.
+var ErrNotCancellable = errors.New("execution is not pending or running")
+
 // TaskExecutionRequest represents a task to be executed
 type TaskExecutionRequest struct {
 	ID             uuid.UUID              `json:"id"`
-	TaskInstanceID uuid.UUID              `json:"task_instance_id"`
+	TaskInstanceID uuid.UUID              `json:"task_instance_id"` // uuid.Nil for test executions
 	TaskType       string                 `json:"task_type"`
 	Parameters     map[string]interface{} `json:"parameters"`
 	SystemConfig   map[string]interface{} `json:"system_config"`
@@ -19,6 +38,12 @@ type TaskExecutionRequest struct {
 	ErrorMessage   *string                `json:"error_message,omitempty"`
 	Result         []byte                 `json:"result,omitempty"`
 	CompletedAt    *time.Time             `json:"completed_at,omitempty"`
+	Mode           string                 `json:"mode"`
+
+	// Test executions have no task instance, so they name the connected system
+	// to check and, optionally, the master task whose result policy applies.
+	ConnectedSystemID *uuid.UUID `json:"connected_system_id,omitempty"`
+	MasterTaskID      *uuid.UUID `json:"master_task_id,omitempty"`
 }
 
 // TaskExecutionResult represents the result of a task execution
@@ -43,6 +68,22 @@ type Queue interface {
 
 	// GetTaskStatus retrieves the current status of a task
 	GetTaskStatus(ctx context.Context, taskID uuid.UUID) (*TaskExecutionRequest, error)
+
+	// CancelTask cancels a pending task or asks the worker to stop a running one.
+	// It returns the task's new status.
+	CancelTask(ctx context.Context, taskID uuid.UUID) (string, error)
+
+	// DeferTask puts a task the worker has picked up back in the queue, to be
+	// dequeued again no earlier than until. reason is kept as its error message.
+	DeferTask(ctx context.Context, taskID uuid.UUID, until time.Time, reason string) error
+
+	// PurgeCompletedTasks deletes tasks that completed before the given time
+	// and returns how many were deleted.
+	PurgeCompletedTasks(ctx context.Context, before time.Time) (int64, error)
+
+	// LeasedTasks returns the tasks a worker has dequeued and not yet
+	// finished or deferred, including those being cancelled.
+	LeasedTasks(ctx context.Context) ([]*TaskExecutionRequest, error)
 }
 
 // NewQueue creates a new queue instance based on the configuration
@@ -0,0 +1,166 @@
+// Package rollup derives the compliance status of a campaign's requirements
+// from the task instances covering them, and sums it up per standard section.
+package rollup
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Requirement statuses.
+const (
+	Compliant          = "Compliant"
+	PartiallyCompliant = "Partially Compliant"
+	NonCompliant       = "Non-compliant"
+	NotStarted         = "Not Started"
+	NotApplicable      = "Not Applicable"
+)
+
+// Instance is what the roll-up looks at of a task instance covering a
+// requirement.
+type Instance struct {
+	Status           string
+	LastCheckStatus  string
+	ApprovedEvidence int
+	RejectedEvidence int
+	PendingEvidence  int
+}
+
+func (i Instance) failed() bool {
+	if i.Status == "Failed" || i.LastCheckStatus == "Failed" {
+		return true
+	}
+	// Rejected evidence no longer counts once approved evidence replaced it.
+	return i.RejectedEvidence > 0 && i.ApprovedEvidence == 0
+}
+
+func (i Instance) untouched() bool {
+	return i.Status == "Open" && i.LastCheckStatus == "" &&
+		i.ApprovedEvidence+i.RejectedEvidence+i.PendingEvidence == 0
+}
+
+// Status returns the status of a requirement covered by instances. A
+// requirement is compliant when every instance is closed with approved
+// evidence and non-compliant when any check failed or evidence was rejected.
+// It has not started while no instance has been worked on, which includes
+// requirements without any.
+func Status(applicable bool, instances []Instance) string {
+	if !applicable {
+		return NotApplicable
+	}
+	compliant, untouched := true, true
+	for _, i := range instances {
+		if i.failed() {
+			return NonCompliant
+		}
+		if i.Status != "Closed" || i.ApprovedEvidence == 0 {
+			compliant = false
+		}
+		if !i.untouched() {
+			untouched = false
+		}
+	}
+	switch {
+	case untouched:
+		return NotStarted
+	case compliant:
+		return Compliant
+	default:
+		return PartiallyCompliant
+	}
+}
+
+// Section returns the section of a standard a control belongs to: its ID up
+// to the first dot that follows a digit ("CC6.1" and "CC6.2" are in "CC6",
+// "A.5.1" in "A.5"). IDs without such a dot are their own section.
+func Section(controlID string) string {
+	runes := []rune(controlID)
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == '.' && unicode.IsDigit(runes[i-1]) {
+			return strings.TrimSpace(string(runes[:i]))
+		}
+	}
+	return strings.TrimSpace(controlID)
+}
+
+// Requirement is one rolled-up requirement, as input to Summarize.
+type Requirement struct {
+	StandardName string
+	ControlID    string
+	Status       string
+	// Mapped reports whether any task instance covers the requirement.
+	Mapped bool
+}
+
+// SectionCoverage sums up the applicable requirements of one section.
+type SectionCoverage struct {
+	StandardName       string
+	Section            string
+	Requirements       int
+	Compliant          int
+	PartiallyCompliant int
+	NonCompliant       int
+	NotStarted         int
+	Unmapped           int
+	// CoveragePercent is the share of requirements covered by task instances,
+	// CompliantPercent the share that is compliant.
+	CoveragePercent  float64
+	CompliantPercent float64
+}
+
+// Summarize returns the coverage of each standard section, ordered by
+// standard and section. Requirements that are not applicable are left out.
+func Summarize(requirements []Requirement) []SectionCoverage {
+	type key struct{ standard, section string }
+	bySection := make(map[key]*SectionCoverage)
+	var keys []key
+	for _, r := range requirements {
+		if r.Status == NotApplicable {
+			continue
+		}
+		k := key{r.StandardName, Section(r.ControlID)}
+		sc, ok := bySection[k]
+		if !ok {
+			sc = &SectionCoverage{StandardName: k.standard, Section: k.section}
+			bySection[k] = sc
+			keys = append(keys, k)
+		}
+		sc.Requirements++
+		if !r.Mapped {
+			sc.Unmapped++
+		}
+		switch r.Status {
+		case Compliant:
+			sc.Compliant++
+		case PartiallyCompliant:
+			sc.PartiallyCompliant++
+		case NonCompliant:
+			sc.NonCompliant++
+		case NotStarted:
+			sc.NotStarted++
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].standard != keys[j].standard {
+			return keys[i].standard < keys[j].standard
+		}
+		return keys[i].section < keys[j].section
+	})
+
+	coverage := make([]SectionCoverage, 0, len(keys))
+	for _, k := range keys {
+		sc := bySection[k]
+		sc.CoveragePercent = percent(sc.Requirements-sc.Unmapped, sc.Requirements)
+		sc.CompliantPercent = percent(sc.Compliant, sc.Requirements)
+		coverage = append(coverage, *sc)
+	}
+	return coverage
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n*10000/total) / 100
+}
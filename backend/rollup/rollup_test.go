@@ -0,0 +1,60 @@
+package rollup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	closedApproved := Instance{Status: "Closed", ApprovedEvidence: 1}
+	open := Instance{Status: "Open"}
+
+	tests := []struct {
+		name       string
+		applicable bool
+		instances  []Instance
+		want       string
+	}{
+		{"not applicable", false, []Instance{closedApproved}, NotApplicable},
+		{"no task instances", true, nil, NotStarted},
+		{"untouched", true, []Instance{open, open}, NotStarted},
+		{"all closed with approved evidence", true, []Instance{closedApproved, closedApproved}, Compliant},
+		{"closed without evidence", true, []Instance{{Status: "Closed"}}, PartiallyCompliant},
+		{"some closed", true, []Instance{closedApproved, open}, PartiallyCompliant},
+		{"evidence pending review", true, []Instance{{Status: "Open", PendingEvidence: 1}}, PartiallyCompliant},
+		{"failed task", true, []Instance{closedApproved, {Status: "Failed"}}, NonCompliant},
+		{"failed check", true, []Instance{{Status: "In Progress", LastCheckStatus: "Failed"}}, NonCompliant},
+		{"rejected evidence", true, []Instance{{Status: "Pending Review", RejectedEvidence: 1}}, NonCompliant},
+		{"rejected evidence replaced", true, []Instance{{Status: "Closed", RejectedEvidence: 1, ApprovedEvidence: 1}}, Compliant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Status(tt.applicable, tt.instances))
+		})
+	}
+}
+
+func TestSection(t *testing.T) {
+	assert.Equal(t, "CC6", Section("CC6.1"))
+	assert.Equal(t, "A.5", Section("A.5.1"))
+	assert.Equal(t, "CIS 1", Section("CIS 1.1"))
+	assert.Equal(t, "PCI 8", Section("PCI 8.2.3"))
+	assert.Equal(t, "GDPR Art. 5(1)(f)", Section("GDPR Art. 5(1)(f)"))
+}
+
+func TestSummarize(t *testing.T) {
+	coverage := Summarize([]Requirement{
+		{StandardName: "SOC 2", ControlID: "CC6.1", Status: Compliant, Mapped: true},
+		{StandardName: "SOC 2", ControlID: "CC6.2", Status: NotStarted, Mapped: false},
+		{StandardName: "SOC 2", ControlID: "CC6.3", Status: NonCompliant, Mapped: true},
+		{StandardName: "SOC 2", ControlID: "CC7.1", Status: NotApplicable, Mapped: true},
+		{StandardName: "ISO 27001", ControlID: "A.5.1", Status: PartiallyCompliant, Mapped: true},
+	})
+
+	assert.Equal(t, []SectionCoverage{
+		{StandardName: "ISO 27001", Section: "A.5", Requirements: 1, PartiallyCompliant: 1, CoveragePercent: 100},
+		{StandardName: "SOC 2", Section: "CC6", Requirements: 3, Compliant: 1, NonCompliant: 1, NotStarted: 1, Unmapped: 1,
+			CoveragePercent: 66.66, CompliantPercent: 33.33},
+	}, coverage)
+}
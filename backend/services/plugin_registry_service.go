@@ -7,18 +7,24 @@ import (
 
 	"github.com/vdparikh/compliance-automation/backend/integrations" // Adjust import path
 	"github.com/vdparikh/compliance-automation/backend/models"
-	"github.com/vdparikh/compliance-automation/backend/store"
 )
 
+// RegistryStore persists the registered plugins and their check types.
+type RegistryStore interface {
+	GetActiveCheckTypeConfigurations() (map[string]models.CheckTypeConfiguration, error)
+	CreateOrUpdateRegisteredPlugin(pluginID string, pluginName string, version string, checkConfigs map[string]models.CheckTypeConfiguration) error
+	SetRegisteredPluginActiveStatus(pluginID string, isActive bool) error
+}
+
 type PluginRegistryService struct {
 	mu                  sync.RWMutex
-	dbStore             *store.DBStore                            // Added DBStore
+	dbStore             RegistryStore
 	registeredPlugins   map[string]integrations.IntegrationPlugin // Keyed by plugin ID
 	compiledCheckTypes  map[string]models.CheckTypeConfiguration  // Keyed by check type key (e.g., "http_get_check")
 	checkTypeToPluginID map[string]string                         // Maps check type key to plugin ID
 }
 
-func NewPluginRegistryService(dbStore *store.DBStore) *PluginRegistryService {
+func NewPluginRegistryService(dbStore RegistryStore) *PluginRegistryService {
 	s := &PluginRegistryService{
 		dbStore:             dbStore,
 		registeredPlugins:   make(map[string]integrations.IntegrationPlugin),
@@ -48,10 +54,15 @@ func (s *PluginRegistryService) RegisterPlugin(plugin integrations.IntegrationPl
 
 	pluginID := plugin.ID()
 	pluginName := plugin.Name()
+	pluginVersion := plugin.Version()
 	checkConfigs := plugin.GetCheckTypeConfigurations()
+	for key, config := range checkConfigs {
+		config.SchemaVersion = config.CurrentSchemaVersion()
+		checkConfigs[key] = config
+	}
 
 	// Persist to DB
-	if err := s.dbStore.CreateOrUpdateRegisteredPlugin(pluginID, pluginName, checkConfigs); err != nil {
+	if err := s.dbStore.CreateOrUpdateRegisteredPlugin(pluginID, pluginName, pluginVersion, checkConfigs); err != nil {
 		log.Printf("Error persisting plugin %s to DB: %v", pluginID, err)
 		return err // Or handle more gracefully
 	}
@@ -64,7 +75,7 @@ func (s *PluginRegistryService) RegisterPlugin(plugin integrations.IntegrationPl
 		s.compiledCheckTypes[key] = config // Keep this for immediate availability if DB load is slow/fails
 		s.checkTypeToPluginID[key] = pluginID
 	}
-	log.Printf("Registered plugin: %s (%s) and persisted to DB.", pluginName, pluginID)
+	log.Printf("Registered plugin: %s (%s) version %s and persisted to DB.", pluginName, pluginID, pluginVersion)
 	return nil
 }
 
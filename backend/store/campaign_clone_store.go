@@ -0,0 +1,256 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/vdparikh/compliance-automation/backend/campaignstate"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+// CampaignCloneOptions controls how CloneCampaign rolls a campaign over into
+// its next period.
+type CampaignCloneOptions struct {
+	Name        string
+	Description *string
+	Rollover    campaignstate.Rollover
+	// CarryForwardEvidence adds approved evidence of the source instances to
+	// their clones as references to the same files, pending review again.
+	CarryForwardEvidence bool
+	// EvidenceUploadedAfter, if not zero, limits carried-forward evidence to
+	// items uploaded after it.
+	EvidenceUploadedAfter time.Time
+}
+
+// CampaignCloneResult summarizes what CloneCampaign created.
+type CampaignCloneResult struct {
+	CampaignID             string `json:"campaign_id"`
+	TaskInstancesCloned    int    `json:"task_instances_cloned"`
+	EvidenceCarriedForward int    `json:"evidence_carried_forward"`
+}
+
+// CloneCampaign creates a Draft campaign from sourceID with the same standards
+// and selected requirements. Each task instance is copied with its owners,
+// assignees, teams, check settings and priority, its due date moved by the
+// rollover, and previous_instance_id pointing at its source. Returns
+// ErrNotFound if the source campaign does not exist.
+func (s *DBStore) CloneCampaign(sourceID string, opts CampaignCloneOptions) (*CampaignCloneResult, error) {
+	source, err := s.GetCampaignByID(sourceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction for campaign clone: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	clone := models.Campaign{
+		ID:                   uuid.NewString(),
+		Name:                 opts.Name,
+		Description:          source.Description,
+		StandardID:           source.StandardID,
+		StartDate:            opts.Rollover.StartDate,
+		EndDate:              opts.Rollover.EndDate,
+		Status:               campaignstate.Draft,
+		ClonedFromCampaignID: &source.ID,
+	}
+	if opts.Description != nil {
+		clone.Description = opts.Description
+	}
+	_, err = tx.Exec(`
+		INSERT INTO campaigns (id, name, description, standard_id, start_date, end_date, status, cloned_from_campaign_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+	`, clone.ID, clone.Name, clone.Description, clone.StandardID, clone.StartDate, clone.EndDate, clone.Status, clone.ClonedFromCampaignID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert cloned campaign: %w", err)
+	}
+	if err := replaceCampaignStandardsTx(tx, clone.ID, source.StandardIDs); err != nil {
+		return nil, err
+	}
+
+	// Selected requirements, keeping track of each one's new ID.
+	sourceReqs, err := s.getCampaignSelectedRequirementsTx(tx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get selected requirements of campaign %s: %w", sourceID, err)
+	}
+	newCSRIDs := make(map[string]string, len(sourceReqs))
+	for _, sr := range sourceReqs {
+		newID := uuid.NewString()
+		if _, err := tx.Exec(`INSERT INTO campaign_selected_requirements (id, campaign_id, requirement_id, is_applicable) VALUES ($1, $2, $3, $4)`,
+			newID, clone.ID, sr.RequirementID, sr.IsApplicable); err != nil {
+			return nil, fmt.Errorf("failed to clone selected requirement %s: %w", sr.RequirementID, err)
+		}
+		newCSRIDs[sr.ID] = newID
+	}
+
+	instances, err := s.getCampaignTaskInstancesForCloneTx(tx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	result := &CampaignCloneResult{CampaignID: clone.ID}
+	for _, instance := range instances {
+		sourceInstanceID := instance.ID
+		cti := instance
+		cti.CampaignID = clone.ID
+		cti.Status = "Open"
+		cti.DueDate = opts.Rollover.ShiftDueDate(instance.DueDate)
+		cti.PreviousInstanceID = &sourceInstanceID
+		if instance.CampaignSelectedRequirementID != nil {
+			if newID, ok := newCSRIDs[*instance.CampaignSelectedRequirementID]; ok {
+				cti.CampaignSelectedRequirementID = &newID
+			} else {
+				cti.CampaignSelectedRequirementID = nil
+			}
+		}
+		newInstanceID, err := s.CreateCampaignTaskInstance(tx, &cti)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone task instance %s: %w", sourceInstanceID, err)
+		}
+		var linked []string
+		for _, csrID := range instance.CampaignSelectedRequirementIDs {
+			if newID, ok := newCSRIDs[csrID]; ok {
+				linked = append(linked, newID)
+			}
+		}
+		if err := linkTaskInstanceRequirementsTx(tx, newInstanceID, linked); err != nil {
+			return nil, err
+		}
+		result.TaskInstancesCloned++
+
+		if opts.CarryForwardEvidence {
+			carried, err := carryForwardEvidenceTx(tx, sourceInstanceID, newInstanceID, opts.EvidenceUploadedAfter)
+			if err != nil {
+				return nil, err
+			}
+			result.EvidenceCarriedForward += carried
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit campaign clone: %w", err)
+	}
+	return result, nil
+}
+
+// getCampaignTaskInstancesForCloneTx reads the instance-level settings of a
+// campaign's task instances, including their owners and requirements.
+func (s *DBStore) getCampaignTaskInstancesForCloneTx(tx *sql.Tx, campaignID string) ([]models.CampaignTaskInstance, error) {
+	rows, err := tx.Query(`
+		SELECT id, master_task_id, campaign_selected_requirement_id, title, description, category,
+		       assignee_user_id, owner_team_id, assignee_team_id, priority, due_date,
+		       check_type, target, parameters,
+		       ARRAY(SELECT user_id::text FROM campaign_task_instance_owners o WHERE o.campaign_task_instance_id = cti.id),
+		       ARRAY(SELECT campaign_selected_requirement_id::text FROM campaign_task_instance_requirements l WHERE l.campaign_task_instance_id = cti.id)
+		FROM campaign_task_instances cti
+		WHERE campaign_id = $1
+		ORDER BY created_at
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task instances of campaign %s: %w", campaignID, err)
+	}
+	defer rows.Close()
+
+	var instances []models.CampaignTaskInstance
+	for rows.Next() {
+		var cti models.CampaignTaskInstance
+		var paramsJSON []byte
+		var owners []string
+		if err := rows.Scan(&cti.ID, &cti.MasterTaskID, &cti.CampaignSelectedRequirementID, &cti.Title, &cti.Description, &cti.Category,
+			&cti.AssigneeUserID, &cti.OwnerTeamID, &cti.AssigneeTeamID, &cti.Priority, &cti.DueDate,
+			&cti.CheckType, &cti.Target, &paramsJSON, pq.Array(&owners), pq.Array(&cti.CampaignSelectedRequirementIDs)); err != nil {
+			return nil, fmt.Errorf("failed to scan task instance of campaign %s: %w", campaignID, err)
+		}
+		if len(paramsJSON) > 0 && string(paramsJSON) != "null" {
+			if err := json.Unmarshal(paramsJSON, &cti.Parameters); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal parameters of task instance %s: %w", cti.ID, err)
+			}
+		}
+		cti.OwnerUserIDs = owners
+		instances = append(instances, cti)
+	}
+	return instances, rows.Err()
+}
+
+// carryForwardEvidenceTx references the approved evidence of one task
+// instance from another and returns how many items it added.
+func carryForwardEvidenceTx(tx *sql.Tx, fromInstanceID, toInstanceID string, uploadedAfter time.Time) (int, error) {
+	query := `
+		INSERT INTO evidence (campaign_task_instance_id, uploaded_by_user_id, file_name, file_path, mime_type, file_size,
+		                      description, uploaded_at, review_status, carried_forward_from_id, created_at, updated_at)
+		SELECT $2, uploaded_by_user_id, file_name, file_path, mime_type, file_size,
+		       description, uploaded_at, 'Pending', id, NOW(), NOW()
+		FROM evidence
+		WHERE campaign_task_instance_id = $1 AND review_status = 'Approved'`
+	args := []interface{}{fromInstanceID, toInstanceID}
+	if !uploadedAfter.IsZero() {
+		query += ` AND uploaded_at > $3`
+		args = append(args, uploadedAfter)
+	}
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to carry forward evidence of task instance %s: %w", fromInstanceID, err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// CompareCampaignWithPrevious pairs each task instance of a cloned campaign
+// with its counterpart in the campaign it was cloned from. Instances added
+// after the clone have no previous side. Returns ErrNotFound if the campaign
+// does not exist and an empty comparison if it was not cloned.
+func (s *DBStore) CompareCampaignWithPrevious(campaignID string) (*models.CampaignComparison, error) {
+	comparison := &models.CampaignComparison{CampaignID: campaignID, Instances: []models.CampaignInstanceComparison{}}
+	err := s.DB.QueryRow(`
+		SELECT c.cloned_from_campaign_id, p.name
+		FROM campaigns c LEFT JOIN campaigns p ON p.id = c.cloned_from_campaign_id
+		WHERE c.id = $1
+	`, campaignID).Scan(&comparison.PreviousCampaignID, &comparison.PreviousCampaignName)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lineage of campaign %s: %w", campaignID, err)
+	}
+	if comparison.PreviousCampaignID == nil {
+		return comparison, nil
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT cur.id, cur.title, cur.status, cur.last_check_status,
+		       (SELECT COUNT(*) FROM evidence e WHERE e.campaign_task_instance_id = cur.id),
+		       prev.id, prev.status, prev.last_check_status,
+		       (SELECT COUNT(*) FROM evidence e WHERE e.campaign_task_instance_id = prev.id)
+		FROM campaign_task_instances cur
+		LEFT JOIN campaign_task_instances prev ON prev.id = cur.previous_instance_id
+		WHERE cur.campaign_id = $1
+		ORDER BY cur.title
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare campaign %s with its previous period: %w", campaignID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ic models.CampaignInstanceComparison
+		var prevEvidence sql.NullInt64
+		if err := rows.Scan(&ic.InstanceID, &ic.Title, &ic.Status, &ic.LastCheckStatus, &ic.EvidenceCount,
+			&ic.PreviousInstanceID, &ic.PreviousStatus, &ic.PreviousLastCheckStatus, &prevEvidence); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign comparison row: %w", err)
+		}
+		if ic.PreviousInstanceID != nil {
+			n := int(prevEvidence.Int64)
+			ic.PreviousEvidenceCount = &n
+		}
+		comparison.Instances = append(comparison.Instances, ic)
+	}
+	return comparison, rows.Err()
+}
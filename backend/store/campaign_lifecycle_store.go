@@ -0,0 +1,346 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/vdparikh/compliance-automation/backend/assignment"
+	"github.com/vdparikh/compliance-automation/backend/campaignstate"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+// campaignDetailColumns are selected after the campaign columns by
+// GetCampaigns and GetCampaignByID and scanned by campaignDetailScan.
+const campaignDetailColumns = `c.signed_off_by_user_id, so.name AS signed_off_by_name, c.signed_off_at, c.sign_off_comment, c.cloned_from_campaign_id,
+	COALESCE((
+		SELECT json_agg(json_build_object('id', std.id, 'name', std.name, 'short_name', std.short_name)
+		                ORDER BY std.id IS NOT DISTINCT FROM c.standard_id DESC, std.name)
+		FROM campaign_standards cst JOIN compliance_standards std ON std.id = cst.standard_id
+		WHERE cst.campaign_id = c.id
+	), '[]') AS standards`
+
+type campaignDetailScan struct {
+	userID, userName sql.NullString
+	at               sql.NullTime
+	comment          sql.NullString
+	clonedFrom       sql.NullString
+	standards        []byte
+}
+
+func (s *campaignDetailScan) dest() []interface{} {
+	return []interface{}{&s.userID, &s.userName, &s.at, &s.comment, &s.clonedFrom, &s.standards}
+}
+
+// apply sets the sign-off, the lineage, the standards and the fields derived
+// from the status.
+func (s *campaignDetailScan) apply(camp *models.Campaign) {
+	if s.at.Valid {
+		camp.SignOff = &models.CampaignSignOff{SignedOffAt: s.at.Time}
+		if s.userID.Valid {
+			camp.SignOff.UserID = &s.userID.String
+		}
+		if s.userName.Valid {
+			camp.SignOff.UserName = &s.userName.String
+		}
+		if s.comment.Valid {
+			camp.SignOff.Comment = &s.comment.String
+		}
+	}
+	if s.clonedFrom.Valid {
+		camp.ClonedFromCampaignID = &s.clonedFrom.String
+	}
+	if err := json.Unmarshal(s.standards, &camp.Standards); err != nil {
+		log.Printf("Warning: failed to unmarshal standards of campaign %s: %v", camp.ID, err)
+	}
+	for _, std := range camp.Standards {
+		camp.StandardIDs = append(camp.StandardIDs, std.ID)
+	}
+	camp.Frozen = campaignstate.IsFrozen(camp.Status)
+	camp.AllowedTransitions = campaignstate.Next(camp.Status)
+}
+
+// createMissingCampaignTaskInstancesTx creates one task instance for every
+// master task linked to any of the campaign's applicable requirements that
+// does not have one yet, with the master task's default owners, assignees and
+// due date, and links each instance to all of those requirements. It returns
+// how many instances it created.
+func (s *DBStore) createMissingCampaignTaskInstancesTx(tx *sql.Tx, campaignID string) (int, error) {
+	var startDate, endDate *time.Time
+	if err := tx.QueryRow(`SELECT start_date, end_date FROM campaigns WHERE id = $1`, campaignID).Scan(&startDate, &endDate); err != nil {
+		return 0, fmt.Errorf("failed to get period of campaign %s: %w", campaignID, err)
+	}
+
+	// Requirements of the primary standard come first, so that a new
+	// instance's primary requirement is one of them where possible.
+	rows, err := tx.Query(`
+		SELECT tr.task_id, csr.id
+		FROM campaign_selected_requirements csr
+		JOIN campaigns c ON c.id = csr.campaign_id
+		JOIN requirements r ON r.id = csr.requirement_id
+		JOIN task_requirements tr ON tr.requirement_id = csr.requirement_id
+		WHERE csr.campaign_id = $1 AND csr.is_applicable = true
+		ORDER BY r.standard_id IS NOT DISTINCT FROM c.standard_id DESC, r.control_id_reference, csr.id
+	`, campaignID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query master tasks of campaign %s: %w", campaignID, err)
+	}
+	var taskOrder []string
+	requirementsByTask := make(map[string][]string)
+	for rows.Next() {
+		var taskID, csrID string
+		if err := rows.Scan(&taskID, &csrID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan master task of campaign %s: %w", campaignID, err)
+		}
+		if _, ok := requirementsByTask[taskID]; !ok {
+			taskOrder = append(taskOrder, taskID)
+		}
+		requirementsByTask[taskID] = append(requirementsByTask[taskID], csrID)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+
+	existing, err := existingTaskInstancesByMasterTaskTx(tx, campaignID)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, taskID := range taskOrder {
+		csrIDs := requirementsByTask[taskID]
+		ctiID, ok := existing[taskID]
+		if !ok {
+			masterTask, err := s.GetTaskByID(taskID)
+			if err != nil {
+				return created, fmt.Errorf("error fetching master task %s: %w", taskID, err)
+			}
+			if masterTask == nil {
+				continue
+			}
+			masterTaskID := masterTask.ID
+			primaryCSRID := csrIDs[0]
+			cti := models.CampaignTaskInstance{
+				CampaignID:                    campaignID,
+				MasterTaskID:                  &masterTaskID,
+				CampaignSelectedRequirementID: &primaryCSRID,
+				Title:                         masterTask.Title,
+				Status:                        "Open",
+				CheckType:                     masterTask.CheckType,
+				Target:                        masterTask.Target,
+				Parameters:                    masterTask.Parameters,
+				Priority:                      masterTask.DefaultPriority,
+			}
+			if masterTask.Description != "" {
+				description := masterTask.Description
+				cti.Description = &description
+			}
+			if masterTask.Category != "" {
+				category := masterTask.Category
+				cti.Category = &category
+			}
+			if err := applyTaskDefaultsTx(tx, &cti, masterTask, startDate, endDate); err != nil {
+				return created, err
+			}
+			if ctiID, err = s.CreateCampaignTaskInstance(tx, &cti); err != nil {
+				return created, fmt.Errorf("error creating CTI for master task %s (CSR_ID: %s): %w", masterTask.ID, primaryCSRID, err)
+			}
+			created++
+		}
+		if err := linkTaskInstanceRequirementsTx(tx, ctiID, csrIDs); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// existingTaskInstancesByMasterTaskTx maps the master tasks of a campaign's
+// task instances to the instance generated from them. Campaigns generated
+// before instances were shared between requirements may have several; the
+// oldest is returned.
+func existingTaskInstancesByMasterTaskTx(tx *sql.Tx, campaignID string) (map[string]string, error) {
+	rows, err := tx.Query(`
+		SELECT master_task_id, id FROM campaign_task_instances
+		WHERE campaign_id = $1 AND master_task_id IS NOT NULL
+		ORDER BY created_at DESC
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task instances of campaign %s: %w", campaignID, err)
+	}
+	defer rows.Close()
+	existing := make(map[string]string)
+	for rows.Next() {
+		var masterTaskID, ctiID string
+		if err := rows.Scan(&masterTaskID, &ctiID); err != nil {
+			return nil, fmt.Errorf("failed to scan task instance of campaign %s: %w", campaignID, err)
+		}
+		existing[masterTaskID] = ctiID
+	}
+	return existing, rows.Err()
+}
+
+// applyTaskDefaultsTx sets the owners, assignees and due date of a task
+// instance about to be generated from masterTask in a campaign running from
+// start to end.
+func applyTaskDefaultsTx(tx *sql.Tx, cti *models.CampaignTaskInstance, masterTask *models.Task, start, end *time.Time) error {
+	cti.OwnerTeamID = masterTask.DefaultOwnerTeamID
+	cti.AssigneeTeamID = masterTask.DefaultAssigneeTeamID
+	if masterTask.DefaultOwnerUserID != nil {
+		cti.OwnerUserIDs = []string{*masterTask.DefaultOwnerUserID}
+	}
+	if masterTask.DueDateAnchor != nil && masterTask.DueDateOffsetDays != nil {
+		cti.DueDate = assignment.DueDate(*masterTask.DueDateAnchor, *masterTask.DueDateOffsetDays, start, end)
+	}
+
+	cti.AssigneeUserID = masterTask.DefaultAssigneeUserID
+	if cti.AssigneeUserID == nil && cti.AssigneeTeamID != nil {
+		userID, ok, err := resolveTeamAssigneeTx(tx, *cti.AssigneeTeamID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			cti.AssigneeUserID = &userID
+		}
+	}
+	return nil
+}
+
+// resolveTeamAssigneeTx picks the member of a team to assign a new task
+// instance to by the team's assignment strategy and records the pick for
+// round-robin. It returns false if the team has no members.
+func resolveTeamAssigneeTx(tx *sql.Tx, teamID string) (string, bool, error) {
+	var strategy string
+	var lastAssigned sql.NullString
+	err := tx.QueryRow(`SELECT assignment_strategy, last_assigned_user_id FROM teams WHERE id = $1 FOR UPDATE`, teamID).Scan(&strategy, &lastAssigned)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get assignment strategy of team %s: %w", teamID, err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT tm.user_id, tm.role_in_team = 'lead',
+		       (SELECT COUNT(*) FROM campaign_task_instances cti WHERE cti.assignee_user_id = tm.user_id AND cti.status <> ALL($2))
+		FROM team_members tm
+		WHERE tm.team_id = $1
+	`, teamID, pq.Array(campaignstate.TerminalTaskStatuses()))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get members of team %s: %w", teamID, err)
+	}
+	var members []assignment.Member
+	for rows.Next() {
+		var m assignment.Member
+		if err := rows.Scan(&m.UserID, &m.IsLead, &m.OpenTasks); err != nil {
+			rows.Close()
+			return "", false, fmt.Errorf("failed to scan member of team %s: %w", teamID, err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Close(); err != nil {
+		return "", false, err
+	}
+
+	userID, ok := assignment.Pick(strategy, members, lastAssigned.String)
+	if !ok {
+		return "", false, nil
+	}
+	if _, err := tx.Exec(`UPDATE teams SET last_assigned_user_id = $2 WHERE id = $1`, teamID, userID); err != nil {
+		return "", false, fmt.Errorf("failed to record assignment of team %s: %w", teamID, err)
+	}
+	return userID, true, nil
+}
+
+// TransitionCampaign moves a campaign to status to if campaignstate allows it
+// from the campaign's current status. Activating a draft creates its task
+// instances; completing requires signOff, which is recorded on the campaign.
+// A refused transition is returned as a *campaignstate.TransitionError.
+func (s *DBStore) TransitionCampaign(campaignID, to string, signOff *models.CampaignSignOff) (from string, generated int, err error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to begin transaction for campaign transition: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRow(`SELECT status FROM campaigns WHERE id = $1 FOR UPDATE`, campaignID).Scan(&from); err != nil {
+		if err == sql.ErrNoRows {
+			return "", 0, ErrNotFound
+		}
+		return "", 0, fmt.Errorf("failed to lock campaign %s: %w", campaignID, err)
+	}
+
+	facts := campaignstate.Facts{SignedOff: signOff != nil}
+	err = tx.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM campaign_selected_requirements WHERE campaign_id = $1 AND is_applicable = true),
+			(SELECT COUNT(*) FROM campaign_task_instances WHERE campaign_id = $1 AND status <> ALL($2))
+	`, campaignID, pq.Array(campaignstate.TerminalTaskStatuses())).Scan(&facts.ApplicableRequirements, &facts.OpenTaskInstances)
+	if err != nil {
+		return from, 0, fmt.Errorf("failed to check campaign %s: %w", campaignID, err)
+	}
+	if err := campaignstate.Check(from, to, facts); err != nil {
+		return from, 0, err
+	}
+
+	if campaignstate.GeneratesTaskInstancesOn(from, to) {
+		if generated, err = s.createMissingCampaignTaskInstancesTx(tx, campaignID); err != nil {
+			return from, generated, err
+		}
+	}
+
+	now := time.Now()
+	if signOff != nil {
+		_, err = tx.Exec(`
+			UPDATE campaigns SET status = $2, updated_at = $3, signed_off_by_user_id = $4, signed_off_at = $5, sign_off_comment = $6
+			WHERE id = $1
+		`, campaignID, to, now, signOff.UserID, signOff.SignedOffAt, signOff.Comment)
+	} else {
+		_, err = tx.Exec(`UPDATE campaigns SET status = $2, updated_at = $3 WHERE id = $1`, campaignID, to, now)
+	}
+	if err != nil {
+		return from, generated, fmt.Errorf("failed to update status of campaign %s: %w", campaignID, err)
+	}
+	return from, generated, tx.Commit()
+}
+
+// GetCampaignStatusForTaskInstance returns the status of the campaign a task
+// instance belongs to, or ErrNotFound if there is no such task instance.
+func (s *DBStore) GetCampaignStatusForTaskInstance(ctiID string) (string, error) {
+	var status string
+	err := s.DB.QueryRow(`
+		SELECT c.status FROM campaign_task_instances cti
+		JOIN campaigns c ON c.id = cti.campaign_id
+		WHERE cti.id = $1
+	`, ctiID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get campaign status for task instance %s: %w", ctiID, err)
+	}
+	return status, nil
+}
+
+// GetCampaignStatusForEvidence returns the status of the campaign an evidence
+// item belongs to, "" for evidence not attached to a campaign task instance,
+// or ErrNotFound if there is no such evidence.
+func (s *DBStore) GetCampaignStatusForEvidence(evidenceID string) (string, error) {
+	var status sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT c.status FROM evidence e
+		LEFT JOIN campaign_task_instances cti ON cti.id = e.campaign_task_instance_id
+		LEFT JOIN campaigns c ON c.id = cti.campaign_id
+		WHERE e.id = $1
+	`, evidenceID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get campaign status for evidence %s: %w", evidenceID, err)
+	}
+	return status.String, nil
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+	"github.com/vdparikh/compliance-automation/backend/rollup"
+)
+
+// GetCampaignComplianceRollup rolls the status of a campaign's task
+// instances, their checks and their evidence up to its selected requirements
+// and the sections of its standards. Returns ErrNotFound if the campaign does
+// not exist.
+func (s *DBStore) GetCampaignComplianceRollup(campaignID string) (*models.CampaignComplianceRollup, error) {
+	var exists bool
+	if err := s.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM campaigns WHERE id = $1)`, campaignID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up campaign %s: %w", campaignID, err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	requirements, err := s.getRequirementRollups(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := s.getRollupInstancesByRequirement(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CampaignComplianceRollup{
+		CampaignID:           campaignID,
+		StatusCounts:         make(map[string]int),
+		Requirements:         requirements,
+		Sections:             []models.SectionCoverage{},
+		UnmappedRequirements: []models.RequirementRollup{},
+	}
+	summarized := make([]rollup.Requirement, 0, len(requirements))
+	for i := range result.Requirements {
+		r := &result.Requirements[i]
+		covering := instances[r.CampaignSelectedRequirementID]
+		r.TaskInstanceIDs = make([]string, 0, len(covering))
+		states := make([]rollup.Instance, 0, len(covering))
+		for _, ri := range covering {
+			r.TaskInstanceIDs = append(r.TaskInstanceIDs, ri.id)
+			states = append(states, ri.state)
+		}
+		r.Section = rollup.Section(r.ControlIDReference)
+		r.Status = rollup.Status(r.IsApplicable, states)
+		result.StatusCounts[r.Status]++
+		if r.IsApplicable && !r.HasMasterTasks {
+			result.UnmappedRequirements = append(result.UnmappedRequirements, *r)
+		}
+		summarized = append(summarized, rollup.Requirement{
+			StandardName: r.StandardName,
+			ControlID:    r.ControlIDReference,
+			Status:       r.Status,
+			Mapped:       len(covering) > 0,
+		})
+	}
+	for _, sc := range rollup.Summarize(summarized) {
+		result.Sections = append(result.Sections, models.SectionCoverage(sc))
+	}
+	return result, nil
+}
+
+// getRequirementRollups reads a campaign's selected requirements, noting for
+// each whether any master task is linked to it.
+func (s *DBStore) getRequirementRollups(campaignID string) ([]models.RequirementRollup, error) {
+	rows, err := s.DB.Query(`
+		SELECT csr.id, csr.requirement_id, csr.is_applicable,
+		       req.control_id_reference, req.requirement_text, std.id, std.name,
+		       EXISTS (SELECT 1 FROM task_requirements tr WHERE tr.requirement_id = csr.requirement_id)
+		FROM campaign_selected_requirements csr
+		JOIN requirements req ON req.id = csr.requirement_id
+		JOIN compliance_standards std ON std.id = req.standard_id
+		WHERE csr.campaign_id = $1
+		ORDER BY std.name, req.control_id_reference
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query selected requirements of campaign %s: %w", campaignID, err)
+	}
+	defer rows.Close()
+
+	requirements := []models.RequirementRollup{}
+	for rows.Next() {
+		var r models.RequirementRollup
+		if err := rows.Scan(&r.CampaignSelectedRequirementID, &r.RequirementID, &r.IsApplicable,
+			&r.ControlIDReference, &r.RequirementText, &r.StandardID, &r.StandardName, &r.HasMasterTasks); err != nil {
+			return nil, fmt.Errorf("failed to scan selected requirement of campaign %s: %w", campaignID, err)
+		}
+		requirements = append(requirements, r)
+	}
+	return requirements, rows.Err()
+}
+
+type rollupInstance struct {
+	id    string
+	state rollup.Instance
+}
+
+// getRollupInstancesByRequirement maps each selected requirement of a
+// campaign to the task instances covering it, with their check result and
+// evidence counts by review status.
+func (s *DBStore) getRollupInstancesByRequirement(campaignID string) (map[string][]rollupInstance, error) {
+	rows, err := s.DB.Query(`
+		SELECT l.csr_id, cti.id, cti.status, cti.last_check_status,
+		       COUNT(e.id) FILTER (WHERE e.review_status = 'Approved'),
+		       COUNT(e.id) FILTER (WHERE e.review_status = 'Rejected'),
+		       COUNT(e.id) FILTER (WHERE e.review_status IS NULL OR e.review_status NOT IN ('Approved', 'Rejected'))
+		FROM (
+			SELECT campaign_task_instance_id AS cti_id, campaign_selected_requirement_id AS csr_id
+			FROM campaign_task_instance_requirements
+			UNION
+			SELECT id, campaign_selected_requirement_id
+			FROM campaign_task_instances
+			WHERE campaign_id = $1 AND campaign_selected_requirement_id IS NOT NULL
+		) l
+		JOIN campaign_task_instances cti ON cti.id = l.cti_id
+		LEFT JOIN evidence e ON e.campaign_task_instance_id = cti.id
+		WHERE cti.campaign_id = $1
+		GROUP BY l.csr_id, cti.id, cti.status, cti.last_check_status, cti.created_at
+		ORDER BY cti.created_at
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task instances of campaign %s for roll-up: %w", campaignID, err)
+	}
+	defer rows.Close()
+
+	byRequirement := make(map[string][]rollupInstance)
+	for rows.Next() {
+		var csrID string
+		var ri rollupInstance
+		var lastCheck sql.NullString
+		if err := rows.Scan(&csrID, &ri.id, &ri.state.Status, &lastCheck,
+			&ri.state.ApprovedEvidence, &ri.state.RejectedEvidence, &ri.state.PendingEvidence); err != nil {
+			return nil, fmt.Errorf("failed to scan task instance of campaign %s for roll-up: %w", campaignID, err)
+		}
+		ri.state.LastCheckStatus = lastCheck.String
+		byRequirement[csrID] = append(byRequirement[csrID], ri)
+	}
+	return byRequirement, rows.Err()
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// replaceCampaignStandardsTx sets the standards a campaign is scoped to.
+func replaceCampaignStandardsTx(tx *sql.Tx, campaignID string, standardIDs []string) error {
+	if _, err := tx.Exec(`DELETE FROM campaign_standards WHERE campaign_id = $1`, campaignID); err != nil {
+		return fmt.Errorf("failed to clear standards of campaign %s: %w", campaignID, err)
+	}
+	if len(standardIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(`
+		INSERT INTO campaign_standards (campaign_id, standard_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`, campaignID, pq.Array(standardIDs))
+	if err != nil {
+		return fmt.Errorf("failed to set standards of campaign %s: %w", campaignID, err)
+	}
+	return nil
+}
+
+// linkTaskInstanceRequirementsTx adds selected requirements to those a task
+// instance covers. Existing links are kept.
+func linkTaskInstanceRequirementsTx(tx *sql.Tx, ctiID string, csrIDs []string) error {
+	if len(csrIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(`
+		INSERT INTO campaign_task_instance_requirements (campaign_task_instance_id, campaign_selected_requirement_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`, ctiID, pq.Array(csrIDs))
+	if err != nil {
+		return fmt.Errorf("failed to link task instance %s to its requirements: %w", ctiID, err)
+	}
+	return nil
+}
+
+// removeCampaignSelectedRequirementTx takes a requirement out of a campaign's
+// scope. Task instances covering only that requirement are deleted; those
+// that also cover others keep them and get one as their primary requirement.
+func removeCampaignSelectedRequirementTx(tx *sql.Tx, campaignID, csrID string) error {
+	_, err := tx.Exec(`
+		DELETE FROM campaign_task_instances cti
+		WHERE cti.campaign_id = $1
+		  AND (cti.campaign_selected_requirement_id = $2 OR EXISTS (
+		      SELECT 1 FROM campaign_task_instance_requirements l
+		      WHERE l.campaign_task_instance_id = cti.id AND l.campaign_selected_requirement_id = $2))
+		  AND NOT EXISTS (
+		      SELECT 1 FROM campaign_task_instance_requirements l
+		      WHERE l.campaign_task_instance_id = cti.id AND l.campaign_selected_requirement_id <> $2)
+	`, campaignID, csrID)
+	if err != nil {
+		return fmt.Errorf("failed to delete task instances of selected requirement %s: %w", csrID, err)
+	}
+	_, err = tx.Exec(`
+		UPDATE campaign_task_instances cti
+		SET campaign_selected_requirement_id = (
+			SELECT l.campaign_selected_requirement_id FROM campaign_task_instance_requirements l
+			WHERE l.campaign_task_instance_id = cti.id AND l.campaign_selected_requirement_id <> $2
+			LIMIT 1)
+		WHERE cti.campaign_id = $1 AND cti.campaign_selected_requirement_id = $2
+	`, campaignID, csrID)
+	if err != nil {
+		return fmt.Errorf("failed to move task instances off selected requirement %s: %w", csrID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM campaign_selected_requirements WHERE id = $1`, csrID); err != nil {
+		return fmt.Errorf("failed to delete campaign_selected_requirement %s: %w", csrID, err)
+	}
+	return nil
+}
+
+// getTaskInstanceRequirementLinks maps each task instance of a campaign to
+// the selected requirements it covers.
+func (s *DBStore) getTaskInstanceRequirementLinks(campaignID string) (map[string][]string, error) {
+	rows, err := s.DB.Query(`
+		SELECT l.campaign_task_instance_id, l.campaign_selected_requirement_id
+		FROM campaign_task_instance_requirements l
+		JOIN campaign_task_instances cti ON cti.id = l.campaign_task_instance_id
+		WHERE cti.campaign_id = $1
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query requirement links of campaign %s: %w", campaignID, err)
+	}
+	defer rows.Close()
+	links := make(map[string][]string)
+	for rows.Next() {
+		var ctiID, csrID string
+		if err := rows.Scan(&ctiID, &csrID); err != nil {
+			return nil, fmt.Errorf("failed to scan requirement link of campaign %s: %w", campaignID, err)
+		}
+		links[ctiID] = append(links[ctiID], csrID)
+	}
+	return links, rows.Err()
+}
@@ -0,0 +1,257 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+// CreateConfigSnapshot saves a snapshot and its resources in one transaction.
+func (s *DBStore) CreateConfigSnapshot(snapshot *models.ConfigSnapshot) error {
+	if snapshot.ID == "" {
+		snapshot.ID = uuid.NewString()
+	}
+	if snapshot.CapturedAt.IsZero() {
+		snapshot.CapturedAt = time.Now()
+	}
+	snapshot.ResourceCount = len(snapshot.Resources)
+
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for config snapshot: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO config_snapshots (id, connected_system_id, scope, campaign_task_instance_id, task_execution_id, resource_count, captured_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		snapshot.ID, snapshot.ConnectedSystemID, snapshot.Scope, snapshot.CampaignTaskInstanceID, snapshot.TaskExecutionID, snapshot.ResourceCount, snapshot.CapturedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create config snapshot: %w", err)
+	}
+
+	stmt, err := tx.Preparex(`
+		INSERT INTO config_snapshot_resources (snapshot_id, resource_type, resource_id, attributes)
+		VALUES ($1, $2, $3, $4)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare config snapshot resource insert: %w", err)
+	}
+	defer stmt.Close()
+	for _, r := range snapshot.Resources {
+		attrs := r.Attributes
+		if attrs == nil {
+			attrs = map[string]interface{}{}
+		}
+		attrsJSON, err := json.Marshal(attrs)
+		if err != nil {
+			return fmt.Errorf("failed to marshal attributes of %s %s: %w", r.ResourceType, r.ResourceID, err)
+		}
+		if _, err := stmt.Exec(snapshot.ID, r.ResourceType, r.ResourceID, attrsJSON); err != nil {
+			return fmt.Errorf("failed to save %s %s in config snapshot: %w", r.ResourceType, r.ResourceID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit config snapshot: %w", err)
+	}
+	return nil
+}
+
+const configSnapshotColumns = `id, connected_system_id, scope, campaign_task_instance_id, task_execution_id, resource_count, captured_at`
+
+// GetConfigSnapshotByID returns a snapshot with its resources, or ErrNotFound.
+func (s *DBStore) GetConfigSnapshotByID(snapshotID string) (*models.ConfigSnapshot, error) {
+	var snapshot models.ConfigSnapshot
+	err := s.DB.Get(&snapshot, `SELECT `+configSnapshotColumns+` FROM config_snapshots WHERE id = $1`, snapshotID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get config snapshot %s: %w", snapshotID, err)
+	}
+	if snapshot.Resources, err = s.getConfigSnapshotResources(snapshotID); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetPreviousConfigSnapshot returns, with resources, the latest snapshot of the
+// same system and scope captured before the given one, or ErrNotFound.
+func (s *DBStore) GetPreviousConfigSnapshot(current *models.ConfigSnapshot) (*models.ConfigSnapshot, error) {
+	var snapshot models.ConfigSnapshot
+	err := s.DB.Get(&snapshot, `
+		SELECT `+configSnapshotColumns+`
+		FROM config_snapshots
+		WHERE connected_system_id = $1 AND scope = $2 AND id <> $3 AND captured_at <= $4
+		ORDER BY captured_at DESC
+		LIMIT 1`,
+		current.ConnectedSystemID, current.Scope, current.ID, current.CapturedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get previous config snapshot: %w", err)
+	}
+	if snapshot.Resources, err = s.getConfigSnapshotResources(snapshot.ID); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetConfigSnapshotsBySystemID lists a system's snapshots without resources,
+// newest first, optionally limited to one scope.
+func (s *DBStore) GetConfigSnapshotsBySystemID(systemID, scope string) ([]models.ConfigSnapshot, error) {
+	snapshots := []models.ConfigSnapshot{}
+	query := `SELECT ` + configSnapshotColumns + ` FROM config_snapshots WHERE connected_system_id = $1`
+	args := []interface{}{systemID}
+	if scope != "" {
+		query += ` AND scope = $2`
+		args = append(args, scope)
+	}
+	query += ` ORDER BY captured_at DESC`
+	if err := s.DB.Select(&snapshots, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get config snapshots for system %s: %w", systemID, err)
+	}
+	return snapshots, nil
+}
+
+func (s *DBStore) getConfigSnapshotResources(snapshotID string) ([]models.SnapshotResource, error) {
+	rows, err := s.DB.Query(`
+		SELECT resource_type, resource_id, attributes
+		FROM config_snapshot_resources
+		WHERE snapshot_id = $1
+		ORDER BY resource_type, resource_id`, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources of config snapshot %s: %w", snapshotID, err)
+	}
+	defer rows.Close()
+
+	resources := []models.SnapshotResource{}
+	for rows.Next() {
+		var r models.SnapshotResource
+		var attrsJSON []byte
+		if err := rows.Scan(&r.ResourceType, &r.ResourceID, &attrsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan config snapshot resource: %w", err)
+		}
+		if err := json.Unmarshal(attrsJSON, &r.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attributes of %s %s: %w", r.ResourceType, r.ResourceID, err)
+		}
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
+}
+
+// CreateConfigDriftEvents saves drift events detected for one snapshot.
+func (s *DBStore) CreateConfigDriftEvents(events []models.ConfigDriftEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for drift events: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Preparex(`
+		INSERT INTO config_drift_events (
+			id, connected_system_id, scope, snapshot_id, previous_snapshot_id, campaign_task_instance_id,
+			resource_type, resource_id, change_type, changed_attributes, requirement_ids, detected_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare drift event insert: %w", err)
+	}
+	defer stmt.Close()
+	for i := range events {
+		e := &events[i]
+		if e.ID == "" {
+			e.ID = uuid.NewString()
+		}
+		_, err := stmt.Exec(e.ID, e.ConnectedSystemID, e.Scope, e.SnapshotID, e.PreviousSnapshotID, e.CampaignTaskInstanceID,
+			e.ResourceType, e.ResourceID, e.ChangeType, pq.Array(e.ChangedAttributes), pq.Array(e.RequirementIDs), e.DetectedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create drift event for %s %s: %w", e.ResourceType, e.ResourceID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ConfigDriftFilter narrows GetConfigDriftEvents. Empty fields are ignored.
+type ConfigDriftFilter struct {
+	ConnectedSystemID string
+	RequirementID     string
+	Since             *time.Time
+}
+
+// GetConfigDriftEvents lists drift events, newest first.
+func (s *DBStore) GetConfigDriftEvents(filter ConfigDriftFilter) ([]models.ConfigDriftEvent, error) {
+	query := `
+		SELECT id, connected_system_id, scope, snapshot_id, previous_snapshot_id, campaign_task_instance_id,
+		       resource_type, resource_id, change_type, changed_attributes, requirement_ids, detected_at
+		FROM config_drift_events
+		WHERE 1 = 1`
+	var args []interface{}
+	if filter.ConnectedSystemID != "" {
+		args = append(args, filter.ConnectedSystemID)
+		query += fmt.Sprintf(" AND connected_system_id = $%d", len(args))
+	}
+	if filter.RequirementID != "" {
+		args = append(args, filter.RequirementID)
+		query += fmt.Sprintf(" AND $%d = ANY(requirement_ids)", len(args))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		query += fmt.Sprintf(" AND detected_at >= $%d", len(args))
+	}
+	query += " ORDER BY detected_at DESC, resource_type, resource_id"
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config drift events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.ConfigDriftEvent{}
+	for rows.Next() {
+		var e models.ConfigDriftEvent
+		if err := rows.Scan(&e.ID, &e.ConnectedSystemID, &e.Scope, &e.SnapshotID, &e.PreviousSnapshotID, &e.CampaignTaskInstanceID,
+			&e.ResourceType, &e.ResourceID, &e.ChangeType, pq.Array(&e.ChangedAttributes), pq.Array(&e.RequirementIDs), &e.DetectedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan config drift event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// GetRequirementIDsForTaskInstance returns the requirements a campaign task
+// instance covers: its campaign-selected requirements and those linked to its master task.
+func (s *DBStore) GetRequirementIDsForTaskInstance(instanceID string) ([]string, error) {
+	var ids []string
+	err := s.DB.Select(&ids, `
+		SELECT DISTINCT requirement_id::text FROM (
+			SELECT csr.requirement_id
+			FROM campaign_task_instances cti
+			JOIN campaign_selected_requirements csr ON csr.id = cti.campaign_selected_requirement_id
+			WHERE cti.id = $1
+			UNION
+			SELECT csr.requirement_id
+			FROM campaign_task_instance_requirements l
+			JOIN campaign_selected_requirements csr ON csr.id = l.campaign_selected_requirement_id
+			WHERE l.campaign_task_instance_id = $1
+			UNION
+			SELECT tr.requirement_id
+			FROM campaign_task_instances cti
+			JOIN task_requirements tr ON tr.task_id = cti.master_task_id
+			WHERE cti.id = $1
+		) reqs
+		ORDER BY 1`, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get requirements for task instance %s: %w", instanceID, err)
+	}
+	return ids, nil
+}
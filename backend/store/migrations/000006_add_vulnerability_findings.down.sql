@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_vulnerability_findings_asset_vuln;
+DROP INDEX IF EXISTS idx_vulnerability_findings_report_id;
+DROP INDEX IF EXISTS idx_vulnerability_reports_cti_id;
+
+DROP TABLE IF EXISTS vulnerability_findings;
+DROP TABLE IF EXISTS vulnerability_reports;
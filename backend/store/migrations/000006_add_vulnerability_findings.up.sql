@@ -0,0 +1,32 @@
+-- Vulnerability scanner reports ingested against campaign task instances.
+-- Reports outlive the task instance (ON DELETE SET NULL) so per-asset history
+-- survives when old campaigns are removed.
+CREATE TABLE IF NOT EXISTS vulnerability_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    campaign_task_instance_id UUID REFERENCES campaign_task_instances(id) ON DELETE SET NULL,
+    evidence_id UUID REFERENCES evidence(id) ON DELETE SET NULL,
+    format VARCHAR(20) NOT NULL, -- trivy, grype, nessus, sarif
+    uploaded_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    findings_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Normalized findings. first_seen_at is copied from the earliest earlier
+-- finding for the same asset, vulnerability and package.
+CREATE TABLE IF NOT EXISTS vulnerability_findings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report_id UUID NOT NULL REFERENCES vulnerability_reports(id) ON DELETE CASCADE,
+    asset VARCHAR(512) NOT NULL,
+    vulnerability_id VARCHAR(255) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    package_name VARCHAR(512) NOT NULL DEFAULT '',
+    installed_version VARCHAR(255),
+    fixed_version VARCHAR(255),
+    title TEXT,
+    first_seen_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_vulnerability_reports_cti_id ON vulnerability_reports(campaign_task_instance_id);
+CREATE INDEX IF NOT EXISTS idx_vulnerability_findings_report_id ON vulnerability_findings(report_id);
+CREATE INDEX IF NOT EXISTS idx_vulnerability_findings_asset_vuln ON vulnerability_findings(asset, vulnerability_id, package_name);
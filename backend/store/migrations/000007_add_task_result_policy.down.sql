@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS result_policy;
+ALTER TABLE tasks DROP COLUMN IF EXISTS result_policy_language;
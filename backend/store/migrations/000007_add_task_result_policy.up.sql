@@ -0,0 +1,4 @@
+-- Optional CEL or Rego policy that decides the status of a master task's
+-- automated check from the plugin output.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS result_policy_language VARCHAR(10);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS result_policy TEXT;
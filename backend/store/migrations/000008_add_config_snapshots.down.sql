@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS config_drift_events;
+DROP TABLE IF EXISTS config_snapshot_resources;
+DROP TABLE IF EXISTS config_snapshots;
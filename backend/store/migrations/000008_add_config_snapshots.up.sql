@@ -0,0 +1,42 @@
+-- Normalized resource snapshots emitted by plugins, one per connected system,
+-- scope (check type) and execution.
+CREATE TABLE IF NOT EXISTS config_snapshots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    connected_system_id UUID NOT NULL REFERENCES connected_systems(id) ON DELETE CASCADE,
+    scope VARCHAR(100) NOT NULL,
+    campaign_task_instance_id UUID REFERENCES campaign_task_instances(id) ON DELETE SET NULL,
+    task_execution_id UUID,
+    resource_count INTEGER NOT NULL DEFAULT 0,
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS config_snapshot_resources (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    snapshot_id UUID NOT NULL REFERENCES config_snapshots(id) ON DELETE CASCADE,
+    resource_type VARCHAR(100) NOT NULL,
+    resource_id VARCHAR(1024) NOT NULL,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    UNIQUE (snapshot_id, resource_type, resource_id)
+);
+
+-- Resources that changed between consecutive snapshots, tagged with the
+-- requirements mapped to the capturing task.
+CREATE TABLE IF NOT EXISTS config_drift_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    connected_system_id UUID NOT NULL REFERENCES connected_systems(id) ON DELETE CASCADE,
+    scope VARCHAR(100) NOT NULL,
+    snapshot_id UUID NOT NULL REFERENCES config_snapshots(id) ON DELETE CASCADE,
+    previous_snapshot_id UUID NOT NULL REFERENCES config_snapshots(id) ON DELETE CASCADE,
+    campaign_task_instance_id UUID REFERENCES campaign_task_instances(id) ON DELETE SET NULL,
+    resource_type VARCHAR(100) NOT NULL,
+    resource_id VARCHAR(1024) NOT NULL,
+    change_type VARCHAR(20) NOT NULL, -- added, removed, changed
+    changed_attributes TEXT[],
+    requirement_ids TEXT[],
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_snapshots_system_scope ON config_snapshots(connected_system_id, scope, captured_at DESC);
+CREATE INDEX IF NOT EXISTS idx_config_snapshot_resources_snapshot_id ON config_snapshot_resources(snapshot_id);
+CREATE INDEX IF NOT EXISTS idx_config_drift_events_system ON config_drift_events(connected_system_id, detected_at DESC);
+CREATE INDEX IF NOT EXISTS idx_config_drift_events_requirement_ids ON config_drift_events USING GIN (requirement_ids);
@@ -0,0 +1,3 @@
+ALTER TABLE task_executions DROP COLUMN IF EXISTS master_task_id;
+ALTER TABLE task_executions DROP COLUMN IF EXISTS connected_system_id;
+ALTER TABLE task_executions DROP COLUMN IF EXISTS mode;
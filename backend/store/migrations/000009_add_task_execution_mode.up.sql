@@ -0,0 +1,5 @@
+-- Test executions from the master task editor share the queue with regular runs.
+-- They carry their own target and master task since they have no task instance.
+ALTER TABLE task_executions ADD COLUMN IF NOT EXISTS mode VARCHAR(20) NOT NULL DEFAULT 'run';
+ALTER TABLE task_executions ADD COLUMN IF NOT EXISTS connected_system_id UUID;
+ALTER TABLE task_executions ADD COLUMN IF NOT EXISTS master_task_id UUID;
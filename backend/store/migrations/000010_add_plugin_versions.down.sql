@@ -0,0 +1,6 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS check_type_schema_version;
+ALTER TABLE campaign_task_instance_results DROP COLUMN IF EXISTS check_type_schema_version;
+ALTER TABLE campaign_task_instance_results DROP COLUMN IF EXISTS plugin_version;
+ALTER TABLE campaign_task_instance_results DROP COLUMN IF EXISTS plugin_id;
+DROP TABLE IF EXISTS registered_plugin_versions;
+ALTER TABLE registered_plugins DROP COLUMN IF EXISTS version;
@@ -0,0 +1,19 @@
+-- Plugin semantic versions, their history, and result provenance.
+ALTER TABLE registered_plugins ADD COLUMN IF NOT EXISTS version TEXT NOT NULL DEFAULT '0.0.0';
+
+CREATE TABLE IF NOT EXISTS registered_plugin_versions (
+    plugin_id TEXT NOT NULL REFERENCES registered_plugins(id) ON DELETE CASCADE,
+    version TEXT NOT NULL,
+    check_type_configurations JSONB NOT NULL,
+    first_registered_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_registered_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (plugin_id, version)
+);
+
+ALTER TABLE campaign_task_instance_results ADD COLUMN IF NOT EXISTS plugin_id TEXT;
+ALTER TABLE campaign_task_instance_results ADD COLUMN IF NOT EXISTS plugin_version TEXT;
+ALTER TABLE campaign_task_instance_results ADD COLUMN IF NOT EXISTS check_type_schema_version INTEGER;
+
+-- Every check type starts at schema version 1, so existing tasks are current.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS check_type_schema_version INTEGER;
+UPDATE tasks SET check_type_schema_version = 1 WHERE check_type IS NOT NULL AND check_type <> '' AND check_type_schema_version IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_execution_logs_execution_id;
+DROP TABLE IF EXISTS task_execution_logs;
@@ -0,0 +1,10 @@
+-- Progress lines reported by plugins while an execution runs.
+CREATE TABLE IF NOT EXISTS task_execution_logs (
+    id BIGSERIAL PRIMARY KEY,
+    execution_id UUID NOT NULL,
+    level VARCHAR(10) NOT NULL DEFAULT 'info',
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_execution_logs_execution_id ON task_execution_logs(execution_id, id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_task_execution_logs_created_at;
+DROP INDEX IF EXISTS idx_ctir_cti_id_timestamp;
+ALTER TABLE campaign_task_instance_results DROP COLUMN IF EXISTS repeated_until;
+ALTER TABLE campaign_task_instance_results DROP COLUMN IF EXISTS repeat_count;
@@ -0,0 +1,6 @@
+-- Retention folds identical consecutive successes into the first of their run.
+ALTER TABLE campaign_task_instance_results ADD COLUMN IF NOT EXISTS repeat_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE campaign_task_instance_results ADD COLUMN IF NOT EXISTS repeated_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_ctir_cti_id_timestamp ON campaign_task_instance_results(campaign_task_instance_id, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_task_execution_logs_created_at ON task_execution_logs(created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE campaigns DROP COLUMN IF EXISTS sign_off_comment;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS signed_off_at;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS signed_off_by_user_id;
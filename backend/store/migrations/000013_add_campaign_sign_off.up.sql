@@ -0,0 +1,4 @@
+-- Completing a campaign is a recorded sign-off by the approving user.
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS signed_off_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS signed_off_at TIMESTAMPTZ;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS sign_off_comment TEXT;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_cti_previous_instance_id;
+DROP INDEX IF EXISTS idx_campaigns_cloned_from;
+ALTER TABLE evidence DROP COLUMN IF EXISTS carried_forward_from_id;
+ALTER TABLE campaign_task_instances DROP COLUMN IF EXISTS previous_instance_id;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS cloned_from_campaign_id;
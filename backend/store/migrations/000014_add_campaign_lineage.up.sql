@@ -0,0 +1,8 @@
+-- Lineage between a campaign and the clone that rolls it over into the next
+-- audit period, down to task instances and carried-forward evidence.
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS cloned_from_campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL;
+ALTER TABLE campaign_task_instances ADD COLUMN IF NOT EXISTS previous_instance_id UUID REFERENCES campaign_task_instances(id) ON DELETE SET NULL;
+ALTER TABLE evidence ADD COLUMN IF NOT EXISTS carried_forward_from_id UUID REFERENCES evidence(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_cloned_from ON campaigns(cloned_from_campaign_id);
+CREATE INDEX IF NOT EXISTS idx_cti_previous_instance_id ON campaign_task_instances(previous_instance_id);
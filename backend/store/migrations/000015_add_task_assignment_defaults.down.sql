@@ -0,0 +1,6 @@
+ALTER TABLE teams DROP COLUMN IF EXISTS last_assigned_user_id;
+ALTER TABLE teams DROP COLUMN IF EXISTS assignment_strategy;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_date_offset_days;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_date_anchor;
+ALTER TABLE tasks DROP COLUMN IF EXISTS default_assignee_user_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS default_owner_user_id;
@@ -0,0 +1,12 @@
+-- Defaults a master task passes on to the task instances generated from it:
+-- owner and assignee users (next to the existing default teams) and a due
+-- date relative to the campaign's start or end. Teams choose how an assignee
+-- team is resolved to one member and remember whom they assigned last.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS default_owner_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS default_assignee_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_date_anchor VARCHAR(10) CHECK (due_date_anchor IN ('start', 'end'));
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_date_offset_days INTEGER;
+
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS assignment_strategy VARCHAR(20) NOT NULL DEFAULT 'lead'
+    CHECK (assignment_strategy IN ('lead', 'round_robin', 'least_loaded'));
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS last_assigned_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS campaign_task_instance_requirements;
+DROP TABLE IF EXISTS campaign_standards;
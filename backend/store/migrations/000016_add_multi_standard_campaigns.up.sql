@@ -0,0 +1,28 @@
+-- Campaigns can be scoped to several standards. campaigns.standard_id stays
+-- as the primary standard and is listed in campaign_standards as well.
+CREATE TABLE IF NOT EXISTS campaign_standards (
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    standard_id UUID NOT NULL REFERENCES compliance_standards(id) ON DELETE CASCADE,
+    PRIMARY KEY (campaign_id, standard_id)
+);
+
+INSERT INTO campaign_standards (campaign_id, standard_id)
+SELECT id, standard_id FROM campaigns WHERE standard_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- A task instance covers every applicable selected requirement its master
+-- task is linked to, so a control shared between standards is tested once.
+-- campaign_task_instances.campaign_selected_requirement_id stays as the
+-- primary requirement and is listed here as well.
+CREATE TABLE IF NOT EXISTS campaign_task_instance_requirements (
+    campaign_task_instance_id UUID NOT NULL REFERENCES campaign_task_instances(id) ON DELETE CASCADE,
+    campaign_selected_requirement_id UUID NOT NULL REFERENCES campaign_selected_requirements(id) ON DELETE CASCADE,
+    PRIMARY KEY (campaign_task_instance_id, campaign_selected_requirement_id)
+);
+
+INSERT INTO campaign_task_instance_requirements (campaign_task_instance_id, campaign_selected_requirement_id)
+SELECT id, campaign_selected_requirement_id FROM campaign_task_instances WHERE campaign_selected_requirement_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_campaign_standards_standard_id ON campaign_standards(standard_id);
+CREATE INDEX IF NOT EXISTS idx_cti_requirements_csr_id ON campaign_task_instance_requirements(campaign_selected_requirement_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_campaigns_created_at;
+DROP INDEX IF EXISTS idx_cti_campaign_id_status_due_date;
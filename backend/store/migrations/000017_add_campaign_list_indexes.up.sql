@@ -0,0 +1,4 @@
+-- The campaign list counts each campaign's task instances by status and
+-- overdue due date; this index answers that without reading the rows.
+CREATE INDEX IF NOT EXISTS idx_cti_campaign_id_status_due_date ON campaign_task_instances(campaign_id, status, due_date);
+CREATE INDEX IF NOT EXISTS idx_campaigns_created_at ON campaigns(created_at);
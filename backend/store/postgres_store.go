@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"github.com/vdparikh/compliance-automation/backend/campaignstate"
 	"github.com/vdparikh/compliance-automation/backend/models"
 )
 
@@ -39,7 +40,7 @@ type Store interface {
 	// CreateTaskEvidence(evidence *models.Evidence) error
 	GetTaskEvidence(taskID string) ([]models.Evidence, error)
 	CreateCampaign(campaign *models.Campaign, selectedReqs []models.CampaignSelectedRequirement) (string, error)
-	GetCampaigns(campaignStatus string) ([]models.Campaign, error)
+	GetCampaigns(filter CampaignListFilter) ([]models.Campaign, int, error)
 	GetCampaignByID(campaignID string) (*models.Campaign, error)
 	UpdateCampaign(campaign *models.Campaign, newSelectedReqs []models.CampaignSelectedRequirement) error
 	DeleteCampaign(campaignID string) error
@@ -69,7 +70,7 @@ type Store interface {
 	GetAuditLogs(filters map[string]interface{}, page, limit int) ([]models.AuditLog, int, error)
 
 	// Plugin Registration
-	CreateOrUpdateRegisteredPlugin(pluginID string, pluginName string, checkConfigs map[string]models.CheckTypeConfiguration) error
+	CreateOrUpdateRegisteredPlugin(pluginID string, pluginName string, version string, checkConfigs map[string]models.CheckTypeConfiguration) error
 	SetRegisteredPluginActiveStatus(pluginID string, isActive bool) error
 	// GetActiveCheckTypeConfigurations() (map[string]models.CheckTypeConfiguration, error) // Already exists
 
@@ -175,27 +176,104 @@ func (s *DBStore) GetActiveCheckTypeConfigurations() (map[string]models.CheckTyp
 	return mergedConfigs, rows.Err()
 }
 
-// CreateOrUpdateRegisteredPlugin persists or updates a plugin's registration details.
-func (s *DBStore) CreateOrUpdateRegisteredPlugin(pluginID string, pluginName string, checkConfigs map[string]models.CheckTypeConfiguration) error {
+// GetCheckTypePluginIDs maps each check type key of an active plugin to the
+// ID of the plugin that registered it.
+func (s *DBStore) GetCheckTypePluginIDs() (map[string]string, error) {
+	rows, err := s.DB.Query(`SELECT id, check_type_configurations FROM registered_plugins WHERE is_active = TRUE;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active plugin check types: %w", err)
+	}
+	defer rows.Close()
+
+	owners := make(map[string]string)
+	for rows.Next() {
+		var pluginID string
+		var configsJSON json.RawMessage
+		if err := rows.Scan(&pluginID, &configsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan plugin check types row: %w", err)
+		}
+		var pluginCheckConfigs map[string]json.RawMessage
+		if err := json.Unmarshal(configsJSON, &pluginCheckConfigs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal check types of plugin %s: %w", pluginID, err)
+		}
+		for key := range pluginCheckConfigs {
+			owners[key] = pluginID
+		}
+	}
+	return owners, rows.Err()
+}
+
+// CreateOrUpdateRegisteredPlugin persists or updates a plugin's registration details
+// and records the version in the plugin's version history.
+func (s *DBStore) CreateOrUpdateRegisteredPlugin(pluginID string, pluginName string, version string, checkConfigs map[string]models.CheckTypeConfiguration) error {
 	configsJSON, err := json.Marshal(checkConfigs)
 	if err != nil {
 		return fmt.Errorf("failed to marshal check type configurations for plugin %s: %w", pluginID, err)
 	}
 
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for plugin %s: %w", pluginID, err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO registered_plugins (id, name, check_type_configurations, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, TRUE, NOW(), NOW())
+		INSERT INTO registered_plugins (id, name, version, check_type_configurations, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, TRUE, NOW(), NOW())
 		ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
+			version = EXCLUDED.version,
 			check_type_configurations = EXCLUDED.check_type_configurations,
 			is_active = TRUE, -- Ensure it's marked active on update/re-registration
 			updated_at = NOW();
 	`
-	_, err = s.DB.Exec(query, pluginID, pluginName, configsJSON)
-	if err != nil {
+	if _, err = tx.Exec(query, pluginID, pluginName, version, configsJSON); err != nil {
 		return fmt.Errorf("failed to create or update registered plugin %s: %w", pluginID, err)
 	}
-	return nil
+
+	// The configurations of a version are kept as last registered, so a
+	// plugin rebuilt without a version bump still shows what it declares.
+	historyQuery := `
+		INSERT INTO registered_plugin_versions (plugin_id, version, check_type_configurations, first_registered_at, last_registered_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (plugin_id, version) DO UPDATE SET
+			check_type_configurations = EXCLUDED.check_type_configurations,
+			last_registered_at = NOW();
+	`
+	if _, err = tx.Exec(historyQuery, pluginID, version, configsJSON); err != nil {
+		return fmt.Errorf("failed to record version %s of plugin %s: %w", version, pluginID, err)
+	}
+	return tx.Commit()
+}
+
+// GetRegisteredPluginVersions returns the version history of a plugin, most
+// recently registered first.
+func (s *DBStore) GetRegisteredPluginVersions(pluginID string) ([]models.RegisteredPluginVersion, error) {
+	query := `
+		SELECT plugin_id, version, check_type_configurations, first_registered_at, last_registered_at
+		FROM registered_plugin_versions
+		WHERE plugin_id = $1
+		ORDER BY last_registered_at DESC;
+	`
+	rows, err := s.DB.Query(query, pluginID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query versions of plugin %s: %w", pluginID, err)
+	}
+	defer rows.Close()
+
+	versions := []models.RegisteredPluginVersion{}
+	for rows.Next() {
+		var v models.RegisteredPluginVersion
+		var configsJSON []byte
+		if err := rows.Scan(&v.PluginID, &v.Version, &configsJSON, &v.FirstRegisteredAt, &v.LastRegisteredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan plugin version row: %w", err)
+		}
+		if err := json.Unmarshal(configsJSON, &v.CheckTypeConfigurations); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal check type configurations of plugin %s %s: %w", pluginID, v.Version, err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
 }
 
 // SetRegisteredPluginActiveStatus updates the active status of a plugin.
@@ -274,9 +352,11 @@ func (s *DBStore) CreateTask(task *models.Task) (string, error) {
 
 	query := `
 		INSERT INTO tasks (
-			id, title, description, category, created_at, updated_at, version, priority, status, tags, high_level_check_type, check_type, target, parameters, linked_document_ids, evidence_types_expected, default_priority
+			id, title, description, category, created_at, updated_at, version, priority, status, tags, high_level_check_type, check_type, target, parameters, linked_document_ids, evidence_types_expected, default_priority,
+			result_policy_language, result_policy, check_type_schema_version,
+			default_owner_team_id, default_assignee_team_id, default_owner_user_id, default_assignee_user_id, due_date_anchor, due_date_offset_days
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26
 		) RETURNING id
 	`
 	_, err = tx.Exec(query,
@@ -297,6 +377,15 @@ func (s *DBStore) CreateTask(task *models.Task) (string, error) {
 		linkedDocIDs,
 		evidenceTypesExpected,
 		task.DefaultPriority,
+		task.ResultPolicyLanguage,
+		task.ResultPolicy,
+		task.CheckTypeSchemaVersion,
+		task.DefaultOwnerTeamID,
+		task.DefaultAssigneeTeamID,
+		task.DefaultOwnerUserID,
+		task.DefaultAssigneeUserID,
+		task.DueDateAnchor,
+		task.DueDateOffsetDays,
 	)
 	if err != nil {
 		return "", fmt.Errorf("failed to insert task: %w", err)
@@ -339,6 +428,8 @@ func (s *DBStore) GetTasks(userID, userField string) ([]models.Task, error) {
 	baseQuery := `
 		SELECT t.id, t.title, t.description, t.category, t.created_at, t.updated_at,
 		       t.version, t.priority, t.status, t.tags, t.high_level_check_type, t.check_type, t.target, t.parameters, t.evidence_types_expected, t.default_priority,
+		       t.result_policy_language, t.result_policy, t.check_type_schema_version,
+		       t.default_owner_team_id, t.default_assignee_team_id, t.default_owner_user_id, t.default_assignee_user_id, t.due_date_anchor, t.due_date_offset_days,
 		       COALESCE(
 			   json_agg(
 				   json_build_object(
@@ -385,6 +476,8 @@ func (s *DBStore) GetTasks(userID, userField string) ([]models.Task, error) {
 			&t.CreatedAt, &t.UpdatedAt,
 			&t.Version, &t.Priority, &t.Status, &tagsJSON, &t.HighLevelCheckType, &t.CheckType, &t.Target,
 			&paramsJSON, pq.Array(&t.EvidenceTypesExpected), &t.DefaultPriority,
+			&t.ResultPolicyLanguage, &t.ResultPolicy, &t.CheckTypeSchemaVersion,
+			&t.DefaultOwnerTeamID, &t.DefaultAssigneeTeamID, &t.DefaultOwnerUserID, &t.DefaultAssigneeUserID, &t.DueDateAnchor, &t.DueDateOffsetDays,
 			&requirementsJSON,
 		)
 		if err != nil {
@@ -437,6 +530,8 @@ func (s *DBStore) GetTaskByID(taskID string) (*models.Task, error) {
 	query := `
 		SELECT t.id, t.title, t.description, t.category, t.created_at, t.updated_at, 
 		       t.check_type, t.target, t.parameters, t.evidence_types_expected, t.default_priority,
+		       t.result_policy_language, t.result_policy, t.check_type_schema_version,
+		       t.default_owner_team_id, t.default_assignee_team_id, t.default_owner_user_id, t.default_assignee_user_id, t.due_date_anchor, t.due_date_offset_days,
 		       COALESCE(
 			   json_agg(
 				   json_build_object(
@@ -462,6 +557,8 @@ func (s *DBStore) GetTaskByID(taskID string) (*models.Task, error) {
 		&task.ID, &task.Title, &task.Description, &task.Category,
 		&task.CreatedAt, &task.UpdatedAt, &task.CheckType, &task.Target,
 		&paramsJSON, pq.Array(&task.EvidenceTypesExpected), &task.DefaultPriority,
+		&task.ResultPolicyLanguage, &task.ResultPolicy, &task.CheckTypeSchemaVersion,
+		&task.DefaultOwnerTeamID, &task.DefaultAssigneeTeamID, &task.DefaultOwnerUserID, &task.DefaultAssigneeUserID, &task.DueDateAnchor, &task.DueDateOffsetDays,
 		&requirementsJSON,
 	)
 	if err != nil {
@@ -523,11 +620,15 @@ func (s *DBStore) UpdateTask(task *models.Task) error {
 
 	query := `
 		UPDATE tasks
-		SET title = $2, description = $3, category = $4, updated_at = $5, version = $6, priority = $7, status = $8, tags = $9, high_level_check_type = $10, check_type = $11, target = $12, parameters = $13, evidence_types_expected = $14, default_priority = $15
+		SET title = $2, description = $3, category = $4, updated_at = $5, version = $6, priority = $7, status = $8, tags = $9, high_level_check_type = $10, check_type = $11, target = $12, parameters = $13, evidence_types_expected = $14, default_priority = $15,
+		    result_policy_language = $16, result_policy = $17, check_type_schema_version = $18,
+		    default_owner_team_id = $19, default_assignee_team_id = $20, default_owner_user_id = $21, default_assignee_user_id = $22, due_date_anchor = $23, due_date_offset_days = $24
 		WHERE id = $1
 	`
 	_, err = tx.Exec(query,
 		task.ID, task.Title, task.Description, task.Category, task.UpdatedAt, task.Version, task.Priority, task.Status, tagsJSON, task.HighLevelCheckType, task.CheckType, task.Target, paramsJSON, pq.Array(task.EvidenceTypesExpected), task.DefaultPriority,
+		task.ResultPolicyLanguage, task.ResultPolicy, task.CheckTypeSchemaVersion,
+		task.DefaultOwnerTeamID, task.DefaultAssigneeTeamID, task.DefaultOwnerUserID, task.DefaultAssigneeUserID, task.DueDateAnchor, task.DueDateOffsetDays,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
@@ -573,6 +674,8 @@ func (s *DBStore) GetTasksByRequirementID(requirementID string) ([]models.Task,
 	query := `
 		SELECT t.id, t.title, t.description, t.category, t.created_at, t.updated_at, 
 		       t.check_type, t.target, t.parameters, t.evidence_types_expected, t.default_priority,
+		       t.result_policy_language, t.result_policy, t.check_type_schema_version,
+		       t.default_owner_team_id, t.default_assignee_team_id, t.default_owner_user_id, t.default_assignee_user_id, t.due_date_anchor, t.due_date_offset_days,
 		       COALESCE(
 			   json_agg(
 				   json_build_object(
@@ -606,6 +709,8 @@ func (s *DBStore) GetTasksByRequirementID(requirementID string) ([]models.Task,
 			&t.ID, &t.Title, &t.Description, &t.Category,
 			&t.CreatedAt, &t.UpdatedAt, &t.CheckType, &t.Target,
 			&paramsJSON, pq.Array(&t.EvidenceTypesExpected), &t.DefaultPriority,
+			&t.ResultPolicyLanguage, &t.ResultPolicy, &t.CheckTypeSchemaVersion,
+			&t.DefaultOwnerTeamID, &t.DefaultAssigneeTeamID, &t.DefaultOwnerUserID, &t.DefaultAssigneeUserID, &t.DueDateAnchor, &t.DueDateOffsetDays,
 			&requirementsJSON,
 		)
 		if err != nil {
@@ -1086,7 +1191,8 @@ func (s *DBStore) GetCampaignTaskInstanceEvidence(campaignTaskInstanceID string)
 	query := `
 		SELECT id, task_id, campaign_task_instance_id, uploaded_by_user_id, 
 		       file_name, file_path, mime_type, file_size, description, uploaded_at,
-		       created_at, updated_at, review_status, reviewed_by_user_id, reviewed_at, review_comments
+		       created_at, updated_at, review_status, reviewed_by_user_id, reviewed_at, review_comments,
+		       carried_forward_from_id
 		FROM evidence
 		WHERE campaign_task_instance_id = $1
 		ORDER BY uploaded_at DESC
@@ -1102,7 +1208,8 @@ func (s *DBStore) GetCampaignTaskInstanceEvidence(campaignTaskInstanceID string)
 		var ev models.Evidence
 		if err := rows.Scan(&ev.ID, &ev.TaskID, &ev.CampaignTaskInstanceID, &ev.UploadedByUserID,
 			&ev.FileName, &ev.FilePath, &ev.MimeType, &ev.FileSize, &ev.Description, &ev.UploadedAt,
-			&ev.CreatedAt, &ev.UpdatedAt, &ev.ReviewStatus, &ev.ReviewedByUserID, &ev.ReviewedAt, &ev.ReviewComments); err != nil {
+			&ev.CreatedAt, &ev.UpdatedAt, &ev.ReviewStatus, &ev.ReviewedByUserID, &ev.ReviewedAt, &ev.ReviewComments,
+			&ev.CarriedForwardFromID); err != nil {
 
 			return nil, fmt.Errorf("failed to scan campaign task evidence row: %w", err)
 		}
@@ -1157,6 +1264,9 @@ func (s *DBStore) CreateCampaign(campaign *models.Campaign, selectedReqs []model
 	if err != nil {
 		return "", fmt.Errorf("failed to insert campaign: %w", err)
 	}
+	if err := replaceCampaignStandardsTx(tx, campaign.ID, campaign.StandardIDs); err != nil {
+		return "", err
+	}
 
 	if len(selectedReqs) > 0 {
 		stmt, err := tx.Prepare(`
@@ -1169,47 +1279,15 @@ func (s *DBStore) CreateCampaign(campaign *models.Campaign, selectedReqs []model
 		defer stmt.Close()
 
 		for _, sr := range selectedReqs {
-			campaignSelectedRequirementID := uuid.NewString()
-			_, err = stmt.Exec(campaignSelectedRequirementID, campaign.ID, sr.RequirementID, sr.IsApplicable)
+			_, err = stmt.Exec(uuid.NewString(), campaign.ID, sr.RequirementID, sr.IsApplicable)
 			if err != nil {
 				return "", fmt.Errorf("failed to insert campaign_selected_requirement: %w", err)
 			}
-			if sr.IsApplicable {
-				masterTasks, err := s.GetTasksByRequirementID(sr.RequirementID)
-				if err != nil {
-					log.Printf("Error fetching master tasks for requirement %s during campaign creation: %v", sr.RequirementID, err)
-					continue
-				}
-
-				for _, masterTask := range masterTasks {
-					campaignTaskInstance := models.CampaignTaskInstance{
-						CampaignID:                    campaign.ID,
-						MasterTaskID:                  &masterTask.ID,
-						CampaignSelectedRequirementID: &campaignSelectedRequirementID,
-						Title:                         masterTask.Title,
-						Description:                   &masterTask.Description,
-						Category:                      &masterTask.Category,
-						Status:                        "Open",
-						CheckType:                     masterTask.CheckType,
-						Target:                        masterTask.Target,
-						Parameters:                    masterTask.Parameters,
-						Priority:                      masterTask.DefaultPriority,
-					}
-
-					if masterTask.Description == "" {
-						campaignTaskInstance.Description = nil
-					}
-					if masterTask.Category == "" {
-						campaignTaskInstance.Category = nil
-					}
-
-					_, err := s.CreateCampaignTaskInstance(tx, &campaignTaskInstance)
-					if err != nil {
-						log.Printf("Error creating campaign task instance for master task %s: %v", masterTask.ID, err)
-						continue
-					}
-				}
-			}
+		}
+	}
+	if campaignstate.GeneratesTaskInstances(campaign.Status) {
+		if _, err := s.createMissingCampaignTaskInstancesTx(tx, campaign.ID); err != nil {
+			return "", err
 		}
 	}
 	if err = tx.Commit(); err != nil {
@@ -1218,57 +1296,155 @@ func (s *DBStore) CreateCampaign(campaign *models.Campaign, selectedReqs []model
 	return campaign.ID, nil
 }
 
-func (s *DBStore) GetCampaigns(campaignStatus string) ([]models.Campaign, error) {
+// CampaignListFilter narrows and pages GetCampaigns. Empty fields are
+// ignored; a Limit of 0 returns all matching campaigns.
+type CampaignListFilter struct {
+	Status     string
+	StandardID string
+	// From and To keep campaigns whose period overlaps the range.
+	From, To  *time.Time
+	SortBy    string // A key of campaignSortColumns, created_at by default
+	SortOrder string // "asc" or "desc" (default)
+	Page      int
+	Limit     int
+}
+
+// campaignSortColumns maps the sort keys of the campaign list to columns.
+var campaignSortColumns = map[string]string{
+	"name":               "c.name",
+	"status":             "c.status",
+	"start_date":         "c.start_date",
+	"end_date":           "c.end_date",
+	"created_at":         "c.created_at",
+	"updated_at":         "c.updated_at",
+	"requirements_count": "requirements_count",
+	"total_tasks":        "ts.total",
+	"overdue_tasks":      "ts.overdue",
+}
+
+// IsCampaignSortColumn reports whether the campaign list can be sorted by key.
+func IsCampaignSortColumn(key string) bool {
+	_, ok := campaignSortColumns[key]
+	return ok
+}
+
+// GetCampaigns returns a page of the campaigns matching filter together with
+// their requirement count and task summary, and the number of campaigns
+// matching in total.
+func (s *DBStore) GetCampaigns(filter CampaignListFilter) ([]models.Campaign, int, error) {
+	where := " WHERE 1 = 1"
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND c.status = $%d", len(args))
+	}
+	if filter.StandardID != "" {
+		args = append(args, filter.StandardID)
+		where += fmt.Sprintf(" AND (c.standard_id = $%[1]d OR EXISTS (SELECT 1 FROM campaign_standards cst WHERE cst.campaign_id = c.id AND cst.standard_id = $%[1]d))", len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(" AND (c.end_date IS NULL OR c.end_date >= $%d)", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(" AND (c.start_date IS NULL OR c.start_date <= $%d)", len(args))
+	}
+
+	var total int
+	if err := s.DB.QueryRow("SELECT COUNT(*) FROM campaigns c"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count campaigns: %w", err)
+	}
+
+	sortColumn, ok := campaignSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = campaignSortColumns["created_at"]
+	}
+	sortOrder := "DESC"
+	if strings.EqualFold(filter.SortOrder, "asc") {
+		sortOrder = "ASC"
+	}
+
 	query := `
-		SELECT c.id, c.name, c.description, c.standard_id, cs.name as standard_name, c.start_date, c.end_date, c.status, c.created_at, c.updated_at
+		SELECT c.id, c.name, c.description, c.standard_id, cs.name as standard_name, c.start_date, c.end_date, c.status, c.created_at, c.updated_at,
+			` + campaignDetailColumns + `,
+			(SELECT COUNT(*) FROM campaign_selected_requirements csr WHERE csr.campaign_id = c.id) AS requirements_count,
+			ts.total, ts.open, ts.in_progress, ts.pending_review, ts.closed, ts.failed, ts.overdue
 		FROM campaigns c
 		LEFT JOIN compliance_standards cs ON c.standard_id = cs.id
-	`
-	if campaignStatus != "" {
-		query += fmt.Sprintf(" WHERE c.status = '%s'", campaignStatus)
+		LEFT JOIN users so ON c.signed_off_by_user_id = so.id
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS total,
+			       COUNT(*) FILTER (WHERE cti.status = 'Open') AS open,
+			       COUNT(*) FILTER (WHERE cti.status = 'In Progress') AS in_progress,
+			       COUNT(*) FILTER (WHERE cti.status = 'Pending Review') AS pending_review,
+			       COUNT(*) FILTER (WHERE cti.status = 'Closed') AS closed,
+			       COUNT(*) FILTER (WHERE cti.status = 'Failed') AS failed,
+			       COUNT(*) FILTER (WHERE cti.due_date < NOW() AND cti.status NOT IN ('Closed', 'Failed')) AS overdue
+			FROM campaign_task_instances cti
+			WHERE cti.campaign_id = c.id
+		) ts
+	` + where + fmt.Sprintf(" ORDER BY %s %s NULLS LAST, c.id", sortColumn, sortOrder)
+	if filter.Limit > 0 {
+		page := filter.Page
+		if page < 1 {
+			page = 1
+		}
+		args = append(args, filter.Limit, (page-1)*filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
 	}
 
-	query += " ORDER BY c.created_at DESC"
-	fmt.Println(query)
-	rows, err := s.DB.Query(query)
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query campaigns: %w", err)
+		return nil, 0, fmt.Errorf("failed to query campaigns: %w", err)
 	}
 	defer rows.Close()
 
-	var campaigns []models.Campaign
+	campaigns := []models.Campaign{}
 	for rows.Next() {
 		var camp models.Campaign
-		if err := rows.Scan(
+		var details campaignDetailScan
+		summary := &models.TaskSummary{}
+		if err := rows.Scan(append(append([]interface{}{
 			&camp.ID, &camp.Name, &camp.Description, &camp.StandardID, &camp.StandardName,
 			&camp.StartDate, &camp.EndDate, &camp.Status, &camp.CreatedAt, &camp.UpdatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan campaign row: %w", err)
-		}
+		}, details.dest()...),
+			&camp.RequirementsCount,
+			&summary.TotalTasks, &summary.Open, &summary.InProgress, &summary.PendingReview,
+			&summary.Closed, &summary.Failed, &summary.OverdueTasks,
+		)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan campaign row: %w", err)
+		}
+		details.apply(&camp)
+		camp.TaskSummary = summary
 		campaigns = append(campaigns, camp)
 	}
-	return campaigns, rows.Err()
+	return campaigns, total, rows.Err()
 }
 
 func (s *DBStore) GetCampaignByID(campaignID string) (*models.Campaign, error) {
 	query := `
-		SELECT c.id, c.name, c.description, c.standard_id, cs.name as standard_name, c.start_date, c.end_date, c.status, c.created_at, c.updated_at
+		SELECT c.id, c.name, c.description, c.standard_id, cs.name as standard_name, c.start_date, c.end_date, c.status, c.created_at, c.updated_at,
+			` + campaignDetailColumns + `
 		FROM campaigns c
 		LEFT JOIN compliance_standards cs ON c.standard_id = cs.id
+		LEFT JOIN users so ON c.signed_off_by_user_id = so.id
 		WHERE c.id = $1
 	`
 	row := s.DB.QueryRow(query, campaignID)
 	var camp models.Campaign
-	err := row.Scan(
+	var details campaignDetailScan
+	err := row.Scan(append([]interface{}{
 		&camp.ID, &camp.Name, &camp.Description, &camp.StandardID, &camp.StandardName,
 		&camp.StartDate, &camp.EndDate, &camp.Status, &camp.CreatedAt, &camp.UpdatedAt,
-	)
+	}, details.dest()...)...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, err
 		}
 		return nil, fmt.Errorf("failed to scan campaign row for ID %s: %w", campaignID, err)
 	}
+	details.apply(&camp)
 	return &camp, nil
 }
 
@@ -1289,6 +1465,11 @@ func (s *DBStore) UpdateCampaign(campaign *models.Campaign, newSelectedReqs []mo
 	if err != nil {
 		return fmt.Errorf("failed to update campaign %s: %w", campaign.ID, err)
 	}
+	if campaign.StandardIDs != nil {
+		if err := replaceCampaignStandardsTx(tx, campaign.ID, campaign.StandardIDs); err != nil {
+			return err
+		}
+	}
 
 	currentDBReqs, err := s.getCampaignSelectedRequirementsTx(tx, campaign.ID)
 	if err != nil {
@@ -1308,13 +1489,8 @@ func (s *DBStore) UpdateCampaign(campaign *models.Campaign, newSelectedReqs []mo
 	for reqID, currentReq := range currentReqMap {
 		newReq, existsInNew := newReqMap[reqID]
 		if !existsInNew || (currentReq.IsApplicable && !newReq.IsApplicable) {
-			_, err = tx.Exec("DELETE FROM campaign_task_instances WHERE campaign_id = $1 AND campaign_selected_requirement_id = $2", campaign.ID, currentReq.ID)
-			if err != nil {
-				return fmt.Errorf("failed to delete task instances for removed/non-applicable requirement %s (csr_id: %s): %w", reqID, currentReq.ID, err)
-			}
-			_, err = tx.Exec("DELETE FROM campaign_selected_requirements WHERE id = $1", currentReq.ID)
-			if err != nil {
-				return fmt.Errorf("failed to delete campaign_selected_requirement %s: %w", currentReq.ID, err)
+			if err := removeCampaignSelectedRequirementTx(tx, campaign.ID, currentReq.ID); err != nil {
+				return fmt.Errorf("failed to remove requirement %s from campaign: %w", reqID, err)
 			}
 		}
 	}
@@ -1331,70 +1507,16 @@ func (s *DBStore) UpdateCampaign(campaign *models.Campaign, newSelectedReqs []mo
 		if err != nil {
 			return fmt.Errorf("failed to insert/update campaign_selected_requirement for req %s: %w", newReq.RequirementID, err)
 		}
+	}
 
-		if newReq.IsApplicable {
-			masterTasks, err := s.GetTasksByRequirementID(newReq.RequirementID)
-			if err != nil {
-				return fmt.Errorf("error fetching master tasks for requirement %s (CSR_ID: %s) during campaign update: %w", newReq.RequirementID, campaignSelectedRequirementID, err)
-			}
-
-			if len(masterTasks) == 0 {
-				log.Printf("No master tasks found for applicable requirement %s (CSR_ID: %s, ControlID: %s) in campaign %s. No new campaign task instances will be created for it if none exist.", newReq.RequirementID, campaignSelectedRequirementID, newReq.ControlIDReference, campaign.ID)
-			}
-
-			existingCTIMasterTaskIDs := make(map[string]bool)
-			queryExistingCTIs := `
-				SELECT master_task_id 
-				FROM campaign_task_instances 
-				WHERE campaign_id = $1 AND campaign_selected_requirement_id = $2 AND master_task_id IS NOT NULL
-			`
-			rows, err := tx.Query(queryExistingCTIs, campaign.ID, campaignSelectedRequirementID)
-			if err != nil {
-				log.Printf("Error fetching existing CTIs for campaign %s, CSR_ID %s: %v", campaign.ID, campaignSelectedRequirementID, err)
-				return fmt.Errorf("failed to fetch existing CTIs for CSR_ID %s: %w", campaignSelectedRequirementID, err)
-			}
-
-			for rows.Next() {
-				var mtID sql.NullString
-				if err := rows.Scan(&mtID); err != nil {
-					rows.Close()
-					log.Printf("Error scanning existing CTI master_task_id for CSR_ID %s: %v", campaignSelectedRequirementID, err)
-					return fmt.Errorf("failed to scan existing CTI master_task_id for CSR_ID %s: %w", campaignSelectedRequirementID, err)
-				}
-				if mtID.Valid {
-					existingCTIMasterTaskIDs[mtID.String] = true
-				}
-			}
-			rows.Close()
-
-			for _, masterTask := range masterTasks {
-				if _, exists := existingCTIMasterTaskIDs[masterTask.ID]; !exists {
-					cti := models.CampaignTaskInstance{
-						CampaignID:                    campaign.ID,
-						MasterTaskID:                  &masterTask.ID,
-						CampaignSelectedRequirementID: &campaignSelectedRequirementID,
-						Title:                         masterTask.Title,
-						Description:                   &masterTask.Description,
-						Category:                      &masterTask.Category,
-						Status:                        "Open",
-						CheckType:                     masterTask.CheckType,
-						Target:                        masterTask.Target,
-						Parameters:                    masterTask.Parameters,
-						Priority:                      masterTask.DefaultPriority,
-					}
-					if masterTask.Description == "" {
-						cti.Description = nil
-					}
-					if masterTask.Category == "" {
-						cti.Category = nil
-					}
-					_, err := s.CreateCampaignTaskInstance(tx, &cti)
-					if err != nil {
-						return fmt.Errorf("error creating new CTI for master task %s (CSR_ID: %s) during campaign update: %w", masterTask.ID, campaignSelectedRequirementID, err)
-					}
-					log.Printf("Successfully created new CTI for master task %s (CSR_ID: %s, ControlID: %s) in campaign %s", masterTask.ID, campaignSelectedRequirementID, newReq.ControlIDReference, campaign.ID)
-				}
-			}
+	// Drafts only record their scope; task instances are created on activation.
+	if campaignstate.GeneratesTaskInstances(campaign.Status) {
+		created, err := s.createMissingCampaignTaskInstancesTx(tx, campaign.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create task instances during campaign update: %w", err)
+		}
+		if created > 0 {
+			log.Printf("Created %d new CTIs for campaign %s", created, campaign.ID)
 		}
 	}
 
@@ -1527,11 +1649,13 @@ func (s *DBStore) DeleteCampaign(campaignID string) error {
 
 func (s *DBStore) GetCampaignSelectedRequirements(campaignID string) ([]models.CampaignSelectedRequirement, error) {
 	query := `
-		SELECT csr.id, csr.campaign_id, csr.requirement_id, r.control_id_reference, r.requirement_text, csr.is_applicable
+		SELECT csr.id, csr.campaign_id, csr.requirement_id, r.control_id_reference, r.requirement_text, csr.is_applicable,
+		       r.standard_id, std.name
 		FROM campaign_selected_requirements csr
 		JOIN requirements r ON csr.requirement_id = r.id
+		JOIN compliance_standards std ON r.standard_id = std.id
 		WHERE csr.campaign_id = $1
-		ORDER BY r.control_id_reference
+		ORDER BY std.name, r.control_id_reference
 	`
 	rows, err := s.DB.Query(query, campaignID)
 	if err != nil {
@@ -1542,7 +1666,8 @@ func (s *DBStore) GetCampaignSelectedRequirements(campaignID string) ([]models.C
 	var reqs []models.CampaignSelectedRequirement
 	for rows.Next() {
 		var r models.CampaignSelectedRequirement
-		if err := rows.Scan(&r.ID, &r.CampaignID, &r.RequirementID, &r.ControlIDReference, &r.RequirementText, &r.IsApplicable); err != nil {
+		if err := rows.Scan(&r.ID, &r.CampaignID, &r.RequirementID, &r.ControlIDReference, &r.RequirementText, &r.IsApplicable,
+			&r.StandardID, &r.StandardName); err != nil {
 			return nil, fmt.Errorf("failed to scan campaign selected requirement: %w", err)
 		}
 		reqs = append(reqs, r)
@@ -1587,9 +1712,9 @@ func (s *DBStore) CreateCampaignTaskInstance(tx *sql.Tx, cti *models.CampaignTas
 		INSERT INTO campaign_task_instances (
 			id, campaign_id, master_task_id, campaign_selected_requirement_id, title, description, category, 
 			assignee_user_id, status, due_date, created_at, updated_at, 
-			check_type, target, parameters, owner_team_id, assignee_team_id
+			check_type, target, parameters, owner_team_id, assignee_team_id, priority, previous_instance_id
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
 	var execFunc func(query string, args ...interface{}) (sql.Result, error)
@@ -1601,11 +1726,19 @@ func (s *DBStore) CreateCampaignTaskInstance(tx *sql.Tx, cti *models.CampaignTas
 
 	_, err = execFunc(query, cti.ID, cti.CampaignID, cti.MasterTaskID, cti.CampaignSelectedRequirementID,
 		cti.Title, cti.Description, cti.Category, cti.AssigneeUserID, cti.Status, cti.DueDate,
-		cti.CreatedAt, cti.UpdatedAt, cti.CheckType, cti.Target, paramsJSON, cti.OwnerTeamID, cti.AssigneeTeamID)
+		cti.CreatedAt, cti.UpdatedAt, cti.CheckType, cti.Target, paramsJSON, cti.OwnerTeamID, cti.AssigneeTeamID,
+		cti.Priority, cti.PreviousInstanceID)
 
 	if err != nil {
 		return "", fmt.Errorf("failed to insert campaign task instance: %w", err)
 	}
+	if cti.CampaignSelectedRequirementID != nil {
+		_, err = execFunc(`INSERT INTO campaign_task_instance_requirements (campaign_task_instance_id, campaign_selected_requirement_id) VALUES ($1, $2)`,
+			cti.ID, cti.CampaignSelectedRequirementID)
+		if err != nil {
+			return "", fmt.Errorf("failed to link campaign task instance to its requirement: %w", err)
+		}
+	}
 
 	err = s.updateCampaignTaskInstanceOwners(tx, cti.ID, cti.OwnerUserIDs)
 
@@ -1661,9 +1794,16 @@ func (s *DBStore) GetCampaignTaskInstances(campaignID string, userID string, use
 		return nil, fmt.Errorf("failed to query campaign task instances for campaign %s: %w", campaignID, err)
 	}
 
+	links, err := s.getTaskInstanceRequirementLinks(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Post-process to fetch owners for each instance
 	// This is done separately as sqlx.Select doesn't easily handle one-to-many for nested slices like Owners.
 	for i := range instances {
+		instances[i].CampaignSelectedRequirementIDs = links[instances[i].ID]
+
 		// Unmarshal Parameters from JSONB to ParametersMap
 		// if instances[i].Parameters != nil && len(instances[i].Parameters) > 0 && string(instances[i].Parameters) != "null" {
 		// 	var tempMap map[string]interface{}
@@ -1690,7 +1830,8 @@ func (s *DBStore) GetCampaignTaskInstanceByID(ctiID string) (*models.CampaignTas
 	query := `SELECT cti.id, cti.campaign_id, c.name as campaign_name, cti.master_task_id, cti.priority,
 	cti.campaign_selected_requirement_id, cti.title, cti.description, cti.category, 
 	cti.assignee_user_id, cti.owner_team_id, cti.assignee_team_id, cti.last_checked_at, cti.last_check_status,
-    cti.status, cti.due_date, cti.created_at, cti.updated_at,
+    cti.status, cti.due_date, cti.created_at, cti.updated_at, cti.previous_instance_id,
+    ARRAY(SELECT l.campaign_selected_requirement_id::text FROM campaign_task_instance_requirements l WHERE l.campaign_task_instance_id = cti.id),
     mt.high_level_check_type, mt.check_type, mt.target, mt.parameters,
     assignee.name as assignee_user_name,
     req.control_id_reference as requirement_control_id_reference,
@@ -1725,7 +1866,8 @@ func (s *DBStore) GetCampaignTaskInstanceByID(ctiID string) (*models.CampaignTas
 		&cti.ID, &cti.CampaignID, &cti.CampaignName, &cti.MasterTaskID, &cti.Priority, &cti.CampaignSelectedRequirementID,
 		&cti.Title, &cti.Description, &cti.Category, &cti.AssigneeUserID, &cti.OwnerTeamID, &cti.AssigneeTeamID,
 		&cti.LastCheckedAt, &cti.LastCheckStatus,
-		&cti.Status, &cti.DueDate, &cti.CreatedAt, &cti.UpdatedAt,
+		&cti.Status, &cti.DueDate, &cti.CreatedAt, &cti.UpdatedAt, &cti.PreviousInstanceID,
+		pq.Array(&cti.CampaignSelectedRequirementIDs),
 		&cti.HighLevelCheckType, &cti.CheckType, &cti.Target, &paramsJSON,
 		&cti.AssigneeUserName, &cti.RequirementControlIDReference, &cti.RequirementText, &cti.RequirementStandardName,
 		&cti.DefaultPriority, pq.Array(&cti.EvidenceTypesExpected), // pq.Array handles NULL arrays
@@ -1808,6 +1950,19 @@ func (s *DBStore) UpdateCampaignTaskInstance(cti *models.CampaignTaskInstance) e
 	return tx.Commit()
 }
 
+// UpdateCampaignTaskInstanceCheckStatus records when an instance's check last
+// ran and with what status, leaving the rest of the instance untouched.
+func (s *DBStore) UpdateCampaignTaskInstanceCheckStatus(ctiID string, checkedAt time.Time, status string) error {
+	_, err := s.DB.Exec(`
+		UPDATE campaign_task_instances
+		SET last_checked_at = $2, last_check_status = $3, updated_at = NOW()
+		WHERE id = $1`, ctiID, checkedAt, status)
+	if err != nil {
+		return fmt.Errorf("failed to update check status of campaign task instance %s: %w", ctiID, err)
+	}
+	return nil
+}
+
 func (s *DBStore) updateCampaignTaskInstanceOwners(tx *sql.Tx, ctiID string, ownerIDs []string) error {
 	var execFunc func(query string, args ...interface{}) (sql.Result, error)
 	if tx != nil {
@@ -2249,8 +2404,9 @@ func (s *DBStore) UpdateConnectedSystemStatus(id string, lastCheckedAt time.Time
 }
 
 func (s *DBStore) CreateCampaignTaskInstanceResult(result *models.CampaignTaskInstanceResult) error {
-	query := `INSERT INTO campaign_task_instance_results (campaign_task_instance_id, task_execution_id, executed_by_user_id, timestamp, status, output)
-              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	query := `INSERT INTO campaign_task_instance_results (campaign_task_instance_id, task_execution_id, executed_by_user_id, timestamp, status, output,
+                  plugin_id, plugin_version, check_type_schema_version)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
 	err := s.DB.QueryRow(query,
 		result.CampaignTaskInstanceID,
 		result.TaskExecutionID,
@@ -2258,6 +2414,9 @@ func (s *DBStore) CreateCampaignTaskInstanceResult(result *models.CampaignTaskIn
 		result.Timestamp,
 		result.Status,
 		result.Output,
+		result.PluginID,
+		result.PluginVersion,
+		result.CheckTypeSchemaVersion,
 	).Scan(&result.ID)
 	if err != nil {
 		log.Printf("Error creating campaign task instance result in DB: %v. Result details: %+v", err, result)
@@ -2267,6 +2426,21 @@ func (s *DBStore) CreateCampaignTaskInstanceResult(result *models.CampaignTaskIn
 }
 
 func (s *DBStore) GetCampaignTaskInstanceResults(instanceID string) ([]models.CampaignTaskInstanceResult, error) {
+	results, _, err := s.GetCampaignTaskInstanceResultsPage(instanceID, 1, 0)
+	return results, err
+}
+
+// GetCampaignTaskInstanceResultsPage returns one page of an instance's results,
+// newest first, and the total number of results. A limit of 0 returns them all.
+func (s *DBStore) GetCampaignTaskInstanceResultsPage(instanceID string, page, limit int) ([]models.CampaignTaskInstanceResult, int, error) {
+	var total int
+	if err := s.DB.QueryRow(`SELECT COUNT(*) FROM campaign_task_instance_results WHERE campaign_task_instance_id = $1`, instanceID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count campaign task instance results for instance %s: %w", instanceID, err)
+	}
+	if total == 0 {
+		return []models.CampaignTaskInstanceResult{}, 0, nil
+	}
+
 	var results []models.CampaignTaskInstanceResult
 	query := `
 		SELECT 
@@ -2277,15 +2451,28 @@ func (s *DBStore) GetCampaignTaskInstanceResults(instanceID string) ([]models.Ca
 			u.name as executed_by_user_name, 
 			ctir.timestamp, 
 			ctir.status, 
-			ctir.output
+			ctir.output,
+			ctir.plugin_id,
+			ctir.plugin_version,
+			ctir.check_type_schema_version,
+			ctir.repeat_count,
+			ctir.repeated_until
 		FROM campaign_task_instance_results ctir
 		LEFT JOIN users u ON ctir.executed_by_user_id = u.id
 		WHERE ctir.campaign_task_instance_id = $1
-		ORDER BY ctir.timestamp DESC
+		ORDER BY ctir.timestamp DESC, ctir.id
 	`
-	rows, err := s.DB.Queryx(query, instanceID)
+	args := []interface{}{instanceID}
+	if limit > 0 {
+		if page < 1 {
+			page = 1
+		}
+		query += " LIMIT $2 OFFSET $3"
+		args = append(args, limit, (page-1)*limit)
+	}
+	rows, err := s.DB.Queryx(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query campaign task instance results for instance %s: %w", instanceID, err)
+		return nil, 0, fmt.Errorf("failed to query campaign task instance results for instance %s: %w", instanceID, err)
 	}
 	defer rows.Close()
 
@@ -2302,9 +2489,14 @@ func (s *DBStore) GetCampaignTaskInstanceResults(instanceID string) ([]models.Ca
 			&result.Timestamp,
 			&result.Status,
 			&result.Output,
+			&result.PluginID,
+			&result.PluginVersion,
+			&result.CheckTypeSchemaVersion,
+			&result.RepeatCount,
+			&result.RepeatedUntil,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan campaign task instance result: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan campaign task instance result: %w", err)
 		}
 		if result.ExecutedByUserID != nil {
 			executedByUser.ID = *result.ExecutedByUserID
@@ -2315,7 +2507,39 @@ func (s *DBStore) GetCampaignTaskInstanceResults(instanceID string) ([]models.Ca
 		}
 		results = append(results, result)
 	}
-	return results, rows.Err()
+	return results, total, rows.Err()
+}
+
+// GetCampaignTaskInstanceResultByID returns a single stored execution result.
+func (s *DBStore) GetCampaignTaskInstanceResultByID(resultID string) (*models.CampaignTaskInstanceResult, error) {
+	var result models.CampaignTaskInstanceResult
+	query := `
+		SELECT id, campaign_task_instance_id, task_execution_id, executed_by_user_id, timestamp, status, output,
+		       plugin_id, plugin_version, check_type_schema_version, repeat_count, repeated_until
+		FROM campaign_task_instance_results
+		WHERE id = $1
+	`
+	err := s.DB.QueryRowx(query, resultID).Scan(
+		&result.ID,
+		&result.CampaignTaskInstanceID,
+		&result.TaskExecutionID,
+		&result.ExecutedByUserID,
+		&result.Timestamp,
+		&result.Status,
+		&result.Output,
+		&result.PluginID,
+		&result.PluginVersion,
+		&result.CheckTypeSchemaVersion,
+		&result.RepeatCount,
+		&result.RepeatedUntil,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get campaign task instance result %s: %w", resultID, err)
+	}
+	return &result, nil
 }
 
 // --- Risk Management Store Methods ---
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+// CompactCampaignTaskInstanceResults deletes results with status
+// successStatus that repeat the previous result of their instance exactly
+// (same status and output) and are older than before. Each run of repeats is
+// folded into its first result, whose repeat_count and repeated_until record
+// what was removed. Runs never span midnight, so the folded repeats of a
+// result fall in its day, week and month and GetResultTrend counts them in
+// the right period. Failures, status changes and every instance's latest
+// result are always kept. It returns the number of results deleted.
+func (s *DBStore) CompactCampaignTaskInstanceResults(before time.Time, successStatus string) (int64, error) {
+	query := `
+		WITH ordered AS (
+			SELECT id, campaign_task_instance_id, timestamp, repeat_count, repeated_until,
+				COALESCE(status = $2
+					AND LAG(status) OVER w = $2
+					AND LAG(output) OVER w IS NOT DISTINCT FROM output
+					AND date_trunc('day', LAG(timestamp) OVER w) = date_trunc('day', timestamp), false) AS repeats_previous,
+				ROW_NUMBER() OVER (PARTITION BY campaign_task_instance_id ORDER BY timestamp DESC, id DESC) AS recency
+			FROM campaign_task_instance_results
+			WINDOW w AS (PARTITION BY campaign_task_instance_id ORDER BY timestamp, id)
+		), runs AS (
+			SELECT *, SUM(CASE WHEN repeats_previous THEN 0 ELSE 1 END)
+				OVER (PARTITION BY campaign_task_instance_id ORDER BY timestamp, id) AS run
+			FROM ordered
+		), removed AS (
+			SELECT r.id, h.id AS head_id, r.repeat_count, COALESCE(r.repeated_until, r.timestamp) AS last_seen
+			FROM runs r
+			JOIN runs h ON h.campaign_task_instance_id = r.campaign_task_instance_id AND h.run = r.run AND NOT h.repeats_previous
+			WHERE r.repeats_previous AND r.recency > 1 AND r.timestamp < $1
+		), folded AS (
+			UPDATE campaign_task_instance_results t
+			SET repeat_count = t.repeat_count + f.removed_count,
+				repeated_until = GREATEST(t.repeated_until, f.last_seen)
+			FROM (
+				SELECT head_id, SUM(1 + repeat_count) AS removed_count, MAX(last_seen) AS last_seen
+				FROM removed GROUP BY head_id
+			) f
+			WHERE t.id = f.head_id
+		)
+		DELETE FROM campaign_task_instance_results WHERE id IN (SELECT id FROM removed)
+	`
+	res, err := s.DB.Exec(query, before, successStatus)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact campaign task instance results: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteTaskExecutionLogsBefore deletes execution log lines written before the given time.
+func (s *DBStore) DeleteTaskExecutionLogsBefore(before time.Time) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM task_execution_logs WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete task execution logs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// GetResultTrend counts results per period by outcome, oldest period first.
+// passedStatus, failedStatus and errorStatus name the statuses counted as
+// passed, failed and errored; every other status counts as other.
+func (s *DBStore) GetResultTrend(filter models.ResultTrendFilter, passedStatus, failedStatus, errorStatus string) ([]models.ResultTrendPoint, error) {
+	args := []interface{}{filter.Interval, passedStatus, failedStatus, errorStatus}
+	var where []string
+	addFilter := func(clause string, value interface{}) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	if filter.CampaignTaskInstanceID != "" {
+		addFilter("r.campaign_task_instance_id = $%d", filter.CampaignTaskInstanceID)
+	}
+	if filter.MasterTaskID != "" {
+		addFilter("cti.master_task_id = $%d", filter.MasterTaskID)
+	}
+	if filter.ConnectedSystemID != "" {
+		addFilter("cti.target = $%d", filter.ConnectedSystemID)
+	}
+	if filter.From != nil {
+		addFilter("r.timestamp >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addFilter("r.timestamp < $%d", *filter.To)
+	}
+
+	query := `
+		SELECT date_trunc($1, r.timestamp) AS period,
+			COALESCE(SUM(CASE WHEN r.status = $2 THEN 1 + r.repeat_count ELSE 0 END), 0) AS passed,
+			COUNT(*) FILTER (WHERE r.status = $3) AS failed,
+			COUNT(*) FILTER (WHERE r.status = $4) AS errored,
+			COALESCE(SUM(1 + r.repeat_count), 0) AS total
+		FROM campaign_task_instance_results r
+		JOIN campaign_task_instances cti ON cti.id = r.campaign_task_instance_id
+	`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " GROUP BY period ORDER BY period"
+
+	points := []models.ResultTrendPoint{}
+	if err := s.DB.Select(&points, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get result trend: %w", err)
+	}
+	for i := range points {
+		p := &points[i]
+		p.Other = p.Total - p.Passed - p.Failed - p.Errored
+		if p.Total > 0 {
+			p.PassRate = float64(p.Passed) / float64(p.Total)
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+// AppendTaskExecutionLog stores one progress line of an execution.
+func (s *DBStore) AppendTaskExecutionLog(executionID, level, message string) error {
+	_, err := s.DB.Exec(`
+		INSERT INTO task_execution_logs (execution_id, level, message)
+		VALUES ($1, $2, $3)`, executionID, level, message)
+	if err != nil {
+		return fmt.Errorf("failed to append log for execution %s: %w", executionID, err)
+	}
+	return nil
+}
+
+// GetTaskExecutionLogs returns up to limit log lines of an execution with an
+// ID greater than afterID, oldest first. Pass afterID 0 to read from the start.
+func (s *DBStore) GetTaskExecutionLogs(executionID string, afterID int64, limit int) ([]models.TaskExecutionLog, error) {
+	logs := []models.TaskExecutionLog{}
+	err := s.DB.Select(&logs, `
+		SELECT id, execution_id, level, message, created_at
+		FROM task_execution_logs
+		WHERE execution_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`, executionID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs for execution %s: %w", executionID, err)
+	}
+	return logs, nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/assignment"
 	"github.com/vdparikh/compliance-automation/backend/models"
 )
 
@@ -15,15 +16,18 @@ func (s *DBStore) CreateTeam(team *models.Team) (string, error) {
 	if team.ID == "" {
 		team.ID = uuid.NewString()
 	}
+	if team.AssignmentStrategy == "" {
+		team.AssignmentStrategy = assignment.DefaultStrategy
+	}
 	team.CreatedAt = time.Now()
 	team.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO teams (id, name, description, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO teams (id, name, description, assignment_strategy, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id`
 
-	err := s.DB.QueryRowx(query, team.ID, team.Name, team.Description, team.CreatedAt, team.UpdatedAt).Scan(&team.ID)
+	err := s.DB.QueryRowx(query, team.ID, team.Name, team.Description, team.AssignmentStrategy, team.CreatedAt, team.UpdatedAt).Scan(&team.ID)
 	if err != nil {
 		log.Printf("Error creating team in DB: %v. Team: %+v", err, team)
 		return "", fmt.Errorf("failed to create team: %w", err)
@@ -33,7 +37,7 @@ func (s *DBStore) CreateTeam(team *models.Team) (string, error) {
 
 func (s *DBStore) GetTeamByID(teamID string) (*models.Team, error) {
 	var team models.Team
-	query := `SELECT id, name, description, created_at, updated_at FROM teams WHERE id = $1`
+	query := `SELECT id, name, description, assignment_strategy, created_at, updated_at FROM teams WHERE id = $1`
 	err := s.DB.Get(&team, query, teamID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -48,7 +52,7 @@ func (s *DBStore) GetTeamByID(teamID string) (*models.Team, error) {
 
 func (s *DBStore) GetTeams() ([]models.Team, error) {
 	var teams []models.Team
-	query := `SELECT id, name, description, created_at, updated_at FROM teams ORDER BY name ASC`
+	query := `SELECT id, name, description, assignment_strategy, created_at, updated_at FROM teams ORDER BY name ASC`
 	err := s.DB.Select(&teams, query)
 	if err != nil {
 		log.Printf("Error getting teams from DB: %v", err)
@@ -66,10 +70,11 @@ func (s *DBStore) UpdateTeam(team *models.Team) error {
 		UPDATE teams SET
 			name = $1,
 			description = $2,
+			assignment_strategy = COALESCE(NULLIF($5, ''), assignment_strategy),
 			updated_at = $3
 		WHERE id = $4`
 
-	result, err := s.DB.Exec(query, team.Name, team.Description, team.UpdatedAt, team.ID)
+	result, err := s.DB.Exec(query, team.Name, team.Description, team.UpdatedAt, team.ID, team.AssignmentStrategy)
 	if err != nil {
 		log.Printf("Error updating team in DB: %v. TeamID: %s", err, team.ID)
 		return fmt.Errorf("failed to update team %s: %w", team.ID, err)
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+// CreateVulnerabilityReport saves a report and its findings in one transaction.
+// Each finding's FirstSeenAt is set to the earliest earlier sighting of the same
+// asset, vulnerability and package, or to the report time for new findings.
+func (s *DBStore) CreateVulnerabilityReport(report *models.VulnerabilityReport, findings []models.VulnerabilityFinding) error {
+	if report.ID == "" {
+		report.ID = uuid.NewString()
+	}
+	report.CreatedAt = time.Now()
+	report.FindingsCount = len(findings)
+
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for vulnerability report: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO vulnerability_reports (id, campaign_task_instance_id, evidence_id, format, uploaded_by_user_id, findings_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		report.ID, report.CampaignTaskInstanceID, report.EvidenceID, report.Format, report.UploadedByUserID, report.FindingsCount, report.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating vulnerability report in DB: %v. Report: %+v", err, report)
+		return fmt.Errorf("failed to create vulnerability report: %w", err)
+	}
+
+	findingQuery := `
+		INSERT INTO vulnerability_findings (
+			id, report_id, asset, vulnerability_id, severity, package_name,
+			installed_version, fixed_version, title, first_seen_at, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9,
+			COALESCE((SELECT MIN(first_seen_at) FROM vulnerability_findings
+			          WHERE asset = $3 AND vulnerability_id = $4 AND package_name = $6), $10),
+			$10
+		) RETURNING first_seen_at`
+	for i := range findings {
+		f := &findings[i]
+		if f.ID == "" {
+			f.ID = uuid.NewString()
+		}
+		f.ReportID = report.ID
+		f.CreatedAt = report.CreatedAt
+		f.CampaignTaskInstanceID = report.CampaignTaskInstanceID
+		err := tx.QueryRowx(findingQuery,
+			f.ID, f.ReportID, f.Asset, f.VulnerabilityID, f.Severity, f.PackageName,
+			f.InstalledVersion, f.FixedVersion, f.Title, report.CreatedAt).Scan(&f.FirstSeenAt)
+		if err != nil {
+			return fmt.Errorf("failed to create vulnerability finding %s for %s: %w", f.VulnerabilityID, f.Asset, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit vulnerability report: %w", err)
+	}
+	return nil
+}
+
+// GetVulnerabilityReportsByInstanceID lists the reports ingested for a campaign task instance, newest first.
+func (s *DBStore) GetVulnerabilityReportsByInstanceID(instanceID string) ([]models.VulnerabilityReport, error) {
+	var reports []models.VulnerabilityReport
+	query := `
+		SELECT id, campaign_task_instance_id, evidence_id, format, uploaded_by_user_id, findings_count, created_at
+		FROM vulnerability_reports
+		WHERE campaign_task_instance_id = $1
+		ORDER BY created_at DESC`
+	if err := s.DB.Select(&reports, query, instanceID); err != nil {
+		return nil, fmt.Errorf("failed to get vulnerability reports for instance %s: %w", instanceID, err)
+	}
+	if reports == nil {
+		reports = []models.VulnerabilityReport{}
+	}
+	return reports, nil
+}
+
+// GetLatestVulnerabilityReportByInstanceID returns ErrNotFound when nothing has been ingested for the instance.
+func (s *DBStore) GetLatestVulnerabilityReportByInstanceID(instanceID string) (*models.VulnerabilityReport, error) {
+	var report models.VulnerabilityReport
+	query := `
+		SELECT id, campaign_task_instance_id, evidence_id, format, uploaded_by_user_id, findings_count, created_at
+		FROM vulnerability_reports
+		WHERE campaign_task_instance_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1`
+	if err := s.DB.Get(&report, query, instanceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get latest vulnerability report for instance %s: %w", instanceID, err)
+	}
+	return &report, nil
+}
+
+func (s *DBStore) GetVulnerabilityFindingsByReportID(reportID string) ([]models.VulnerabilityFinding, error) {
+	var findings []models.VulnerabilityFinding
+	query := `
+		SELECT vf.id, vf.report_id, vf.asset, vf.vulnerability_id, vf.severity, vf.package_name,
+		       vf.installed_version, vf.fixed_version, vf.title, vf.first_seen_at, vf.created_at,
+		       vr.campaign_task_instance_id
+		FROM vulnerability_findings vf
+		JOIN vulnerability_reports vr ON vr.id = vf.report_id
+		WHERE vf.report_id = $1
+		ORDER BY vf.asset, vf.vulnerability_id, vf.package_name`
+	if err := s.DB.Select(&findings, query, reportID); err != nil {
+		return nil, fmt.Errorf("failed to get vulnerability findings for report %s: %w", reportID, err)
+	}
+	if findings == nil {
+		findings = []models.VulnerabilityFinding{}
+	}
+	return findings, nil
+}
+
+// GetVulnerabilityHistoryByAsset returns every sighting of every finding on an asset,
+// across all reports and campaigns, oldest first.
+func (s *DBStore) GetVulnerabilityHistoryByAsset(asset string) ([]models.VulnerabilityFinding, error) {
+	var findings []models.VulnerabilityFinding
+	query := `
+		SELECT vf.id, vf.report_id, vf.asset, vf.vulnerability_id, vf.severity, vf.package_name,
+		       vf.installed_version, vf.fixed_version, vf.title, vf.first_seen_at, vf.created_at,
+		       vr.campaign_task_instance_id, cti.campaign_id, c.name AS campaign_name
+		FROM vulnerability_findings vf
+		JOIN vulnerability_reports vr ON vr.id = vf.report_id
+		LEFT JOIN campaign_task_instances cti ON cti.id = vr.campaign_task_instance_id
+		LEFT JOIN campaigns c ON c.id = cti.campaign_id
+		WHERE vf.asset = $1
+		ORDER BY vf.vulnerability_id, vf.package_name, vf.created_at`
+	if err := s.DB.Select(&findings, query, asset); err != nil {
+		return nil, fmt.Errorf("failed to get vulnerability history for asset %s: %w", asset, err)
+	}
+	if findings == nil {
+		findings = []models.VulnerabilityFinding{}
+	}
+	return findings, nil
+}
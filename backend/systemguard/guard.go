@@ -0,0 +1,245 @@
+// Package systemguard rate limits executions per connected system and stops
+// sending them to a system that keeps failing (a circuit breaker).
+package systemguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// What to do with an execution while its system's circuit is open.
+const (
+	OnOpenFail  = "fail"
+	OnOpenDefer = "defer"
+)
+
+// Connected system statuses recorded when a circuit opens and closes again.
+const (
+	SystemStatusCircuitOpen = "circuit_open"
+	SystemStatusConnected   = "connected"
+)
+
+// Limits configures the guard of one system type.
+type Limits struct {
+	// RequestsPerMinute is the sustained execution rate per system; 0 disables rate limiting.
+	RequestsPerMinute float64 `json:"requestsPerMinute"`
+	// Burst is how many executions may start back to back. Defaults to 1.
+	Burst int `json:"burst"`
+	// FailureThreshold is the number of consecutive failures that opens the circuit; 0 disables it.
+	FailureThreshold int `json:"failureThreshold"`
+	// OpenSeconds is how long the circuit stays open before one trial execution is let through.
+	OpenSeconds int `json:"openSeconds"`
+	// OnOpen is OnOpenFail or OnOpenDefer.
+	OnOpen string `json:"onOpen"`
+}
+
+// Config holds the default limits and overrides per system type. An override
+// replaces the default entirely.
+type Config struct {
+	Default     Limits            `json:"default"`
+	SystemTypes map[string]Limits `json:"systemTypes"`
+}
+
+// DefaultConfig leaves rate limiting off and opens a circuit after five
+// consecutive failures for five minutes, failing executions fast meanwhile.
+func DefaultConfig() Config {
+	return Config{
+		Default: Limits{
+			FailureThreshold: 5,
+			OpenSeconds:      300,
+			OnOpen:           OnOpenFail,
+		},
+		SystemTypes: map[string]Limits{},
+	}
+}
+
+// LoadConfig reads a JSON Config from path. An empty path gives DefaultConfig.
+func LoadConfig(path string) (Config, error) {
+	config := DefaultConfig()
+	if path == "" {
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read system limits file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse system limits file %s: %w", path, err)
+	}
+	for systemType, limits := range config.SystemTypes {
+		if err := limits.validate(); err != nil {
+			return config, fmt.Errorf("invalid limits for system type %s: %w", systemType, err)
+		}
+	}
+	if err := config.Default.validate(); err != nil {
+		return config, fmt.Errorf("invalid default limits: %w", err)
+	}
+	return config, nil
+}
+
+// For returns the limits of a system type.
+func (c Config) For(systemType string) Limits {
+	if limits, ok := c.SystemTypes[systemType]; ok {
+		return limits
+	}
+	return c.Default
+}
+
+func (l Limits) validate() error {
+	if l.RequestsPerMinute < 0 || l.Burst < 0 || l.FailureThreshold < 0 || l.OpenSeconds < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	if l.OnOpen != "" && l.OnOpen != OnOpenFail && l.OnOpen != OnOpenDefer {
+		return fmt.Errorf("onOpen must be %q or %q, got %q", OnOpenFail, OnOpenDefer, l.OnOpen)
+	}
+	return nil
+}
+
+// Decision is the guard's answer for one execution.
+type Decision struct {
+	Allowed bool
+	// CircuitOpen is set when the execution was refused because the circuit is open.
+	CircuitOpen bool
+	// RetryAfter is when the execution could next be admitted, if refused.
+	RetryAfter time.Time
+	// OnOpen is the configured behaviour while the circuit is open.
+	OnOpen string
+	// Failures is the number of consecutive failures recorded for the system.
+	Failures int
+}
+
+// Transition reports a change of circuit state caused by Record.
+type Transition int
+
+const (
+	NoTransition Transition = iota
+	Opened
+	Closed
+)
+
+type systemState struct {
+	tokens     float64
+	refilledAt time.Time
+	failures   int
+	openUntil  time.Time
+	open       bool
+	trial      bool // a half-open trial execution is running
+}
+
+// Guard tracks the rate limit and circuit of every connected system. It is
+// safe for concurrent use.
+type Guard struct {
+	config  Config
+	now     func() time.Time
+	mu      sync.Mutex
+	systems map[string]*systemState
+}
+
+// New returns a Guard using config.
+func New(config Config) *Guard {
+	return &Guard{config: config, now: time.Now, systems: map[string]*systemState{}}
+}
+
+func (g *Guard) state(systemID string, limits Limits) *systemState {
+	st, ok := g.systems[systemID]
+	if !ok {
+		st = &systemState{tokens: float64(burst(limits)), refilledAt: g.now()}
+		g.systems[systemID] = st
+	}
+	return st
+}
+
+func burst(l Limits) int {
+	if l.Burst < 1 {
+		return 1
+	}
+	return l.Burst
+}
+
+// Admit decides whether an execution against the system may start now. An
+// allowed execution must be followed by Record with its outcome.
+func (g *Guard) Admit(systemID, systemType string) Decision {
+	limits := g.config.For(systemType)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := g.state(systemID, limits)
+	now := g.now()
+	decision := Decision{OnOpen: limits.OnOpen, Failures: st.failures}
+	if decision.OnOpen == "" {
+		decision.OnOpen = OnOpenFail
+	}
+
+	if st.open {
+		if now.Before(st.openUntil) || st.trial {
+			decision.CircuitOpen = true
+			decision.RetryAfter = st.openUntil
+			if !decision.RetryAfter.After(now) {
+				decision.RetryAfter = now.Add(time.Duration(limits.OpenSeconds) * time.Second)
+			}
+			return decision
+		}
+		// Half open: let one execution through to probe the system.
+		st.trial = true
+	}
+
+	if limits.RequestsPerMinute > 0 {
+		perSecond := limits.RequestsPerMinute / 60
+		st.tokens += now.Sub(st.refilledAt).Seconds() * perSecond
+		if capacity := float64(burst(limits)); st.tokens > capacity {
+			st.tokens = capacity
+		}
+		st.refilledAt = now
+		if st.tokens < 1 {
+			st.trial = false
+			wait := time.Duration((1 - st.tokens) / perSecond * float64(time.Second))
+			decision.RetryAfter = now.Add(wait)
+			return decision
+		}
+		st.tokens--
+	}
+	decision.Allowed = true
+	return decision
+}
+
+// Record stores the outcome of an admitted execution. A failure is an error
+// reaching or using the system, not a failed compliance check.
+func (g *Guard) Record(systemID, systemType string, failed bool) Transition {
+	limits := g.config.For(systemType)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := g.state(systemID, limits)
+	wasOpen := st.open
+	st.trial = false
+
+	if !failed {
+		st.failures = 0
+		st.open = false
+		if wasOpen {
+			return Closed
+		}
+		return NoTransition
+	}
+
+	st.failures++
+	if limits.FailureThreshold > 0 && (wasOpen || st.failures >= limits.FailureThreshold) {
+		st.open = true
+		st.openUntil = g.now().Add(time.Duration(limits.OpenSeconds) * time.Second)
+		if !wasOpen {
+			return Opened
+		}
+	}
+	return NoTransition
+}
+
+// Release ends an admitted execution that produced no outcome, such as one
+// cancelled by a user, without counting it either way.
+func (g *Guard) Release(systemID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if st, ok := g.systems[systemID]; ok {
+		st.trial = false
+	}
+}
@@ -0,0 +1,110 @@
+package systemguard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestGuard(config Config) (*Guard, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	g := New(config)
+	g.now = clock.now
+	return g, clock
+}
+
+func TestRateLimit(t *testing.T) {
+	g, clock := newTestGuard(Config{Default: Limits{RequestsPerMinute: 60, Burst: 2}})
+
+	assert.True(t, g.Admit("sys-1", "aws").Allowed)
+	assert.True(t, g.Admit("sys-1", "aws").Allowed)
+	d := g.Admit("sys-1", "aws")
+	require.False(t, d.Allowed, "burst is spent")
+	assert.False(t, d.CircuitOpen)
+	assert.Equal(t, clock.t.Add(time.Second), d.RetryAfter)
+
+	assert.True(t, g.Admit("sys-2", "aws").Allowed, "buckets are per system")
+
+	clock.advance(time.Second)
+	assert.True(t, g.Admit("sys-1", "aws").Allowed)
+	assert.False(t, g.Admit("sys-1", "aws").Allowed)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	g, clock := newTestGuard(Config{
+		Default:     Limits{FailureThreshold: 2, OpenSeconds: 60, OnOpen: OnOpenDefer},
+		SystemTypes: map[string]Limits{"github": {}},
+	})
+
+	require.True(t, g.Admit("sys-1", "aws").Allowed)
+	assert.Equal(t, NoTransition, g.Record("sys-1", "aws", true))
+	require.True(t, g.Admit("sys-1", "aws").Allowed)
+	assert.Equal(t, Opened, g.Record("sys-1", "aws", true))
+
+	d := g.Admit("sys-1", "aws")
+	assert.False(t, d.Allowed)
+	assert.True(t, d.CircuitOpen)
+	assert.Equal(t, OnOpenDefer, d.OnOpen)
+	assert.Equal(t, 2, d.Failures)
+	assert.Equal(t, clock.t.Add(time.Minute), d.RetryAfter)
+
+	// After the open period one trial goes through; a failed trial reopens the circuit.
+	clock.advance(time.Minute)
+	require.True(t, g.Admit("sys-1", "aws").Allowed)
+	assert.True(t, g.Admit("sys-1", "aws").CircuitOpen, "only one trial at a time")
+	assert.Equal(t, NoTransition, g.Record("sys-1", "aws", true))
+	assert.True(t, g.Admit("sys-1", "aws").CircuitOpen)
+
+	clock.advance(time.Minute)
+	require.True(t, g.Admit("sys-1", "aws").Allowed)
+	assert.Equal(t, Closed, g.Record("sys-1", "aws", false))
+	assert.True(t, g.Admit("sys-1", "aws").Allowed)
+
+	// The github override has no threshold, so its circuit never opens.
+	for i := 0; i < 5; i++ {
+		require.True(t, g.Admit("sys-2", "github").Allowed)
+		assert.Equal(t, NoTransition, g.Record("sys-2", "github", true))
+	}
+}
+
+func TestReleaseEndsTrial(t *testing.T) {
+	g, clock := newTestGuard(Config{Default: Limits{FailureThreshold: 1, OpenSeconds: 10}})
+	require.True(t, g.Admit("sys-1", "ssh").Allowed)
+	require.Equal(t, Opened, g.Record("sys-1", "ssh", true))
+
+	clock.advance(10 * time.Second)
+	require.True(t, g.Admit("sys-1", "ssh").Allowed)
+	g.Release("sys-1")
+	d := g.Admit("sys-1", "ssh")
+	assert.True(t, d.Allowed, "a released trial does not block the next one")
+	assert.Equal(t, OnOpenFail, d.OnOpen)
+}
+
+func TestLoadConfig(t *testing.T) {
+	config, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), config)
+
+	path := filepath.Join(t.TempDir(), "limits.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"default": {"requestsPerMinute": 30},
+		"systemTypes": {"github": {"requestsPerMinute": 10, "burst": 5, "onOpen": "defer"}}
+	}`), 0o600))
+	config, err = LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, Limits{RequestsPerMinute: 30, FailureThreshold: 5, OpenSeconds: 300, OnOpen: OnOpenFail}, config.For("aws"))
+	assert.Equal(t, Limits{RequestsPerMinute: 10, Burst: 5, OnOpen: OnOpenDefer}, config.For("github"))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"systemTypes": {"aws": {"onOpen": "retry"}}}`), 0o600))
+	_, err = LoadConfig(path)
+	assert.ErrorContains(t, err, "system type aws")
+}
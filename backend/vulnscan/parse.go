@@ -0,0 +1,399 @@
+// Package vulnscan normalizes vulnerability scanner reports (Trivy, Grype,
+// Nessus and SARIF) into models.VulnerabilityFinding and evaluates them
+// against an age based remediation policy.
+package vulnscan
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const (
+	FormatTrivy  = "trivy"
+	FormatGrype  = "grype"
+	FormatNessus = "nessus"
+	FormatSARIF  = "sarif"
+)
+
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+	SeverityUnknown  = "unknown"
+)
+
+// Formats lists the supported report formats.
+var Formats = []string{FormatTrivy, FormatGrype, FormatNessus, FormatSARIF}
+
+// DetectFormat guesses the report format from its content.
+func DetectFormat(data []byte) (string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		if bytes.Contains(trimmed, []byte("<NessusClientData_v2")) {
+			return FormatNessus, nil
+		}
+		return "", fmt.Errorf("unrecognized XML report; only Nessus (.nessus) XML is supported")
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &probe); err != nil {
+		return "", fmt.Errorf("report is neither JSON nor XML: %w", err)
+	}
+	switch {
+	case probe["runs"] != nil && probe["version"] != nil:
+		return FormatSARIF, nil
+	case probe["matches"] != nil:
+		return FormatGrype, nil
+	case probe["Results"] != nil || probe["ArtifactName"] != nil:
+		return FormatTrivy, nil
+	}
+	return "", fmt.Errorf("unrecognized JSON report; expected Trivy, Grype or SARIF output")
+}
+
+// Parse normalizes a report. Findings carry the asset named in the report
+// where the format records one; SARIF reports only do so when they include
+// version control provenance.
+func Parse(format string, data []byte) ([]models.VulnerabilityFinding, error) {
+	var (
+		findings []models.VulnerabilityFinding
+		err      error
+	)
+	switch format {
+	case FormatTrivy:
+		findings, err = parseTrivy(data)
+	case FormatGrype:
+		findings, err = parseGrype(data)
+	case FormatNessus:
+		findings, err = parseNessus(data)
+	case FormatSARIF:
+		findings, err = parseSARIF(data)
+	default:
+		return nil, fmt.Errorf("unsupported report format %q; supported formats are %s", format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s report: %w", format, err)
+	}
+	return findings, nil
+}
+
+// NormalizeSeverity maps scanner specific severity names onto the
+// critical/high/medium/low/info/unknown scale.
+func NormalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "critical":
+		return SeverityCritical
+	case "high", "important":
+		return SeverityHigh
+	case "medium", "moderate":
+		return SeverityMedium
+	case "low", "negligible":
+		return SeverityLow
+	case "info", "informational", "none":
+		return SeverityInfo
+	}
+	return SeverityUnknown
+}
+
+// severityFromCVSS buckets a CVSS v3 base score the way NVD does.
+func severityFromCVSS(score float64) string {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	}
+	return SeverityInfo
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	Results      []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+func parseTrivy(data []byte) ([]models.VulnerabilityFinding, error) {
+	var report trivyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	var findings []models.VulnerabilityFinding
+	for _, result := range report.Results {
+		asset := report.ArtifactName
+		if asset == "" {
+			asset = result.Target
+		}
+		for _, v := range result.Vulnerabilities {
+			findings = append(findings, models.VulnerabilityFinding{
+				Asset:            asset,
+				VulnerabilityID:  v.VulnerabilityID,
+				Severity:         NormalizeSeverity(v.Severity),
+				PackageName:      v.PkgName,
+				InstalledVersion: optional(v.InstalledVersion),
+				FixedVersion:     optional(v.FixedVersion),
+				Title:            optional(v.Title),
+			})
+		}
+	}
+	return findings, nil
+}
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID          string `json:"id"`
+			Severity    string `json:"severity"`
+			Description string `json:"description"`
+			Fix         struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		RelatedVulnerabilities []struct {
+			ID string `json:"id"`
+		} `json:"relatedVulnerabilities"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Source struct {
+		// Target is an object for image scans and a plain path for directory scans.
+		Target json.RawMessage `json:"target"`
+	} `json:"source"`
+}
+
+func (r grypeReport) asset() string {
+	var path string
+	if json.Unmarshal(r.Source.Target, &path) == nil {
+		return path
+	}
+	var image struct {
+		UserInput string `json:"userInput"`
+	}
+	json.Unmarshal(r.Source.Target, &image)
+	return image.UserInput
+}
+
+func parseGrype(data []byte) ([]models.VulnerabilityFinding, error) {
+	var report grypeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	asset := report.asset()
+	var findings []models.VulnerabilityFinding
+	for _, m := range report.Matches {
+		// Grype reports GHSA IDs for language ecosystems; prefer the related CVE.
+		id := m.Vulnerability.ID
+		if !strings.HasPrefix(id, "CVE-") {
+			for _, related := range m.RelatedVulnerabilities {
+				if strings.HasPrefix(related.ID, "CVE-") {
+					id = related.ID
+					break
+				}
+			}
+		}
+		findings = append(findings, models.VulnerabilityFinding{
+			Asset:            asset,
+			VulnerabilityID:  id,
+			Severity:         NormalizeSeverity(m.Vulnerability.Severity),
+			PackageName:      m.Artifact.Name,
+			InstalledVersion: optional(m.Artifact.Version),
+			FixedVersion:     optional(strings.Join(m.Vulnerability.Fix.Versions, ", ")),
+			Title:            optional(m.Vulnerability.Description),
+		})
+	}
+	return findings, nil
+}
+
+type nessusReport struct {
+	Hosts []struct {
+		Name  string `xml:"name,attr"`
+		Items []struct {
+			Port       string   `xml:"port,attr"`
+			Protocol   string   `xml:"protocol,attr"`
+			Severity   int      `xml:"severity,attr"`
+			PluginID   string   `xml:"pluginID,attr"`
+			PluginName string   `xml:"pluginName,attr"`
+			RiskFactor string   `xml:"risk_factor"`
+			CVEs       []string `xml:"cve"`
+		} `xml:"ReportItem"`
+	} `xml:"Report>ReportHost"`
+}
+
+// nessusSeverities maps the ReportItem severity attribute (0-4).
+var nessusSeverities = []string{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+func parseNessus(data []byte) ([]models.VulnerabilityFinding, error) {
+	var report nessusReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	var findings []models.VulnerabilityFinding
+	for _, host := range report.Hosts {
+		for _, item := range host.Items {
+			// Severity 0 items are informational plugin output, not vulnerabilities.
+			if item.Severity <= 0 || item.Severity >= len(nessusSeverities) {
+				continue
+			}
+			severity := nessusSeverities[item.Severity]
+			if rf := NormalizeSeverity(item.RiskFactor); rf != SeverityUnknown && rf != SeverityInfo {
+				severity = rf
+			}
+			location := item.Protocol + "/" + item.Port
+			ids := item.CVEs
+			if len(ids) == 0 {
+				ids = []string{"NESSUS-" + item.PluginID}
+			}
+			for _, id := range ids {
+				findings = append(findings, models.VulnerabilityFinding{
+					Asset:           host.Name,
+					VulnerabilityID: strings.TrimSpace(id),
+					Severity:        severity,
+					PackageName:     location,
+					Title:           optional(item.PluginName),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+type sarifReport struct {
+	Runs []struct {
+		Tool struct {
+			Driver struct {
+				Rules []sarifRule `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		VersionControlProvenance []struct {
+			RepositoryURI string `json:"repositoryUri"`
+		} `json:"versionControlProvenance"`
+		Results []struct {
+			RuleID    string `json:"ruleId"`
+			RuleIndex *int   `json:"ruleIndex"`
+			Level     string `json:"level"`
+			Message   struct {
+				Text string `json:"text"`
+			} `json:"message"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+type sarifRule struct {
+	ID               string `json:"id"`
+	ShortDescription struct {
+		Text string `json:"text"`
+	} `json:"shortDescription"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// sarifLevels maps result levels when no security-severity score is present.
+var sarifLevels = map[string]string{"error": SeverityHigh, "warning": SeverityMedium, "note": SeverityLow, "none": SeverityInfo}
+
+// securitySeverity reads the CVSS style "security-severity" property used by
+// GitHub code scanning and most container scanners' SARIF output.
+func securitySeverity(props map[string]interface{}) (string, bool) {
+	switch v := props["security-severity"].(type) {
+	case string:
+		if score, err := strconv.ParseFloat(v, 64); err == nil {
+			return severityFromCVSS(score), true
+		}
+	case float64:
+		return severityFromCVSS(v), true
+	}
+	return "", false
+}
+
+func parseSARIF(data []byte) ([]models.VulnerabilityFinding, error) {
+	var report sarifReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	var findings []models.VulnerabilityFinding
+	for _, run := range report.Runs {
+		rules := make(map[string]sarifRule, len(run.Tool.Driver.Rules))
+		for _, rule := range run.Tool.Driver.Rules {
+			rules[rule.ID] = rule
+		}
+		var asset string
+		if len(run.VersionControlProvenance) > 0 {
+			asset = run.VersionControlProvenance[0].RepositoryURI
+		}
+		for _, result := range run.Results {
+			rule, ok := rules[result.RuleID]
+			if !ok && result.RuleIndex != nil && *result.RuleIndex < len(run.Tool.Driver.Rules) {
+				rule = run.Tool.Driver.Rules[*result.RuleIndex]
+			}
+			id := result.RuleID
+			if id == "" {
+				id = rule.ID
+			}
+
+			severity, ok := securitySeverity(result.Properties)
+			if !ok {
+				severity, ok = securitySeverity(rule.Properties)
+			}
+			if !ok {
+				level := result.Level
+				if level == "" {
+					level = "warning" // SARIF default
+				}
+				if severity, ok = sarifLevels[level]; !ok {
+					severity = SeverityUnknown
+				}
+			}
+
+			var location string
+			if len(result.Locations) > 0 {
+				location = result.Locations[0].PhysicalLocation.ArtifactLocation.URI
+			}
+			title := rule.ShortDescription.Text
+			if title == "" {
+				title = result.Message.Text
+			}
+			findings = append(findings, models.VulnerabilityFinding{
+				Asset:           asset,
+				VulnerabilityID: id,
+				Severity:        severity,
+				PackageName:     location,
+				Title:           optional(title),
+			})
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,165 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+const trivyReportJSON = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "registry.example.com/shop/api:1.4.2",
+  "ArtifactType": "container_image",
+  "Results": [
+    {
+      "Target": "registry.example.com/shop/api:1.4.2 (debian 12.4)",
+      "Class": "os-pkgs",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2024-2961", "PkgName": "libc6", "InstalledVersion": "2.36-9+deb12u4", "FixedVersion": "2.36-9+deb12u7", "Severity": "HIGH", "Title": "glibc: iconv out-of-bounds write"},
+        {"VulnerabilityID": "CVE-2023-45853", "PkgName": "zlib1g", "InstalledVersion": "1:1.2.13.dfsg-1", "Severity": "CRITICAL"}
+      ]
+    },
+    {"Target": "app/package-lock.json", "Class": "lang-pkgs"}
+  ]
+}`
+
+const grypeReportJSON = `{
+  "matches": [
+    {
+      "vulnerability": {"id": "GHSA-jfh8-c2jp-5v3q", "severity": "Critical", "description": "log4j RCE", "fix": {"versions": ["2.15.0"], "state": "fixed"}},
+      "relatedVulnerabilities": [{"id": "CVE-2021-44228"}],
+      "artifact": {"name": "log4j-core", "version": "2.14.1", "type": "java-archive"}
+    },
+    {
+      "vulnerability": {"id": "CVE-2022-3715", "severity": "Negligible", "fix": {"versions": [], "state": "not-fixed"}},
+      "artifact": {"name": "bash", "version": "5.1-2"}
+    }
+  ],
+  "source": {"type": "image", "target": {"userInput": "alpine:3.18", "imageID": "sha256:abc"}}
+}`
+
+const grypeDirReportJSON = `{"matches": [], "source": {"type": "directory", "target": "/src/service"}}`
+
+const nessusReportXML = `<?xml version="1.0" ?>
+<NessusClientData_v2>
+  <Report name="Weekly internal" xmlns:cm="http://www.nessus.org/cm">
+    <ReportHost name="10.0.0.5">
+      <HostProperties><tag name="host-ip">10.0.0.5</tag></HostProperties>
+      <ReportItem port="0" svc_name="general" protocol="tcp" severity="0" pluginID="19506" pluginName="Nessus Scan Information"/>
+      <ReportItem port="443" svc_name="www" protocol="tcp" severity="3" pluginID="156164" pluginName="Apache 2.4.x &lt; 2.4.52 Multiple Vulnerabilities">
+        <risk_factor>High</risk_factor>
+        <cve>CVE-2021-44790</cve>
+        <cve>CVE-2021-44224</cve>
+      </ReportItem>
+      <ReportItem port="22" svc_name="ssh" protocol="tcp" severity="2" pluginID="70658" pluginName="SSH Server CBC Mode Ciphers Enabled">
+        <risk_factor>Low</risk_factor>
+      </ReportItem>
+    </ReportHost>
+  </Report>
+</NessusClientData_v2>`
+
+const sarifReportJSON = `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {"driver": {"name": "CodeQL", "rules": [
+        {"id": "go/sql-injection", "shortDescription": {"text": "Database query built from user-controlled sources"}, "properties": {"security-severity": "8.8"}},
+        {"id": "go/unused-variable", "shortDescription": {"text": "Unused variable"}}
+      ]}},
+      "versionControlProvenance": [{"repositoryUri": "https://github.com/example/shop"}],
+      "results": [
+        {"ruleId": "go/sql-injection", "level": "error", "message": {"text": "query depends on user input"},
+         "locations": [{"physicalLocation": {"artifactLocation": {"uri": "internal/db/orders.go"}}}]},
+        {"ruleIndex": 1, "level": "note", "message": {"text": "x is never used"},
+         "locations": [{"physicalLocation": {"artifactLocation": {"uri": "cmd/main.go"}}}]}
+      ]
+    }
+  ]
+}`
+
+func TestDetectFormat(t *testing.T) {
+	for want, report := range map[string]string{
+		FormatTrivy:  trivyReportJSON,
+		FormatGrype:  grypeReportJSON,
+		FormatNessus: nessusReportXML,
+		FormatSARIF:  sarifReportJSON,
+	} {
+		got, err := DetectFormat([]byte(report))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := DetectFormat([]byte(`{"hello": "world"}`))
+	assert.Error(t, err)
+	_, err = DetectFormat([]byte(`<html></html>`))
+	assert.Error(t, err)
+}
+
+func TestParseTrivy(t *testing.T) {
+	findings, err := Parse(FormatTrivy, []byte(trivyReportJSON))
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "registry.example.com/shop/api:1.4.2", findings[0].Asset)
+	assert.Equal(t, "CVE-2024-2961", findings[0].VulnerabilityID)
+	assert.Equal(t, SeverityHigh, findings[0].Severity)
+	assert.Equal(t, "libc6", findings[0].PackageName)
+	assert.Equal(t, "2.36-9+deb12u7", *findings[0].FixedVersion)
+	assert.Equal(t, SeverityCritical, findings[1].Severity)
+	assert.Nil(t, findings[1].FixedVersion)
+}
+
+func TestParseGrype(t *testing.T) {
+	findings, err := Parse(FormatGrype, []byte(grypeReportJSON))
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "alpine:3.18", findings[0].Asset)
+	assert.Equal(t, "CVE-2021-44228", findings[0].VulnerabilityID, "the related CVE is preferred over the GHSA ID")
+	assert.Equal(t, SeverityCritical, findings[0].Severity)
+	assert.Equal(t, "log4j-core", findings[0].PackageName)
+	assert.Equal(t, "2.15.0", *findings[0].FixedVersion)
+	assert.Equal(t, SeverityLow, findings[1].Severity)
+	assert.Nil(t, findings[1].FixedVersion)
+
+	_, err = Parse(FormatGrype, []byte(grypeDirReportJSON))
+	require.NoError(t, err)
+	var report grypeReport
+	require.NoError(t, json.Unmarshal([]byte(grypeDirReportJSON), &report))
+	assert.Equal(t, "/src/service", report.asset())
+}
+
+func TestParseNessus(t *testing.T) {
+	findings, err := Parse(FormatNessus, []byte(nessusReportXML))
+	require.NoError(t, err)
+	assert.Equal(t, []models.VulnerabilityFinding{
+		{Asset: "10.0.0.5", VulnerabilityID: "CVE-2021-44790", Severity: SeverityHigh, PackageName: "tcp/443", Title: optional("Apache 2.4.x < 2.4.52 Multiple Vulnerabilities")},
+		{Asset: "10.0.0.5", VulnerabilityID: "CVE-2021-44224", Severity: SeverityHigh, PackageName: "tcp/443", Title: optional("Apache 2.4.x < 2.4.52 Multiple Vulnerabilities")},
+		{Asset: "10.0.0.5", VulnerabilityID: "NESSUS-70658", Severity: SeverityLow, PackageName: "tcp/22", Title: optional("SSH Server CBC Mode Ciphers Enabled")},
+	}, findings)
+}
+
+func TestParseSARIF(t *testing.T) {
+	findings, err := Parse(FormatSARIF, []byte(sarifReportJSON))
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "https://github.com/example/shop", findings[0].Asset)
+	assert.Equal(t, "go/sql-injection", findings[0].VulnerabilityID)
+	assert.Equal(t, SeverityHigh, findings[0].Severity, "security-severity takes precedence over level")
+	assert.Equal(t, "internal/db/orders.go", findings[0].PackageName)
+	assert.Equal(t, "go/unused-variable", findings[1].VulnerabilityID, "rule resolved through ruleIndex")
+	assert.Equal(t, SeverityLow, findings[1].Severity)
+	assert.Equal(t, "Unused variable", *findings[1].Title)
+}
+
+func TestParseRejectsUnknownFormatAndBadInput(t *testing.T) {
+	_, err := Parse("qualys", []byte(`{}`))
+	assert.Error(t, err)
+	_, err = Parse(FormatTrivy, []byte(`not json`))
+	assert.Error(t, err)
+	_, err = Parse(FormatNessus, []byte(`<NessusClientData_v2><Report>`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package vulnscan
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+// DefaultPolicy is applied when neither the upload nor the task sets one.
+const DefaultPolicy = "critical:15, high:30"
+
+// severityRank orders severities for reporting, most severe first.
+var severityRank = map[string]int{SeverityCritical: 0, SeverityHigh: 1, SeverityMedium: 2, SeverityLow: 3, SeverityInfo: 4, SeverityUnknown: 5}
+
+// Policy maps a severity to the maximum number of days a finding of that
+// severity may stay open, counted from when it was first seen on the asset.
+// A limit of 0 fails any open finding of that severity. Severities without
+// an entry are reported but never fail the task.
+type Policy map[string]int
+
+// ParsePolicy reads a policy written as "critical:15, high:30".
+func ParsePolicy(s string) (Policy, error) {
+	policy := Policy{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, days, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("policy entry %q must be severity:days", entry)
+		}
+		severity := NormalizeSeverity(name)
+		if severity == SeverityUnknown && !strings.EqualFold(strings.TrimSpace(name), SeverityUnknown) {
+			return nil, fmt.Errorf("policy entry %q has an unknown severity", entry)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(days))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("policy entry %q must have a non-negative number of days", entry)
+		}
+		policy[severity] = n
+	}
+	if len(policy) == 0 {
+		return nil, fmt.Errorf("policy %q has no entries", s)
+	}
+	return policy, nil
+}
+
+func (p Policy) String() string {
+	severities := make([]string, 0, len(p))
+	for severity := range p {
+		severities = append(severities, severity)
+	}
+	sort.Slice(severities, func(i, j int) bool { return severityRank[severities[i]] < severityRank[severities[j]] })
+	entries := make([]string, len(severities))
+	for i, severity := range severities {
+		entries[i] = fmt.Sprintf("%s:%d", severity, p[severity])
+	}
+	return strings.Join(entries, ", ")
+}
+
+// Violation is a finding that has been open longer than the policy allows.
+type Violation struct {
+	Asset           string    `json:"asset"`
+	VulnerabilityID string    `json:"vulnerability_id"`
+	Severity        string    `json:"severity"`
+	PackageName     string    `json:"package_name,omitempty"`
+	FirstSeenAt     time.Time `json:"first_seen_at"`
+	AgeDays         int       `json:"age_days"`
+	MaxAgeDays      int       `json:"max_age_days"`
+}
+
+// Evaluation is the outcome of checking a report's findings against a policy.
+type Evaluation struct {
+	Passed         bool           `json:"passed"`
+	Policy         string         `json:"policy"`
+	TotalFindings  int            `json:"total_findings"`
+	SeverityCounts map[string]int `json:"severity_counts"`
+	Assets         []string       `json:"assets"`
+	Violations     []Violation    `json:"violations"`
+}
+
+// Evaluate checks findings against the policy as of now. Findings must have
+// FirstSeenAt set, which the store does when the report is saved.
+func (p Policy) Evaluate(findings []models.VulnerabilityFinding, now time.Time) Evaluation {
+	eval := Evaluation{
+		Policy:         p.String(),
+		TotalFindings:  len(findings),
+		SeverityCounts: map[string]int{},
+		Assets:         []string{},
+		Violations:     []Violation{},
+	}
+	assets := map[string]bool{}
+	for _, f := range findings {
+		eval.SeverityCounts[f.Severity]++
+		if !assets[f.Asset] {
+			assets[f.Asset] = true
+			eval.Assets = append(eval.Assets, f.Asset)
+		}
+		maxDays, enforced := p[f.Severity]
+		if !enforced {
+			continue
+		}
+		age := int(now.Sub(f.FirstSeenAt).Hours() / 24)
+		if maxDays > 0 && age <= maxDays {
+			continue
+		}
+		eval.Violations = append(eval.Violations, Violation{
+			Asset:           f.Asset,
+			VulnerabilityID: f.VulnerabilityID,
+			Severity:        f.Severity,
+			PackageName:     f.PackageName,
+			FirstSeenAt:     f.FirstSeenAt,
+			AgeDays:         age,
+			MaxAgeDays:      maxDays,
+		})
+	}
+	sort.Strings(eval.Assets)
+	sort.SliceStable(eval.Violations, func(i, j int) bool {
+		a, b := eval.Violations[i], eval.Violations[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		return a.AgeDays > b.AgeDays
+	})
+	eval.Passed = len(eval.Violations) == 0
+	return eval
+}
+
+// PolicyFromParameters reads the "policy" task parameter, falling back to
+// DefaultPolicy when the task does not set one.
+func PolicyFromParameters(params map[string]interface{}) (Policy, error) {
+	if s, ok := params["policy"].(string); ok && strings.TrimSpace(s) != "" {
+		return ParsePolicy(s)
+	}
+	return ParsePolicy(DefaultPolicy)
+}
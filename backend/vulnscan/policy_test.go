@@ -0,0 +1,58 @@
+package vulnscan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vdparikh/compliance-automation/backend/models"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("Critical:15, high : 30,moderate:90")
+	require.NoError(t, err)
+	assert.Equal(t, Policy{SeverityCritical: 15, SeverityHigh: 30, SeverityMedium: 90}, policy)
+	assert.Equal(t, "critical:15, high:30, medium:90", policy.String())
+
+	for _, bad := range []string{"", "critical", "critical:soon", "critical:-1", "severe:10"} {
+		_, err := ParsePolicy(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	findings := []models.VulnerabilityFinding{
+		{Asset: "api:1.4", VulnerabilityID: "CVE-1", Severity: SeverityCritical, PackageName: "openssl", FirstSeenAt: daysAgo(3)},
+		{Asset: "api:1.4", VulnerabilityID: "CVE-2", Severity: SeverityCritical, PackageName: "zlib", FirstSeenAt: daysAgo(16)},
+		{Asset: "web:2.0", VulnerabilityID: "CVE-3", Severity: SeverityCritical, PackageName: "zlib", FirstSeenAt: daysAgo(15)},
+		{Asset: "web:2.0", VulnerabilityID: "CVE-4", Severity: SeverityHigh, PackageName: "curl", FirstSeenAt: daysAgo(45)},
+		{Asset: "web:2.0", VulnerabilityID: "CVE-5", Severity: SeverityMedium, PackageName: "bash", FirstSeenAt: daysAgo(400)},
+		{Asset: "web:2.0", VulnerabilityID: "CVE-6", Severity: SeverityCritical, PackageName: "glibc", FirstSeenAt: daysAgo(60)},
+	}
+
+	policy, err := ParsePolicy(DefaultPolicy)
+	require.NoError(t, err)
+	eval := policy.Evaluate(findings, now)
+	assert.False(t, eval.Passed)
+	assert.Equal(t, 6, eval.TotalFindings)
+	assert.Equal(t, map[string]int{SeverityCritical: 4, SeverityHigh: 1, SeverityMedium: 1}, eval.SeverityCounts)
+	assert.Equal(t, []string{"api:1.4", "web:2.0"}, eval.Assets)
+	assert.Equal(t, []Violation{
+		{Asset: "web:2.0", VulnerabilityID: "CVE-6", Severity: SeverityCritical, PackageName: "glibc", FirstSeenAt: daysAgo(60), AgeDays: 60, MaxAgeDays: 15},
+		{Asset: "api:1.4", VulnerabilityID: "CVE-2", Severity: SeverityCritical, PackageName: "zlib", FirstSeenAt: daysAgo(16), AgeDays: 16, MaxAgeDays: 15},
+		{Asset: "web:2.0", VulnerabilityID: "CVE-4", Severity: SeverityHigh, PackageName: "curl", FirstSeenAt: daysAgo(45), AgeDays: 45, MaxAgeDays: 30},
+	}, eval.Violations, "exactly 15 days old is still within a 15 day limit; medium is not enforced")
+
+	zeroTolerance := Policy{SeverityCritical: 0}
+	eval = zeroTolerance.Evaluate(findings[:1], now)
+	assert.False(t, eval.Passed)
+	assert.Len(t, eval.Violations, 1)
+
+	eval = policy.Evaluate(nil, now)
+	assert.True(t, eval.Passed)
+	assert.Empty(t, eval.Violations)
+}
@@ -7,7 +7,7 @@ import {
 import Select from 'react-select';
 import CreatableSelect from 'react-select/creatable';
 
-import { fetchIntegrationCheckTypes } from '../../services/api';
+import { fetchIntegrationCheckTypes, getTeams } from '../../services/api';
 const evidenceTypeOptions = [
     { value: 'screenshot', label: 'Screenshot' },
     { value: 'log_file', label: 'Log File' },
@@ -37,6 +37,13 @@ function TaskForm({ initialData, onSubmit, onCancel, mode, requirements = [], us
     const [parameters, setParameters] = useState({});
     const [evidenceTypesExpected, setEvidenceTypesExpected] = useState([]);
     const [linkedDocumentIDs, setLinkedDocumentIDs] = useState([]);
+    const [defaultOwnerTeamId, setDefaultOwnerTeamId] = useState('');
+    const [defaultAssigneeTeamId, setDefaultAssigneeTeamId] = useState('');
+    const [defaultOwnerUserId, setDefaultOwnerUserId] = useState('');
+    const [defaultAssigneeUserId, setDefaultAssigneeUserId] = useState('');
+    const [dueDateAnchor, setDueDateAnchor] = useState('');
+    const [dueDateOffsetDays, setDueDateOffsetDays] = useState('');
+    const [teams, setTeams] = useState([]);
     const [error, setError] = useState('');
 
     const [dynamicCheckTypeConfigurations, setDynamicCheckTypeConfigurations] = useState({});
@@ -66,6 +73,12 @@ function TaskForm({ initialData, onSubmit, onCancel, mode, requirements = [], us
             setCheckType(initialData.checkType || initialData.check_type || 'manual');
             setTarget(initialData.target || '');
             setParameters(initialData.parameters || {});
+            setDefaultOwnerTeamId(initialData.defaultOwnerTeamId || '');
+            setDefaultAssigneeTeamId(initialData.defaultAssigneeTeamId || '');
+            setDefaultOwnerUserId(initialData.defaultOwnerUserId || '');
+            setDefaultAssigneeUserId(initialData.defaultAssigneeUserId || '');
+            setDueDateAnchor(initialData.dueDateAnchor || '');
+            setDueDateOffsetDays(initialData.dueDateOffsetDays ?? '');
             setEvidenceTypesExpected(
                 initialData.evidenceTypesExpected
                     ? initialData.evidenceTypesExpected.map(et => evidenceTypeOptions.find(opt => opt.value === et) || { value: et, label: et })
@@ -94,6 +107,12 @@ function TaskForm({ initialData, onSubmit, onCancel, mode, requirements = [], us
             setCheckType('automated');
             setTarget('');
             setParameters({});
+            setDefaultOwnerTeamId('');
+            setDefaultAssigneeTeamId('');
+            setDefaultOwnerUserId('');
+            setDefaultAssigneeUserId('');
+            setDueDateAnchor('');
+            setDueDateOffsetDays('');
             setEvidenceTypesExpected([]);
             setLinkedDocumentIDs([]);
         }
@@ -115,6 +134,9 @@ function TaskForm({ initialData, onSubmit, onCancel, mode, requirements = [], us
             }
         };
         fetchCheckTypeConfigs();
+        getTeams()
+            .then(response => setTeams(response.data || []))
+            .catch(err => console.error("Error fetching teams:", err));
     }, []);
 
     const handleSubmit = (e) => {
@@ -145,6 +167,11 @@ function TaskForm({ initialData, onSubmit, onCancel, mode, requirements = [], us
             return;
         }
 
+        if ((dueDateAnchor === '') !== (dueDateOffsetDays === '')) {
+            setError('Set both the due date anchor and the offset in days, or neither');
+            return;
+        }
+
         if (highLevelCheckType === 'automated' && !target) {
             setError('Target system is required for automated checks');
             return;
@@ -164,6 +191,12 @@ function TaskForm({ initialData, onSubmit, onCancel, mode, requirements = [], us
             parameters,
             evidenceTypesExpected: evidenceTypesExpected.map(opt => opt.value),
             linkedDocumentIDs: linkedDocumentIDs.map(opt => opt.value),
+            defaultOwnerTeamId: defaultOwnerTeamId || null,
+            defaultAssigneeTeamId: defaultAssigneeTeamId || null,
+            defaultOwnerUserId: defaultOwnerUserId || null,
+            defaultAssigneeUserId: defaultAssigneeUserId || null,
+            dueDateAnchor: dueDateAnchor || null,
+            dueDateOffsetDays: dueDateOffsetDays === '' ? null : Number(dueDateOffsetDays),
         });
     };
 
@@ -310,6 +343,71 @@ function TaskForm({ initialData, onSubmit, onCancel, mode, requirements = [], us
                 </Card.Body>
             </Card>
 
+            <Card className='bg-light mb-3'>
+                <Card.Header><b>Campaign Defaults</b></Card.Header>
+                <Card.Body>
+                    <Row>
+                        <Col md={6}>
+                            <FloatingLabel controlId="formDefaultOwnerTeam" label="Owner Team" className="mb-3">
+                                <Form.Select value={defaultOwnerTeamId} onChange={(e) => setDefaultOwnerTeamId(e.target.value)}>
+                                    <option value="">None</option>
+                                    {teams.map(team => <option key={team.id} value={team.id}>{team.name}</option>)}
+                                </Form.Select>
+                            </FloatingLabel>
+                        </Col>
+                        <Col md={6}>
+                            <FloatingLabel controlId="formDefaultOwnerUser" label="Owner" className="mb-3">
+                                <Form.Select value={defaultOwnerUserId} onChange={(e) => setDefaultOwnerUserId(e.target.value)}>
+                                    <option value="">None</option>
+                                    {users.map(user => <option key={user.id} value={user.id}>{user.name || user.email}</option>)}
+                                </Form.Select>
+                            </FloatingLabel>
+                        </Col>
+                    </Row>
+                    <Row>
+                        <Col md={6}>
+                            <FloatingLabel controlId="formDefaultAssigneeTeam" label="Assignee Team" className="mb-3">
+                                <Form.Select value={defaultAssigneeTeamId} onChange={(e) => setDefaultAssigneeTeamId(e.target.value)}>
+                                    <option value="">None</option>
+                                    {teams.map(team => <option key={team.id} value={team.id}>{team.name}</option>)}
+                                </Form.Select>
+                            </FloatingLabel>
+                        </Col>
+                        <Col md={6}>
+                            <FloatingLabel controlId="formDefaultAssigneeUser" label="Assignee" className="mb-3">
+                                <Form.Select value={defaultAssigneeUserId} onChange={(e) => setDefaultAssigneeUserId(e.target.value)}>
+                                    <option value="">{defaultAssigneeTeamId ? 'Picked from the assignee team' : 'None'}</option>
+                                    {users.map(user => <option key={user.id} value={user.id}>{user.name || user.email}</option>)}
+                                </Form.Select>
+                            </FloatingLabel>
+                        </Col>
+                    </Row>
+                    <Row>
+                        <Col md={6}>
+                            <FloatingLabel controlId="formDueDateAnchor" label={<><FaCalendarAlt className="me-1" />Due Relative To</>} className="mb-3">
+                                <Form.Select value={dueDateAnchor} onChange={(e) => setDueDateAnchor(e.target.value)}>
+                                    <option value="">No default due date</option>
+                                    <option value="start">Campaign start</option>
+                                    <option value="end">Campaign end</option>
+                                </Form.Select>
+                            </FloatingLabel>
+                        </Col>
+                        <Col md={6}>
+                            <FloatingLabel controlId="formDueDateOffsetDays" label="Offset (days)" className="mb-3">
+                                <Form.Control
+                                    type="number"
+                                    value={dueDateOffsetDays}
+                                    onChange={(e) => setDueDateOffsetDays(e.target.value)}
+                                    placeholder="e.g., -14"
+                                    disabled={!dueDateAnchor}
+                                />
+                            </FloatingLabel>
+                        </Col>
+                    </Row>
+                    <Form.Text muted>Applied to task instances generated in campaigns. Use a negative offset for days before the anchor, e.g. -14 for two weeks before the campaign ends.</Form.Text>
+                </Card.Body>
+            </Card>
+
             <Card className='bg-light mb-3'>
                 <Card.Header><b>Evidence Automation</b></Card.Header>
                 <Card.Body>
@@ -1,6 +1,6 @@
 import React, { useState, useEffect, useCallback } from 'react';
 import { Button, ListGroup, Form, Spinner, Alert, Row, Col, Card } from 'react-bootstrap';
-import { getCampaigns, getCampaignTaskInstances, getEvidenceByCampaignTaskInstanceId } from '../../services/api';
+import { getAllCampaigns, getCampaignTaskInstances, getEvidenceByCampaignTaskInstanceId } from '../../services/api';
 import { FaCopy, FaFileAlt, FaLink } from 'react-icons/fa';
 import RightSidePanel from '../layout/RightSidePanel';
 
@@ -20,8 +20,8 @@ const CopyEvidenceModal = ({ show, onHide, targetCampaignId, onCopySubmit }) =>
     const fetchCampaignsForModal = useCallback(async () => {
         setLoadingCampaigns(true);
         try {
-            const response = await getCampaigns(); 
-            setCampaigns(Array.isArray(response.data) ? response.data : []);
+            const response = await getAllCampaigns();
+            setCampaigns(Array.isArray(response.data?.campaigns) ? response.data.campaigns : []);
             if (targetCampaignId) { 
                 setSelectedCampaignId(targetCampaignId);
             }
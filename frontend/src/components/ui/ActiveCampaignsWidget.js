@@ -13,9 +13,9 @@ const ActiveCampaignsWidget = () => {
         setLoading(true);
         setError('');
         try {
-            const response = await getCampaigns("Active");
+            const response = await getCampaigns({ status: 'Active', limit: 100 });
             if (response.data) {
-                setActiveCampaigns(Array.isArray(response.data) ? response.data : []);
+                setActiveCampaigns(Array.isArray(response.data.campaigns) ? response.data.campaigns : []);
             } else {
                 setActiveCampaigns([]);
             }
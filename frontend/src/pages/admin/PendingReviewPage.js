@@ -2,7 +2,7 @@ import React, { useState, useEffect, useCallback, useContext, useMemo, useRef }
 import { Container, Row, Col, Card, Button, ButtonGroup, Alert, Spinner, Tabs, Tab, ListGroup, Badge, Modal, Form, FloatingLabel, InputGroup, Dropdown, DropdownButton, Table } from 'react-bootstrap';
 import { FaHourglassHalf, FaCheckCircle, FaTimesCircle, FaEye, FaEdit, FaTrash, FaFilter, FaSort, FaSortUp, FaSortDown, FaDownload, FaUpload, FaEyeSlash, FaLink, FaUnlink, FaTasks, FaBookOpen, FaFileContract, FaExclamationTriangle, FaUserShield, FaHistory, FaCalendar, FaUser, FaCog, FaSearch, FaPlus, FaMinus, FaArrowUp, FaArrowDown, FaSortAmountUp, FaSortAmountDown, FaListUl, FaTable } from 'react-icons/fa';
 import { Link } from 'react-router-dom';
-import { getPendingReviews, approveReview, rejectReview, getReviewDetails, getUsers, getTasks, getCampaigns, getRequirements, getStandards, getDocuments, getConnectedSystems, getCampaignTasksByStatus } from '../../services/api';
+import { getPendingReviews, approveReview, rejectReview, getReviewDetails, getUsers, getTasks, getAllCampaigns, getRequirements, getStandards, getDocuments, getConnectedSystems, getCampaignTasksByStatus } from '../../services/api';
 import { getTaskCategoryIcon, getStatusColor } from '../../utils/displayUtils';
 import { getStandardCategoryIcon } from '../../utils/iconMap';
 import { RightPanelContext } from '../../App';
@@ -48,7 +48,7 @@ function PendingReviewPage() {
         try {
             const tasksPromise = getCampaignTasksByStatus("Active", "Pending Review");
             const usersPromise = getUsers();
-            const campaignsPromise = getCampaigns("Active");
+            const campaignsPromise = getAllCampaigns({ status: 'Active' });
 
             // Await promises and handle potential individual errors more gracefully
             const [tasksResponse, usersResponse, campaignsResponse] = await Promise.allSettled([tasksPromise, usersPromise, campaignsPromise]);
@@ -77,7 +77,7 @@ function PendingReviewPage() {
             }
 
             if (campaignsResponse.status === 'fulfilled' && campaignsResponse.value?.data) {
-                setAllCampaigns(Array.isArray(campaignsResponse.value.data) ? campaignsResponse.value.data : []);
+                setAllCampaigns(Array.isArray(campaignsResponse.value.data.campaigns) ? campaignsResponse.value.data.campaigns : []);
             } else if (campaignsResponse.status === 'fulfilled' && !campaignsResponse.value?.data) {
                 console.warn("No data in campaigns response or response format unexpected", campaignsResponse.value);
                 setAllCampaigns([]);
@@ -7,6 +7,9 @@ import {
     getCampaignSelectedRequirements,
     getCampaignTaskInstances,
     updateCampaign,
+    transitionCampaign,
+    signOffCampaign,
+    cloneCampaign,
     deleteCampaign, // This now includes risks
     updateCampaignTaskInstance,
     getUsers,
@@ -51,6 +54,7 @@ import {
     FaEdit,
     FaLink,
     FaTrashAlt,
+    FaCopy,
     FaCheckCircle,
     FaSpinner,
 
@@ -88,6 +92,13 @@ import RiskDetailModal from '../../components/modals/RiskDetailModal';
 
 ChartJS.register(ArcElement, Tooltip, Legend, CategoryScale, LinearScale, BarElement, Title);
 
+// A task instance counts toward every scoped requirement it is linked to, so
+// a control shared between standards shows up under each of them.
+const taskCoversRequirement = (task, campaignSelectedRequirementId) =>
+    (task.campaign_selected_requirement_ids?.length
+        ? task.campaign_selected_requirement_ids
+        : [task.campaign_selected_requirement_id]
+    ).includes(campaignSelectedRequirementId);
 
 function CampaignDetail() {
     const { campaignId } = useParams();
@@ -190,7 +201,7 @@ function CampaignDetail() {
             );
         }
         if (selectedRequirementFilterId) {
-            tempTasks = tempTasks.filter(task => task.campaign_selected_requirement_id === selectedRequirementFilterId);
+            tempTasks = tempTasks.filter(task => taskCoversRequirement(task, selectedRequirementFilterId));
         }
         if (activeStatusFilter) {
             tempTasks = tempTasks.filter(task => task.status === activeStatusFilter);
@@ -281,32 +292,48 @@ function CampaignDetail() {
         if (!campaign || campaign.status === newStatus) return;
         setError('');
         try {
-
-
-
-
-            const campaignUpdateData = {
-                ...campaign, status: newStatus,
-                selected_requirements: currentSelectedRequirementsForCampaign.map(({ requirement_id, is_applicable }) => ({ requirement_id, is_applicable }))
-            };
-            const updatedCampaign = await updateCampaign(campaignId, campaignUpdateData);
+            let updatedCampaign;
+            if (newStatus === 'Completed') {
+                const comment = window.prompt('Sign-off comment (optional):');
+                if (comment === null) return;
+                updatedCampaign = await signOffCampaign(campaignId, comment || undefined);
+            } else {
+                updatedCampaign = await transitionCampaign(campaignId, newStatus);
+            }
             setCampaign(updatedCampaign.data);
-
+            if (newStatus === 'Active') {
+                fetchCampaignData();
+            }
         } catch (err) {
             console.error("Error updating campaign status:", err);
             setError(`Failed to update campaign status. ${err.response?.data?.error || err.message}`);
         }
     };
 
+    const handleCloneCampaign = async () => {
+        if (!campaign) return;
+        if (!window.confirm('Clone this campaign into the next audit period as a new draft?')) return;
+        const carryForward = window.confirm('Carry forward approved evidence as references?');
+        setError('');
+        try {
+            const response = await cloneCampaign(campaignId, { carry_forward_evidence: carryForward });
+            navigate(`/campaigns/${response.data.campaign.id}`);
+        } catch (err) {
+            console.error("Error cloning campaign:", err);
+            setError(`Failed to clone campaign. ${err.response?.data?.error || err.message}`);
+        }
+    };
+
     const handleOpenRequirementsModal = async () => {
-        if (!campaign?.standard_id) {
+        const standardIds = campaign?.standard_ids?.length ? campaign.standard_ids : [campaign?.standard_id].filter(Boolean);
+        if (standardIds.length === 0) {
             setError("Campaign standard is not set. Cannot fetch requirements.");
             return;
         }
         try {
             const allReqsRes = await getAllMasterRequirements();
             const filteredReqs = Array.isArray(allReqsRes.data)
-                ? allReqsRes.data.filter(r => r.standardId === campaign.standard_id)
+                ? allReqsRes.data.filter(r => standardIds.includes(r.standardId))
                 : [];
             setAvailableRequirementsForModal(filteredReqs);
 
@@ -487,7 +514,7 @@ function CampaignDetail() {
 
     const renderRequirementWithProgressBar = (req) => {
         const tasksForThisRequirement = taskInstances.filter(
-            task => task.campaign_selected_requirement_id === req.id
+            task => taskCoversRequirement(task, req.id)
         );
         const totalTasks = tasksForThisRequirement.length;
         const statusCounts = tasksForThisRequirement.reduce((acc, task) => {
@@ -726,7 +753,11 @@ function CampaignDetail() {
 
     return (
         <Container fluid>
-            <div className=''><Badge className="small mb-1">{campaign.standard_name || 'N/A'}</Badge></div>
+            <div className=''>
+                {campaign.standards?.length
+                    ? campaign.standards.map(std => <Badge key={std.id} className="small mb-1 me-1">{std.short_name || std.name}</Badge>)
+                    : <Badge className="small mb-1">{campaign.standard_name || 'N/A'}</Badge>}
+            </div>
 
             <PageHeader
                 icon={<FaBullhorn />}
@@ -746,14 +777,19 @@ function CampaignDetail() {
                                         {campaign.status}
                                     </Dropdown.Toggle>
                                     <Dropdown.Menu>
-                                        <Dropdown.Item onClick={() => handleCampaignStatusChange('Draft')} active={campaign.status === 'Draft'} disabled={!canEditCampaign}>Draft</Dropdown.Item>
-                                        <Dropdown.Item onClick={() => handleCampaignStatusChange('Active')} active={campaign.status === 'Active'} disabled={!canEditCampaign}>Active</Dropdown.Item>
-                                        <Dropdown.Item onClick={() => handleCampaignStatusChange('In Progress')} active={campaign.status === 'In Progress'} disabled={!canEditCampaign}>In Progress</Dropdown.Item>
-                                        <Dropdown.Item onClick={() => handleCampaignStatusChange('Pending Review')} active={campaign.status === 'Pending Review'} disabled={!canEditCampaign}>Pending Review</Dropdown.Item>
-                                        <Dropdown.Item onClick={() => handleCampaignStatusChange('Completed')} active={campaign.status === 'Completed'} disabled={!canEditCampaign}>Completed</Dropdown.Item>
-                                        <Dropdown.Item onClick={() => handleCampaignStatusChange('Archived')} active={campaign.status === 'Archived'} disabled={!canEditCampaign}>Archived</Dropdown.Item>
+                                        {(campaign.allowed_transitions || []).map(status => (
+                                            <Dropdown.Item key={status} onClick={() => handleCampaignStatusChange(status)}>
+                                                {status === 'Completed' ? 'Sign off as Completed' : status}
+                                            </Dropdown.Item>
+                                        ))}
+                                        {(campaign.allowed_transitions || []).length === 0 && (
+                                            <Dropdown.Item disabled>No further status changes</Dropdown.Item>
+                                        )}
                                     </Dropdown.Menu>
                                 </Dropdown>
+                                <Button variant="outline-secondary" size="sm" onClick={handleCloneCampaign} title="Clone into next period" className="ms-1">
+                                    <FaCopy />
+                                </Button>
                                 <Button variant="outline-danger" size="sm" onClick={() => setShowDeleteConfirmModal(true)} title="Delete Campaign" className="ms-1">
                                     <FaTrashAlt />
                                 </Button>
@@ -1046,7 +1082,7 @@ function CampaignDetail() {
                     <Modal.Title>Edit Scoped Requirements for: {campaign?.name}</Modal.Title>
                 </Modal.Header>
                 <Modal.Body style={{ maxHeight: '60vh', overflowY: 'auto' }}>
-                    {availableRequirementsForModal.length === 0 && <p>No requirements found for this campaign's standard.</p>}
+                    {availableRequirementsForModal.length === 0 && <p>No requirements found for this campaign's standards.</p>}
                     <ListGroup>
                         {availableRequirementsForModal.map(req => {
                             const isSelected = currentSelectedRequirementsForCampaign.some(sr => sr.requirement_id === req.id);
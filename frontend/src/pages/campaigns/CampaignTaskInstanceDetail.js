@@ -512,7 +512,7 @@ function CampaignTaskInstanceDetail() {
         setLoadingResults(true);
         try {
             const response = await getCampaignTaskInstanceResults(instanceId);
-            setExecutionResults(Array.isArray(response.data) ? response.data : []);
+            setExecutionResults(Array.isArray(response.data?.results) ? response.data.results : []);
         } catch (err) {
             console.error("Error fetching execution results:", err);
             setExecutionError(`Failed to fetch execution results. ${err.response?.data?.error || ''}`);
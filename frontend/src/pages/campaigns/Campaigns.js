@@ -32,7 +32,9 @@ import {
     FaExclamationTriangle
 } from 'react-icons/fa';
 import PageHeader from '../../components/ui/PageHeader';
-import { ProgressBar, Spinner } from 'react-bootstrap';
+import { ProgressBar, Spinner, Pagination } from 'react-bootstrap';
+
+const CAMPAIGNS_PAGE_SIZE = 12;
 
 function Campaigns() {
     const { currentUser } = useAuth();
@@ -47,6 +49,7 @@ function Campaigns() {
     const [newCampaignName, setNewCampaignName] = useState('');
     const [newCampaignDescription, setNewCampaignDescription] = useState('');
     const [selectedStandard, setSelectedStandard] = useState('');
+    const [additionalStandards, setAdditionalStandards] = useState([]);
     const [allStandards, setAllStandards] = useState([]);
     const [startDate, setStartDate] = useState('');
     const [endDate, setEndDate] = useState('');
@@ -57,22 +60,40 @@ function Campaigns() {
     const [selectedRequirementsForCampaign, setSelectedRequirementsForCampaign] = useState([]);
 
     const [loadingCampaigns, setLoadingCampaigns] = useState(true);
+    const [listFilters, setListFilters] = useState({ status: '', standard_id: '', from: '', to: '', sort_by: 'created_at', sort_order: 'desc' });
+    const [currentPage, setCurrentPage] = useState(1);
+    const [totalPages, setTotalPages] = useState(0);
 
     const fetchCampaigns = useCallback(async () => {
         try {
-            // const response = await getCampaigns();
             setLoadingCampaigns(true);
-            // Request task summaries when fetching campaigns for the "existing" tab
-            const response = await getCampaigns(null, true); // Pass true to include summaries
-
-            setCampaigns(Array.isArray(response.data) ? response.data : []);
+            const params = {
+                page: currentPage,
+                limit: CAMPAIGNS_PAGE_SIZE,
+                sort_by: listFilters.sort_by,
+                sort_order: listFilters.sort_order,
+            };
+            if (listFilters.status) params.status = listFilters.status;
+            if (listFilters.standard_id) params.standard_id = listFilters.standard_id;
+            if (listFilters.from) params.from = `${listFilters.from}T00:00:00Z`;
+            if (listFilters.to) params.to = `${listFilters.to}T23:59:59Z`;
+            const response = await getCampaigns(params);
+
+            setCampaigns(Array.isArray(response.data?.campaigns) ? response.data.campaigns : []);
+            setTotalPages(response.data?.pagination?.total_pages || 0);
         } catch (err) {
             console.error("Error fetching campaigns:", err);
             setError('Failed to fetch campaigns.');
         } finally {
             setLoadingCampaigns(false);
         }
-    }, []);
+    }, [currentPage, listFilters]);
+
+    const handleListFilterChange = (e) => {
+        const { name, value } = e.target;
+        setListFilters(prev => ({ ...prev, [name]: value }));
+        setCurrentPage(1);
+    };
 
     const fetchFormData = useCallback(async () => {
         try {
@@ -86,33 +107,49 @@ function Campaigns() {
 
     useEffect(() => {
         fetchCampaigns();
+    }, [fetchCampaigns]);
+
+    useEffect(() => {
         fetchFormData();
         if (location.state?.successMessage) {
             setSuccess(location.state.successMessage);
 
             window.history.replaceState({}, document.title)
         }
-    }, [fetchCampaigns, fetchFormData, location.state]);
+    }, [fetchFormData, location.state]);
 
-    const handleStandardChangeForModal = async (standardId) => {
-        setSelectedStandard(standardId);
-        if (standardId) {
-            try {
-
-                const reqRes = await getRequirements();
-                const filteredReqs = Array.isArray(reqRes.data) ? reqRes.data.filter(r => r.standardId === standardId) : [];
-                setAvailableRequirements(filteredReqs);
-                setSelectedRequirementsForCampaign([]);
-            } catch (err) {
-                console.error("Error fetching requirements for modal:", err);
-                setAvailableRequirements([]);
-            }
-        } else {
+    // Loads the requirements of all standards in scope and drops selections
+    // of standards that are no longer in it.
+    const loadRequirementsForStandards = async (standardIds) => {
+        if (standardIds.length === 0) {
             setAvailableRequirements([]);
             setSelectedRequirementsForCampaign([]);
+            return;
+        }
+        try {
+            const reqRes = await getRequirements();
+            const filteredReqs = Array.isArray(reqRes.data) ? reqRes.data.filter(r => standardIds.includes(r.standardId)) : [];
+            setAvailableRequirements(filteredReqs);
+            setSelectedRequirementsForCampaign(prev => prev.filter(sr => filteredReqs.some(r => r.id === sr.requirement_id)));
+        } catch (err) {
+            console.error("Error fetching requirements for modal:", err);
+            setAvailableRequirements([]);
         }
     };
 
+    const handleStandardChangeForModal = async (standardId) => {
+        setSelectedStandard(standardId);
+        const remaining = additionalStandards.filter(id => id !== standardId);
+        setAdditionalStandards(remaining);
+        await loadRequirementsForStandards([standardId, ...remaining].filter(Boolean));
+    };
+
+    const handleAdditionalStandardToggle = async (standardId, checked) => {
+        const next = checked ? [...additionalStandards, standardId] : additionalStandards.filter(id => id !== standardId);
+        setAdditionalStandards(next);
+        await loadRequirementsForStandards([selectedStandard, ...next].filter(Boolean));
+    };
+
     const handleRequirementSelectionChange = (reqId, controlIdRef) => {
         setSelectedRequirementsForCampaign(prev => {
             const existing = prev.find(r => r.requirement_id === reqId);
@@ -172,6 +209,7 @@ function Campaigns() {
             name: newCampaignName.trim(),
             description: newCampaignDescription.trim(),
             standard_id: selectedStandard,
+            standard_ids: [selectedStandard, ...additionalStandards],
             start_date: startDate || null,
             end_date: endDate || null,
 
@@ -185,6 +223,7 @@ function Campaigns() {
             setNewCampaignName('');
             setNewCampaignDescription('');
             setSelectedStandard('');
+            setAdditionalStandards([]);
             setStartDate('');
             setEndDate('');
             setSelectedRequirementsForCampaign([]);
@@ -215,6 +254,47 @@ function Campaigns() {
             <Tabs activeKey={activeTabKey} onSelect={(k) => setActiveTabKey(k)} id="campaigns-tabs" className="mb-3 nav-line-tabs">
 
                 <Tab eventKey="existing" title={<><FaListUl className="me-1" />Existing Campaigns</>}>
+                    <Row className="g-2 mb-3">
+                        <Col md={2}>
+                            <Form.Select size="sm" name="status" value={listFilters.status} onChange={handleListFilterChange}>
+                                <option value="">All Statuses</option>
+                                {['Draft', 'Active', 'In Progress', 'Pending Review', 'Completed', 'Archived'].map(status => (
+                                    <option key={status} value={status}>{status}</option>
+                                ))}
+                            </Form.Select>
+                        </Col>
+                        <Col md={3}>
+                            <Form.Select size="sm" name="standard_id" value={listFilters.standard_id} onChange={handleListFilterChange}>
+                                <option value="">All Standards</option>
+                                {allStandards.map(std => (
+                                    <option key={std.id} value={std.id}>{std.name}</option>
+                                ))}
+                            </Form.Select>
+                        </Col>
+                        <Col md={2}>
+                            <Form.Control size="sm" type="date" name="from" value={listFilters.from} onChange={handleListFilterChange} title="Period from" />
+                        </Col>
+                        <Col md={2}>
+                            <Form.Control size="sm" type="date" name="to" value={listFilters.to} onChange={handleListFilterChange} title="Period to" />
+                        </Col>
+                        <Col md={2}>
+                            <Form.Select size="sm" name="sort_by" value={listFilters.sort_by} onChange={handleListFilterChange}>
+                                <option value="created_at">Created</option>
+                                <option value="name">Name</option>
+                                <option value="status">Status</option>
+                                <option value="start_date">Start Date</option>
+                                <option value="end_date">End Date</option>
+                                <option value="requirements_count">Requirements</option>
+                                <option value="overdue_tasks">Overdue Tasks</option>
+                            </Form.Select>
+                        </Col>
+                        <Col md={1}>
+                            <Form.Select size="sm" name="sort_order" value={listFilters.sort_order} onChange={handleListFilterChange}>
+                                <option value="desc">Desc</option>
+                                <option value="asc">Asc</option>
+                            </Form.Select>
+                        </Col>
+                    </Row>
                     {loadingCampaigns ? (
                         <div className="text-center mt-5"><Spinner animation="border" /> Loading campaigns...</div>
                     ) : campaigns.length === 0 ? (
@@ -226,7 +306,7 @@ function Campaigns() {
                                     <Card className="h-100 shadow-sm campaign-card">
                                         <Card.Header className="d-flex justify-content-between align-items-center">
                                             <FaShieldAlt className="me-2 text-primary" />
-                                            <span className="fw-bold">{camp.standard_name || getStandardName(camp.standard_id)}</span>
+                                            <span className="fw-bold">{camp.standards?.length ? camp.standards.map(std => std.short_name || std.name).join(' + ') : (camp.standard_name || getStandardName(camp.standard_id))}</span>
                                             <Badge bg={getStatusColor(camp.status)} pill>{camp.status}</Badge>
                                         </Card.Header>
                                         <Card.Body as={Link} to={`/campaigns/${camp.id}`} className="text-decoration-none text-dark stretched-link">
@@ -259,6 +339,13 @@ function Campaigns() {
                             ))}
                         </Row>
                     )}
+                    {totalPages > 1 && (
+                        <Pagination className="justify-content-center mt-4">
+                            <Pagination.Prev onClick={() => setCurrentPage(currentPage - 1)} disabled={currentPage === 1} />
+                            <Pagination.Item active>{currentPage} / {totalPages}</Pagination.Item>
+                            <Pagination.Next onClick={() => setCurrentPage(currentPage + 1)} disabled={currentPage === totalPages} />
+                        </Pagination>
+                    )}
 
                 </Tab>
 
@@ -286,6 +373,25 @@ function Campaigns() {
                                         </FloatingLabel>
                                     </Col>
                                 </Row>
+                                {selectedStandard && allStandards.length > 1 && (
+                                    <Form.Group className="mb-3" controlId="campaignAdditionalStandards">
+                                        <Form.Label>Additional Standards</Form.Label>
+                                        <div>
+                                            {allStandards.filter(std => std.id !== selectedStandard).map(std => (
+                                                <Form.Check
+                                                    inline
+                                                    key={std.id}
+                                                    type="checkbox"
+                                                    id={`additional-standard-${std.id}`}
+                                                    label={std.shortName || std.name}
+                                                    checked={additionalStandards.includes(std.id)}
+                                                    onChange={(e) => handleAdditionalStandardToggle(std.id, e.target.checked)}
+                                                />
+                                            ))}
+                                        </div>
+                                        <Form.Text muted>Master tasks mapped to requirements of several standards become one task instance that covers all of them.</Form.Text>
+                                    </Form.Group>
+                                )}
                                 <Row className="mb-3">
                                     <Col md={6}>
                                         <FloatingLabel controlId="campaignStartDate" label="Start Date">
@@ -333,7 +439,7 @@ function Campaigns() {
 
             <Modal show={showRequirementsModal} onHide={() => setShowRequirementsModal(false)} size="lg">
                 <Modal.Header closeButton>
-                    <Modal.Title>Select Requirements for Standard: {getStandardName(selectedStandard)}</Modal.Title>
+                    <Modal.Title>Select Requirements for: {[selectedStandard, ...additionalStandards].map(getStandardName).join(', ')}</Modal.Title>
                 </Modal.Header>
                 <Modal.Body style={{ maxHeight: '60vh', overflowY: 'auto' }}>
                     {availableRequirements.length === 0 && <p>No requirements found for this standard, or standard not selected.</p>}
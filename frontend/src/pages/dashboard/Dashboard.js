@@ -1,6 +1,6 @@
 import React, { useState, useEffect, useCallback, useMemo, useRef } from 'react';
 import { Link } from 'react-router-dom';
-import { getUserCampaignTasks, getCampaigns, getUsers, getUserFeed } from '../../services/api'; 
+import { getUserCampaignTasks, getAllCampaigns, getUsers, getUserFeed } from '../../services/api'; 
 import { Row, Col, Card, Spinner, Alert, ListGroup, Badge, ProgressBar } from 'react-bootstrap';
 import { FaTachometerAlt, FaBullhorn, FaComment } from 'react-icons/fa';
 
@@ -44,7 +44,7 @@ function Dashboard() {
         try {
             
             const tasksPromise = getUserCampaignTasks(loggedInUserId, "owner", "Active"); 
-            const campaignsPromise = getCampaigns("Active"); 
+            const campaignsPromise = getAllCampaigns({ status: 'Active' });
             const feedPromise = getUserFeed({ limit: 7 }); 
             const usersPromise = getUsers();
 
@@ -60,7 +60,7 @@ function Dashboard() {
             }
 
             if (campaignsResponse.status === 'fulfilled' && campaignsResponse.value.data) {
-                setActiveCampaigns(Array.isArray(campaignsResponse.value.data) ? campaignsResponse.value.data : []);
+                setActiveCampaigns(Array.isArray(campaignsResponse.value.data.campaigns) ? campaignsResponse.value.data.campaigns : []);
             } else {
                 console.warn("Failed to fetch campaigns or no campaigns data:", campaignsResponse.reason || "No data");
                 setActiveCampaigns([]);
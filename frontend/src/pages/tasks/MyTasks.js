@@ -1,6 +1,6 @@
 import React, { useState, useEffect, useCallback, useMemo, useRef } from 'react';
 import { Link } from 'react-router-dom';
-import { getUserCampaignTasks, getUsers, getCampaigns } from '../../services/api';
+import { getUserCampaignTasks, getUsers, getAllCampaigns } from '../../services/api';
 import ListGroup from 'react-bootstrap/ListGroup';
 import Alert from 'react-bootstrap/Alert';
 import Badge from 'react-bootstrap/Badge';
@@ -77,7 +77,7 @@ function MyTasks() {
         try {
             const tasksPromise = getUserCampaignTasks(loggedInUserId, "owner", "Active");
             const usersPromise = getUsers();
-            const campaignsPromise = getCampaigns("Active");
+            const campaignsPromise = getAllCampaigns({ status: 'Active' });
 
             const [tasksResponse, usersResponse, campaignsResponse] = await Promise.all([tasksPromise, usersPromise, campaignsPromise]);
 
@@ -90,7 +90,7 @@ function MyTasks() {
                 setUsers([]);
             }
             if (campaignsResponse.data) {
-                setAllCampaigns(Array.isArray(campaignsResponse.data) ? campaignsResponse.data : []);
+                setAllCampaigns(Array.isArray(campaignsResponse.data.campaigns) ? campaignsResponse.data.campaigns : []);
             } else {
                 console.warn("No data in campaigns response or response format unexpected", campaignsResponse);
                 setAllCampaigns([]);
@@ -32,7 +32,8 @@ import { RightPanelContext } from '../../App';
 function TeamForm({ team, onSuccess, onClose }) {
     const [formData, setFormData] = useState({ 
         name: team?.name || '', 
-        description: team?.description || '' 
+        description: team?.description || '',
+        assignment_strategy: team?.assignment_strategy || 'lead'
     });
     const [error, setError] = useState('');
     const [loading, setLoading] = useState(false);
@@ -93,6 +94,20 @@ function TeamForm({ team, onSuccess, onClose }) {
                         onChange={handleInputChange} 
                     />
                 </Form.Group>
+
+                <Form.Group className="mb-3" controlId="teamAssignmentStrategy">
+                    <Form.Label>Assignment Strategy</Form.Label>
+                    <Form.Select
+                        name="assignment_strategy"
+                        value={formData.assignment_strategy}
+                        onChange={handleInputChange}
+                    >
+                        <option value="lead">Team lead</option>
+                        <option value="round_robin">Round-robin</option>
+                        <option value="least_loaded">Least loaded</option>
+                    </Form.Select>
+                    <Form.Text muted>Who gets task instances generated for this team when the master task names no assignee.</Form.Text>
+                </Form.Group>
                 
                 <div className="d-flex justify-content-end gap-2">
                     <Button variant="secondary" onClick={onClose}>
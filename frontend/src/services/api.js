@@ -61,14 +61,28 @@ export const getSystemTypeDefinitions = async () => {
 };
 
 
-export const getCampaigns = async (status, includeTaskSummary = false) => {
-    let url = `/campaigns`;
-    const params = new URLSearchParams();
-    if (status) { // Corrected: append status to params
-        url += `?status=${encodeURIComponent(status)}`;
+// Returns { campaigns, pagination }. params may hold status, standard_id,
+// from, to, sort_by, sort_order, page and limit.
+export const getCampaigns = async (params = {}) => {
+    const response = await apiClient.get('/campaigns', { params });
+    return response;
+};
+
+// Like getCampaigns, but walks every page so callers that look campaigns up
+// by id see all of them. Returns the first response with data.campaigns
+// holding the campaigns of all pages.
+export const getAllCampaigns = async (params = {}) => {
+    const query = { ...params, limit: 100, page: 1 };
+    const response = await getCampaigns(query);
+    const campaigns = Array.isArray(response.data?.campaigns) ? [...response.data.campaigns] : [];
+    const totalPages = response.data?.pagination?.total_pages || 1;
+    for (let page = 2; page <= totalPages; page++) {
+        const next = await getCampaigns({ ...query, page });
+        if (Array.isArray(next.data?.campaigns)) {
+            campaigns.push(...next.data.campaigns);
+        }
     }
-    const response = await apiClient.get(url);
-    return response;
+    return { ...response, data: { ...response.data, campaigns } };
 };
 
 export const getUserFeed = async (params) => {
@@ -236,6 +250,31 @@ export const updateCampaign = async (campaignId, campaignData) => {
     return response;
 };
 
+export const transitionCampaign = async (campaignId, status) => {
+    const response = await apiClient.post(`/campaigns/${campaignId}/transitions`, { status });
+    return response;
+};
+
+export const signOffCampaign = async (campaignId, comment) => {
+    const response = await apiClient.post(`/campaigns/${campaignId}/sign-off`, { comment });
+    return response;
+};
+
+export const cloneCampaign = async (campaignId, cloneData) => {
+    const response = await apiClient.post(`/campaigns/${campaignId}/clone`, cloneData);
+    return response;
+};
+
+export const getCampaignComparison = async (campaignId) => {
+    const response = await apiClient.get(`/campaigns/${campaignId}/comparison`);
+    return response;
+};
+
+export const getCampaignCompliance = async (campaignId) => {
+    const response = await apiClient.get(`/campaigns/${campaignId}/compliance`);
+    return response;
+};
+
 export const deleteCampaign = async (campaignId) => {
     const response = await apiClient.delete(`/campaigns/${campaignId}`);
     return response;
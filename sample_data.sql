@@ -2,6 +2,12 @@
 
 -- Clear existing data to prevent duplicate key errors and ensure idempotency
 -- Order matters due to foreign key constraints
+DELETE FROM task_execution_logs;
+DELETE FROM config_drift_events;
+DELETE FROM config_snapshot_resources;
+DELETE FROM config_snapshots;
+DELETE FROM vulnerability_findings;
+DELETE FROM vulnerability_reports;
 DELETE FROM campaign_task_instance_results;
 DELETE FROM task_comments;
 DELETE FROM evidence;
@@ -16,6 +22,7 @@ DELETE FROM requirements;
 DELETE FROM compliance_standards;
 DELETE FROM connected_systems;
 DELETE FROM system_type_definitions; -- Added this
+DELETE FROM registered_plugin_versions;
 DELETE FROM registered_plugins; -- Added this
 DELETE FROM documents;
 DELETE FROM team_members; -- Added this
@@ -153,10 +160,51 @@ INSERT INTO system_type_definitions (value, label, description, icon_name, color
 ]'::jsonb),
 ('n8n', 'n8n', 'Workflow Automation Platform', 'FaCogs', '#FF6B6B', 'Automation', '[
     {"name":"baseUrl","label":"n8n Base URL","type":"url","placeholder":"http://localhost:5678","required":true,"sensitive":false,"options":null,"helpText":"The URL where your n8n instance is running."},
-    {"name":"apiKey","label":"API Key (Optional)","type":"password","placeholder":"your-n8n-api-key","required":false,"sensitive":true,"options":null,"helpText":"API key for authentication (if enabled in n8n)."}
+    {"name":"apiKey","label":"API Key (Optional)","type":"password","placeholder":"your-n8n-api-key","required":false,"sensitive":true,"options":null,"helpText":"n8n public API key, sent as X-N8N-API-KEY. Required to wait for workflow executions to finish."}
 ]'::jsonb),
 ('temporal', 'Temporal', 'Temporal Workflow Platform', 'FaClock', '#6366F1', 'Automation', '[
-    {"name":"serverUrl","label":"Temporal Server URL","type":"url","placeholder":"http://localhost:8080","required":true,"sensitive":false,"options":null,"helpText":"The URL where your Temporal REST API is running (typically port 8080, not 7233)."},
-    {"name":"namespace","label":"Namespace","type":"text","placeholder":"default","required":true,"sensitive":false,"options":null,"helpText":"Temporal namespace for workflow execution."}
+    {"name":"hostPort","label":"Frontend Host:Port","type":"text","placeholder":"localhost:7233","required":true,"sensitive":false,"options":null,"helpText":"gRPC address of the Temporal frontend service (typically port 7233)."},
+    {"name":"namespace","label":"Namespace","type":"text","placeholder":"default","required":true,"sensitive":false,"options":null,"helpText":"Temporal namespace for workflow execution."},
+    {"name":"apiKey","label":"API Key (Optional)","type":"password","placeholder":null,"required":false,"sensitive":true,"options":null,"helpText":"Temporal Cloud API key. Implies TLS."},
+    {"name":"tls","label":"Use TLS","type":"select","placeholder":"false","required":false,"sensitive":false,"options":["false","true"],"helpText":"Connect to the frontend over TLS."},
+    {"name":"tlsCaCert","label":"CA Certificate (PEM, Optional)","type":"textarea","placeholder":null,"required":false,"sensitive":false,"options":null,"helpText":"CA used to verify the frontend certificate. Defaults to the system roots."},
+    {"name":"tlsClientCert","label":"Client Certificate (PEM, Optional)","type":"textarea","placeholder":null,"required":false,"sensitive":false,"options":null,"helpText":"Client certificate for mTLS."},
+    {"name":"tlsClientKey","label":"Client Key (PEM, Optional)","type":"textarea","placeholder":null,"required":false,"sensitive":true,"options":null,"helpText":"Private key for the mTLS client certificate."},
+    {"name":"tlsServerName","label":"TLS Server Name (Optional)","type":"text","placeholder":null,"required":false,"sensitive":false,"options":null,"helpText":"Overrides the server name used to verify the frontend certificate."},
+    {"name":"uiUrl","label":"Web UI URL (Optional)","type":"url","placeholder":"http://localhost:8233","required":false,"sensitive":false,"options":null,"helpText":"Base URL of the Temporal Web UI, used to link results to workflow runs."}
+]'::jsonb),
+('kubernetes', 'Kubernetes', 'Kubernetes Cluster', 'FaDharmachakra', '#326CE5', 'Cloud', '[
+    {"name":"apiServerUrl","label":"API Server URL","type":"url","placeholder":"https://k8s.example.com:6443","required":false,"sensitive":false,"options":null,"helpText":"Kubernetes API server endpoint. Not needed when a kubeconfig is provided."},
+    {"name":"bearerToken","label":"Bearer Token","type":"password","placeholder":null,"required":false,"sensitive":true,"options":null,"helpText":"Token of a service account with read access to pods, namespaces, network policies and RBAC objects."},
+    {"name":"caCert","label":"CA Certificate (PEM, Optional)","type":"textarea","placeholder":null,"required":false,"sensitive":false,"options":null,"helpText":"CA used to verify the API server certificate."},
+    {"name":"kubeconfig","label":"Kubeconfig (Optional)","type":"textarea","placeholder":null,"required":false,"sensitive":true,"options":null,"helpText":"Full kubeconfig; its current context is used instead of the fields above."},
+    {"name":"insecureSkipTlsVerify","label":"Skip TLS Verification","type":"select","placeholder":"false","required":false,"sensitive":false,"options":["false","true"],"helpText":"Disable API server certificate verification. Not recommended."}
+]'::jsonb),
+('ldap', 'LDAP / Active Directory', 'LDAP or Active Directory directory service', 'FaUsers', '#0F6CBD', 'Identity', '[
+    {"name":"url","label":"Server URL","type":"url","placeholder":"ldaps://dc01.example.com:636","required":true,"sensitive":false,"options":null,"helpText":"ldap:// or ldaps:// URL of the directory server."},
+    {"name":"bindDn","label":"Bind DN","type":"text","placeholder":"CN=svc-audit,OU=Service Accounts,DC=example,DC=com","required":true,"sensitive":false,"options":null,"helpText":"Read-only account used to query the directory."},
+    {"name":"bindPassword","label":"Bind Password","type":"password","placeholder":null,"required":true,"sensitive":true,"options":null,"helpText":null},
+    {"name":"baseDn","label":"Base DN","type":"text","placeholder":"DC=example,DC=com","required":true,"sensitive":false,"options":null,"helpText":"Search base for users and groups."},
+    {"name":"directoryType","label":"Directory Type","type":"select","placeholder":"active_directory","required":true,"sensitive":false,"options":["active_directory","openldap"],"helpText":"Selects the default user filter and account attributes."},
+    {"name":"startTls","label":"Use StartTLS","type":"select","placeholder":"false","required":false,"sensitive":false,"options":["false","true"],"helpText":"Upgrade an ldap:// connection with StartTLS."},
+    {"name":"caCert","label":"CA Certificate (PEM, Optional)","type":"textarea","placeholder":null,"required":false,"sensitive":false,"options":null,"helpText":"CA used to verify the server certificate. Defaults to the system roots."},
+    {"name":"insecureSkipVerify","label":"Skip TLS Verification","type":"select","placeholder":"false","required":false,"sensitive":false,"options":["false","true"],"helpText":"Disable server certificate verification. Not recommended."},
+    {"name":"userFilter","label":"User Filter (Optional)","type":"text","placeholder":"(&(objectClass=user)(objectCategory=person))","required":false,"sensitive":false,"options":null,"helpText":"Overrides the default filter used to find user accounts."}
+]'::jsonb),
+('server', 'Server / Host', 'Network-reachable server or host', 'FaServer', '#4B5563', 'Infrastructure', '[
+    {"name":"hostAddress","label":"Host Address","type":"text","placeholder":"bastion.example.com","required":true,"sensitive":false,"options":null,"helpText":"Hostname or IP address used by network checks such as the port scanner and SSH hardening checker."},
+    {"name":"port","label":"SSH Port (Optional)","type":"number","placeholder":"22","required":false,"sensitive":false,"options":null,"helpText":"SSH port, when not 22."}
+]'::jsonb),
+('vulnerability_scanner', 'Vulnerability Scanner', 'Uploaded Scanner Reports (Trivy, Grype, Nessus, SARIF)', 'FaBug', '#B91C1C', 'Security', '[
+    {"name":"scannerType","label":"Scanner","type":"select","placeholder":"","required":true,"sensitive":false,"options":["trivy","grype","nessus","sarif"],"helpText":"Report format the scanner produces. Reports are uploaded to each task with POST /campaign-task-instances/:id/vulnerability-reports."},
+    {"name":"pipelineUrl","label":"Pipeline URL (Optional)","type":"text","placeholder":"https://ci.example.com/shop/api","required":false,"sensitive":false,"options":null,"helpText":"CI job that runs the scan, for reference."}
+]'::jsonb),
+('prometheus', 'Prometheus', 'Prometheus-compatible metrics API (Prometheus, Thanos, Mimir, VictoriaMetrics)', 'FaChartArea', '#E6522C', 'Infrastructure', '[
+    {"name":"baseUrl","label":"Base URL","type":"url","placeholder":"https://prometheus.example.com","required":true,"sensitive":false,"options":null,"helpText":"URL that serves /api/v1/query. Include any path prefix, e.g. https://mimir.example.com/prometheus."},
+    {"name":"bearerToken","label":"Bearer Token (Optional)","type":"password","placeholder":null,"required":false,"sensitive":true,"options":null,"helpText":"Sent as an Authorization: Bearer header. Takes precedence over basic auth."},
+    {"name":"username","label":"Username (Optional)","type":"text","placeholder":null,"required":false,"sensitive":false,"options":null,"helpText":"Basic auth username."},
+    {"name":"password","label":"Password (Optional)","type":"password","placeholder":null,"required":false,"sensitive":true,"options":null,"helpText":"Basic auth password."},
+    {"name":"tenantId","label":"Tenant ID (Optional)","type":"text","placeholder":"team-a","required":false,"sensitive":false,"options":null,"helpText":"Sent as X-Scope-OrgID for multi-tenant Mimir, Cortex or Loki."},
+    {"name":"insecureSkipTlsVerify","label":"Skip TLS Verification","type":"select","placeholder":"false","required":false,"sensitive":false,"options":["false","true"],"helpText":"Disable certificate verification. Not recommended."}
 ]'::jsonb)
 ON CONFLICT (value) DO NOTHING;